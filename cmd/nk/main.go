@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+
 	"github.com/sim4gh/nikte-cli/internal/cli"
 	"github.com/sim4gh/nikte-cli/internal/config"
 )
@@ -10,5 +12,5 @@ func main() {
 	config.Load()
 
 	// Execute CLI
-	cli.Execute()
+	os.Exit(cli.Execute())
 }
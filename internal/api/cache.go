@@ -0,0 +1,83 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+)
+
+// cacheablePrefixes are the list and get endpoints whose GET responses are
+// cached by ETag and revalidated with If-None-Match.
+var cacheablePrefixes = []string{"/shorts", "/screenshots", "/files", "/links", "/shares"}
+
+// cacheEntry is one cached response body and the ETag it was served with.
+type cacheEntry struct {
+	ETag string `json:"etag"`
+	Body []byte `json:"body"`
+}
+
+func cacheable(path string) bool {
+	for _, prefix := range cacheablePrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") || strings.HasPrefix(path, prefix+"?") {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheFile returns where the response for url is cached. Entries are kept
+// per profile so accounts never see each other's data.
+func cacheFile(url string) (string, bool) {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(config.ActiveProfile() + "\x00" + url))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json"), true
+}
+
+// loadCached returns the entry in file, or nil if there is none.
+func loadCached(file string) *cacheEntry {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil || e.ETag == "" {
+		return nil
+	}
+	return &e
+}
+
+// storeCached saves body under etag. Failures are ignored: the cache only
+// saves bandwidth.
+func storeCached(file, etag string, body []byte) {
+	data, err := json.Marshal(cacheEntry{ETag: etag, Body: body})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		os.Remove(tmp)
+	}
+}
+
+// ClearCache deletes every cached response, e.g. on logout.
+func ClearCache() error {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
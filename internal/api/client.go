@@ -2,15 +2,21 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/sim4gh/nikte-cli/internal/auth"
 	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/retry"
+	"github.com/sim4gh/nikte-cli/internal/transport"
 )
 
 // Response represents an API response
@@ -18,6 +24,9 @@ type Response struct {
 	StatusCode int
 	Headers    http.Header
 	Body       json.RawMessage
+	RateLimit  *RateLimit // nil if the server sent no X-RateLimit-* headers
+	RequestID  string     // the server's request ID, or the X-Request-ID we sent
+	Stale      bool       // served from the cache because the API was unreachable
 }
 
 // RequestOptions configures an API request
@@ -28,7 +37,8 @@ type RequestOptions struct {
 	RequireAuth bool
 }
 
-// DefaultClient is a pre-configured HTTP client
+// DefaultClient is a pre-configured HTTP client. Requests use the shared
+// proxy-aware transport unless its Transport is set.
 var DefaultClient = &http.Client{
 	Timeout: 60 * time.Second,
 }
@@ -36,8 +46,107 @@ var DefaultClient = &http.Client{
 // DefaultBaseURL is the default API base URL
 const DefaultBaseURL = "https://auth.nikte.co"
 
+// baseURLOverride is set with SetBaseURL.
+var baseURLOverride string
+
+// SetBaseURL sends requests to url instead of the configured base URL, e.g.
+// an httptest server in unit tests. Passing "" restores the config. It
+// returns the previous override, for putting it back.
+func SetBaseURL(url string) (previous string) {
+	previous, baseURLOverride = baseURLOverride, url
+	return previous
+}
+
+// SetClient replaces DefaultClient. Its Timeout is ignored in favour of the
+// http_timeout and download_timeout settings; a nil Transport uses the shared
+// one.
+func SetClient(c *http.Client) {
+	DefaultClient = c
+}
+
 // Request makes an authenticated API request
 func Request(path string, opts *RequestOptions) (*Response, error) {
+	return RequestContext(context.Background(), path, opts)
+}
+
+// RequestContext is Request with a context. Cancelling ctx aborts the request
+// in flight and any wait before a retry.
+func RequestContext(ctx context.Context, path string, opts *RequestOptions) (*Response, error) {
+	p, err := prepare(ctx, path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Revalidate cached list/get responses instead of refetching them.
+	var cached *cacheEntry
+	var extra map[string]string
+	cachePath, useCache := "", false
+	if p.method == "GET" && p.token != "" && cacheable(path) {
+		if cachePath, useCache = cacheFile(p.url); useCache {
+			if cached = loadCached(cachePath); cached != nil {
+				extra = map[string]string{"If-None-Match": cached.ETag}
+			}
+		}
+	}
+
+	resp, err := p.send(ctx, config.GetTuning().HTTPTimeout, extra)
+	if errors.Is(err, ErrOffline) && cached != nil {
+		// Offline: let read-only commands work from what was last fetched.
+		return &Response{StatusCode: http.StatusOK, Body: cached.Body, Stale: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if useCache {
+		switch {
+		case resp.StatusCode == http.StatusNotModified && cached != nil:
+			return &Response{
+				StatusCode: http.StatusOK,
+				Headers:    resp.Header,
+				Body:       cached.Body,
+				RateLimit:  parseRateLimit(resp.Header),
+				RequestID:  transport.RequestID(resp),
+			}, nil
+		case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+			storeCached(cachePath, resp.Header.Get("ETag"), body)
+		case cached != nil:
+			os.Remove(cachePath)
+		}
+	}
+
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		Body:       body,
+		RateLimit:  parseRateLimit(resp.Header),
+		RequestID:  transport.RequestID(resp),
+	}, nil
+}
+
+// prepared is a request resolved against the config: where it goes, its
+// encoded body and the bearer token to send.
+type prepared struct {
+	method  string
+	url     string
+	baseURL string
+	body    []byte
+	gzipped []byte // body compressed, if worth sending that way
+	form    *form  // multipart body, sent instead of body
+	headers map[string]string
+	token   string
+}
+
+// prepare resolves path and opts into a request, refreshing the session
+// token first if it has expired.
+func prepare(ctx context.Context, path string, opts *RequestOptions) (*prepared, error) {
 	if opts == nil {
 		opts = &RequestOptions{}
 	}
@@ -55,23 +164,29 @@ func Request(path string, opts *RequestOptions) (*Response, error) {
 	}
 
 	cfg := config.Get()
+	apiToken := auth.APIToken()
 	baseURL := DefaultBaseURL
-	if cfg != nil && cfg.BaseURL != "" {
+	if baseURLOverride != "" {
+		baseURL = baseURLOverride
+	} else if cfg != nil && cfg.BaseURL != "" {
 		baseURL = cfg.BaseURL
-	} else if requireAuth {
+	} else if requireAuth && apiToken == "" {
 		return nil, errors.New("not configured. Please run \"nk auth login\" first")
 	}
 
-	// Get valid token if auth is required
+	// Get valid token if auth is required. A personal access token bypasses the
+	// device-flow session entirely (no refresh needed).
 	var idToken string
-	if requireAuth {
+	if requireAuth && apiToken != "" {
+		idToken = apiToken
+	} else if requireAuth {
 		if cfg == nil || cfg.IDToken == "" {
 			return nil, errors.New("not authenticated. Please run \"nk auth login\" first")
 		}
 
 		// Check if token needs refresh
 		if auth.IsTokenExpired(cfg.IDToken) {
-			tokens, err := auth.RefreshTokens()
+			tokens, err := auth.RefreshTokens(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("authentication expired: %w", err)
 			}
@@ -81,99 +196,246 @@ func Request(path string, opts *RequestOptions) (*Response, error) {
 		}
 	}
 
-	// Build URL
-	url := baseURL + path
-
 	// Prepare request body
-	var bodyReader io.Reader
+	var bodyBytes []byte
 	if opts.Body != nil {
-		bodyBytes, err := json.Marshal(opts.Body)
+		var err error
+		bodyBytes, err = json.Marshal(opts.Body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		bodyReader = bytes.NewReader(bodyBytes)
 	}
 
-	// Create request
-	req, err := http.NewRequest(method, url, bodyReader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	return &prepared{
+		method:  method,
+		url:     baseURL + path,
+		baseURL: baseURL,
+		body:    bodyBytes,
+		gzipped: compressBody(bodyBytes),
+		headers: opts.Headers,
+		token:   idToken,
+	}, nil
+}
+
+// send executes p with the given client timeout, retrying transient failures
+// and waiting out rate limits, and returns the final response for the caller
+// to read and close. A 429 that outlasts the retries is returned as a
+// *RateLimitError.
+func (p *prepared) send(ctx context.Context, timeout time.Duration, extra map[string]string) (*http.Response, error) {
+	tuning := config.GetTuning()
+	client := *DefaultClient
+	client.Timeout = timeout
+	if client.Transport == nil {
+		client.Transport = transport.Transport()
 	}
 
-	// Set default headers
-	req.Header.Set("Content-Type", "application/json")
+	// Execute request, retrying transient failures
+	var resp *http.Response
+	waits := 0
+	for attempt := 0; ; attempt++ {
+		var bodyReader io.Reader
+		contentType := "application/json"
+		switch {
+		case p.form != nil:
+			bodyReader, contentType = p.form.open()
+		case p.gzipped != nil:
+			bodyReader = bytes.NewReader(p.gzipped)
+		case p.body != nil:
+			bodyReader = bytes.NewReader(p.body)
+		}
 
-	// Set custom headers
-	for k, v := range opts.Headers {
-		req.Header.Set(k, v)
-	}
+		// Create request
+		req, err := http.NewRequestWithContext(ctx, p.method, p.url, bodyReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
 
-	// Set authorization header
-	if idToken != "" {
-		req.Header.Set("Authorization", "Bearer "+idToken)
-	}
+		// Set default headers
+		req.Header.Set("Content-Type", contentType)
+		if p.gzipped != nil {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
 
-	// Execute request
-	resp, err := DefaultClient.Do(req)
-	if err != nil {
-		if err.Error() == "connection refused" || err.Error() == "dial tcp" {
-			return nil, fmt.Errorf("unable to connect to API at %s", baseURL)
+		// Set custom headers
+		for k, v := range p.headers {
+			req.Header.Set(k, v)
 		}
-		return nil, err
+		for k, v := range extra {
+			req.Header.Set(k, v)
+		}
+
+		// Set authorization header
+		if p.token != "" {
+			req.Header.Set("Authorization", "Bearer "+p.token)
+		}
+
+		for _, hook := range requestHooks {
+			hook(req)
+		}
+		start := time.Now()
+		resp, err = client.Do(req)
+		for _, hook := range responseHooks {
+			hook(req, resp, err, time.Since(start))
+		}
+		if ctx.Err() != nil {
+			if resp != nil {
+				transport.DrainAndClose(resp.Body)
+			}
+			return nil, ctx.Err()
+		}
+		if err == nil && resp.StatusCode == http.StatusUnsupportedMediaType && p.gzipped != nil {
+			// The API doesn't take compressed bodies; resend this one plain
+			// and stop compressing.
+			transport.DrainAndClose(resp.Body)
+			gzipUnsupported.Store(true)
+			p.gzipped = nil
+			continue
+		}
+		if err == nil && resp.StatusCode == http.StatusTooManyRequests &&
+			RateLimitWait != nil && waits < maxRateLimitWaits && p.rewind() {
+			transport.DrainAndClose(resp.Body)
+			waits++
+			wait := rateLimitDelay(resp)
+			if wait == 0 {
+				wait = retry.Backoff(tuning.RetryBackoff, waits-1)
+			}
+			if err := RateLimitWait(ctx, wait); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if attempt < tuning.RetryCount && shouldRetry(p.method, resp, err) && p.rewind() {
+			if wait, ok := retry.Delay(resp, tuning.RetryBackoff, attempt); ok {
+				if resp != nil {
+					transport.DrainAndClose(resp.Body)
+				}
+				if err := retry.Sleep(ctx, wait); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		if err != nil {
+			if unreachable(err) {
+				return nil, &OfflineError{BaseURL: p.baseURL, Err: err}
+			}
+			return nil, err
+		}
+		break
 	}
-	defer resp.Body.Close()
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		transport.DrainAndClose(resp.Body)
+		return nil, &RateLimitError{RateLimit: parseRateLimit(resp.Header), Wait: rateLimitDelay(resp)}
 	}
+	return resp, nil
+}
 
-	return &Response{
-		StatusCode: resp.StatusCode,
-		Headers:    resp.Header,
-		Body:       body,
-	}, nil
+// rewind readies p's body to be sent again, reporting false if it can't be:
+// a multipart body whose files aren't seekable.
+func (p *prepared) rewind() bool {
+	return p.form == nil || p.form.rewind()
+}
+
+// shouldRetry reports whether a request failed transiently. Network errors and
+// 5xx errors are only retried for idempotent methods, since a POST may have
+// been applied before the connection dropped; 429 is always safe to retry.
+// Failing to connect at all (DNS, refused) isn't retried: it rarely clears up
+// within the backoff window and would only delay the error.
+func shouldRetry(method string, resp *http.Response, err error) bool {
+	idempotent := method == "GET" || method == "HEAD" || method == "PUT" || method == "DELETE"
+	if err != nil {
+		// Unreachable hosts and proxies won't recover within the backoff.
+		return idempotent && !unreachable(err)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
 }
 
 // Get makes a GET request
 func Get(path string) (*Response, error) {
-	return Request(path, &RequestOptions{Method: "GET", RequireAuth: true})
+	return GetContext(context.Background(), path)
+}
+
+// GetContext makes a GET request with a context
+func GetContext(ctx context.Context, path string) (*Response, error) {
+	return RequestContext(ctx, path, &RequestOptions{Method: "GET", RequireAuth: true})
 }
 
 // Post makes a POST request
 func Post(path string, body interface{}) (*Response, error) {
-	return Request(path, &RequestOptions{Method: "POST", Body: body, RequireAuth: true})
+	return PostContext(context.Background(), path, body)
+}
+
+// PostContext makes a POST request with a context
+func PostContext(ctx context.Context, path string, body interface{}) (*Response, error) {
+	return RequestContext(ctx, path, &RequestOptions{Method: "POST", Body: body, RequireAuth: true})
 }
 
 // Put makes a PUT request
 func Put(path string, body interface{}) (*Response, error) {
-	return Request(path, &RequestOptions{Method: "PUT", Body: body, RequireAuth: true})
+	return PutContext(context.Background(), path, body)
+}
+
+// PutContext makes a PUT request with a context
+func PutContext(ctx context.Context, path string, body interface{}) (*Response, error) {
+	return RequestContext(ctx, path, &RequestOptions{Method: "PUT", Body: body, RequireAuth: true})
 }
 
 // Patch makes a PATCH request
 func Patch(path string, body interface{}) (*Response, error) {
-	return Request(path, &RequestOptions{Method: "PATCH", Body: body, RequireAuth: true})
+	return PatchContext(context.Background(), path, body)
+}
+
+// PatchContext makes a PATCH request with a context
+func PatchContext(ctx context.Context, path string, body interface{}) (*Response, error) {
+	return RequestContext(ctx, path, &RequestOptions{Method: "PATCH", Body: body, RequireAuth: true})
 }
 
 // Delete makes a DELETE request
 func Delete(path string) (*Response, error) {
-	return Request(path, &RequestOptions{Method: "DELETE", RequireAuth: true})
+	return DeleteContext(context.Background(), path)
+}
+
+// DeleteContext makes a DELETE request with a context
+func DeleteContext(ctx context.Context, path string) (*Response, error) {
+	return RequestContext(ctx, path, &RequestOptions{Method: "DELETE", RequireAuth: true})
 }
 
 // GetNoAuth makes an unauthenticated GET request
 func GetNoAuth(path string) (*Response, error) {
-	return Request(path, &RequestOptions{Method: "GET", RequireAuth: false})
+	return GetNoAuthContext(context.Background(), path)
+}
+
+// GetNoAuthContext makes an unauthenticated GET request with a context
+func GetNoAuthContext(ctx context.Context, path string) (*Response, error) {
+	return RequestContext(ctx, path, &RequestOptions{Method: "GET", RequireAuth: false})
 }
 
 // PostNoAuth makes an unauthenticated POST request
 func PostNoAuth(path string, body interface{}) (*Response, error) {
-	return Request(path, &RequestOptions{Method: "POST", Body: body, RequireAuth: false})
+	return PostNoAuthContext(context.Background(), path, body)
+}
+
+// PostNoAuthContext makes an unauthenticated POST request with a context
+func PostNoAuthContext(ctx context.Context, path string, body interface{}) (*Response, error) {
+	return RequestContext(ctx, path, &RequestOptions{Method: "POST", Body: body, RequireAuth: false})
 }
 
 // PutNoAuth makes an unauthenticated PUT request
 func PutNoAuth(path string, body interface{}) (*Response, error) {
-	return Request(path, &RequestOptions{Method: "PUT", Body: body, RequireAuth: false})
+	return PutNoAuthContext(context.Background(), path, body)
+}
+
+// PutNoAuthContext makes an unauthenticated PUT request with a context
+func PutNoAuthContext(ctx context.Context, path string, body interface{}) (*Response, error) {
+	return RequestContext(ctx, path, &RequestOptions{Method: "PUT", Body: body, RequireAuth: false})
 }
 
 // Unmarshal unmarshals the response body into the given interface
@@ -181,26 +443,44 @@ func (r *Response) Unmarshal(v interface{}) error {
 	return json.Unmarshal(r.Body, v)
 }
 
-// GetString returns a string field from the response body
-func (r *Response) GetString(key string) string {
-	var m map[string]interface{}
-	if err := json.Unmarshal(r.Body, &m); err != nil {
-		return ""
+// Lookup returns the value at path in the response body. path is a
+// dot-separated list of object keys and array indexes, such as "shortId",
+// "data.shortId" or "presignedUrls.0.url"; ok is false if any step is missing.
+func (r *Response) Lookup(path string) (v interface{}, ok bool) {
+	if err := json.Unmarshal(r.Body, &v); err != nil {
+		return nil, false
 	}
-	if v, ok := m[key].(string); ok {
-		return v
+	for _, step := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			if v, ok = node[step]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(step)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
 	}
-	return ""
+	return v, true
 }
 
-// GetInt returns an int field from the response body
-func (r *Response) GetInt(key string) int {
-	var m map[string]interface{}
-	if err := json.Unmarshal(r.Body, &m); err != nil {
-		return 0
-	}
-	if v, ok := m[key].(float64); ok {
-		return int(v)
-	}
-	return 0
+// GetString returns the string at path (see Lookup) in the response body, or
+// "" if there is none.
+func (r *Response) GetString(path string) string {
+	v, _ := r.Lookup(path)
+	s, _ := v.(string)
+	return s
+}
+
+// GetInt returns the number at path (see Lookup) in the response body, or 0
+// if there is none.
+func (r *Response) GetInt(path string) int {
+	v, _ := r.Lookup(path)
+	f, _ := v.(float64)
+	return int(f)
 }
@@ -0,0 +1,225 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestServer points the api package at a server running handler, with a
+// personal access token so no login is needed.
+func newTestServer(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("NIKTE_TOKEN", "test-token")
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	SetBaseURL(srv.URL)
+	t.Cleanup(func() { SetBaseURL("") })
+}
+
+func TestDoSendsTokenAndDecodesBody(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if r.Header.Get("X-Request-ID") == "" {
+			t.Error("missing X-Request-ID")
+		}
+		w.Write([]byte(`{"id":"abc"}`))
+	})
+
+	resp, err := Do(context.Background(), "POST", "/shorts", map[string]string{"content": "hi"})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if got := resp.GetString("id"); got != "abc" {
+		t.Fatalf("id = %q, want abc", got)
+	}
+}
+
+func TestDoReturnsAPIError(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"short not found"}`))
+	})
+
+	_, err := Do(context.Background(), "DELETE", "/shorts/missing", nil)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+	if want := "short not found [request id req-1]"; err.Error() != want {
+		t.Fatalf("err = %q, want %q", err, want)
+	}
+}
+
+func TestPostMultipartStreamsFieldsAndFiles(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("ParseMultipartForm: %v", err)
+			return
+		}
+		if got := r.FormValue("title"); got != "avatar" {
+			t.Errorf("title = %q", got)
+		}
+		f, hdr, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("FormFile: %v", err)
+			return
+		}
+		data, _ := io.ReadAll(f)
+		if hdr.Filename != "me.png" || hdr.Header.Get("Content-Type") != "image/png" || string(data) != "PNGDATA" {
+			t.Errorf("file = %q %q %q", hdr.Filename, hdr.Header.Get("Content-Type"), data)
+		}
+		w.Write([]byte(`{"id":"up1"}`))
+	})
+
+	resp, err := PostMultipart(context.Background(), "/uploads", map[string]string{"title": "avatar"},
+		FormFile{Field: "file", Filename: "me.png", ContentType: "image/png", Reader: strings.NewReader("PNGDATA")})
+	if err != nil {
+		t.Fatalf("PostMultipart: %v", err)
+	}
+	if got := resp.GetString("id"); got != "up1" {
+		t.Fatalf("id = %q, want up1", got)
+	}
+}
+
+func TestResponseLookupPaths(t *testing.T) {
+	r := &Response{Body: []byte(`{"shortId":"top","data":{"shortId":"nested","parts":[{"n":1},{"n":2}]}}`)}
+	for path, want := range map[string]string{
+		"shortId":      "top",
+		"data.shortId": "nested",
+		"data.missing": "",
+		"data.parts.9": "",
+	} {
+		if got := r.GetString(path); got != want {
+			t.Errorf("GetString(%q) = %q, want %q", path, got, want)
+		}
+	}
+	if got := r.GetInt("data.parts.1.n"); got != 2 {
+		t.Errorf("GetInt(data.parts.1.n) = %d, want 2", got)
+	}
+}
+
+func TestHooksSeeEachRequest(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Tenant"); got != "acme" {
+			t.Errorf("X-Tenant = %q", got)
+		}
+		w.Write([]byte(`{}`))
+	})
+	t.Cleanup(func() { requestHooks, responseHooks = nil, nil })
+
+	OnRequest(func(req *http.Request) { req.Header.Set("X-Tenant", "acme") })
+	var status int
+	OnResponse(func(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+		if err == nil {
+			status = resp.StatusCode
+		}
+	})
+
+	if _, err := Do(context.Background(), "GET", "/health", nil); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if status != http.StatusOK {
+		t.Fatalf("response hook saw status %d", status)
+	}
+}
+
+func TestRecorderReplaysWithoutNetwork(t *testing.T) {
+	hits := 0
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "POST" {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"shortId":"abc"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"short not found"}`))
+	})
+	old := DefaultClient
+	t.Cleanup(func() { SetClient(old) })
+	cassette := t.TempDir() + "/cassette.json"
+	ctx := context.Background()
+
+	rec, err := NewRecorder(cassette, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetClient(rec.Client())
+	content := rec.Value("content", func() string { return "recorded" })
+	if _, err := Do(ctx, "POST", "/shorts", map[string]string{"content": content}); err != nil {
+		t.Fatalf("record POST: %v", err)
+	}
+	if _, err := Do(ctx, "GET", "/shorts/missing", nil); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("record GET: err = %v, want ErrNotFound", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	recorded := hits
+	rec, err = NewRecorder(cassette, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetClient(rec.Client())
+	if got := rec.Value("content", func() string { return "fresh" }); got != "recorded" {
+		t.Errorf("Value = %q, want the recorded one", got)
+	}
+	resp, err := Do(ctx, "POST", "/shorts", map[string]string{"content": "different body"})
+	if err != nil || resp.StatusCode != http.StatusCreated || resp.GetString("shortId") != "abc" {
+		t.Fatalf("replay POST = %+v, %v", resp, err)
+	}
+	if _, err := Do(ctx, "GET", "/shorts/missing", nil); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("replay GET: err = %v, want ErrNotFound", err)
+	}
+	if _, err := Do(ctx, "POST", "/shorts", nil); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Fatalf("unrecorded POST: err = %v", err)
+	}
+	if hits != recorded {
+		t.Fatalf("replay made %d requests to the server", hits-recorded)
+	}
+
+	if _, err := NewRecorder(t.TempDir()+"/none.json", ModeReplay); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("missing cassette: err = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestPaginateStopsOnRepeatedCursor(t *testing.T) {
+	requests := 0
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 10 {
+			t.Error("still paginating after 10 requests")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// Page 2 points back at itself.
+		w.Write([]byte(`{"items":[],"nextToken":"t2"}`))
+	})
+
+	var pages int
+	var err error
+	for _, err = range Paginate(context.Background(), "/shorts", nil) {
+		if err != nil {
+			break
+		}
+		pages++
+	}
+	if !errors.Is(err, ErrPageLoop) {
+		t.Fatalf("err = %v, want ErrPageLoop", err)
+	}
+	if pages != 2 || requests != 2 {
+		t.Errorf("got %d pages from %d requests, want 2 and 2", pages, requests)
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"sync/atomic"
+)
+
+// Responses need nothing here: the transport asks for gzip and decompresses
+// transparently as long as no request sets Accept-Encoding itself.
+
+// minCompressBody is the smallest request body worth gzipping. Small JSON
+// bodies gain little and cost a round of allocation; base64 screenshots and
+// long text shorts shrink a lot.
+const minCompressBody = 8 << 10
+
+// gzipUnsupported is set once the API has rejected a gzipped body with 415,
+// after which bodies are sent as is for the rest of the run.
+var gzipUnsupported atomic.Bool
+
+// compressBody returns body gzipped if it is large enough to be worth it and
+// the API hasn't refused compressed bodies, or nil to send it as is.
+func compressBody(body []byte) []byte {
+	if len(body) < minCompressBody || gzipUnsupported.Load() {
+		return nil
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil
+	}
+	if err := zw.Close(); err != nil {
+		return nil
+	}
+	if buf.Len() >= len(body) {
+		return nil
+	}
+	return buf.Bytes()
+}
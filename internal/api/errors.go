@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error kinds an *APIError matches with errors.Is, by status code.
+var (
+	ErrBadRequest   = errors.New("bad request")       // 400, 422
+	ErrUnauthorized = errors.New("unauthorized")      // 401
+	ErrForbidden    = errors.New("forbidden")         // 403
+	ErrNotFound     = errors.New("not found")         // 404
+	ErrConflict     = errors.New("conflict")          // 409
+	ErrTooLarge     = errors.New("payload too large") // 413
+	ErrServer       = errors.New("server error")      // 5xx
+)
+
+// APIError is an error response from the API.
+type APIError struct {
+	StatusCode int
+	Code       string // machine-readable code from the body, if any
+	Message    string // human-readable message from the body, if any
+	RequestID  string // quoted in the message so support can find the request
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	switch {
+	case e.StatusCode >= 500 && msg == "":
+		msg = fmt.Sprintf("server error (status %d)", e.StatusCode)
+	case e.StatusCode >= 500:
+		msg = fmt.Sprintf("%s (server error %d)", msg, e.StatusCode)
+	case msg == "":
+		msg = fmt.Sprintf("unexpected status %d", e.StatusCode)
+	}
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" [request id %s]", e.RequestID)
+	}
+	return msg
+}
+
+// Is matches the error kind for e's status code, so callers can write
+// errors.Is(err, api.ErrNotFound).
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrTooLarge:
+		return e.StatusCode == http.StatusRequestEntityTooLarge
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// Err returns an *APIError describing r if its status is 400 or above, and
+// nil otherwise.
+func (r *Response) Err() error {
+	if r.StatusCode < 400 {
+		return nil
+	}
+	e := &APIError{
+		StatusCode: r.StatusCode,
+		Code:       r.GetString("code"),
+		Message:    r.GetString("message"),
+		RequestID:  r.GetString("requestId"),
+	}
+	// Some endpoints send {"error": "..."} alone, others pair it with a
+	// message, in which case it is the code.
+	if errField := r.GetString("error"); e.Message == "" {
+		e.Message = errField
+	} else if e.Code == "" {
+		e.Code = errField
+	}
+	if e.RequestID == "" {
+		e.RequestID = r.RequestID
+	}
+	return e
+}
+
+// Do sends an authenticated request and returns the response, or an
+// *APIError if the API answered with an error status.
+func Do(ctx context.Context, method, path string, body interface{}) (*Response, error) {
+	resp, err := RequestContext(ctx, path, &RequestOptions{
+		Method:      method,
+		Body:        body,
+		RequireAuth: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
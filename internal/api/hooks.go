@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+// RequestHook runs just before an API request is sent, including each retry.
+// It may modify req, e.g. to add headers.
+type RequestHook func(req *http.Request)
+
+// ResponseHook runs after each attempt at an API request with its outcome:
+// resp is nil when err is set. It must not read or close resp.Body.
+type ResponseHook func(req *http.Request, resp *http.Response, err error, elapsed time.Duration)
+
+var (
+	requestHooks  []RequestHook
+	responseHooks []ResponseHook
+)
+
+// OnRequest registers fn to run before every API request, for instance to
+// inject headers. Hooks run in the order registered and must be registered
+// before the first request.
+func OnRequest(fn RequestHook) {
+	requestHooks = append(requestHooks, fn)
+}
+
+// OnResponse registers fn to run after every API request attempt, for
+// instance to record timings or metrics. Hooks run in the order registered
+// and must be registered before the first request.
+func OnResponse(fn ResponseHook) {
+	responseHooks = append(responseHooks, fn)
+}
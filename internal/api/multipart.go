@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"sort"
+	"strings"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/transport"
+)
+
+// FormFile is a file part of a multipart/form-data request.
+type FormFile struct {
+	Field       string    // form field name
+	Filename    string    // sent as the part's filename
+	ContentType string    // defaults to application/octet-stream
+	Reader      io.Reader // the file's content, streamed as the request is sent
+}
+
+// form is a multipart/form-data body. Files are streamed through a pipe
+// rather than buffered, so the body can only be sent again if every file
+// can seek back to where it started.
+type form struct {
+	fields map[string]string
+	files  []FormFile
+	starts []int64 // each file's starting offset, or -1 if it can't seek
+}
+
+func newForm(fields map[string]string, files []FormFile) *form {
+	f := &form{fields: fields, files: files, starts: make([]int64, len(files))}
+	for i, file := range files {
+		f.starts[i] = -1
+		if s, ok := file.Reader.(io.Seeker); ok {
+			if off, err := s.Seek(0, io.SeekCurrent); err == nil {
+				f.starts[i] = off
+			}
+		}
+	}
+	return f
+}
+
+// open starts writing the form and returns the body to send and its content
+// type. The writer stops when the transport closes the body.
+func (f *form) open() (io.Reader, string) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(f.write(mw))
+	}()
+	return pr, mw.FormDataContentType()
+}
+
+func (f *form) write(mw *multipart.Writer) error {
+	names := make([]string, 0, len(f.fields))
+	for name := range f.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := mw.WriteField(name, f.fields[name]); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range f.files {
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(file.Field), escapeQuotes(file.Filename)))
+		h.Set("Content-Type", contentType)
+		part, err := mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, file.Reader); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// rewind seeks every file back to its start, reporting false if one can't.
+func (f *form) rewind() bool {
+	for i, file := range f.files {
+		if f.starts[i] < 0 {
+			return false
+		}
+		if _, err := file.Reader.(io.Seeker).Seek(f.starts[i], io.SeekStart); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+// PostMultipart sends fields and files to path as an authenticated
+// multipart/form-data POST and returns the response, or an *APIError if the
+// API answered with an error status. Files are streamed, not loaded into
+// memory; a rate-limited request is only retried if every file is an
+// io.Seeker (an *os.File, say). upload_timeout applies instead of
+// http_timeout.
+func PostMultipart(ctx context.Context, path string, fields map[string]string, files ...FormFile) (*Response, error) {
+	p, err := prepare(ctx, path, &RequestOptions{Method: "POST", RequireAuth: true})
+	if err != nil {
+		return nil, err
+	}
+	p.form = newForm(fields, files)
+
+	resp, err := p.send(ctx, config.GetTuning().UploadTimeout, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	r := &Response{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		Body:       body,
+		RateLimit:  parseRateLimit(resp.Header),
+		RequestID:  transport.RequestID(resp),
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
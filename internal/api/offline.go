@@ -0,0 +1,57 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+)
+
+// ErrOffline matches errors from requests that never reached the API because
+// the host could not be resolved or connected to.
+var ErrOffline = errors.New("offline")
+
+// OfflineError reports that the API at BaseURL could not be reached.
+type OfflineError struct {
+	BaseURL string
+	Err     error
+}
+
+func (e *OfflineError) Error() string {
+	reason := e.Err.Error()
+	var dnsErr *net.DNSError
+	var sysErr *os.SyscallError
+	var opErr *net.OpError
+	switch {
+	case errors.As(e.Err, &dnsErr):
+		reason = fmt.Sprintf("could not resolve %s", dnsErr.Name)
+	case errors.As(e.Err, &sysErr):
+		reason = sysErr.Err.Error()
+	case errors.As(e.Err, &opErr) && opErr.Err != nil:
+		reason = opErr.Err.Error()
+	}
+	return fmt.Sprintf("cannot reach %s (%s). Check your network connection and proxy settings", e.BaseURL, reason)
+}
+
+func (e *OfflineError) Unwrap() error { return e.Err }
+
+// Is makes errors.Is(err, api.ErrOffline) match.
+func (e *OfflineError) Is(target error) bool { return target == ErrOffline }
+
+// unreachable reports whether err means the request never got to the
+// server: a DNS failure, or a refused or timed-out connection to the host or
+// proxy.
+func unreachable(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		switch opErr.Op {
+		case "dial", "proxyconnect", "socks connect":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PageOptions configures Paginate.
+type PageOptions struct {
+	PageSize int // sent as "limit"; 0 leaves the page size to the server
+	MaxPages int // stop after this many pages; 0 fetches them all
+}
+
+// pageCursor is the part of a list response that says where the next page
+// starts. Endpoints use either an opaque token or a page number.
+type pageCursor struct {
+	NextToken string `json:"nextToken"`
+	NextPage  int    `json:"nextPage"`
+}
+
+// ErrPageLoop is yielded by Paginate when the server hands back a cursor it
+// already returned, which would otherwise fetch the same pages forever.
+var ErrPageLoop = errors.New("pagination cursor repeated")
+
+// Paginate fetches a list endpoint page by page, following the nextToken (or
+// nextPage) each response carries, and yields every page. Iteration ends after
+// the last page, at MaxPages, or after yielding the first error, which is an
+// *APIError for error statuses or ErrPageLoop.
+//
+//	for page, err := range api.Paginate(ctx, "/shorts", nil) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func Paginate(ctx context.Context, path string, opts *PageOptions) iter.Seq2[*Response, error] {
+	if opts == nil {
+		opts = &PageOptions{}
+	}
+	return func(yield func(*Response, error) bool) {
+		params := url.Values{}
+		if opts.PageSize > 0 {
+			params.Set("limit", strconv.Itoa(opts.PageSize))
+		}
+
+		seen := map[string]bool{}
+		for pages := 0; opts.MaxPages == 0 || pages < opts.MaxPages; pages++ {
+			resp, err := Do(ctx, "GET", withQuery(path, params), nil)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(resp, nil) {
+				return
+			}
+
+			var cursor pageCursor
+			if json.Unmarshal(resp.Body, &cursor) != nil {
+				return
+			}
+			var key string
+			switch {
+			case cursor.NextToken != "":
+				key = "nextToken=" + cursor.NextToken
+				params.Set("nextToken", cursor.NextToken)
+			case cursor.NextPage > 0:
+				key = "page=" + strconv.Itoa(cursor.NextPage)
+				params.Set("page", strconv.Itoa(cursor.NextPage))
+			default:
+				return
+			}
+			if seen[key] {
+				yield(nil, fmt.Errorf("%w: %s", ErrPageLoop, key))
+				return
+			}
+			seen[key] = true
+		}
+	}
+}
+
+// withQuery appends params to path, which may already have a query string.
+func withQuery(path string, params url.Values) string {
+	if len(params) == 0 {
+		return path
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + params.Encode()
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/retry"
+)
+
+// maxRateLimitWaits bounds how many times one request waits out a 429 via
+// RateLimitWait before giving up.
+const maxRateLimitWaits = 3
+
+// ErrRateLimited matches (with errors.Is) the error returned when the API
+// keeps answering 429 Too Many Requests.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimitWait, when set, is called to wait out a 429 before the request is
+// sent again. The CLI sets it for --wait-on-ratelimit to show a countdown;
+// when nil, 429s only get the normal short retries.
+var RateLimitWait func(ctx context.Context, d time.Duration) error
+
+// RateLimit is the request quota reported in the X-RateLimit-* headers.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time // zero if the server didn't say
+}
+
+// parseRateLimit reads the X-RateLimit-* headers, returning nil if the
+// response has none.
+func parseRateLimit(h http.Header) *RateLimit {
+	limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return nil
+	}
+	rl := &RateLimit{Limit: limit}
+	rl.Remaining, _ = strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil && reset > 0 {
+		rl.Reset = time.Unix(reset, 0)
+	}
+	return rl
+}
+
+// RateLimitError is returned when a request is still rate limited after its
+// retries (and any waits) are used up.
+type RateLimitError struct {
+	RateLimit *RateLimit    // nil if the response had no quota headers
+	Wait      time.Duration // how long until the limit lifts; 0 if unknown
+}
+
+func (e *RateLimitError) Error() string {
+	msg := "rate limit exceeded"
+	if e.RateLimit != nil {
+		msg += fmt.Sprintf(" (%d requests per window)", e.RateLimit.Limit)
+	}
+	if e.Wait > 0 {
+		msg += fmt.Sprintf("; resets at %s (in %s)",
+			time.Now().Add(e.Wait).Local().Format("3:04:05 PM"), e.Wait.Round(time.Second))
+	}
+	return msg + `. Try again later, or pass --wait-on-ratelimit`
+}
+
+// Is makes errors.Is(err, ErrRateLimited) match.
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// rateLimitDelay is how long a 429 response asks the client to wait: its
+// Retry-After, else the time until X-RateLimit-Reset, else 0.
+func rateLimitDelay(resp *http.Response) time.Duration {
+	if d, ok := retry.RetryAfter(resp.Header); ok {
+		return d
+	}
+	if rl := parseRateLimit(resp.Header); rl != nil && !rl.Reset.IsZero() {
+		return max(time.Until(rl.Reset), 0)
+	}
+	return 0
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sim4gh/nikte-cli/internal/transport"
+)
+
+// RecordMode is how a Recorder treats requests.
+type RecordMode string
+
+const (
+	// ModeReplay answers every request from the cassette and never touches
+	// the network.
+	ModeReplay RecordMode = "replay"
+	// ModeRecord sends requests to the live API and saves the exchanges to
+	// the cassette.
+	ModeRecord RecordMode = "record"
+	// ModeLive sends requests to the live API and saves nothing.
+	ModeLive RecordMode = "live"
+)
+
+// RecordModeEnv names the environment variable RecordModeFromEnv reads.
+const RecordModeEnv = "NIKTE_RECORD_MODE"
+
+// RecordModeFromEnv returns the mode set in NIKTE_RECORD_MODE, defaulting
+// to ModeLive until cassettes are committed for every integration test.
+func RecordModeFromEnv() (RecordMode, error) {
+	switch m := RecordMode(os.Getenv(RecordModeEnv)); m {
+	case "":
+		return ModeLive, nil
+	case ModeReplay, ModeRecord, ModeLive:
+		return m, nil
+	default:
+		return "", fmt.Errorf("%s must be replay, record or live, not %q", RecordModeEnv, m)
+	}
+}
+
+// Interaction is one recorded request and its response. Requests are matched
+// by method and path (with query) alone, so bodies with timestamps or random
+// ciphertext replay fine; no request headers or bodies are stored, so tokens
+// never end up in a cassette.
+type Interaction struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        string `json:"body"`
+}
+
+// cassette is the file a Recorder reads and writes. Values are the
+// generated inputs of a test (see Recorder.Value), so a replay sends the
+// same content that was recorded.
+type cassette struct {
+	Values       map[string]string `json:"values,omitempty"`
+	Interactions []Interaction     `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper that records API exchanges to a cassette
+// file, or replays them from it, VCR-style: integration tests record real
+// responses once and replay them in CI without credentials. Install it with
+// SetClient(rec.Client()).
+type Recorder struct {
+	mode RecordMode
+	path string
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	cassette cassette
+	used     []bool
+}
+
+// NewRecorder returns a recorder for the cassette at path. In ModeReplay the
+// cassette must exist; the error then wraps os.ErrNotExist so tests can skip.
+func NewRecorder(path string, mode RecordMode) (*Recorder, error) {
+	r := &Recorder{mode: mode, path: path, next: transport.Transport(), cassette: cassette{Values: map[string]string{}}}
+	if mode != ModeReplay {
+		return r, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("cassette %s: %w", path, err)
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// Mode returns the recorder's mode.
+func (r *Recorder) Mode() RecordMode { return r.mode }
+
+// Client returns an HTTP client that goes through r, for SetClient and for
+// requests a test makes outside the api package.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Value returns the value gen makes for name, such as unique content to
+// upload. Recording saves it in the cassette and replaying returns the saved
+// value, so tests that compare what they sent with what came back still pass.
+func (r *Recorder) Value(name string, gen func() string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.cassette.Values[name]; ok && r.mode == ModeReplay {
+		return v
+	}
+	v := gen()
+	if r.mode == ModeRecord {
+		r.cassette.Values[name] = v
+	}
+	return v
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch r.mode {
+	case ModeLive:
+		return r.next.RoundTrip(req)
+	case ModeRecord:
+		return r.record(req)
+	default:
+		return r.replay(req)
+	}
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	// Record full responses, not 304s answered from a local cache the
+	// replay won't have.
+	req = req.Clone(req.Context())
+	req.Header.Del("If-None-Match")
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Method:      req.Method,
+		Path:        req.URL.RequestURI(),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(body),
+	})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// replay answers req with the first unused interaction of the same method
+// and path, so repeated requests get their responses in recorded order.
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	path := req.URL.RequestURI()
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || in.Method != req.Method || in.Path != path {
+			continue
+		}
+		r.used[i] = true
+		resp := &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+			StatusCode:    in.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{},
+			Body:          io.NopCloser(bytes.NewReader([]byte(in.Body))),
+			ContentLength: int64(len(in.Body)),
+			Request:       req,
+		}
+		if in.ContentType != "" {
+			resp.Header.Set("Content-Type", in.ContentType)
+		}
+		return resp, nil
+	}
+	return nil, fmt.Errorf("no recorded response for %s %s in %s; record it again with %s=record",
+		req.Method, path, r.path, RecordModeEnv)
+}
+
+// Save writes the recorded exchanges to the cassette. It does nothing unless
+// recording.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
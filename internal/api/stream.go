@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/transport"
+)
+
+// maxErrorBody is how much of a streamed error response Err reads to find
+// the API's message.
+const maxErrorBody = 64 << 10
+
+// StreamResponse is an API response whose body is read as it arrives instead
+// of being buffered. The caller must close Body.
+type StreamResponse struct {
+	StatusCode int
+	Headers    http.Header
+	Body       io.ReadCloser
+	RateLimit  *RateLimit // nil if the server sent no X-RateLimit-* headers
+	RequestID  string     // the server's request ID, or the X-Request-ID we sent
+	Stale      bool       // served from the cache because the API was unreachable
+}
+
+// Err returns an *APIError describing r if its status is 400 or above, and
+// nil otherwise. It reads the start of the body to find the API's message,
+// so Body should not be used after a non-nil Err.
+func (r *StreamResponse) Err() error {
+	if r.StatusCode < 400 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(r.Body, maxErrorBody))
+	return (&Response{
+		StatusCode: r.StatusCode,
+		Headers:    r.Headers,
+		Body:       body,
+		RateLimit:  r.RateLimit,
+		RequestID:  r.RequestID,
+	}).Err()
+}
+
+// Stream is Request for endpoints that return large payloads: the body is
+// handed back unread rather than loaded into memory.
+func Stream(path string, opts *RequestOptions) (*StreamResponse, error) {
+	return StreamContext(context.Background(), path, opts)
+}
+
+// StreamContext is Stream with a context. Responses are never cached, though
+// a GET that cannot reach the API falls back to a cached copy if Request
+// stored one. download_timeout applies instead of http_timeout since the body
+// may take a while to arrive.
+func StreamContext(ctx context.Context, path string, opts *RequestOptions) (*StreamResponse, error) {
+	p, err := prepare(ctx, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.send(ctx, config.GetTuning().DownloadTimeout, nil)
+	if errors.Is(err, ErrOffline) && p.method == "GET" && p.token != "" && cacheable(path) {
+		if file, ok := cacheFile(p.url); ok {
+			if cached := loadCached(file); cached != nil {
+				return &StreamResponse{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader(cached.Body)),
+					Stale:      true,
+				}, nil
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &StreamResponse{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		Body:       resp.Body,
+		RateLimit:  parseRateLimit(resp.Header),
+		RequestID:  transport.RequestID(resp),
+	}, nil
+}
+
+// GetStream performs an authenticated GET and streams the response body.
+func GetStream(ctx context.Context, path string) (*StreamResponse, error) {
+	return StreamContext(ctx, path, &RequestOptions{Method: "GET", RequireAuth: true})
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"os"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+)
+
+// APITokenEnvVars are checked, in order, for a personal access token. OIO_TOKEN
+// is accepted for scripts written against the original oio CLI.
+var APITokenEnvVars = []string{"NIKTE_TOKEN", "OIO_TOKEN"}
+
+// APIToken returns the long-lived personal access token to use instead of the
+// device-flow session, or "" when none is configured. The environment wins
+// over the stored token so CI can override a developer's local setup.
+func APIToken() string {
+	token, _ := APITokenSource()
+	return token
+}
+
+// APITokenSource returns the personal access token and where it came from: the
+// environment variable name or "config".
+func APITokenSource() (token, source string) {
+	for _, name := range APITokenEnvVars {
+		if v := os.Getenv(name); v != "" {
+			return v, name
+		}
+	}
+	if cfg := config.Get(); cfg != nil && cfg.APIToken != "" {
+		return cfg.APIToken, "config"
+	}
+	return "", ""
+}
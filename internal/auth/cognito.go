@@ -1,8 +1,10 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -10,15 +12,42 @@ import (
 	"time"
 
 	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/transport"
 )
 
 // Cognito configuration. Values for the CDK-managed user pool (nikte-cdk-dev).
+// These are the defaults; the auth_* config keys override them for
+// self-hosted or staging deployments.
 const (
 	CognitoDomain = "niktecdk-dev.auth.us-west-2.amazoncognito.com"
 	ClientID      = "64jbkkmjb9lrnk8pra3ivobfmp"
 	TokenEndpoint = "https://" + CognitoDomain + "/oauth2/token"
 )
 
+// clientID returns the OAuth client ID, honoring auth_client_id.
+func clientID() string {
+	if cfg := config.Get(); cfg != nil && cfg.AuthClientID != "" {
+		return cfg.AuthClientID
+	}
+	return ClientID
+}
+
+// tokenEndpoint returns the OAuth token endpoint used for refreshes. An
+// explicit auth_token_endpoint wins over one derived from auth_domain.
+func tokenEndpoint() string {
+	cfg := config.Get()
+	if cfg == nil {
+		return TokenEndpoint
+	}
+	if cfg.AuthTokenEndpoint != "" {
+		return cfg.AuthTokenEndpoint
+	}
+	if cfg.AuthDomain != "" {
+		return "https://" + strings.TrimSuffix(cfg.AuthDomain, "/") + "/oauth2/token"
+	}
+	return TokenEndpoint
+}
+
 // TokenResponse represents the response from Cognito token endpoint
 type TokenResponse struct {
 	IDToken      string `json:"id_token"`
@@ -34,27 +63,77 @@ type TokenErrorResponse struct {
 	ErrorDescription string `json:"error_description"`
 }
 
-// RefreshTokens exchanges the refresh token for new access/id tokens
-func RefreshTokens() (*TokenResponse, error) {
+// ErrSessionExpired is returned (wrapped) by RefreshTokens when the refresh
+// token itself has been rejected, so only a fresh login can help.
+var ErrSessionExpired = errors.New("session expired")
+
+// RefreshTokens exchanges the refresh token for new access/id tokens. The
+// exchange runs under the config file lock, so concurrent nk processes don't
+// each spend the same refresh token; a process that finds a fresh token already
+// written by another one uses it instead. Cancelling ctx aborts the exchange
+// and releases the lock.
+func RefreshTokens(ctx context.Context) (*TokenResponse, error) {
 	cfg := config.Get()
 	if cfg == nil || cfg.RefreshToken == "" {
 		return nil, errors.New("no refresh token available. Please run \"nk auth login\" again")
 	}
 
+	// Resolve everything that reads the config before taking the lock.
+	staleIDToken := cfg.IDToken
+	endpoint := tokenEndpoint()
+	client := clientID()
+	now, buffer := Now(), RefreshBuffer()
+
+	var tokenResp *TokenResponse
+	err := config.Update(func(cfg *config.Config) error {
+		if cfg.IDToken != staleIDToken && !isExpiredAt(cfg.IDToken, now, buffer) {
+			tokenResp = &TokenResponse{IDToken: cfg.IDToken, AccessToken: cfg.AccessToken, RefreshToken: cfg.RefreshToken}
+			return nil
+		}
+		if cfg.RefreshToken == "" {
+			return errors.New("no refresh token available. Please run \"nk auth login\" again")
+		}
+
+		resp, err := exchangeRefreshToken(ctx, endpoint, client, cfg.RefreshToken)
+		if err != nil {
+			return err
+		}
+		tokenResp = resp
+
+		// Update stored tokens
+		cfg.IDToken = resp.IDToken
+		cfg.AccessToken = resp.AccessToken
+		if resp.RefreshToken != "" {
+			cfg.RefreshToken = resp.RefreshToken
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenResp.IDToken != staleIDToken {
+		RecordClockSkew(tokenResp.IDToken)
+	}
+	return tokenResp, nil
+}
+
+// exchangeRefreshToken performs the refresh_token grant against endpoint.
+func exchangeRefreshToken(ctx context.Context, endpoint, client, refreshToken string) (*TokenResponse, error) {
 	data := url.Values{}
 	data.Set("grant_type", "refresh_token")
-	data.Set("client_id", ClientID)
-	data.Set("refresh_token", cfg.RefreshToken)
+	data.Set("client_id", client)
+	data.Set("refresh_token", refreshToken)
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	req, err := http.NewRequest("POST", TokenEndpoint, strings.NewReader(data.Encode()))
+	httpClient := transport.Client(30 * time.Second)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := client.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -70,6 +149,12 @@ func RefreshTokens() (*TokenResponse, error) {
 		if err := json.Unmarshal(body, &errResp); err != nil {
 			return nil, errors.New("failed to refresh tokens: " + string(body))
 		}
+		if errResp.Error == "invalid_grant" {
+			if errResp.ErrorDescription != "" {
+				return nil, fmt.Errorf("%w: %s", ErrSessionExpired, errResp.ErrorDescription)
+			}
+			return nil, ErrSessionExpired
+		}
 		if errResp.ErrorDescription != "" {
 			return nil, errors.New(errResp.ErrorDescription)
 		}
@@ -83,23 +168,15 @@ func RefreshTokens() (*TokenResponse, error) {
 	if err := json.Unmarshal(body, &tokenResp); err != nil {
 		return nil, err
 	}
-
-	// Update stored tokens
-	cfg.IDToken = tokenResp.IDToken
-	cfg.AccessToken = tokenResp.AccessToken
-	if tokenResp.RefreshToken != "" {
-		cfg.RefreshToken = tokenResp.RefreshToken
-	}
-
-	if err := config.SetConfig(cfg); err != nil {
-		return nil, err
-	}
-
 	return &tokenResp, nil
 }
 
 // EnsureValidToken checks if the token is valid and refreshes if needed
-func EnsureValidToken() (string, error) {
+func EnsureValidToken(ctx context.Context) (string, error) {
+	if token := APIToken(); token != "" {
+		return token, nil
+	}
+
 	cfg := config.Get()
 	if cfg == nil || cfg.IDToken == "" {
 		return "", errors.New("not authenticated. Please run \"nk auth login\" first")
@@ -110,9 +187,9 @@ func EnsureValidToken() (string, error) {
 	}
 
 	// Token is expired, try to refresh
-	tokens, err := RefreshTokens()
+	tokens, err := RefreshTokens(ctx)
 	if err != nil {
-		return "", errors.New("authentication expired: " + err.Error())
+		return "", fmt.Errorf("authentication expired: %w", err)
 	}
 
 	return tokens.IDToken, nil
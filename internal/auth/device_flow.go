@@ -1,18 +1,42 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/retry"
+	"github.com/sim4gh/nikte-cli/internal/transport"
 )
 
-// BaseURL is the API base URL
+// BaseURL is the default API base URL
 const BaseURL = "https://auth.nikte.co"
 
+// LoginBaseURL returns the base URL to log in against: the configured baseurl
+// if any, otherwise BaseURL.
+func LoginBaseURL() string {
+	if cfg := config.Get(); cfg != nil && cfg.BaseURL != "" {
+		return strings.TrimSuffix(cfg.BaseURL, "/")
+	}
+	return BaseURL
+}
+
+// deviceAuthEndpoint returns the device authorization endpoint, honoring
+// auth_device_endpoint.
+func deviceAuthEndpoint() string {
+	if cfg := config.Get(); cfg != nil && cfg.AuthDeviceEndpoint != "" {
+		return cfg.AuthDeviceEndpoint
+	}
+	return LoginBaseURL() + "/device_authorization"
+}
+
 // DeviceAuthResponse represents the response from device authorization endpoint
 type DeviceAuthResponse struct {
 	DeviceCode              string `json:"device_code"`
@@ -35,16 +59,15 @@ type DeviceTokenResponse struct {
 }
 
 // InitiateDeviceAuth starts the device authorization flow
-func InitiateDeviceAuth() (*DeviceAuthResponse, error) {
-	client := &http.Client{Timeout: 30 * time.Second}
+func InitiateDeviceAuth(ctx context.Context) (*DeviceAuthResponse, error) {
+	client := transport.Client(config.GetTuning().HTTPTimeout)
 
-	req, err := http.NewRequest("POST", BaseURL+"/device_authorization", nil)
+	req, err := http.NewRequestWithContext(ctx, "POST", deviceAuthEndpoint(), nil)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", "nikte")
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -69,9 +92,17 @@ func InitiateDeviceAuth() (*DeviceAuthResponse, error) {
 	return &authResp, nil
 }
 
-// PollForToken polls the token endpoint until authentication is complete
-func PollForToken(deviceCode string, interval int) (*DeviceTokenResponse, error) {
-	client := &http.Client{Timeout: 30 * time.Second}
+// slowDownStep is how much a slow_down answer lengthens the polling interval
+// (RFC 8628 section 3.5).
+const slowDownStep = 5 * time.Second
+
+// PollForToken polls the token endpoint until authentication is complete or
+// ctx is cancelled. slow_down answers lengthen the interval for the rest of
+// the flow; network errors, 429 and 5xx are retried with the usual backoff
+// (or the server's Retry-After) up to retry_count times in a row.
+func PollForToken(ctx context.Context, deviceCode string, interval int) (*DeviceTokenResponse, error) {
+	tuning := config.GetTuning()
+	client := transport.Client(tuning.HTTPTimeout)
 
 	data := url.Values{}
 	data.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
@@ -82,22 +113,43 @@ func PollForToken(deviceCode string, interval int) (*DeviceTokenResponse, error)
 		pollInterval = 2 * time.Second
 	}
 
+	wait := pollInterval
+	failures := 0
 	for {
-		time.Sleep(pollInterval)
+		if err := retry.Sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		wait = pollInterval
 
-		req, err := http.NewRequest("POST", BaseURL+"/token", strings.NewReader(data.Encode()))
+		req, err := http.NewRequestWithContext(ctx, "POST", LoginBaseURL()+"/token", strings.NewReader(data.Encode()))
 		if err != nil {
 			return nil, err
 		}
 
 		req.Header.Set("Accept", "application/json")
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-		req.Header.Set("User-Agent", "nikte")
 
 		resp, err := client.Do(req)
 		if err != nil {
-			return nil, err
+			if ctx.Err() != nil || failures >= tuning.RetryCount {
+				return nil, err
+			}
+			wait = max(pollInterval, retry.Backoff(tuning.RetryBackoff, failures))
+			failures++
+			continue
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			d, ok := retry.Delay(resp, tuning.RetryBackoff, failures)
+			transport.DrainAndClose(resp.Body)
+			if !ok || failures >= tuning.RetryCount {
+				return nil, fmt.Errorf("login failed: token endpoint returned status %d [request id %s]", resp.StatusCode, transport.RequestID(resp))
+			}
+			wait = max(pollInterval, d)
+			failures++
+			continue
 		}
+		failures = 0
 
 		body, err := io.ReadAll(resp.Body)
 		resp.Body.Close()
@@ -114,25 +166,22 @@ func PollForToken(deviceCode string, interval int) (*DeviceTokenResponse, error)
 			return &tokenResp, nil
 		}
 
-		// Check for authorization_pending - continue polling
-		if tokenResp.Error == "authorization_pending" || tokenResp.ErrorCode == "authorization_pending" {
-			continue
+		errCode := tokenResp.Error
+		if errCode == "" {
+			errCode = tokenResp.ErrorCode
 		}
-
-		// Check for slow_down - increase interval
-		if tokenResp.Error == "slow_down" {
-			pollInterval += 5 * time.Second
+		switch errCode {
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			pollInterval += slowDownStep
+			wait = pollInterval
 			continue
+		case "":
+			errCode = "unknown error"
 		}
 
 		// Other errors should stop the flow
-		errMsg := tokenResp.Error
-		if errMsg == "" {
-			errMsg = tokenResp.ErrorCode
-		}
-		if errMsg == "" {
-			errMsg = "unknown error"
-		}
-		return nil, errors.New("login failed: " + errMsg)
+		return nil, errors.New("login failed: " + errCode)
 	}
 }
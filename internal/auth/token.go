@@ -4,8 +4,13 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
 )
 
 // JWTPayload represents the decoded JWT payload
@@ -53,27 +58,87 @@ func DecodeJWT(token string) (*JWTPayload, error) {
 	return &result, nil
 }
 
-// IsTokenExpired checks if a token is expired or will expire within the buffer period
+// IsTokenExpired checks if a token is expired or will expire within the buffer
+// period, judged by the server's clock rather than ours
 func IsTokenExpired(token string) bool {
 	if token == "" {
 		return true
 	}
+	return isExpiredAt(token, Now(), RefreshBuffer())
+}
 
+// isExpiredAt is IsTokenExpired with the clock and buffer supplied, for use
+// inside config.Update where the config can't be consulted.
+func isExpiredAt(token string, now time.Time, buffer time.Duration) bool {
 	payload, err := DecodeJWT(token)
-	if err != nil {
+	if err != nil || payload.Exp == 0 {
 		return true
 	}
+	return time.Unix(payload.Exp, 0).Before(now.Add(buffer))
+}
 
-	if payload.Exp == 0 {
-		return true
+// DefaultRefreshBuffer is how long before expiry a token is treated as expired
+// and refreshed, unless token_refresh_buffer is set.
+const DefaultRefreshBuffer = 60 * time.Second
+
+// RefreshBuffer returns the configured token_refresh_buffer, or
+// DefaultRefreshBuffer.
+func RefreshBuffer() time.Duration {
+	if cfg := config.Get(); cfg != nil && cfg.TokenRefreshBuffer != "" {
+		if d, err := time.ParseDuration(cfg.TokenRefreshBuffer); err == nil && d >= 0 {
+			return d
+		}
+	}
+	return DefaultRefreshBuffer
+}
+
+// Now returns the current time corrected by the clock skew measured against
+// the identity provider (see RecordClockSkew).
+func Now() time.Time {
+	if cfg := config.Get(); cfg != nil && cfg.ClockSkew != 0 {
+		return time.Now().Add(time.Duration(cfg.ClockSkew) * time.Second)
+	}
+	return time.Now()
+}
+
+const (
+	// skewThreshold is the smallest difference between a fresh token's iat
+	// and local time that is treated as clock skew rather than latency.
+	skewThreshold = 30 * time.Second
+
+	// skewWarnThreshold is the skew beyond which the user is told to fix
+	// their clock.
+	skewWarnThreshold = 2 * time.Minute
+)
+
+// RecordClockSkew compares a freshly issued token's iat with local time and
+// stores the difference, so expiry checks use the server's notion of "now".
+// Without it a clock running ahead makes every new token look expired and
+// each request triggers another refresh.
+func RecordClockSkew(token string) {
+	payload, err := DecodeJWT(token)
+	if err != nil || payload.Iat == 0 {
+		return
 	}
 
-	// Check if token expires within 60 seconds (buffer)
-	expirationTime := time.Unix(payload.Exp, 0)
-	now := time.Now()
-	buffer := 60 * time.Second
+	skew := time.Until(time.Unix(payload.Iat, 0)).Round(time.Second)
+	if skew.Abs() < skewThreshold {
+		skew = 0
+	}
+
+	if cfg := config.Get(); cfg != nil && time.Duration(cfg.ClockSkew)*time.Second == skew {
+		return
+	}
+	_ = config.Set("clock_skew", strconv.FormatInt(int64(skew/time.Second), 10))
 
-	return expirationTime.Before(now.Add(buffer))
+	if skew.Abs() >= skewWarnThreshold {
+		direction := "behind"
+		if skew < 0 {
+			direction = "ahead of"
+		}
+		fmt.Fprintf(os.Stderr, "Warning: your system clock is %s %s the server's. "+
+			"Token expiry is being adjusted, but consider syncing your clock.\n", skew.Abs(), direction)
+	}
 }
 
 // GetTokenExpiry returns the expiration time of a token
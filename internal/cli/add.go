@@ -1,16 +1,25 @@
 package cli
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/atotto/clipboard"
 	"github.com/briandowns/spinner"
 	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/config"
 	"github.com/sim4gh/nikte-cli/internal/crypto"
+	"github.com/sim4gh/nikte-cli/internal/models"
 	"github.com/sim4gh/nikte-cli/internal/platform"
 	"github.com/sim4gh/nikte-cli/internal/upload"
 	"github.com/sim4gh/nikte-cli/internal/util"
@@ -24,13 +33,27 @@ var (
 	addPassword   string
 	addTitle      string
 	addDesc       string
-	addWindow     bool
+	addWindow     string
 	addFullscreen bool
+	addDisplay    string
+	addRegion     string
+	addSaveRegion string
 	addWatch      string
 	addQR         bool
 	addMaxViews   int
 	addEncrypt    bool
 	addEncPass    string
+	addPwPrompt   bool
+	addGzip       bool
+	addZstd       bool
+
+	// addResult is what the current `nk a` created, emitted in json/ndjson
+	// output modes.
+	addResult itemResult
+
+	// addMu serializes uploads made outside `nk a` (by the daemon and the
+	// local API server), since the add helpers share the flags above.
+	addMu sync.Mutex
 )
 
 const (
@@ -41,16 +64,22 @@ const (
 
 func addAddCommand() {
 	addCmd := &cobra.Command{
-		Use:   "a [input]",
+		Use:   "a [input | files...]",
 		Short: "Add item from clipboard, screenshot, file, or text",
 		Long: `Add item from clipboard, screenshot, file, or text
 
 Examples:
   nk a                        Add from clipboard (text or image)
-    ├ sc                       Take screenshot (macOS)
+    ├ sc                       Take screenshot (macOS, Linux)
+    ├ sc --window=firefox      Capture Firefox's front window (--window=list)
+    ├ sc --display 2           Capture the second monitor (--display list)
+    ├ sc --region preset:demo  Capture a saved area (see --region, --save-region)
     ├ sc --watch               Continuous screenshot mode
     ├ sc --watch 5             Auto-capture every 5 seconds
     ├ document.pdf             Add file from path
+    ├ logs.tar --zstd          Compress before upload (stored as logs.tar.zst)
+    │                          via a temporary copy, up to the file's size, in $TMPDIR
+    ├ *.pdf ./photos           Upload several files, 3 at a time (--jobs)
     ├ "Hello world"            Add text content
     ├ --permanent              Add with no expiration
     └ photo.jpg --public       Add and share`,
@@ -61,16 +90,24 @@ Examples:
 	addCmd.Flags().BoolVar(&addPermanent, "permanent", false, "Keep forever (default: 24h TTL)")
 	addCmd.Flags().StringVar(&addTTL, "ttl", defaultTTL, "Custom TTL (e.g., 1h, 7d)")
 	addCmd.Flags().BoolVarP(&addPublic, "public", "p", false, "Create public share on add (Pro)")
-	addCmd.Flags().StringVar(&addPassword, "password", "", "Password-protected share (Pro)")
+	addCmd.Flags().StringVar(&addPassword, "password", "", "Password-protected share (Pro; no value prompts)")
+	allowPasswordPrompt(addCmd)
+	addCmd.Flags().BoolVar(&addPwPrompt, "password-prompt", false, "Read the share password from a hidden prompt (Pro)")
 	addCmd.Flags().StringVar(&addTitle, "title", "", "Share title for social previews (with --public)")
 	addCmd.Flags().StringVar(&addDesc, "desc", "", "Share description for social previews (with --public)")
-	addCmd.Flags().BoolVarP(&addWindow, "window", "w", false, "Capture specific window (for screenshot)")
+	addCmd.Flags().StringVarP(&addWindow, "window", "w", "", "Capture a window (for screenshot; =N, =name or =list to skip picking)")
+	addCmd.Flags().Lookup("window").NoOptDefVal = windowPick
 	addCmd.Flags().BoolVarP(&addFullscreen, "fullscreen", "f", false, "Capture full screen (for screenshot)")
+	addCmd.Flags().StringVar(&addDisplay, "display", "", "Capture display N in full (for screenshot; \"list\" shows them)")
+	addRegionFlags(addCmd)
 	addCmd.Flags().StringVar(&addWatch, "watch", "", "Continuous screenshot mode (optional: interval in seconds)")
 	addCmd.Flags().BoolVar(&addQR, "qr", false, "Print a scannable QR code of the share URL (with --public/--password)")
 	addCmd.Flags().IntVar(&addMaxViews, "max-views", 0, "Burn-after-read: delete the share after N views (with --public/--password)")
 	addCmd.Flags().BoolVarP(&addEncrypt, "encrypt", "e", false, "Encrypt client-side before upload (zero-knowledge; server never sees plaintext)")
 	addCmd.Flags().StringVar(&addEncPass, "enc-pass", "", "Encryption passphrase (else prompt or NIKTE_PASSPHRASE)")
+	addCmd.Flags().BoolVar(&addGzip, "gzip", false, "Compress a file with gzip before upload, via a temporary copy (stored with a .gz extension)")
+	addCmd.Flags().BoolVar(&addZstd, "zstd", false, "Compress a file with zstd before upload, via a temporary copy (stored with a .zst extension)")
+	addCmd.Flags().IntVarP(&addJobs, "jobs", "j", defaultAddJobs, "Files uploaded at once when adding several")
 
 	rootCmd.AddCommand(addCmd)
 }
@@ -81,6 +118,10 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		input = args[0]
 	}
 
+	if err := resolvePasswordFlag(&addPassword, addPwPrompt); err != nil {
+		return err
+	}
+
 	// Encryption is for private at-rest storage: a public/password share of an
 	// encrypted item would only expose unusable ciphertext to the recipient (it's
 	// not their account, so they can't `nk g` to decrypt). Refuse the combination.
@@ -93,32 +134,71 @@ To share a secret, use a password-protected or burn-after-read share instead:
   nk sh <id> --password <pw>`)
 	}
 
+	if addGzip && addZstd {
+		return fmt.Errorf("--gzip and --zstd cannot be combined")
+	}
+
+	if isMultiUpload(args) {
+		return addFiles(cmd.Context(), args)
+	}
+	if len(args) > 1 {
+		// Unquoted words: nk a hello world
+		input = strings.Join(args, " ")
+	}
+
+	addResult = itemResult{}
+	if err := addInput(cmd.Context(), input); err != nil {
+		return err
+	}
+	if addResult.ID == "" {
+		return nil
+	}
+	noteHistory(addResult)
+	return emitResult(addResult)
+}
+
+// addInput uploads input (a file path, text, "sc" for a screenshot, or the
+// clipboard when empty), recording what was created in addResult.
+func addInput(ctx context.Context, input string) error {
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
 
 	// Case 1: Screenshot command "nk a sc"
 	if input == "sc" {
-		return handleScreenshot(s)
+		return handleScreenshot(ctx, s)
 	}
 
 	// Case 2: File path provided
 	if input != "" {
 		if fileInfo, err := os.Stat(input); err == nil && !fileInfo.IsDir() {
-			return handleFileUpload(input, s)
+			return handleFileUpload(ctx, input, s)
 		}
 	}
 
+	if addGzip || addZstd {
+		return fmt.Errorf("--gzip and --zstd only apply to file uploads")
+	}
+
 	// Case 3: Direct text content provided
 	if input != "" {
-		return handleTextContent(input, s)
+		return handleTextContent(ctx, input, s)
 	}
 
 	// Case 4: No input - read from clipboard
-	return handleClipboard(s)
+	return handleClipboard(ctx, s)
 }
 
-func handleScreenshot(s *spinner.Spinner) error {
+func handleScreenshot(ctx context.Context, s *spinner.Spinner) error {
+	if addDisplay == "list" {
+		return listDisplays()
+	}
+	if addWindow == "list" {
+		return listWindows()
+	}
+	if addRegion == "list" {
+		return listRegions()
+	}
 	if !platform.IsScreenshotSupported() {
-		return fmt.Errorf("screenshot capture is only supported on macOS")
+		return platform.ErrScreenshotUnsupported
 	}
 
 	// Check for watch mode
@@ -126,39 +206,188 @@ func handleScreenshot(s *spinner.Spinner) error {
 		return handleWatchMode(s)
 	}
 
-	fmt.Println("Select area for screenshot...")
-	imageData, err := platform.CaptureScreenshot(addWindow, addFullscreen)
+	imageData, err := captureScreenshot()
 	if err != nil {
 		return err
 	}
 	if imageData == nil {
-		fmt.Println("Screenshot cancelled")
+		fmt.Fprintln(info, "Screenshot cancelled")
 		return nil
 	}
 
 	s.Suffix = " Uploading screenshot..."
 	s.Start()
-	return uploadImage(imageData, s, "screenshot")
+	return uploadImage(ctx, imageData, s, "screenshot")
+}
+
+// captureScreenshot captures what the screenshot flags ask for: a region, a
+// display, a window, or otherwise an area selected (or window clicked) by the
+// user.
+func captureScreenshot() ([]byte, error) {
+	if addRegion != "" {
+		r, err := resolveRegion()
+		if err != nil {
+			return nil, err
+		}
+		return platform.CaptureRegion(r.X, r.Y, r.Width, r.Height)
+	}
+	if addSaveRegion != "" {
+		return nil, fmt.Errorf("--save-region needs the area to save: --region x,y,width,height")
+	}
+	if addDisplay != "" {
+		n, err := strconv.Atoi(addDisplay)
+		if err != nil {
+			return nil, fmt.Errorf("--display takes a display number or \"list\", not %q", addDisplay)
+		}
+		return platform.CaptureDisplay(n)
+	}
+	if addWindow != "" {
+		w, ok, err := chooseWindow()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return platform.CaptureWindow(w)
+		}
+		fmt.Fprintln(info, "Click the window to capture...")
+		return platform.CaptureScreenshot(true, false)
+	}
+	fmt.Fprintln(info, "Select area for screenshot...")
+	return platform.CaptureScreenshot(false, addFullscreen)
+}
+
+// listDisplays prints the displays --display can capture.
+func listDisplays() error {
+	displays, err := platform.ListDisplays()
+	if err != nil {
+		return err
+	}
+	if machineOutput() {
+		return emitResult(displays)
+	}
+	for _, d := range displays {
+		fmt.Fprintln(stdout, d)
+	}
+	return nil
 }
 
 func handleWatchMode(s *spinner.Spinner) error {
 	// Simplified watch mode - just capture once for now
-	fmt.Println("Watch mode not yet implemented in Go version")
+	fmt.Fprintln(info, "Watch mode not yet implemented in Go version")
 	return nil
 }
 
-func handleFileUpload(filePath string, s *spinner.Spinner) error {
-	fileInfo, err := os.Stat(filePath)
+// uploadReporter is how a file upload reports what it is doing: a single
+// upload through a spinner and info lines, queued ones through a line each
+// in a shared display.
+type uploadReporter interface {
+	Status(msg string)                               // the step under way, e.g. "Initializing upload..."
+	Infof(format string, args ...any)                // a detail worth keeping on screen
+	Warnf(format string, args ...any)                // a problem the user may need to act on
+	Progress(completed, total int, done, size int64) // parts and bytes uploaded so far
+	Stop()                                           // clears any status before the caller prints
+}
+
+// spinnerReporter reports a single upload with a spinner.
+type spinnerReporter struct {
+	s     *spinner.Spinner
+	meter *upload.Meter
+}
+
+func (r *spinnerReporter) Status(msg string) {
+	r.s.Suffix = " " + msg
+	r.s.Start()
+}
+
+func (r *spinnerReporter) Infof(format string, args ...any) {
+	r.s.Stop()
+	fmt.Fprintf(info, format+"\n", args...)
+}
+
+func (r *spinnerReporter) Warnf(format string, args ...any) {
+	r.s.Stop()
+	fmt.Fprintf(stderr, format+"\n", args...)
+}
+
+func (r *spinnerReporter) Progress(completed, total int, done, size int64) {
+	if r.meter == nil {
+		r.meter = upload.NewMeter(size)
+	}
+	r.meter.Update(done)
+	progress := util.CreateProgressBar(done, size, 30)
+	r.Status(fmt.Sprintf("Uploading %d/%d parts... %s %s/%s %s", completed, total, progress,
+		util.FormatBytes(done), util.FormatBytes(size), r.meter))
+}
+
+func (r *spinnerReporter) Stop() {
+	r.s.Stop()
+}
+
+// batchReporter reports one upload of a batch (nk sync, nk import) whose
+// spinner already names the file: only warnings are shown, prefixed with it.
+type batchReporter struct {
+	s    *spinner.Spinner
+	name string
+}
+
+func (r *batchReporter) Status(msg string)                               {}
+func (r *batchReporter) Infof(format string, args ...any)                {}
+func (r *batchReporter) Progress(completed, total int, done, size int64) {}
+
+func (r *batchReporter) Warnf(format string, args ...any) {
+	r.s.Stop()
+	fmt.Fprintf(stderr, "! %s: %s\n", r.name, fmt.Sprintf(format, args...))
+}
+
+func (r *batchReporter) Stop() {
+	r.s.Stop()
+}
+
+func handleFileUpload(ctx context.Context, filePath string, s *spinner.Spinner) error {
+	r := &spinnerReporter{s: s}
+	result, err := uploadFile(ctx, filePath, r)
+	r.Stop()
 	if err != nil {
 		return err
 	}
 
+	fmt.Fprintln(info, "Upload complete!")
+	fmt.Fprintln(info)
+	fmt.Fprintf(info, "ID: %s\n", result.ID)
+	addResult = *result
+	if result.ExpiresAt > 0 {
+		fmt.Fprintf(info, "Expires: %s\n", util.FormatExpiryTime(result.ExpiresAt))
+	} else {
+		fmt.Fprintln(info, "Expires: never (permanent)")
+	}
+
+	// Copy ID to clipboard
+	copyToClipboard(result.ID, "ID", autoCopyID)
+
+	// Handle sharing if requested
+	if addPublic || addPassword != "" {
+		return createShare(ctx, result.ID, "short")
+	}
+
+	return nil
+}
+
+// uploadFile uploads the file at filePath with the add flags applied
+// (compression, encryption, TTL), resuming an earlier interrupted attempt
+// where it can, and returns what it created. A stored file that doesn't match
+// the local one is reported through r and marked in the result.
+func uploadFile(ctx context.Context, filePath string, r uploadReporter) (*itemResult, error) {
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+
 	if fileInfo.Size() == 0 {
-		return fmt.Errorf("cannot upload empty file")
+		return nil, fmt.Errorf("cannot upload empty file")
 	}
 
 	if fileInfo.Size() > maxFileSizeBytes {
-		return fmt.Errorf("file too large. Maximum size is 10GB, file is %s", util.FormatBytes(fileInfo.Size()))
+		return nil, fmt.Errorf("file too large. Maximum size is 10GB, file is %s", util.FormatBytes(fileInfo.Size()))
 	}
 
 	filename := filepath.Base(filePath)
@@ -166,149 +395,219 @@ func handleFileUpload(filePath string, s *spinner.Spinner) error {
 	fileSize := fileInfo.Size()
 	ttlSeconds := calculateTTL(true)
 
-	fmt.Printf("File: %s\n", filename)
-	fmt.Printf("Size: %s\n", util.FormatBytes(fileSize))
-	fmt.Printf("Type: %s\n", contentType)
+	r.Infof("File: %s", filename)
+	r.Infof("Size: %s", util.FormatBytes(fileSize))
+	r.Infof("Type: %s", contentType)
 
-	s.Suffix = " Reading file..."
-	s.Start()
-
-	fileData, err := os.ReadFile(filePath)
+	// Parts are read from the file as they upload rather than loading it
+	// into memory.
+	file, err := os.Open(filePath)
 	if err != nil {
-		s.Stop()
-		return err
+		return nil, err
+	}
+	defer file.Close()
+	var fileData io.ReaderAt = file
+
+	// Optional compression streams the file into a compressed temporary
+	// copy, which is uploaded in its place.
+	var compression string
+	if addGzip || addZstd {
+		c, _ := upload.CompressionFor(upload.Gzip)
+		if addZstd {
+			c, _ = upload.CompressionFor(upload.Zstd)
+		}
+		r.Status("Compressing...")
+		tmp, err := upload.CompressFile(ctx, file, fileInfo.Size(), c)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		tmpInfo, err := tmp.Stat()
+		if err != nil {
+			return nil, err
+		}
+		fileData = tmp
+		compression = c.Name
+		filename += c.Extension
+		contentType = c.ContentType
+		fileSize = tmpInfo.Size()
+		r.Infof("Compressed: %s (%s, %d%% of original)", filename, util.FormatBytes(fileSize), fileSize*100/fileInfo.Size())
 	}
 
-	s.Stop()
-	fmt.Println("File read successfully")
-
 	// Client-side encryption: encrypt the bytes before upload. The ciphertext is
 	// self-describing and the filename is marked so `nk g` can decrypt it back.
+	// The whole file is encrypted at once, so it has to be read into memory.
 	if addEncrypt {
 		pass, err := resolvePassphrase(addEncPass, true)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		enc, err := crypto.EncryptBytes(pass, fileData)
+		r.Status("Reading file...")
+		plain, err := io.ReadAll(io.NewSectionReader(fileData, 0, fileSize))
 		if err != nil {
-			return fmt.Errorf("encryption failed: %w", err)
+			return nil, err
+		}
+		enc, err := crypto.EncryptBytes(pass, plain)
+		if err != nil {
+			return nil, fmt.Errorf("encryption failed: %w", err)
 		}
-		fileData = enc
+		fileData = bytes.NewReader(enc)
 		filename += crypto.FileSuffix
 		contentType = "application/octet-stream"
-		fileSize = int64(len(fileData))
-		fmt.Printf("Encrypted: %s (%s)\n", filename, util.FormatBytes(fileSize))
+		fileSize = int64(len(enc))
+		r.Infof("Encrypted: %s (%s)", filename, util.FormatBytes(fileSize))
 	}
 
-	// Initialize multipart upload
-	s.Suffix = " Initializing upload..."
-	s.Start()
-
-	initBody := map[string]interface{}{
-		"filename":    filename,
-		"contentType": contentType,
-		"fileSize":    fileSize,
-	}
-	if addPermanent {
-		initBody["ttl"] = "permanent"
-	} else if ttlSeconds > 0 {
-		initBody["ttl"] = fmt.Sprintf("%ds", ttlSeconds)
+	// An upload interrupted earlier picks up where it stopped. Encrypted and
+	// compressed uploads always start over, since what is sent is rebuilt on
+	// every run.
+	resumable := !addEncrypt && compression == ""
+	var state *upload.ResumeState
+	if resumable {
+		state = upload.LoadResumeState(filePath, fileInfo)
 	}
 
-	resp, err := api.Post("/shorts/file/init", initBody)
-	if err != nil {
-		s.Stop()
-		return err
+	var initResp *models.InitUploadResponse
+	if state != nil {
+		initResp, err = resumeUpload(ctx, state, r)
+		if err != nil {
+			return nil, err
+		}
+		if initResp == nil {
+			state = nil
+		}
 	}
 
-	if resp.StatusCode != 201 {
-		s.Stop()
-		return fmt.Errorf("failed to initialize upload: %s", resp.GetString("message"))
-	}
+	if initResp == nil {
+		// Initialize multipart upload
+		r.Status("Initializing upload...")
 
-	var initResp struct {
-		ShortID       string `json:"shortId"`
-		PresignedUrls []struct {
-			PartNumber int    `json:"partNumber"`
-			URL        string `json:"url"`
-		} `json:"presignedUrls"`
-		PartSize  int   `json:"partSize"`
-		ExpiresAt int64 `json:"expiresAt"`
-	}
-	if err := resp.Unmarshal(&initResp); err != nil {
-		s.Stop()
-		return err
-	}
+		initBody := models.InitUploadRequest{
+			Filename:    filename,
+			ContentType: contentType,
+			FileSize:    fileSize,
+			PartSize:    config.GetTuning().UploadPartSize,
+			Compression: compression,
+		}
+		if compression != "" {
+			initBody.OriginalSize = fileInfo.Size()
+		}
+		if initBody.PartSize == 0 {
+			initBody.PartSize = upload.PartSizeFor(fileSize)
+		}
+		if addPermanent {
+			initBody.TTL = "permanent"
+		} else if ttlSeconds > 0 {
+			initBody.TTL = fmt.Sprintf("%ds", ttlSeconds)
+		}
 
-	s.Stop()
-	fmt.Printf("Upload initialized (ID: %s)\n", initResp.ShortID)
-
-	// Convert presigned URLs to upload.PresignedURL type
-	presignedUrls := make([]upload.PresignedURL, len(initResp.PresignedUrls))
-	for i, pu := range initResp.PresignedUrls {
-		presignedUrls[i] = upload.PresignedURL{
-			PartNumber: pu.PartNumber,
-			URL:        pu.URL,
+		resp, err := api.Do(ctx, "POST", "/shorts/file/init", initBody)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize upload: %w", err)
+		}
+
+		initResp = &models.InitUploadResponse{}
+		if err := resp.Unmarshal(initResp); err != nil {
+			return nil, err
+		}
+
+		r.Infof("Upload initialized (ID: %s)", initResp.ShortID)
+
+		if resumable {
+			state = upload.NewResumeState(filePath, fileInfo, initResp.ShortID, initResp.PartSize, len(initResp.PresignedURLs), initResp.ExpiresAt)
 		}
 	}
 
+	presignedUrls := initResp.PresignedURLs
+	opts := upload.Options{
+		OnProgress: r.Progress,
+		Refresh: func(ctx context.Context, parts []int) ([]upload.PresignedURL, error) {
+			fresh, err := presignParts(ctx, initResp.ShortID, parts)
+			if err != nil {
+				return nil, err
+			}
+			return fresh.PresignedURLs, nil
+		},
+	}
+	if state != nil {
+		opts.OnPart = state.Add
+	}
+
 	// Upload parts
 	totalParts := len(presignedUrls)
-	s.Suffix = fmt.Sprintf(" Uploading 0/%d parts...", totalParts)
-	s.Start()
+	r.Status(fmt.Sprintf("Uploading 0/%d parts...", totalParts))
 
-	completedParts, err := upload.UploadParts(presignedUrls, fileData, initResp.PartSize, func(completed, total int, completedBytes, totalBytes int64) {
-		progress := util.CreateProgressBar(completedBytes, totalBytes, 30)
-		s.Suffix = fmt.Sprintf(" Uploading %d/%d parts... %s %s/%s", completed, total, progress, util.FormatBytes(completedBytes), util.FormatBytes(totalBytes))
-	})
+	completedParts, err := upload.UploadParts(ctx, presignedUrls, fileData, fileSize, initResp.PartSize, opts)
 	if err != nil {
-		s.Stop()
-		return err
+		if state != nil && len(state.Completed()) > 0 {
+			r.Warnf("Upload interrupted after %d of %d parts. Run the same command again to resume, or discard it with: nk d %s",
+				len(state.Completed()), state.TotalParts, initResp.ShortID)
+			return nil, err
+		}
+		abortUpload(ctx, initResp.ShortID, r)
+		return nil, err
+	}
+	if state != nil {
+		completedParts = state.Completed()
 	}
 
-	s.Stop()
-	fmt.Printf("Uploaded %d parts\n", totalParts)
+	r.Infof("Uploaded %d parts", totalParts)
 
 	// Complete multipart upload
-	s.Suffix = " Finalizing upload..."
-	s.Start()
+	r.Status("Finalizing upload...")
 
-	completeResp, err := api.Post("/shorts/file/complete", map[string]interface{}{
-		"shortId": initResp.ShortID,
-		"parts":   completedParts,
+	resp, err := api.Do(ctx, "POST", "/shorts/file/complete", models.CompleteUploadRequest{
+		ShortID: initResp.ShortID,
+		Parts:   completedParts,
 	})
 	if err != nil {
-		s.Stop()
-		return err
-	}
-
-	if completeResp.StatusCode != 200 {
-		s.Stop()
-		return fmt.Errorf("failed to complete upload: %s", completeResp.GetString("message"))
-	}
-
-	s.Stop()
-	fmt.Println("Upload complete!")
-	fmt.Println()
-	fmt.Printf("ID: %s\n", initResp.ShortID)
-	if initResp.ExpiresAt > 0 {
-		fmt.Printf("Expires: %s\n", util.FormatExpiryTime(initResp.ExpiresAt))
-	} else {
-		fmt.Println("Expires: never (permanent)")
-	}
-
-	// Copy ID to clipboard
-	copyToClipboard(initResp.ShortID, "ID")
-
-	// Handle sharing if requested
-	if addPublic || addPassword != "" {
-		return createShare(initResp.ShortID, "short")
+		// Every part is stored, so unless the server rejected them the
+		// complete call alone is retried on resume.
+		var apiErr *api.APIError
+		if state != nil && !(errors.As(err, &apiErr) && apiErr.StatusCode < 500) {
+			r.Warnf("Upload could not be finalized. Run the same command again to retry, or discard it with: nk d %s", initResp.ShortID)
+			return nil, fmt.Errorf("failed to complete upload: %w", err)
+		}
+		if state != nil {
+			state.Remove()
+		}
+		abortUpload(ctx, initResp.ShortID, r)
+		return nil, fmt.Errorf("failed to complete upload: %w", err)
+	}
+	if state != nil {
+		state.Remove()
+	}
+
+	// Check the stored object against the local data while the local copy
+	// is still around. Servers that don't report an ETag are trusted.
+	var completed models.CompleteUploadResponse
+	mismatch := false
+	if err := resp.Unmarshal(&completed); err == nil && completed.ETag != "" {
+		r.Status("Verifying upload...")
+		want, ok, err := upload.VerifyETag(fileData, fileSize, initResp.PartSize, completed.ETag)
+		switch {
+		case err != nil:
+			r.Warnf("Could not verify the upload: %v", err)
+		case !ok || (completed.Size > 0 && completed.Size != fileSize):
+			r.Warnf("The stored file does not match the local one (ETag %s, expected %s). Keep your local copy and upload it again.",
+				completed.ETag, want)
+			mismatch = true
+		}
 	}
 
-	return nil
+	return &itemResult{
+		ID:          initResp.ShortID,
+		Type:        "file",
+		Filename:    filename,
+		Size:        fileSize,
+		ContentType: contentType,
+		ExpiresAt:   initResp.ExpiresAt,
+		mismatch:    mismatch,
+	}, nil
 }
 
-func handleTextContent(content string, s *spinner.Spinner) error {
+func handleTextContent(ctx context.Context, content string, s *spinner.Spinner) error {
 	contentBytes := len(content)
 	if contentBytes > maxTextSizeBytes {
 		return fmt.Errorf("content exceeds maximum size of %dKB (current: %.2fKB)",
@@ -318,30 +617,28 @@ func handleTextContent(content string, s *spinner.Spinner) error {
 	s.Suffix = " Creating item..."
 	s.Start()
 
-	return uploadTextContent(content, s)
+	return uploadTextContent(ctx, content, s)
 }
 
-func handleClipboard(s *spinner.Spinner) error {
+func handleClipboard(ctx context.Context, s *spinner.Spinner) error {
 	s.Suffix = " Reading clipboard..."
 	s.Start()
 
-	// Check for image first (macOS only)
-	if platform.IsScreenshotSupported() {
-		if platform.ClipboardHasImage() {
-			imageData, err := platform.GetClipboardImage()
-			if err == nil && imageData != nil {
-				s.Stop()
-				fmt.Println("Clipboard image read successfully")
-				uploadSpinner := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-				uploadSpinner.Suffix = " Uploading image..."
-				uploadSpinner.Start()
-				return uploadImage(imageData, uploadSpinner, "clipboard")
-			}
+	// Check for image first (macOS, and Linux under Wayland or X11)
+	if platform.ClipboardHasImage() {
+		imageData, err := platform.GetClipboardImage()
+		if err == nil && imageData != nil {
+			s.Stop()
+			fmt.Fprintln(info, "Clipboard image read successfully")
+			uploadSpinner := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+			uploadSpinner.Suffix = " Uploading image..."
+			uploadSpinner.Start()
+			return uploadImage(ctx, imageData, uploadSpinner, "clipboard")
 		}
 	}
 
 	// Try to read text from clipboard
-	text, err := clipboard.ReadAll()
+	text, err := platform.PasteText()
 	if err != nil {
 		s.Stop()
 		return fmt.Errorf("failed to read clipboard: %w", err)
@@ -353,16 +650,16 @@ func handleClipboard(s *spinner.Spinner) error {
 	}
 
 	s.Stop()
-	fmt.Println("Clipboard content read successfully")
+	fmt.Fprintln(info, "Clipboard content read successfully")
 
 	createSpinner := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
 	createSpinner.Suffix = " Creating item..."
 	createSpinner.Start()
 
-	return uploadTextContent(text, createSpinner)
+	return uploadTextContent(ctx, text, createSpinner)
 }
 
-func uploadTextContent(content string, s *spinner.Spinner) error {
+func uploadTextContent(ctx context.Context, content string, s *spinner.Spinner) error {
 	// Client-side encryption: replace content with a self-describing ciphertext
 	// blob before it ever leaves the machine.
 	if addEncrypt {
@@ -381,123 +678,167 @@ func uploadTextContent(content string, s *spinner.Spinner) error {
 
 	ttlSeconds := calculateTTL(false)
 
-	body := map[string]interface{}{
-		"content": content,
-	}
-	if addPermanent {
-		body["ttl"] = 0
-	} else if ttlSeconds > 0 {
-		body["ttl"] = ttlSeconds
+	body := models.CreateShortRequest{Content: content}
+	if addPermanent || ttlSeconds > 0 {
+		body.TTL = &ttlSeconds
 	}
 
-	resp, err := api.Post("/shorts", body)
+	resp, err := api.Do(ctx, "POST", "/shorts", body)
+	s.Stop()
+	if errors.Is(err, api.ErrTooLarge) {
+		return fmt.Errorf("content too large: %w", err)
+	}
 	if err != nil {
-		s.Stop()
-		return err
+		return fmt.Errorf("failed to create item: %w", err)
 	}
 
-	s.Stop()
-
-	if resp.StatusCode == 201 {
-		fmt.Println("Item created successfully")
-
-		var result struct {
-			ShortID   string `json:"shortId"`
-			ExpiresAt int64  `json:"expiresAt"`
-		}
-		if err := resp.Unmarshal(&result); err != nil {
-			return err
-		}
-
-		fmt.Printf("\nID: %s\n", result.ShortID)
-		if result.ExpiresAt > 0 {
-			fmt.Printf("Expires: %s\n", util.FormatExpiryTime(result.ExpiresAt))
-		} else {
-			fmt.Println("Expires: never (permanent)")
-		}
+	fmt.Fprintln(info, "Item created successfully")
 
-		copyToClipboard(result.ShortID, "ID")
-
-		// Handle sharing if requested
-		if addPublic || addPassword != "" {
-			return createShare(result.ShortID, "short")
-		}
+	var result models.CreateShortResponse
+	if err := resp.Unmarshal(&result); err != nil {
+		return err
+	}
 
-		return nil
+	fmt.Fprintf(info, "\nID: %s\n", result.ShortID)
+	addResult = itemResult{ID: result.ShortID, Type: "text", ExpiresAt: result.ExpiresAt}
+	if result.ExpiresAt > 0 {
+		fmt.Fprintf(info, "Expires: %s\n", util.FormatExpiryTime(result.ExpiresAt))
+	} else {
+		fmt.Fprintln(info, "Expires: never (permanent)")
 	}
 
-	if resp.StatusCode == 413 {
-		return fmt.Errorf("content too large: %s", resp.GetString("message"))
+	copyToClipboard(result.ShortID, "ID", autoCopyID)
+
+	// Handle sharing if requested
+	if addPublic || addPassword != "" {
+		return createShare(ctx, result.ShortID, "short")
 	}
 
-	return fmt.Errorf("failed to create item: %s", resp.GetString("message"))
+	return nil
 }
 
-func uploadImage(imageData []byte, s *spinner.Spinner, source string) error {
+func uploadImage(ctx context.Context, imageData []byte, s *spinner.Spinner, source string) error {
 	ttlSeconds := calculateTTL(true)
 	base64Data := base64.StdEncoding.EncodeToString(imageData)
 
-	body := map[string]interface{}{
-		"contentType": "image/png",
-		"data":        base64Data,
+	// Screenshots are PNG, but a copied image may be in another format.
+	contentType := http.DetectContentType(imageData)
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = "image/png"
+	}
+
+	body := models.CreateScreenshotRequest{
+		ContentType: contentType,
+		Data:        base64Data,
+		TTL:         "24h",
 	}
 	if addPermanent {
-		body["ttl"] = "permanent"
+		body.TTL = "permanent"
 	} else if ttlSeconds > 0 {
-		body["ttl"] = fmt.Sprintf("%ds", ttlSeconds)
-	} else {
-		body["ttl"] = "24h"
+		body.TTL = fmt.Sprintf("%ds", ttlSeconds)
 	}
 
-	resp, err := api.Post("/screenshots", body)
+	resp, err := api.Do(ctx, "POST", "/screenshots", body)
+	s.Stop()
+	if errors.Is(err, api.ErrTooLarge) {
+		return fmt.Errorf("image too large: %w", err)
+	}
 	if err != nil {
-		s.Stop()
-		return err
+		return fmt.Errorf("failed to upload image: %w", err)
 	}
 
-	s.Stop()
+	fmt.Fprintln(info, "Image uploaded successfully")
 
-	if resp.StatusCode == 201 {
-		fmt.Println("Image uploaded successfully")
-
-		var result struct {
-			ScreenshotID string `json:"screenshotId"`
-			ExpiresAt    int64  `json:"expiresAt"`
-		}
-		if err := resp.Unmarshal(&result); err != nil {
-			return err
-		}
+	var result models.CreateScreenshotResponse
+	if err := resp.Unmarshal(&result); err != nil {
+		return err
+	}
+	addResult = itemResult{ID: result.ScreenshotID, Type: "screenshot", ContentType: contentType, ExpiresAt: result.ExpiresAt}
 
-		// Get the download URL
-		urlResp, err := api.Get(fmt.Sprintf("/screenshots/%s", result.ScreenshotID))
-		if err == nil && urlResp.StatusCode == 200 {
-			var urlResult struct {
-				DownloadURL string `json:"downloadUrl"`
-			}
-			if err := urlResp.Unmarshal(&urlResult); err == nil {
-				fmt.Printf("\nID: %s\n", result.ScreenshotID)
-				fmt.Printf("URL: %s\n", urlResult.DownloadURL)
-				if result.ExpiresAt > 0 {
-					fmt.Printf("Expires: %s\n", util.FormatExpiryTime(result.ExpiresAt))
-				}
-
-				copyToClipboard(urlResult.DownloadURL, "URL")
-			}
-		} else {
-			fmt.Printf("\nID: %s\n", result.ScreenshotID)
+	// Get the download URL
+	urlResp, err := api.GetContext(ctx, fmt.Sprintf("/screenshots/%s", result.ScreenshotID))
+	if err == nil && urlResp.StatusCode == 200 {
+		var urlResult models.Screenshot
+		if err := urlResp.Unmarshal(&urlResult); err == nil {
+			fmt.Fprintf(info, "\nID: %s\n", result.ScreenshotID)
+			fmt.Fprintf(info, "URL: %s\n", urlResult.DownloadURL)
+			addResult.URL = urlResult.DownloadURL
 			if result.ExpiresAt > 0 {
-				fmt.Printf("Expires: %s\n", util.FormatExpiryTime(result.ExpiresAt))
+				fmt.Fprintf(info, "Expires: %s\n", util.FormatExpiryTime(result.ExpiresAt))
 			}
+
+			copyToClipboard(urlResult.DownloadURL, "URL", autoCopyURL)
 		}
+	} else {
+		fmt.Fprintf(info, "\nID: %s\n", result.ScreenshotID)
+		if result.ExpiresAt > 0 {
+			fmt.Fprintf(info, "Expires: %s\n", util.FormatExpiryTime(result.ExpiresAt))
+		}
+	}
 
-		return nil
+	return nil
+}
+
+// resumeUpload asks the server for URLs for the parts of an interrupted
+// upload that are still missing. It returns nil, with the state removed, if
+// the upload can no longer be resumed and should start over.
+func resumeUpload(ctx context.Context, state *upload.ResumeState, r uploadReporter) (*models.InitUploadResponse, error) {
+	missing := state.Missing()
+	r.Status("Resuming upload...")
+	resumed, err := presignParts(ctx, state.ShortID, missing)
+
+	var apiErr *api.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode < 500 {
+		state.Remove()
+		r.Infof("Previous upload %s can no longer be resumed (%v); starting over", state.ShortID, err)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume upload %s: %w", state.ShortID, err)
 	}
 
-	if resp.StatusCode == 413 {
-		return fmt.Errorf("image too large: %s", resp.GetString("message"))
+	resumed.ShortID = state.ShortID
+	resumed.PartSize = state.PartSize
+	if resumed.ExpiresAt == 0 {
+		resumed.ExpiresAt = state.ExpiresAt
 	}
+	r.Infof("Resuming upload %s: %d of %d parts already uploaded",
+		state.ShortID, state.TotalParts-len(missing), state.TotalParts)
+	return resumed, nil
+}
 
-	return fmt.Errorf("failed to upload image: %s", resp.GetString("message"))
+// presignParts asks for new upload URLs for parts of an unfinished upload,
+// to resume it or to replace URLs that expired mid-upload.
+func presignParts(ctx context.Context, shortID string, parts []int) (*models.InitUploadResponse, error) {
+	resp, err := api.Do(ctx, "POST", "/shorts/file/parts", models.UploadPartsRequest{
+		ShortID:     shortID,
+		PartNumbers: parts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var presigned models.InitUploadResponse
+	if err := resp.Unmarshal(&presigned); err != nil {
+		return nil, err
+	}
+	return &presigned, nil
+}
+
+// abortTimeout bounds the abort request sent after a failed or cancelled
+// upload.
+const abortTimeout = 10 * time.Second
+
+// abortUpload asks the backend to discard an unfinished multipart upload so
+// it doesn't linger against the quota. It runs even when ctx was cancelled;
+// if it fails, the orphaned ID is reported so it can be deleted by hand.
+func abortUpload(ctx context.Context, shortID string, r uploadReporter) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), abortTimeout)
+	defer cancel()
+	if _, err := api.Do(ctx, "POST", "/shorts/file/abort", models.AbortUploadRequest{ShortID: shortID}); err != nil {
+		r.Warnf("Could not discard the unfinished upload %s (%v). Remove it with: nk d %s", shortID, err, shortID)
+		return
+	}
+	r.Infof("Discarded the unfinished upload %s", shortID)
 }
 
 func calculateTTL(isFile bool) int {
@@ -518,8 +859,8 @@ func calculateTTL(isFile bool) int {
 	return ttlSeconds
 }
 
-func createShare(itemID, itemType string) error {
-	fmt.Println("\nCreating share link...")
+func createShare(ctx context.Context, itemID, itemType string) error {
+	fmt.Fprintln(info, "\nCreating share link...")
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
 	s.Suffix = " Creating share..."
 	s.Start()
@@ -529,58 +870,32 @@ func createShare(itemID, itemType string) error {
 		endpoint = fmt.Sprintf("/screenshots/%s/share", itemID)
 	}
 
-	body := map[string]interface{}{
-		"isPublic": addPublic || addPassword == "",
-	}
-	if addPassword != "" {
-		body["password"] = addPassword
-	}
-	if addTitle != "" {
-		body["title"] = addTitle
-	}
-	if addDesc != "" {
-		body["description"] = addDesc
-	}
-	if addMaxViews > 0 {
-		body["maxViews"] = addMaxViews
+	body := models.ShareRequest{
+		IsPublic:    addPublic || addPassword == "",
+		Password:    addPassword,
+		Title:       addTitle,
+		Description: addDesc,
+		MaxViews:    addMaxViews,
 	}
 
-	resp, err := api.Post(endpoint, body)
+	resp, err := api.Do(ctx, "POST", endpoint, body)
+	s.Stop()
 	if err != nil {
-		s.Stop()
 		return fmt.Errorf("failed to create share: %w", err)
 	}
 
-	s.Stop()
-
-	if resp.StatusCode == 200 || resp.StatusCode == 201 {
-		fmt.Println("Share link created!")
+	fmt.Fprintln(info, "Share link created!")
 
-		var shareResult struct {
-			ShareURL string `json:"shareUrl"`
-			URL      string `json:"url"`
-		}
-		if err := resp.Unmarshal(&shareResult); err == nil {
-			shareURL := shareResult.ShareURL
-			if shareURL == "" {
-				shareURL = shareResult.URL
-			}
-			if shareURL != "" {
-				fmt.Printf("\nShare URL: %s\n", shareURL)
-				copyToClipboard(shareURL, "Share URL")
-				if addQR {
-					printQR(shareURL)
-				}
+	var shareResult models.Share
+	if err := resp.Unmarshal(&shareResult); err == nil {
+		if shareURL := shareResult.Link(); shareURL != "" {
+			fmt.Fprintf(info, "\nShare URL: %s\n", shareURL)
+			addResult.ShareURL = shareURL
+			copyToClipboard(shareURL, "Share URL", autoCopyURL)
+			if addQR {
+				printQR(shareURL)
 			}
 		}
-		return nil
-	}
-
-	return fmt.Errorf("failed to create share: %s", resp.GetString("message"))
-}
-
-func copyToClipboard(text, label string) {
-	if err := clipboard.WriteAll(text); err == nil {
-		fmt.Printf("\n(%s copied to clipboard)\n", label)
 	}
+	return nil
 }
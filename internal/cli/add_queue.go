@@ -0,0 +1,313 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/upload"
+	"github.com/sim4gh/nikte-cli/internal/util"
+	"golang.org/x/term"
+)
+
+// addJobs is the --jobs flag: how many files `nk a` uploads at once when
+// given several.
+var addJobs int
+
+// defaultAddJobs is the default for --jobs. Each file also uploads
+// upload_concurrency parts in parallel.
+const defaultAddJobs = 3
+
+// queueRedrawInterval is how often the live queue display is redrawn.
+const queueRedrawInterval = 200 * time.Millisecond
+
+// isMultiUpload reports whether the add arguments name more than one file:
+// several arguments, a directory, or a glob pattern the shell didn't expand.
+// Every argument must be an existing path or a pattern matching something,
+// so text such as "Did you see this?" or unquoted words stay a text item.
+func isMultiUpload(inputs []string) bool {
+	multi := len(inputs) > 1
+	for _, input := range inputs {
+		if fi, err := os.Stat(input); err == nil {
+			multi = multi || fi.IsDir()
+			continue
+		}
+		if !strings.ContainsAny(input, "*?[") {
+			return false
+		}
+		if matches, err := filepath.Glob(input); err != nil || len(matches) == 0 {
+			return false
+		}
+		multi = true
+	}
+	return multi
+}
+
+// collectUploadFiles expands the add arguments into the files to upload.
+// Directories are walked for regular files, skipping hidden ones, and
+// patterns are globbed (quoted, or on shells that leave them alone).
+func collectUploadFiles(inputs []string) ([]string, error) {
+	var files []string
+	for _, input := range inputs {
+		fi, err := os.Stat(input)
+		switch {
+		case err == nil && fi.IsDir():
+			err := filepath.WalkDir(input, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if path != input && strings.HasPrefix(d.Name(), ".") {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if d.Type().IsRegular() {
+					files = append(files, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		case err == nil:
+			files = append(files, input)
+		case strings.ContainsAny(input, "*?["):
+			matches, err := filepath.Glob(input)
+			if err != nil {
+				return nil, fmt.Errorf("bad pattern %q: %w", input, err)
+			}
+			for _, m := range matches {
+				if fi, err := os.Stat(m); err == nil && fi.Mode().IsRegular() {
+					files = append(files, m)
+				}
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %s", input)
+			}
+		default:
+			return nil, err
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files to upload")
+	}
+	return files, nil
+}
+
+// addFiles uploads several files through a queue, addJobs at a time, with
+// one progress line per upload in flight. Every file is attempted; the
+// error reports how many failed.
+func addFiles(ctx context.Context, inputs []string) error {
+	if addPublic || addPassword != "" {
+		return fmt.Errorf("--public/--password share a single item; share uploaded files with: nk sh <id>")
+	}
+	files, err := collectUploadFiles(inputs)
+	if err != nil {
+		return err
+	}
+	// Ask for the passphrase once rather than once per file.
+	if addEncrypt {
+		pass, err := resolvePassphrase(addEncPass, true)
+		if err != nil {
+			return err
+		}
+		addEncPass = pass
+	}
+
+	d := newQueueDisplay(info, len(files))
+	results := make([]*itemResult, len(files))
+	errs := make([]error, len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < max(addJobs, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				r := d.start(filepath.Base(files[i]))
+				results[i], errs[i] = uploadFile(ctx, files[i], r)
+				d.finish(r, results[i], errs[i])
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	d.close()
+
+	var uploaded []itemResult
+	for i, res := range results {
+		if res != nil {
+			uploaded = append(uploaded, *res)
+			noted := *res
+			noted.Path = files[i]
+			noteHistory(noted)
+		}
+	}
+	if machineOutput() {
+		if err := emitResult(uploaded); err != nil {
+			return err
+		}
+	}
+	if failed := len(files) - len(uploaded); failed > 0 {
+		return fmt.Errorf("%d of %d uploads failed", failed, len(files))
+	}
+	fmt.Fprintf(info, "Uploaded %d files\n", len(uploaded))
+	return nil
+}
+
+// queueDisplay shows a queue of uploads: a line for each finished file,
+// printed once, under which a live region shows the overall count and a
+// progress bar per upload in flight. When w isn't a terminal only the
+// finished lines are printed.
+type queueDisplay struct {
+	mu       sync.Mutex
+	w        io.Writer
+	live     bool
+	total    int
+	finished int
+	active   []*queueReporter
+	drawn    int // lines in the live region as last drawn
+	done     chan struct{}
+}
+
+func newQueueDisplay(w io.Writer, total int) *queueDisplay {
+	d := &queueDisplay{w: w, total: total, done: make(chan struct{})}
+	if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		d.live = true
+		go func() {
+			tick := time.NewTicker(queueRedrawInterval)
+			defer tick.Stop()
+			for {
+				select {
+				case <-d.done:
+					return
+				case <-tick.C:
+					d.mu.Lock()
+					d.redraw()
+					d.mu.Unlock()
+				}
+			}
+		}()
+	}
+	return d
+}
+
+// start adds a line for an upload of the file called name.
+func (d *queueDisplay) start(name string) *queueReporter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	r := &queueReporter{d: d, name: name, status: "Waiting..."}
+	d.active = append(d.active, r)
+	return r
+}
+
+// finish replaces r's live line with its outcome.
+func (d *queueDisplay) finish(r *queueReporter, res *itemResult, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, a := range d.active {
+		if a == r {
+			d.active = append(d.active[:i], d.active[i+1:]...)
+			break
+		}
+	}
+	d.finished++
+	if err != nil {
+		d.println(fmt.Sprintf("✗ %s: %v", r.name, err))
+	} else {
+		d.println(fmt.Sprintf("✓ %s  %s", r.name, res.ID))
+	}
+}
+
+// close clears the live region for good.
+func (d *queueDisplay) close() {
+	close(d.done)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.clear()
+}
+
+// println prints a permanent line above the live region. d.mu must be held.
+func (d *queueDisplay) println(line string) {
+	d.clear()
+	fmt.Fprintln(d.w, line)
+	d.redraw()
+}
+
+// clear erases the live region. d.mu must be held.
+func (d *queueDisplay) clear() {
+	for ; d.drawn > 0; d.drawn-- {
+		fmt.Fprint(d.w, "\x1b[1A\x1b[2K")
+	}
+}
+
+// redraw repaints the live region. d.mu must be held.
+func (d *queueDisplay) redraw() {
+	if !d.live {
+		return
+	}
+	d.clear()
+	fmt.Fprintf(d.w, "Uploading %d files: %d done, %d in progress\n", d.total, d.finished, len(d.active))
+	d.drawn = 1
+	for _, r := range d.active {
+		fmt.Fprintf(d.w, "  %-24s %s\n", util.Truncate(r.name, 24), r.line())
+		d.drawn++
+	}
+}
+
+// queueReporter is the uploadReporter for one file in a queueDisplay. Detail
+// lines are dropped to keep the display compact; warnings are printed.
+type queueReporter struct {
+	d      *queueDisplay
+	name   string
+	status string
+	meter  *upload.Meter
+	done   int64
+	size   int64
+}
+
+// line renders r's progress. d.mu must be held.
+func (r *queueReporter) line() string {
+	if r.meter == nil {
+		return r.status
+	}
+	return fmt.Sprintf("%s %s/%s %s", util.CreateProgressBar(r.done, r.size, 20),
+		util.FormatBytes(r.done), util.FormatBytes(r.size), r.meter)
+}
+
+func (r *queueReporter) Status(msg string) {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+	r.status = msg
+	r.meter = nil
+}
+
+func (r *queueReporter) Infof(format string, args ...any) {}
+
+func (r *queueReporter) Warnf(format string, args ...any) {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+	r.d.println(fmt.Sprintf("! %s: %s", r.name, fmt.Sprintf(format, args...)))
+}
+
+func (r *queueReporter) Progress(completed, total int, done, size int64) {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+	if r.meter == nil {
+		r.meter = upload.NewMeter(size)
+	}
+	r.meter.Update(done)
+	r.done, r.size = done, size
+}
+
+func (r *queueReporter) Stop() {}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsMultiUpload(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, b := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+
+	for _, tt := range []struct {
+		inputs []string
+		want   bool
+	}{
+		{nil, false},
+		{[]string{a}, false},
+		{[]string{a, b}, true},
+		{[]string{dir}, true},
+		{[]string{filepath.Join(dir, "*.txt")}, true},
+		{[]string{filepath.Join(dir, "*.png")}, false},
+		{[]string{"Did you see this?"}, false},
+		{[]string{"hello", "world"}, false},
+		{[]string{a, "typo.txt"}, false},
+	} {
+		if got := isMultiUpload(tt.inputs); got != tt.want {
+			t.Errorf("isMultiUpload(%q) = %v, want %v", tt.inputs, got, tt.want)
+		}
+	}
+}
+
+func TestAddJoinsUnquotedWords(t *testing.T) {
+	n := newTestNK(t)
+	if _, errOut, code := n.run("a", "hello", "world", "--permanent"); code != 0 {
+		t.Fatalf("nk a hello world: exit code %d: %s", code, errOut)
+	}
+	if _, errOut, code := n.run("a", "Did you see this?", "--permanent"); code != 0 {
+		t.Fatalf("nk a with a question mark: exit code %d: %s", code, errOut)
+	}
+	for id, want := range map[string]string{"t1": "hello world", "t2": "Did you see this?"} {
+		if out, _, _ := n.run("cat", id); strings.TrimSpace(out) != want {
+			t.Errorf("nk cat %s = %q, want %q", id, out, want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// regionPresetPrefix marks a --region value naming a saved region.
+const regionPresetPrefix = "preset:"
+
+// addRegionFlags registers --region and --save-region on a screenshot
+// command.
+func addRegionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&addRegion, "region", "", "Capture the area x,y,width,height, or a saved one as preset:NAME (\"list\" shows them)")
+	cmd.Flags().StringVar(&addSaveRegion, "save-region", "", "Save the --region area as NAME for --region preset:NAME")
+}
+
+// resolveRegion returns the area --region names, saving it first when
+// --save-region asks to.
+func resolveRegion() (config.Region, error) {
+	if name, ok := strings.CutPrefix(addRegion, regionPresetPrefix); ok {
+		if addSaveRegion != "" {
+			return config.Region{}, fmt.Errorf("--save-region needs coordinates, not a preset")
+		}
+		return config.LookupRegion(name)
+	}
+
+	r, err := config.ParseRegion(addRegion)
+	if err != nil {
+		return r, err
+	}
+	if addSaveRegion != "" {
+		if err := config.SaveRegion(addSaveRegion, r); err != nil {
+			return r, fmt.Errorf("saving region: %w", err)
+		}
+		fmt.Fprintf(info, "Saved region %s as %q (use --region %s%s)\n", r, addSaveRegion, regionPresetPrefix, addSaveRegion)
+	}
+	return r, nil
+}
+
+// listRegions prints the saved regions.
+func listRegions() error {
+	names := config.RegionNames()
+	if machineOutput() {
+		regions := make(map[string]string, len(names))
+		for _, name := range names {
+			regions[name] = config.Get().Regions[name]
+		}
+		return emitResult(regions)
+	}
+	if len(names) == 0 {
+		fmt.Fprintln(info, "No saved regions. Save one with: nk sc --region x,y,width,height --save-region NAME")
+		return nil
+	}
+	for _, name := range names {
+		fmt.Fprintf(stdout, "%s: %s\n", name, config.Get().Regions[name])
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sim4gh/nikte-cli/internal/platform"
+	"golang.org/x/term"
+)
+
+// windowPick is the value of a bare --window: list the windows and ask which
+// one, or click it when not at a terminal.
+const windowPick = "pick"
+
+// listWindows prints the windows --window can capture by number or name.
+func listWindows() error {
+	windows, err := platform.ListWindows()
+	if err != nil {
+		return err
+	}
+	if machineOutput() {
+		return emitResult(windows)
+	}
+	for _, w := range windows {
+		fmt.Fprintln(stdout, w)
+	}
+	return nil
+}
+
+// chooseWindow returns the window --window names. Given without a value it
+// lists the windows and asks; ok is false if the user would rather click
+// the window, or there's no terminal to ask at.
+func chooseWindow() (w platform.Window, ok bool, err error) {
+	if addWindow != windowPick {
+		windows, err := platform.ListWindows()
+		if err != nil {
+			return w, false, err
+		}
+		w, err = matchWindow(windows, addWindow)
+		return w, err == nil, err
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return w, false, nil
+	}
+	windows, err := platform.ListWindows()
+	if err != nil || len(windows) == 0 {
+		return w, false, nil
+	}
+	for _, w := range windows {
+		fmt.Fprintln(info, w)
+	}
+	fmt.Fprint(stderr, "Window number or name (Enter to click one): ")
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	if err != nil || answer == "" {
+		return w, false, nil
+	}
+	w, err = matchWindow(windows, answer)
+	return w, err == nil, err
+}
+
+// matchWindow finds the window query names: by number, by application name
+// (its frontmost window), or by a unique match of part of the application
+// and title, falling back to the letters of query in order.
+func matchWindow(windows []platform.Window, query string) (platform.Window, error) {
+	if n, err := strconv.Atoi(query); err == nil {
+		for _, w := range windows {
+			if w.Number == n {
+				return w, nil
+			}
+		}
+		return platform.Window{}, fmt.Errorf("no window %d (see --window=list)", n)
+	}
+
+	q := strings.ToLower(query)
+	for _, w := range windows {
+		if strings.ToLower(w.App) == q {
+			return w, nil
+		}
+	}
+	var matches []platform.Window
+	for _, match := range []func(string) bool{
+		func(s string) bool { return strings.Contains(s, q) },
+		func(s string) bool { return fuzzyContains(s, q) },
+	} {
+		for _, w := range windows {
+			if match(strings.ToLower(w.App + " " + w.Title)) {
+				matches = append(matches, w)
+			}
+		}
+		if len(matches) > 0 {
+			break
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return platform.Window{}, fmt.Errorf("no window matches %q (see --window=list)", query)
+	case 1:
+		return matches[0], nil
+	}
+	var names []string
+	for _, w := range matches {
+		names = append(names, "  "+w.String())
+	}
+	return platform.Window{}, fmt.Errorf("%d windows match %q; use a number or more of the name:\n%s",
+		len(matches), query, strings.Join(names, "\n"))
+}
+
+// fuzzyContains reports whether the letters of sub appear in s in order.
+func fuzzyContains(s, sub string) bool {
+	for _, r := range s {
+		if sub == "" {
+			break
+		}
+		if strings.HasPrefix(sub, string(r)) {
+			sub = sub[len(string(r)):]
+		}
+	}
+	return sub == ""
+}
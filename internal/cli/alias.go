@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// aliasListJSON is the --json flag of `nk alias ls`.
+var aliasListJSON bool
+
+// aliasEntry is one alias as `nk alias ls --json` prints it.
+type aliasEntry struct {
+	Name string `json:"name"`
+	ID   string `json:"id"`
+}
+
+func addAliasCommand() {
+	aliasCmd := &cobra.Command{
+		Use:   "alias",
+		Short: "Name items so you can use the name instead of the ID",
+		Long: `Give items memorable names. Any command taking an item ID (get, delete,
+extend, share, wa send --item, and the nk serve API) accepts an alias in its
+place. Aliases are kept per profile in the config directory; deleting an item
+with nk delete removes its aliases.
+
+Examples:
+  nk alias set weekly abc1               Name item abc1 "weekly"
+  nk get weekly                          Same as nk get abc1
+  nk alias ls                            List aliases
+  nk alias rm weekly                     Remove an alias (the item stays)`,
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set <name> <id>",
+		Short: "Make name an alias for an item",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runAliasSet,
+	}
+	lsCmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List aliases",
+		Args:    cobra.NoArgs,
+		RunE:    runAliasList,
+	}
+	lsCmd.Flags().BoolVar(&aliasListJSON, "json", false, "Output as JSON")
+	rmCmd := &cobra.Command{
+		Use:     "rm <name>...",
+		Aliases: []string{"remove"},
+		Short:   "Remove aliases",
+		Args:    cobra.MinimumNArgs(1),
+		RunE:    runAliasRemove,
+	}
+
+	aliasCmd.AddCommand(setCmd, lsCmd, rmCmd)
+	rootCmd.AddCommand(aliasCmd)
+}
+
+func runAliasSet(cmd *cobra.Command, args []string) error {
+	name, id := args[0], config.ResolveAlias(args[1])
+	previous, err := config.SetAlias(name, id)
+	if err != nil {
+		return err
+	}
+	switch {
+	case previous == "" || previous == id:
+		fmt.Fprintf(stdout, "✓ %s → %s\n", name, id)
+	default:
+		fmt.Fprintf(stdout, "✓ %s → %s (was %s)\n", name, id, previous)
+	}
+	return nil
+}
+
+func runAliasList(cmd *cobra.Command, args []string) error {
+	if aliasListJSON && !machineOutput() {
+		outputMode = outputJSON
+	}
+	aliases, err := config.Aliases()
+	if err != nil {
+		return err
+	}
+	entries := make([]aliasEntry, 0, len(aliases))
+	for name, id := range aliases {
+		entries = append(entries, aliasEntry{Name: name, ID: id})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	if machineOutput() {
+		return emitResult(entries)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(stdout, "No aliases. Add one with: nk alias set <name> <id>")
+		return nil
+	}
+	width := len("NAME")
+	for _, e := range entries {
+		width = max(width, len(e.Name))
+	}
+	fmt.Fprintf(stdout, "%-*s  %s\n", width, "NAME", "ID")
+	for _, e := range entries {
+		fmt.Fprintf(stdout, "%-*s  %s\n", width, e.Name, e.ID)
+	}
+	return nil
+}
+
+func runAliasRemove(cmd *cobra.Command, args []string) error {
+	missing := 0
+	for _, name := range args {
+		found, err := config.RemoveAlias(name)
+		if err != nil {
+			return err
+		}
+		if !found {
+			fmt.Fprintf(stdout, "✗ No alias %q\n", name)
+			missing++
+			continue
+		}
+		fmt.Fprintf(stdout, "✓ Removed %s\n", name)
+	}
+	if missing > 0 {
+		return fmt.Errorf("%d of %d aliases not found", missing, len(args))
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/crypto"
+	"github.com/sim4gh/nikte-cli/internal/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Flags of `nk append`.
+var (
+	appendTimestamp bool
+	appendEncPass   string
+)
+
+func addAppendCommand() {
+	appendCmd := &cobra.Command{
+		Use:   "append <id> [text...]",
+		Short: "Add a line or stdin to the end of a text item",
+		Long: `Add text to the end of a text item, keeping its ID: the arguments as one
+line, or stdin when there are none. A newline is added between the old and
+new content where needed. Encrypted items stay encrypted.
+
+Examples:
+  nk append log "deployed v1.4"          Add a line
+  nk append log -t "backup ok"           Prefix it with the time
+  make 2>&1 | nk append abc1             Append a command's output`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runAppend,
+	}
+	appendCmd.Flags().BoolVarP(&appendTimestamp, "timestamp", "t", false, "Prefix each added line with the date and time")
+	appendCmd.Flags().StringVar(&appendEncPass, "enc-pass", "", "Passphrase of an encrypted item (else prompt or NIKTE_PASSPHRASE)")
+
+	rootCmd.AddCommand(appendCmd)
+}
+
+func runAppend(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	id := config.ResolveAlias(args[0])
+
+	var added string
+	if len(args) > 1 {
+		added = strings.Join(args[1:], " ") + "\n"
+	} else {
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			return fmt.Errorf("nothing to append: pass the text as arguments or pipe it to stdin")
+		}
+		data, err := io.ReadAll(io.LimitReader(os.Stdin, maxTextSizeBytes+1))
+		if err != nil {
+			return err
+		}
+		added = string(data)
+	}
+	if added == "" {
+		return fmt.Errorf("nothing to append: stdin was empty")
+	}
+	if appendTimestamp {
+		stamp := time.Now().Format("2006-01-02 15:04:05") + " "
+		lines := strings.SplitAfter(added, "\n")
+		for i, line := range lines {
+			if line != "" {
+				lines[i] = stamp + line
+			}
+		}
+		added = strings.Join(lines, "")
+	}
+
+	short, err := fetchTextShort(ctx, id)
+	if err != nil {
+		return err
+	}
+	content, pass := short.Content, ""
+	if crypto.IsEncryptedText(content) {
+		if pass, err = resolvePassphrase(appendEncPass, false); err != nil {
+			return err
+		}
+		if content, err = crypto.DecryptText(pass, content); err != nil {
+			return err
+		}
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += added
+	if len(content) > maxTextSizeBytes {
+		return fmt.Errorf("appending %s would make %s %s, over the %dKB limit for text",
+			util.FormatBytes(int64(len(added))), id, util.FormatBytes(int64(len(content))), maxTextSizeBytes/1024)
+	}
+
+	newID, err := replaceText(ctx, short, content, pass)
+	if err != nil {
+		return err
+	}
+	if newID == id {
+		fmt.Fprintf(stdout, "✓ Appended %s to %s (now %s)\n", util.FormatBytes(int64(len(added))), id, util.FormatBytes(int64(len(content))))
+	} else {
+		fmt.Fprintf(stdout, "✓ Appended %s to %s; it is now %s\n", util.FormatBytes(int64(len(added))), id, newID)
+	}
+	return nil
+}
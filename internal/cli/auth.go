@@ -1,142 +1,300 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
-	"github.com/pkg/browser"
+	"github.com/sim4gh/nikte-cli/internal/api"
 	"github.com/sim4gh/nikte-cli/internal/auth"
 	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/platform"
+	"github.com/sim4gh/nikte-cli/internal/util"
 	"github.com/spf13/cobra"
 )
 
+// loginCmd is `nk auth login`, which offerRelogin also runs.
+var loginCmd *cobra.Command
+
+// Flags of `nk auth login` and `nk auth status`.
+var (
+	loginNoBrowser bool
+	authStatusJSON bool
+)
+
 func addAuthCommands() {
 	authCmd := &cobra.Command{
 		Use:   "auth",
 		Short: "Authentication commands",
 	}
 
+	loginCmd = &cobra.Command{
+		Use:   "login",
+		Short: "Login using device flow authentication",
+		Long: `Login using device flow authentication
+
+Examples:
+  nk auth login                     Log in to the default profile
+  nk auth login --profile work      Log in to a separate "work" profile
+  nk auth login --no-browser        Print the URL and code only (SSH, servers)
+
+The browser is never opened over SSH (SSH_CONNECTION/SSH_TTY set).`,
+		RunE: runLogin,
+	}
+	loginCmd.Flags().BoolVar(&loginNoBrowser, "no-browser", false, "Don't open a browser; print the verification URL and code")
+
+	logoutCmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Clear stored credentials and logout",
+		RunE:  runLogout,
+	}
+
+	switchCmd := &cobra.Command{
+		Use:   "switch <profile>",
+		Short: "Switch the active account profile",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runAuthSwitch,
+	}
+
+	profilesCmd := &cobra.Command{
+		Use:   "profiles",
+		Short: "List account profiles",
+		Args:  cobra.NoArgs,
+		RunE:  runAuthProfiles,
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show token validity and expiry details",
+		Long: `Show token validity and expiry details
+
+Reports whether the ID and access tokens are valid, when they expire, how long
+until the next automatic refresh, and which profile is active. Exits with
+status 1 when not authenticated, so it can be used as a login check.
+
+Examples:
+  nk auth status
+  nk auth status --json | jq .authenticated`,
+		Args: cobra.NoArgs,
+		RunE: runAuthStatus,
+	}
+	statusCmd.Flags().BoolVar(&authStatusJSON, "json", false, "Output as JSON (for scripting)")
+
+	whoamiCmd := &cobra.Command{
+		Use:   "whoami",
+		Short: "Show current user information",
+		RunE:  runWhoami,
+	}
+
 	authCmd.AddCommand(loginCmd)
 	authCmd.AddCommand(logoutCmd)
 	authCmd.AddCommand(whoamiCmd)
+	authCmd.AddCommand(statusCmd)
+	authCmd.AddCommand(newAuthTokenCommand())
+	authCmd.AddCommand(newAuthExportCommand())
+	authCmd.AddCommand(newAuthImportCommand())
+	authCmd.AddCommand(switchCmd)
+	authCmd.AddCommand(profilesCmd)
 
 	rootCmd.AddCommand(authCmd)
 }
 
-var loginCmd = &cobra.Command{
-	Use:   "login",
-	Short: "Login using device flow authentication",
-	RunE:  runLogin,
-}
-
-var logoutCmd = &cobra.Command{
-	Use:   "logout",
-	Short: "Clear stored credentials and logout",
-	RunE:  runLogout,
-}
-
-var whoamiCmd = &cobra.Command{
-	Use:   "whoami",
-	Short: "Show current user information",
-	RunE:  runWhoami,
-}
-
 func runLogin(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
 	// Initialize device authorization
-	deviceAuth, err := auth.InitiateDeviceAuth()
+	deviceAuth, err := auth.InitiateDeviceAuth(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to initiate device authorization: %w", err)
 	}
 
-	// Display verification URL and user code
-	fmt.Println("\nTo complete authentication, please visit:")
-	fmt.Printf("  %s\n", deviceAuth.VerificationURIComplete)
-	fmt.Printf("\nUser Code: %s\n\n", deviceAuth.UserCode)
+	noBrowser := loginNoBrowser || isRemoteSession()
 
-	// Try to open browser
-	_ = browser.OpenURL(deviceAuth.VerificationURIComplete)
+	if noBrowser {
+		printDeviceCodeBanner(deviceAuth)
+	} else {
+		// Display verification URL and user code
+		fmt.Fprintln(stdout, "\nTo complete authentication, please visit:")
+		fmt.Fprintf(stdout, "  %s\n", deviceAuth.VerificationURIComplete)
+		fmt.Fprintf(stdout, "\nUser Code: %s\n\n", deviceAuth.UserCode)
+
+		// Try to open browser
+		_ = platform.OpenURL(deviceAuth.VerificationURIComplete)
+	}
 
 	// Start spinner
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
 	s.Suffix = " Waiting for you to complete the login in the browser..."
+	if noBrowser {
+		s.Suffix = " Waiting for you to complete the login on another device..."
+	}
 	s.Start()
 
 	// Poll for token
-	tokenResp, err := auth.PollForToken(deviceAuth.DeviceCode, deviceAuth.Interval)
+	tokenResp, err := auth.PollForToken(ctx, deviceAuth.DeviceCode, deviceAuth.Interval)
 	if err != nil {
 		s.Stop()
 		return err
 	}
 
 	s.Stop()
-	fmt.Println("Login successful!")
+	fmt.Fprintln(stdout, "Login successful!")
 
-	// Load or create config
-	cfg, err := config.Load()
-	if err != nil {
-		cfg = &config.Config{}
-	}
+	profile := config.ActiveProfile()
 
-	// Store credentials
-	cfg.BaseURL = auth.BaseURL
-	cfg.IDToken = tokenResp.IDToken
-	cfg.AccessToken = tokenResp.AccessToken
-	cfg.RefreshToken = tokenResp.RefreshToken
-	cfg.LoggedInAt = time.Now().Format(time.RFC3339)
+	auth.RecordClockSkew(tokenResp.IDToken)
 
-	if err := config.SetConfig(cfg); err != nil {
+	// Store credentials
+	baseURL := auth.LoginBaseURL()
+	err = config.Update(func(cfg *config.Config) error {
+		cfg.BaseURL = baseURL
+		cfg.IDToken = tokenResp.IDToken
+		cfg.AccessToken = tokenResp.AccessToken
+		cfg.RefreshToken = tokenResp.RefreshToken
+		cfg.LoggedInAt = time.Now().Format(time.RFC3339)
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to save credentials: %w", err)
 	}
 
-	fmt.Println("\nAuthentication complete! You are now logged in.")
+	if profile != config.DefaultProfile {
+		fmt.Fprintf(stdout, "\nAuthentication complete! You are now logged in to profile %q.\n", profile)
+		fmt.Fprintf(stdout, "Use \"nk --profile %s <command>\" or \"nk auth switch %s\" to use it.\n", profile, profile)
+		return nil
+	}
+
+	fmt.Fprintln(stdout, "\nAuthentication complete! You are now logged in.")
 	return nil
 }
 
+// isRemoteSession reports whether we're running over SSH, where opening a
+// browser would at best open it on the remote machine.
+func isRemoteSession() bool {
+	return os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != ""
+}
+
+// printDeviceCodeBanner prints the verification URL and user code in a box so
+// they stand out when the user has to open the URL on another device.
+func printDeviceCodeBanner(deviceAuth *auth.DeviceAuthResponse) {
+	lines := []string{
+		"Open this URL on any device to log in:",
+		"",
+		"  " + deviceAuth.VerificationURIComplete,
+		"",
+		"Or visit " + deviceAuth.VerificationURI + " and enter code:",
+		"",
+		"  " + deviceAuth.UserCode,
+	}
+	if deviceAuth.VerificationURI == "" {
+		lines = lines[:len(lines)-3]
+		lines[len(lines)-1] = "Code: " + deviceAuth.UserCode
+	}
+
+	width := 0
+	for _, l := range lines {
+		if len(l) > width {
+			width = len(l)
+		}
+	}
+
+	border := "+" + strings.Repeat("-", width+2) + "+"
+	fmt.Fprintln(stdout)
+	fmt.Fprintln(stdout, border)
+	for _, l := range lines {
+		fmt.Fprintf(stdout, "| %-*s |\n", width, l)
+	}
+	fmt.Fprintln(stdout, border)
+	fmt.Fprintln(stdout)
+}
+
 func runLogout(cmd *cobra.Command, args []string) error {
 	cfg := config.Get()
-	if cfg == nil || cfg.BaseURL == "" {
-		fmt.Println("You are not currently logged in.")
+	if cfg == nil || (cfg.RefreshToken == "" && cfg.IDToken == "" && cfg.APIToken == "") {
+		fmt.Fprintln(stdout, "You are not currently logged in.")
 		return nil
 	}
 
-	// Clear all stored credentials
-	if err := config.Clear(); err != nil {
+	// Clear the active profile's stored credentials
+	profile := config.ActiveProfile()
+	if err := config.ClearProfile(); err != nil {
 		return fmt.Errorf("failed to clear credentials: %w", err)
 	}
+	_ = api.ClearCache()
 
-	fmt.Println("Successfully logged out. All credentials have been cleared.")
+	if profile != config.DefaultProfile {
+		fmt.Fprintf(stdout, "Successfully logged out of profile %q. Its credentials have been cleared.\n", profile)
+		return nil
+	}
+	fmt.Fprintln(stdout, "Successfully logged out. All credentials have been cleared.")
+	return nil
+}
+
+func runAuthSwitch(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := config.SwitchProfile(name); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Switched to profile %q.\n", name)
+	return nil
+}
+
+func runAuthProfiles(cmd *cobra.Command, args []string) error {
+	active := config.ActiveProfile()
+	for _, name := range config.ProfileNames() {
+		marker := "  "
+		if name == active {
+			marker = "* "
+		}
+		fmt.Fprintf(stdout, "%s%s\n", marker, name)
+	}
 	return nil
 }
 
 func runWhoami(cmd *cobra.Command, args []string) error {
 	cfg := config.Get()
+	if token, source := auth.APITokenSource(); token != "" {
+		fmt.Fprintln(stdout, "\nCurrent Authentication Status:")
+		fmt.Fprintln(stdout, "------------------------------")
+		fmt.Fprintf(stdout, "Profile: %s\n", config.ActiveProfile())
+		fmt.Fprintf(stdout, "Authenticated with API token (%s): %s\n", source, util.MaskToken(token))
+		printAccountInfo(cmd.Context())
+		fmt.Fprintln(stdout)
+		return nil
+	}
 	if cfg == nil || cfg.BaseURL == "" || cfg.AccessToken == "" {
-		fmt.Println("You are not currently logged in.")
-		fmt.Println("Run \"nk auth login\" to authenticate.")
+		fmt.Fprintln(stdout, "You are not currently logged in.")
+		fmt.Fprintln(stdout, "Run \"nk auth login\" to authenticate.")
 		return nil
 	}
 
-	fmt.Println("\nCurrent Authentication Status:")
-	fmt.Println("------------------------------")
-	fmt.Printf("Base URL: %s\n", cfg.BaseURL)
+	fmt.Fprintln(stdout, "\nCurrent Authentication Status:")
+	fmt.Fprintln(stdout, "------------------------------")
+	fmt.Fprintf(stdout, "Profile: %s\n", config.ActiveProfile())
+	fmt.Fprintf(stdout, "Base URL: %s\n", cfg.BaseURL)
 
 	// Decode and display ID token payload if available
 	if cfg.IDToken != "" {
 		payload, err := auth.DecodeJWT(cfg.IDToken)
 		if err == nil {
-			fmt.Println("\nUser Information:")
+			fmt.Fprintln(stdout, "\nUser Information:")
 			if payload.Sub != "" {
-				fmt.Printf("  User ID: %s\n", payload.Sub)
+				fmt.Fprintf(stdout, "  User ID: %s\n", payload.Sub)
 			}
 			if payload.Email != "" {
-				fmt.Printf("  Email: %s\n", payload.Email)
+				fmt.Fprintf(stdout, "  Email: %s\n", payload.Email)
 			}
 			if payload.Name != "" {
-				fmt.Printf("  Name: %s\n", payload.Name)
+				fmt.Fprintf(stdout, "  Name: %s\n", payload.Name)
 			}
 			if payload.PreferredUsername != "" {
-				fmt.Printf("  Username: %s\n", payload.PreferredUsername)
+				fmt.Fprintf(stdout, "  Username: %s\n", payload.PreferredUsername)
 			}
 		}
 	}
@@ -148,21 +306,342 @@ func runWhoami(cmd *cobra.Command, args []string) error {
 			sessionExpiry := loginDate.AddDate(1, 0, 0) // 365 days
 			daysRemaining := int(time.Until(sessionExpiry).Hours() / 24)
 
-			fmt.Println("\nSession Information:")
-			fmt.Printf("  Logged in: %s\n", loginDate.Local().Format("Jan 2, 2006 3:04 PM"))
-			fmt.Printf("  Session expires: %s\n", sessionExpiry.Local().Format("Jan 2, 2006 3:04 PM"))
+			fmt.Fprintln(stdout, "\nSession Information:")
+			fmt.Fprintf(stdout, "  Logged in: %s\n", loginDate.Local().Format("Jan 2, 2006 3:04 PM"))
+			fmt.Fprintf(stdout, "  Session expires: %s\n", sessionExpiry.Local().Format("Jan 2, 2006 3:04 PM"))
 			if daysRemaining > 0 {
-				fmt.Printf("  Status: Valid (%d days remaining)\n", daysRemaining)
+				fmt.Fprintf(stdout, "  Status: Valid (%d days remaining)\n", daysRemaining)
 			} else {
-				fmt.Println("  Status: EXPIRED (please login again)")
+				fmt.Fprintln(stdout, "  Status: EXPIRED (please login again)")
 			}
 		}
 	} else {
-		fmt.Println("\nSession Information:")
-		fmt.Println("  Session expires: ~1 year from login")
-		fmt.Println("  (Re-login to see exact expiration date)")
+		fmt.Fprintln(stdout, "\nSession Information:")
+		fmt.Fprintln(stdout, "  Session expires: ~1 year from login")
+		fmt.Fprintln(stdout, "  (Re-login to see exact expiration date)")
+	}
+
+	printAccountInfo(cmd.Context())
+
+	fmt.Fprintln(stdout)
+	return nil
+}
+
+// tokenStatus describes one stored JWT in `nk auth status`.
+type tokenStatus struct {
+	Present   bool       `json:"present"`
+	Valid     bool       `json:"valid"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	ExpiresIn int64      `json:"expiresInSeconds"`
+}
+
+// authStatus is the `nk auth status --json` document.
+type authStatus struct {
+	Profile          string       `json:"profile"`
+	Authenticated    bool         `json:"authenticated"`
+	Method           string       `json:"method"`
+	BaseURL          string       `json:"baseUrl,omitempty"`
+	IDToken          *tokenStatus `json:"idToken,omitempty"`
+	AccessToken      *tokenStatus `json:"accessToken,omitempty"`
+	RefreshIn        *int64       `json:"refreshInSeconds,omitempty"`
+	HasRefreshToken  bool         `json:"hasRefreshToken"`
+	SessionExpiresAt *time.Time   `json:"sessionExpiresAt,omitempty"`
+	ClockSkew        int64        `json:"clockSkewSeconds,omitempty"`
+}
+
+func newTokenStatus(token string, now time.Time) *tokenStatus {
+	ts := &tokenStatus{Present: token != ""}
+	if token == "" {
+		return ts
+	}
+	exp, err := auth.GetTokenExpiry(token)
+	if err != nil || exp.Unix() == 0 {
+		return ts
+	}
+	ts.ExpiresAt = &exp
+	ts.ExpiresIn = int64(exp.Sub(now).Seconds())
+	ts.Valid = exp.After(now)
+	return ts
+}
+
+func collectAuthStatus() *authStatus {
+	now := auth.Now()
+	st := &authStatus{Profile: config.ActiveProfile(), Method: "none"}
+
+	if token, source := auth.APITokenSource(); token != "" {
+		st.Authenticated = true
+		st.Method = "api_token:" + source
+		st.BaseURL = api.DefaultBaseURL
+		if cfg := config.Get(); cfg != nil && cfg.BaseURL != "" {
+			st.BaseURL = cfg.BaseURL
+		}
+		return st
+	}
+
+	cfg := config.Get()
+	if cfg == nil || cfg.IDToken == "" {
+		return st
+	}
+
+	st.Method = "device_flow"
+	st.BaseURL = cfg.BaseURL
+	st.IDToken = newTokenStatus(cfg.IDToken, now)
+	st.AccessToken = newTokenStatus(cfg.AccessToken, now)
+	st.HasRefreshToken = cfg.RefreshToken != ""
+	st.ClockSkew = cfg.ClockSkew
+
+	if st.IDToken.ExpiresAt != nil {
+		refreshIn := int64(st.IDToken.ExpiresAt.Add(-auth.RefreshBuffer()).Sub(now).Seconds())
+		if refreshIn < 0 {
+			refreshIn = 0
+		}
+		st.RefreshIn = &refreshIn
+	}
+
+	if loginDate, err := time.Parse(time.RFC3339, cfg.LoggedInAt); err == nil {
+		sessionExpiry := loginDate.AddDate(1, 0, 0)
+		st.SessionExpiresAt = &sessionExpiry
+	}
+
+	// A stale ID token is fine as long as it can still be refreshed.
+	st.Authenticated = st.IDToken.Valid ||
+		(st.HasRefreshToken && (st.SessionExpiresAt == nil || st.SessionExpiresAt.After(now)))
+	return st
+}
+
+func runAuthStatus(cmd *cobra.Command, args []string) error {
+	st := collectAuthStatus()
+
+	if authStatusJSON {
+		data, err := json.MarshalIndent(st, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, string(data))
+	} else {
+		printAuthStatus(st)
+	}
+
+	if !st.Authenticated {
+		// The status above says why; exit 1 without repeating it.
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+func printAuthStatus(st *authStatus) {
+	fmt.Fprintf(stdout, "Profile: %s\n", st.Profile)
+	if !st.Authenticated && st.Method == "none" {
+		fmt.Fprintln(stdout, "Status: not logged in")
+		fmt.Fprintln(stdout, "Run \"nk auth login\" to authenticate.")
+		return
+	}
+	if strings.HasPrefix(st.Method, "api_token:") {
+		fmt.Fprintf(stdout, "Status: authenticated with API token (%s)\n", strings.TrimPrefix(st.Method, "api_token:"))
+		fmt.Fprintf(stdout, "Base URL: %s\n", st.BaseURL)
+		return
+	}
+
+	if st.Authenticated {
+		fmt.Fprintln(stdout, "Status: authenticated")
+	} else {
+		fmt.Fprintln(stdout, "Status: session expired (run \"nk auth login\")")
+	}
+	fmt.Fprintf(stdout, "Base URL: %s\n", st.BaseURL)
+
+	printToken := func(label string, ts *tokenStatus) {
+		switch {
+		case !ts.Present:
+			fmt.Fprintf(stdout, "%s: missing\n", label)
+		case ts.ExpiresAt == nil:
+			fmt.Fprintf(stdout, "%s: present (expiry unknown)\n", label)
+		case ts.Valid:
+			fmt.Fprintf(stdout, "%s: valid until %s (in %s)\n", label,
+				ts.ExpiresAt.Local().Format("Jan 2, 2006 3:04:05 PM"), time.Duration(ts.ExpiresIn)*time.Second)
+		default:
+			fmt.Fprintf(stdout, "%s: expired at %s\n", label, ts.ExpiresAt.Local().Format("Jan 2, 2006 3:04:05 PM"))
+		}
+	}
+	printToken("ID token", st.IDToken)
+	printToken("Access token", st.AccessToken)
+
+	if st.RefreshIn != nil {
+		if *st.RefreshIn == 0 {
+			fmt.Fprintln(stdout, "Next refresh: on next request")
+		} else {
+			fmt.Fprintf(stdout, "Next refresh: in %s\n", time.Duration(*st.RefreshIn)*time.Second)
+		}
+	}
+	if !st.HasRefreshToken {
+		fmt.Fprintln(stdout, "Refresh token: missing")
+	}
+	if st.SessionExpiresAt != nil {
+		fmt.Fprintf(stdout, "Session expires: %s\n", st.SessionExpiresAt.Local().Format("Jan 2, 2006 3:04 PM"))
+	}
+	if st.ClockSkew != 0 {
+		fmt.Fprintf(stdout, "Clock skew: %s (server minus local; expiry times adjusted)\n", time.Duration(st.ClockSkew)*time.Second)
+	}
+}
+
+// accountInfo is the response of GET /account.
+type accountInfo struct {
+	Plan         string         `json:"plan"`
+	StorageUsed  int64          `json:"storageUsed"`
+	StorageQuota int64          `json:"storageQuota"`
+	ItemCount    int            `json:"itemCount"`
+	ItemCounts   map[string]int `json:"itemCounts"`
+	RateLimit    *struct {
+		Limit     int   `json:"limit"`
+		Remaining int   `json:"remaining"`
+		ResetAt   int64 `json:"resetAt"`
+	} `json:"rateLimit"`
+}
+
+// printAccountInfo fetches the plan, storage quota and rate limits for the
+// current account. It's informational only, so failures are reported inline
+// rather than failing whoami.
+func printAccountInfo(ctx context.Context) {
+	resp, err := api.GetContext(ctx, "/account")
+	if err != nil {
+		fmt.Fprintf(stdout, "\nAccount: unavailable (%v)\n", err)
+		return
+	}
+	if resp.StatusCode != 200 {
+		fmt.Fprintf(stdout, "\nAccount: unavailable (HTTP %d)\n", resp.StatusCode)
+		return
+	}
+
+	var info accountInfo
+	if err := resp.Unmarshal(&info); err != nil {
+		fmt.Fprintf(stdout, "\nAccount: unavailable (%v)\n", err)
+		return
+	}
+
+	fmt.Fprintln(stdout, "\nAccount:")
+	if info.Plan != "" {
+		fmt.Fprintf(stdout, "  Plan: %s\n", strings.ToUpper(info.Plan[:1])+info.Plan[1:])
+	}
+	if info.StorageQuota > 0 {
+		pct := float64(info.StorageUsed) / float64(info.StorageQuota) * 100
+		fmt.Fprintf(stdout, "  Storage: %s of %s (%.1f%%)\n",
+			util.FormatBytes(info.StorageUsed), util.FormatBytes(info.StorageQuota), pct)
+		fmt.Fprintf(stdout, "           %s\n", util.CreateProgressBar(info.StorageUsed, info.StorageQuota, 30))
+	} else {
+		fmt.Fprintf(stdout, "  Storage: %s used\n", util.FormatBytes(info.StorageUsed))
+	}
+	if info.ItemCount > 0 || len(info.ItemCounts) > 0 {
+		fmt.Fprintf(stdout, "  Items: %d\n", info.ItemCount)
+		kinds := make([]string, 0, len(info.ItemCounts))
+		for k := range info.ItemCounts {
+			kinds = append(kinds, k)
+		}
+		sort.Strings(kinds)
+		for _, k := range kinds {
+			fmt.Fprintf(stdout, "    %s: %d\n", k, info.ItemCounts[k])
+		}
+	}
+
+	// Prefer the body, but fall back to the standard rate-limit headers.
+	limit, remaining, reset := 0, 0, int64(0)
+	if info.RateLimit != nil {
+		limit, remaining, reset = info.RateLimit.Limit, info.RateLimit.Remaining, info.RateLimit.ResetAt
+	} else if rl := resp.RateLimit; rl != nil {
+		limit, remaining = rl.Limit, rl.Remaining
+		if !rl.Reset.IsZero() {
+			reset = rl.Reset.Unix()
+		}
+	}
+	if limit > 0 {
+		fmt.Fprintf(stdout, "  Rate limit: %d/%d requests remaining", remaining, limit)
+		if reset > 0 {
+			fmt.Fprintf(stdout, " (resets %s)", time.Unix(reset, 0).Local().Format("3:04 PM"))
+		}
+		fmt.Fprintln(stdout)
+	}
+}
+
+// newAuthTokenCommand builds `nk auth token`, which manages a long-lived
+// personal access token for CI and scripts. A stored token (or the NIKTE_TOKEN
+// env var) is sent as the bearer token instead of the device-flow session.
+func newAuthTokenCommand() *cobra.Command {
+	tokenCmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage a personal access token for CI and scripts",
+		Long: `Manage a personal access token for CI and scripts
+
+A personal access token bypasses the interactive device flow. It can be stored
+in the config with "nk auth token set" or supplied per-process via the
+NIKTE_TOKEN (or OIO_TOKEN) environment variable, which takes precedence.
+
+Examples:
+  nk auth token set            Prompt for the token (hidden input)
+  nk auth token set <token>    Store the token given as an argument
+  echo $TOKEN | nk auth token set -
+                               Read the token from stdin
+  nk auth token clear          Remove the stored token`,
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set [token|-]",
+		Short: "Store a personal access token",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runAuthTokenSet,
+	}
+
+	clearCmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove the stored personal access token",
+		Args:  cobra.NoArgs,
+		RunE:  runAuthTokenClear,
+	}
+
+	tokenCmd.AddCommand(setCmd, clearCmd)
+	return tokenCmd
+}
+
+func runAuthTokenSet(cmd *cobra.Command, args []string) error {
+	var token string
+	switch {
+	case len(args) == 0:
+		t, err := promptSecret("token", false)
+		if err != nil {
+			return err
+		}
+		token = t
+	case args[0] == "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read token from stdin: %w", err)
+		}
+		token = string(data)
+	default:
+		token = args[0]
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return fmt.Errorf("token cannot be empty")
+	}
+
+	if err := config.Set("api_token", token); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "API token saved (%s).\n", util.MaskToken(token))
+	return nil
+}
+
+func runAuthTokenClear(cmd *cobra.Command, args []string) error {
+	cfg := config.Get()
+	if cfg == nil || cfg.APIToken == "" {
+		fmt.Fprintln(stdout, "No API token is stored.")
+		return nil
+	}
+
+	if err := config.Set("api_token", ""); err != nil {
+		return fmt.Errorf("failed to clear token: %w", err)
 	}
 
-	fmt.Println()
+	fmt.Fprintln(stdout, "API token removed.")
 	return nil
 }
@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/crypto"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sessionOutput  string
+	sessionEncPass string
+	sessionForce   bool
+)
+
+// sessionFile is the plaintext of an exported session. It carries everything a
+// login writes to the config, so importing it is equivalent to logging in.
+type sessionFile struct {
+	Version      int    `json:"version"`
+	ExportedAt   string `json:"exported_at"`
+	Profile      string `json:"profile"`
+	BaseURL      string `json:"baseurl"`
+	IDToken      string `json:"id_token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	LoggedInAt   string `json:"logged_in_at,omitempty"`
+
+	AuthDomain         string `json:"auth_domain,omitempty"`
+	AuthClientID       string `json:"auth_client_id,omitempty"`
+	AuthTokenEndpoint  string `json:"auth_token_endpoint,omitempty"`
+	AuthDeviceEndpoint string `json:"auth_device_endpoint,omitempty"`
+}
+
+const sessionFileVersion = 1
+
+func newAuthExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the current login, encrypted with a passphrase",
+		Long: `Export the current login, encrypted with a passphrase
+
+Writes the active profile's tokens, encrypted with a passphrase, so the login
+can be moved to another machine with "nk auth import" instead of redoing the
+device flow. Anyone with the file and passphrase can act as you until the
+session expires; treat it like a password.
+
+Examples:
+  nk auth export > session.enc
+  nk auth export -o session.enc
+  nk auth import session.enc         (on the new machine)`,
+		Args: cobra.NoArgs,
+		RunE: runAuthExport,
+	}
+	cmd.Flags().StringVarP(&sessionOutput, "output", "o", "", "Write to a file instead of stdout")
+	cmd.Flags().StringVar(&sessionEncPass, "enc-pass", "", "Passphrase (or NIKTE_PASSPHRASE; prompted if unset)")
+	return cmd
+}
+
+func newAuthImportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <file|->",
+		Short: "Import a login exported with nk auth export",
+		Long: `Import a login exported with nk auth export
+
+The session is stored in the active profile (or the one named with --profile).
+
+Examples:
+  nk auth import session.enc
+  nk --profile work auth import session.enc`,
+		Args: cobra.ExactArgs(1),
+		RunE: runAuthImport,
+	}
+	cmd.Flags().StringVar(&sessionEncPass, "enc-pass", "", "Passphrase (or NIKTE_PASSPHRASE; prompted if unset)")
+	cmd.Flags().BoolVarP(&sessionForce, "force", "f", false, "Overwrite an existing login in the profile")
+	return cmd
+}
+
+func runAuthExport(cmd *cobra.Command, args []string) error {
+	cfg := config.Get()
+	if cfg == nil || cfg.RefreshToken == "" {
+		return fmt.Errorf("no device-flow login to export. Run \"nk auth login\" first")
+	}
+
+	if sessionOutput == "" && isTerminal(stdout) {
+		return fmt.Errorf("refusing to write an encrypted session to the terminal. Redirect stdout or use -o <file>")
+	}
+
+	session := sessionFile{
+		Version:            sessionFileVersion,
+		ExportedAt:         time.Now().Format(time.RFC3339),
+		Profile:            config.ActiveProfile(),
+		BaseURL:            cfg.BaseURL,
+		IDToken:            cfg.IDToken,
+		AccessToken:        cfg.AccessToken,
+		RefreshToken:       cfg.RefreshToken,
+		LoggedInAt:         cfg.LoggedInAt,
+		AuthDomain:         cfg.AuthDomain,
+		AuthClientID:       cfg.AuthClientID,
+		AuthTokenEndpoint:  cfg.AuthTokenEndpoint,
+		AuthDeviceEndpoint: cfg.AuthDeviceEndpoint,
+	}
+	plaintext, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	pass, err := resolvePassphrase(sessionEncPass, true)
+	if err != nil {
+		return err
+	}
+	data, err := crypto.EncryptBytes(pass, plaintext)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt session: %w", err)
+	}
+
+	if sessionOutput == "" {
+		_, err = stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(sessionOutput, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", sessionOutput, err)
+	}
+	fmt.Fprintf(stderr, "Session exported to %s\n", sessionOutput)
+	return nil
+}
+
+func runAuthImport(cmd *cobra.Command, args []string) error {
+	var (
+		data []byte
+		err  error
+	)
+	if args[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read session: %w", err)
+	}
+	if !crypto.IsEncryptedBytes(data) {
+		return fmt.Errorf("%s is not an exported nikte session", args[0])
+	}
+
+	cfg := config.Get()
+	if cfg != nil && cfg.RefreshToken != "" && !sessionForce {
+		return fmt.Errorf("profile %q is already logged in. Use --force to replace it", config.ActiveProfile())
+	}
+
+	pass, err := resolvePassphrase(sessionEncPass, false)
+	if err != nil {
+		return err
+	}
+	plaintext, err := crypto.DecryptBytes(pass, data)
+	if err != nil {
+		return err
+	}
+
+	var session sessionFile
+	if err := json.Unmarshal(plaintext, &session); err != nil {
+		return fmt.Errorf("invalid session file: %w", err)
+	}
+	if session.Version != sessionFileVersion {
+		return fmt.Errorf("unsupported session file version %d", session.Version)
+	}
+	if session.RefreshToken == "" {
+		return fmt.Errorf("session file contains no refresh token")
+	}
+
+	err = config.Update(func(cfg *config.Config) error {
+		cfg.BaseURL = session.BaseURL
+		cfg.IDToken = session.IDToken
+		cfg.AccessToken = session.AccessToken
+		cfg.RefreshToken = session.RefreshToken
+		cfg.LoggedInAt = session.LoggedInAt
+		cfg.AuthDomain = session.AuthDomain
+		cfg.AuthClientID = session.AuthClientID
+		cfg.AuthTokenEndpoint = session.AuthTokenEndpoint
+		cfg.AuthDeviceEndpoint = session.AuthDeviceEndpoint
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "Session imported into profile %q.\n", config.ActiveProfile())
+	return nil
+}
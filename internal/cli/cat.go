@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/crypto"
+	"github.com/sim4gh/nikte-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// catEncPass is the --enc-pass flag of `nk cat`.
+var catEncPass string
+
+// Exit codes of `nk cat` besides 0 and 1 (any other error).
+const (
+	catExitNotFound = 2
+	catExitNotText  = 3
+)
+
+func addCatCommand() {
+	catCmd := &cobra.Command{
+		Use:   "cat <id>",
+		Short: "Print a text item's content and nothing else",
+		Long: `Print the content of a text item to stdout exactly as stored, with no
+headers, spinner or clipboard copy, for pipelines and shell prompts.
+Encrypted items are decrypted.
+
+Exit status: 0 printed, 2 no such item (or expired), 3 not a text item
+(use nk get), 1 any other error.
+
+Examples:
+  nk cat abc1 | jq .                     Pipe a text item
+  nk cat abc1 > notes.md                 Save it as is
+  nk cat todo 2>/dev/null || echo none   Test whether an item exists`,
+		Args: cobra.ExactArgs(1),
+		RunE: runCat,
+	}
+	catCmd.Flags().StringVar(&catEncPass, "enc-pass", "", "Passphrase to decrypt an encrypted item (else prompt or NIKTE_PASSPHRASE)")
+
+	rootCmd.AddCommand(catCmd)
+}
+
+func runCat(cmd *cobra.Command, args []string) error {
+	id := config.ResolveAlias(args[0])
+	resp, err := api.Do(cmd.Context(), "GET", "/shorts/"+id, nil)
+	if errors.Is(err, api.ErrNotFound) {
+		if _, err := api.Do(cmd.Context(), "GET", "/screenshots/"+id, nil); err == nil {
+			return &exitError{code: catExitNotText, err: fmt.Errorf("%s is a screenshot, not text; download it with: nk get %s", id, id)}
+		}
+		return &exitError{code: catExitNotFound, err: fmt.Errorf("no text item %q; it may have expired or been deleted", id)}
+	}
+	if err != nil {
+		return err
+	}
+
+	var short models.Short
+	if err := resp.Unmarshal(&short); err != nil {
+		return err
+	}
+	if short.Type != "" && short.Type != "text" {
+		return &exitError{code: catExitNotText, err: fmt.Errorf("%s is a %s, not text; download it with: nk get %s", id, short.Type, id)}
+	}
+
+	content := short.Content
+	if crypto.IsEncryptedText(content) {
+		pass, err := resolvePassphrase(catEncPass, false)
+		if err != nil {
+			return err
+		}
+		if content, err = crypto.DecryptText(pass, content); err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(stdout, content)
+	return err
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/platform"
+)
+
+// Kinds of value commands copy to the clipboard on their own, selected with
+// the auto_copy config key.
+const (
+	autoCopyID      = "id"
+	autoCopyURL     = "url"
+	autoCopyContent = "content"
+)
+
+// rootNoClipboard is the global --no-clipboard flag
+var rootNoClipboard bool
+
+// autoCopyEnabled reports whether values of kind are copied automatically.
+// With auto_copy unset everything is copied; --no-clipboard disables it all.
+// Explicit requests such as `nk g --copy` don't go through this check.
+func autoCopyEnabled(kind string) bool {
+	if rootNoClipboard {
+		return false
+	}
+	cfg := config.Get()
+	if cfg == nil || cfg.AutoCopy == "" {
+		return true
+	}
+	for _, k := range strings.Split(cfg.AutoCopy, ",") {
+		if strings.TrimSpace(k) == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// copyToClipboard copies text if auto_copy allows kind, and says so.
+func copyToClipboard(text, label, kind string) {
+	if !autoCopyEnabled(kind) {
+		return
+	}
+	if err := platform.CopyText(text); err == nil {
+		fmt.Fprintf(info, "\n(%s copied to clipboard)\n", label)
+	}
+}
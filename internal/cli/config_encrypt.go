@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+)
+
+func init() {
+	config.PassphrasePrompt = func() (string, error) {
+		return promptSecret("config passphrase", false)
+	}
+}
+
+// encryptConfig encrypts the stored tokens of every profile, with a new
+// passphrase or (--keychain) a random key kept in the OS keychain.
+func encryptConfig() error {
+	if encrypted, mode := config.IsEncrypted(); encrypted {
+		return fmt.Errorf("tokens are already encrypted (%s). Run \"nk config decrypt\" first to change how", mode)
+	}
+
+	mode, pass := config.EncryptKeychain, ""
+	if !configKeychain {
+		mode = config.EncryptPassphrase
+		pass = os.Getenv(config.PassphraseEnvVar)
+		if pass == "" {
+			var err error
+			if pass, err = promptSecret("new config passphrase", true); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := config.Encrypt(mode, pass); err != nil {
+		return fmt.Errorf("failed to encrypt tokens: %w", err)
+	}
+
+	if mode == config.EncryptKeychain {
+		fmt.Fprintln(stdout, "Tokens encrypted with a key stored in the OS keychain.")
+	} else {
+		fmt.Fprintln(stdout, "Tokens encrypted. nk will ask for the passphrase when it needs them")
+		fmt.Fprintf(stdout, "(or set %s).\n", config.PassphraseEnvVar)
+	}
+	return nil
+}
+
+// decryptConfig stores the tokens in plaintext again.
+func decryptConfig() error {
+	if encrypted, _ := config.IsEncrypted(); !encrypted {
+		return fmt.Errorf("tokens are not encrypted")
+	}
+	if err := config.Decrypt(); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "Tokens are now stored unencrypted.")
+	return nil
+}
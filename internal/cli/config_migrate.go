@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/auth"
+	"github.com/sim4gh/nikte-cli/internal/config"
+)
+
+// errNothingToMigrate stops the config update when the oio config adds
+// nothing, so the file isn't rewritten.
+var errNothingToMigrate = errors.New("nothing to migrate")
+
+// migrateConfig imports the Node.js oio CLI's config from path (or the first
+// legacy location found) into the active profile, then checks that the
+// imported login still works. Settings already set here are kept.
+func migrateConfig(ctx context.Context, path string) error {
+	if path == "" {
+		found, ok := config.FindLegacy()
+		if !ok {
+			return fmt.Errorf("no oio CLI config found. Looked in:\n  %s\nPass the file with: nk config migrate <file>",
+				strings.Join(config.LegacyPaths(), "\n  "))
+		}
+		path = found
+	}
+
+	legacy, err := config.ReadLegacy(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	fmt.Fprintf(stdout, "Found oio CLI config: %s\n", path)
+
+	if err := config.Unlock(); err != nil {
+		return err
+	}
+	cfg := config.Get()
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	hasLogin := legacy.RefreshToken != "" || legacy.IDToken != ""
+	if hasLogin && cfg.RefreshToken != "" && !configForce {
+		return fmt.Errorf("profile %q is already logged in. Use --force to replace it with the oio login", config.ActiveProfile())
+	}
+
+	// default_ttl and quiet are written with config.Set afterwards: under a
+	// named profile Update only stores credentials and endpoints, while Set
+	// stores them as the profile's overrides.
+	var migrated []string
+	var settings [][2]string
+	err = config.Update(func(cfg *config.Config) error {
+		migrated, settings = nil, nil
+		if hasLogin {
+			cfg.IDToken = legacy.IDToken
+			cfg.AccessToken = legacy.AccessToken
+			cfg.RefreshToken = legacy.RefreshToken
+			cfg.LoggedInAt = legacy.LoggedInAt
+			if legacy.BaseURL != "" {
+				cfg.BaseURL = legacy.BaseURL
+			}
+			migrated = append(migrated, "login")
+		} else if cfg.BaseURL == "" && legacy.BaseURL != "" {
+			cfg.BaseURL = legacy.BaseURL
+			migrated = append(migrated, "baseurl")
+		}
+		if cfg.DefaultTTL == "" && legacy.DefaultTTL != "" {
+			settings = append(settings, [2]string{"default_ttl", legacy.DefaultTTL})
+		}
+		if !cfg.Quiet && legacy.Quiet {
+			settings = append(settings, [2]string{"quiet", "true"})
+		}
+		if len(migrated) == 0 {
+			return errNothingToMigrate
+		}
+		return nil
+	})
+	if errors.Is(err, errNothingToMigrate) {
+		if len(settings) == 0 {
+			fmt.Fprintln(stdout, "Nothing to migrate.")
+			return nil
+		}
+		err = nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	for _, kv := range settings {
+		if err := config.Set(kv[0], kv[1]); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		migrated = append(migrated, kv[0])
+	}
+	fmt.Fprintf(stdout, "Migrated: %s\n", strings.Join(migrated, ", "))
+
+	if !hasLogin {
+		return nil
+	}
+	return verifyMigratedLogin(ctx)
+}
+
+// verifyMigratedLogin makes an authenticated request with the imported
+// tokens (refreshing them if needed). A login the server rejects is removed so
+// the next command asks for a fresh one; if the server can't be reached the
+// tokens are kept.
+func verifyMigratedLogin(ctx context.Context) error {
+	fmt.Fprintln(stdout, "Verifying login...")
+
+	_, err := api.Do(ctx, "GET", "/account", nil)
+	rejected := errors.Is(err, auth.ErrSessionExpired) || errors.Is(err, api.ErrUnauthorized)
+	if err != nil && !rejected {
+		return fmt.Errorf("settings and login were migrated, but the login could not be verified: %w", err)
+	}
+	if rejected {
+		err := config.Update(func(cfg *config.Config) error {
+			cfg.IDToken, cfg.AccessToken, cfg.RefreshToken, cfg.LoggedInAt = "", "", "", ""
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("settings were migrated, but the oio login is no longer valid. Run \"nk auth login\"")
+	}
+
+	fmt.Fprintln(stdout, "Login verified. You can now use nk without logging in again.")
+	return nil
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportConfigSkipsEndpoints(t *testing.T) {
+	n := newTestNK(t)
+	file := filepath.Join(t.TempDir(), "team.json")
+	settings := `{"default_ttl": "7d", "baseurl": "https://attacker.example", "auth_token_endpoint": "https://attacker.example/token"}`
+	if err := os.WriteFile(file, []byte(settings), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		for _, key := range []string{"default_ttl", "baseurl", "auth_token_endpoint"} {
+			n.run("config", "unset", key)
+		}
+	})
+
+	out, errOut, code := n.run("config", "import", file)
+	if code != 0 {
+		t.Fatalf("nk config import: exit code %d: %s", code, errOut)
+	}
+	if !strings.Contains(out, `"default_ttl"`) || strings.Contains(out, "attacker") {
+		t.Errorf("nk config import printed:\n%s", out)
+	}
+	for _, key := range []string{"baseurl", "auth_token_endpoint"} {
+		if !strings.Contains(errOut, "skipped \""+key+"\"") {
+			t.Errorf("no warning about %s in:\n%s", key, errOut)
+		}
+		if got, _, _ := n.run("config", "get", key); strings.Contains(got, "attacker") {
+			t.Errorf("%s was imported: %s", key, got)
+		}
+	}
+
+	if _, errOut, code := n.run("config", "import", file, "--allow-endpoints"); code != 0 {
+		t.Fatalf("nk config import --allow-endpoints: exit code %d: %s", code, errOut)
+	}
+	if got, _, _ := n.run("config", "get", "baseurl"); !strings.Contains(got, "attacker") {
+		t.Errorf("baseurl after --allow-endpoints: %q", got)
+	}
+}
+
+func TestMigrateConfigIntoNamedProfile(t *testing.T) {
+	n := newTestNK(t)
+	file := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(file, []byte(`{"defaultTtl": "7d", "quiet": true}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		for _, key := range []string{"default_ttl", "quiet"} {
+			n.run("config", "unset", key, "--profile", "work")
+		}
+	})
+
+	out, errOut, code := n.run("config", "migrate", file, "--profile", "work")
+	if code != 0 {
+		t.Fatalf("nk config migrate: exit code %d: %s", code, errOut)
+	}
+	if !strings.Contains(out, "Migrated: default_ttl, quiet") {
+		t.Errorf("nk config migrate printed:\n%s", out)
+	}
+	for key, want := range map[string]string{"default_ttl": "7d", "quiet": "true"} {
+		if got, _, _ := n.run("config", "get", key, "--profile", "work"); strings.TrimSpace(got) != want {
+			t.Errorf("%s in profile work = %q, want %q", key, strings.TrimSpace(got), want)
+		}
+		if got, _, _ := n.run("config", "get", key); strings.TrimSpace(got) == want {
+			t.Errorf("%s leaked into the default profile", key)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/crypto"
+	"github.com/sim4gh/nikte-cli/internal/platform"
+	"github.com/sim4gh/nikte-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// Flags of `nk cp`.
+var (
+	cpImage   bool
+	cpEncPass string
+)
+
+func addCpCommand() {
+	cpCmd := &cobra.Command{
+		Use:   "cp <id>",
+		Short: "Copy an item's content or download URL to the clipboard",
+		Long: `Copy a text item's content, or the download URL of a file or screenshot,
+to the clipboard without printing it, so secrets stay out of the terminal's
+scrollback. Encrypted text is decrypted first.
+
+Examples:
+  nk cp abc1                             Text → clipboard; files → download URL
+  nk cp s1 --image                       The screenshot itself, as an image`,
+		Args: cobra.ExactArgs(1),
+		RunE: runCp,
+	}
+	cpCmd.Flags().BoolVar(&cpImage, "image", false, "For screenshots, copy the image rather than its URL")
+	cpCmd.Flags().StringVar(&cpEncPass, "enc-pass", "", "Passphrase to decrypt an encrypted item (else prompt or NIKTE_PASSPHRASE)")
+
+	rootCmd.AddCommand(cpCmd)
+}
+
+func runCp(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	id := config.ResolveAlias(args[0])
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " Fetching item..."
+	s.Start()
+	res, d, err := lookupItem(ctx, id)
+	s.Stop()
+	if errors.Is(err, api.ErrNotFound) {
+		return fmt.Errorf("no item found with ID %q. The item may have already expired or been deleted", id)
+	}
+	if err != nil {
+		return err
+	}
+
+	if cpImage && res.Type != "screenshot" {
+		return fmt.Errorf("--image only applies to screenshots; %s is a %s", id, res.Type)
+	}
+
+	switch {
+	case res.URL == "":
+		content := res.Content
+		if d.Encrypted {
+			pass, err := resolvePassphrase(cpEncPass, false)
+			if err != nil {
+				return err
+			}
+			if content, err = crypto.DecryptText(pass, content); err != nil {
+				return err
+			}
+		}
+		if err := platform.CopyText(content); err != nil {
+			return fmt.Errorf("failed to copy to the clipboard: %w", err)
+		}
+		fmt.Fprintf(stdout, "✓ Copied the text of %s (%s) to the clipboard\n", id, util.FormatBytes(int64(len(content))))
+
+	case cpImage:
+		s.Suffix = " Downloading image..."
+		s.Start()
+		data, err := downloadBytes(ctx, res.URL)
+		s.Stop()
+		if err != nil {
+			return fmt.Errorf("failed to download the screenshot: %w", err)
+		}
+		if err := platform.SetClipboardImage(data); err != nil {
+			return fmt.Errorf("failed to copy the image to the clipboard: %w", err)
+		}
+		fmt.Fprintf(stdout, "✓ Copied screenshot %s (%s) to the clipboard\n", id, util.FormatBytes(int64(len(data))))
+
+	default:
+		if err := platform.CopyText(res.URL); err != nil {
+			return fmt.Errorf("failed to copy to the clipboard: %w", err)
+		}
+		fmt.Fprintf(stdout, "✓ Copied the download URL of %s to the clipboard (valid for 1 hour)\n", id)
+	}
+	return nil
+}
@@ -0,0 +1,544 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/sim4gh/nikte-cli/internal/daemon"
+	"github.com/sim4gh/nikte-cli/internal/platform"
+	"github.com/sim4gh/nikte-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// Flags of `nk daemon start` and `nk daemon run`, choosing its tasks.
+var (
+	daemonClipboard    bool
+	daemonCaptureEvery time.Duration
+	daemonWatchDir     string
+	daemonNotifyExpiry time.Duration
+	daemonStatusJSON   bool
+)
+
+const (
+	// daemonPollInterval is how often the clipboard and watched folder are
+	// checked for changes.
+	daemonPollInterval = 2 * time.Second
+
+	// daemonExpiryInterval is how often items are checked for expiry.
+	daemonExpiryInterval = 5 * time.Minute
+
+	// daemonStartTimeout is how long `nk daemon start` waits for the new
+	// process to answer on its socket.
+	daemonStartTimeout = 5 * time.Second
+
+	// daemonRecentEvents is how many events `nk daemon status` shows.
+	daemonRecentEvents = 10
+)
+
+func addDaemonCommand() {
+	daemonCmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run background tasks: clipboard sync, captures, folder watch",
+		Long: `Run a background process that uploads what you copy, takes screenshots
+on a schedule, uploads files dropped into a folder, and warns before items
+expire. The CLI controls it over a socket in the config directory.
+
+Examples:
+  nk daemon start --clipboard            Upload each new clipboard text or image
+    ├ --capture-every 30m                 Full-screen screenshot every 30 minutes
+    ├ --watch-dir ~/Drop                  Upload files added to ~/Drop
+    └ --notify-expiry 1h                  Notify an hour before items expire
+  nk daemon status                       Tasks, uploads and recent events
+  nk daemon stop                         Stop the daemon
+  nk daemon run --clipboard              Run in the foreground (systemd, launchd)`,
+	}
+
+	startCmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start the daemon in the background",
+		Args:  cobra.NoArgs,
+		RunE:  runDaemonStart,
+	}
+	runCmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run the daemon in the foreground",
+		Args:  cobra.NoArgs,
+		RunE:  runDaemonRun,
+	}
+	for _, c := range []*cobra.Command{startCmd, runCmd} {
+		c.Flags().BoolVar(&daemonClipboard, "clipboard", false, "Upload new clipboard contents (text and images)")
+		c.Flags().DurationVar(&daemonCaptureEvery, "capture-every", 0, "Take and upload a full-screen screenshot at this interval (e.g. 30m)")
+		c.Flags().StringVar(&daemonWatchDir, "watch-dir", "", "Upload files added to this folder")
+		c.Flags().DurationVar(&daemonNotifyExpiry, "notify-expiry", 0, "Show a notification this long before an item expires (e.g. 1h)")
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the daemon is running and what it has done",
+		Args:  cobra.NoArgs,
+		RunE:  runDaemonStatus,
+	}
+	statusCmd.Flags().BoolVar(&daemonStatusJSON, "json", false, "Output as JSON")
+
+	stopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the daemon",
+		Args:  cobra.NoArgs,
+		RunE:  runDaemonStop,
+	}
+
+	daemonCmd.AddCommand(startCmd, runCmd, statusCmd, stopCmd)
+	rootCmd.AddCommand(daemonCmd)
+}
+
+// daemonTasks describes the tasks the daemon flags enable.
+func daemonTasks() []string {
+	var tasks []string
+	if daemonClipboard {
+		tasks = append(tasks, "clipboard")
+	}
+	if daemonCaptureEvery > 0 {
+		tasks = append(tasks, "capture every "+daemonCaptureEvery.String())
+	}
+	if daemonWatchDir != "" {
+		tasks = append(tasks, "watch "+daemonWatchDir)
+	}
+	if daemonNotifyExpiry > 0 {
+		tasks = append(tasks, "notify "+daemonNotifyExpiry.String()+" before expiry")
+	}
+	return tasks
+}
+
+// validateDaemonFlags checks the task flags before the daemon starts, so
+// mistakes are reported to the terminal rather than the log.
+func validateDaemonFlags() error {
+	if len(daemonTasks()) == 0 {
+		return fmt.Errorf("nothing to do: pass --clipboard, --capture-every, --watch-dir or --notify-expiry")
+	}
+	if daemonCaptureEvery > 0 && daemonCaptureEvery < time.Minute {
+		return fmt.Errorf("--capture-every must be at least 1m")
+	}
+	if daemonCaptureEvery > 0 && !platform.IsScreenshotSupported() {
+		return fmt.Errorf("--capture-every: screenshots are not supported on this system")
+	}
+	if daemonWatchDir != "" {
+		abs, err := filepath.Abs(daemonWatchDir)
+		if err != nil {
+			return err
+		}
+		if fi, err := os.Stat(abs); err != nil || !fi.IsDir() {
+			return fmt.Errorf("--watch-dir %s is not a directory", daemonWatchDir)
+		}
+		daemonWatchDir = abs
+	}
+	return nil
+}
+
+// runDaemonStart re-runs nk as `nk daemon run` with the same flags,
+// detached from the terminal with its output going to the daemon log, and
+// waits for it to answer.
+func runDaemonStart(cmd *cobra.Command, args []string) error {
+	if err := validateDaemonFlags(); err != nil {
+		return err
+	}
+	if _, err := daemon.Call(daemon.Request{Cmd: daemon.CmdStatus}); err == nil {
+		return fmt.Errorf("the daemon is already running; stop it first with: nk daemon stop")
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	logPath, err := daemon.LogPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0o700); err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	runArgs := []string{"daemon", "run"}
+	if rootProfile != "" {
+		runArgs = append(runArgs, "--profile", rootProfile)
+	}
+	if daemonClipboard {
+		runArgs = append(runArgs, "--clipboard")
+	}
+	if daemonCaptureEvery > 0 {
+		runArgs = append(runArgs, "--capture-every", daemonCaptureEvery.String())
+	}
+	if daemonWatchDir != "" {
+		runArgs = append(runArgs, "--watch-dir", daemonWatchDir)
+	}
+	if daemonNotifyExpiry > 0 {
+		runArgs = append(runArgs, "--notify-expiry", daemonNotifyExpiry.String())
+	}
+
+	child := exec.Command(exe, runArgs...)
+	child.Stdout, child.Stderr = logFile, logFile
+	daemon.Detach(child)
+	if err := child.Start(); err != nil {
+		return fmt.Errorf("failed to start the daemon: %w", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- child.Wait() }()
+
+	deadline := time.After(daemonStartTimeout)
+	for {
+		select {
+		case <-exited:
+			return fmt.Errorf("the daemon exited on start; see %s", logPath)
+		case <-deadline:
+			return fmt.Errorf("the daemon did not answer within %s; see %s", daemonStartTimeout, logPath)
+		case <-time.After(100 * time.Millisecond):
+		}
+		if _, err := daemon.Call(daemon.Request{Cmd: daemon.CmdStatus}); err == nil {
+			fmt.Fprintf(stdout, "✓ Daemon started (pid %d): %s\n", child.Process.Pid, strings.Join(daemonTasks(), ", "))
+			fmt.Fprintf(stdout, "Log: %s\n", logPath)
+			return nil
+		}
+	}
+}
+
+func runDaemonStop(cmd *cobra.Command, args []string) error {
+	if _, err := daemon.Call(daemon.Request{Cmd: daemon.CmdStop}); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "✓ Daemon stopped")
+	return nil
+}
+
+func runDaemonStatus(cmd *cobra.Command, args []string) error {
+	if daemonStatusJSON && !machineOutput() {
+		outputMode = outputJSON
+	}
+	resp, err := daemon.Call(daemon.Request{Cmd: daemon.CmdStatus})
+	if errors.Is(err, daemon.ErrNotRunning) {
+		if machineOutput() {
+			return emitResult(map[string]bool{"running": false})
+		}
+		fmt.Fprintln(stdout, "Daemon: not running")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	st := resp.Status
+	if machineOutput() {
+		return emitResult(st)
+	}
+	fmt.Fprintf(stdout, "Daemon: running (pid %d, up %s)\n", st.PID, time.Since(st.Started).Round(time.Second))
+	fmt.Fprintf(stdout, "Tasks: %s\n", strings.Join(st.Tasks, ", "))
+	fmt.Fprintf(stdout, "Uploads: %d, errors: %d\n", st.Uploads, st.Errors)
+	if st.Log != "" {
+		fmt.Fprintf(stdout, "Log: %s\n", st.Log)
+	}
+	if len(st.Recent) > 0 {
+		fmt.Fprintln(stdout, "\nRecent:")
+		for _, e := range st.Recent {
+			fmt.Fprintf(stdout, "  %s  %s\n", e.At.Local().Format("15:04:05"), e.Message)
+		}
+	}
+	return nil
+}
+
+// daemonState is the running daemon's status, shared by its tasks.
+type daemonState struct {
+	mu     sync.Mutex
+	status daemon.Status
+}
+
+// logf records an event in the log and the recent list; failures also
+// count as errors.
+func (d *daemonState) logf(failed bool, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	now := time.Now()
+	fmt.Fprintf(stdout, "%s %s\n", now.Format(time.RFC3339), msg)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if failed {
+		d.status.Errors++
+	}
+	d.status.Recent = append(d.status.Recent, daemon.Event{At: now, Message: msg})
+	if len(d.status.Recent) > daemonRecentEvents {
+		d.status.Recent = d.status.Recent[len(d.status.Recent)-daemonRecentEvents:]
+	}
+}
+
+func (d *daemonState) uploaded(format string, args ...any) {
+	d.mu.Lock()
+	d.status.Uploads++
+	d.mu.Unlock()
+	d.logf(false, format, args...)
+}
+
+// notifyWebhook sends event about res to the webhook, logging failures.
+func (d *daemonState) notifyWebhook(ctx context.Context, event string, res itemResult) {
+	if err := sendWebhook(ctx, event, &res); err != nil {
+		d.logf(true, "%v", err)
+	}
+}
+
+// added runs the post_add hook and webhook for an upload the daemon made,
+// logging failures. Items go straight to the hooks rather than through
+// historyItems, which would grow for as long as the daemon runs.
+func (d *daemonState) added(ctx context.Context, res itemResult) {
+	runItemHooks(ctx, "add", []itemResult{res}, func(format string, args ...any) {
+		d.logf(true, format, args...)
+	})
+}
+
+func (d *daemonState) snapshot() daemon.Status {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	st := d.status
+	st.Recent = append([]daemon.Event(nil), d.status.Recent...)
+	return st
+}
+
+// runDaemonRun is the daemon itself: it serves the control socket and runs
+// the enabled tasks until stopped or interrupted.
+func runDaemonRun(cmd *cobra.Command, args []string) error {
+	if err := validateDaemonFlags(); err != nil {
+		return err
+	}
+	ln, err := daemon.Listen()
+	if err != nil {
+		return err
+	}
+
+	// The upload helpers report to info and copy IDs to the clipboard; in
+	// the daemon that would be noise, and would feed the clipboard watcher.
+	info = io.Discard
+	rootNoClipboard = true
+
+	ctx, stop := context.WithCancel(cmd.Context())
+	defer stop()
+	logPath, _ := daemon.LogPath()
+	d := &daemonState{status: daemon.Status{Running: true, PID: os.Getpid(), Started: time.Now(), Tasks: daemonTasks(), Log: logPath}}
+
+	var wg sync.WaitGroup
+	spawn := func(task func(context.Context, *daemonState)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			task(ctx, d)
+		}()
+	}
+	if daemonClipboard {
+		spawn(watchClipboard)
+	}
+	if daemonCaptureEvery > 0 {
+		spawn(captureOnSchedule)
+	}
+	if daemonWatchDir != "" {
+		spawn(watchFolder)
+	}
+	if daemonNotifyExpiry > 0 {
+		spawn(notifyExpiring)
+	}
+
+	d.logf(false, "Daemon started (pid %d): %s", os.Getpid(), strings.Join(d.status.Tasks, ", "))
+	daemon.Serve(ctx, ln, func(req daemon.Request) daemon.Response {
+		switch req.Cmd {
+		case daemon.CmdStatus:
+			st := d.snapshot()
+			return daemon.Response{OK: true, Status: &st}
+		case daemon.CmdStop:
+			stop()
+			return daemon.Response{OK: true}
+		default:
+			return daemon.Response{Error: fmt.Sprintf("unknown command %q", req.Cmd)}
+		}
+	})
+	wg.Wait()
+	d.logf(false, "Daemon stopped")
+	return nil
+}
+
+// quietSpinner is a spinner for the add helpers that draws nothing.
+func quietSpinner() *spinner.Spinner {
+	return spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(io.Discard))
+}
+
+// watchClipboard uploads the clipboard whenever it changes: images as
+// screenshots, text as text items. What is on it at start is left alone.
+func watchClipboard(ctx context.Context, d *daemonState) {
+	read := func() (text string, image []byte, sum [32]byte) {
+		if platform.ClipboardHasImage() {
+			if data, err := platform.GetClipboardImage(); err == nil && len(data) > 0 {
+				return "", data, sha256.Sum256(data)
+			}
+		}
+		text, _ = platform.PasteText()
+		return text, nil, sha256.Sum256([]byte(text))
+	}
+
+	_, _, last := read()
+	tick := time.NewTicker(daemonPollInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+		text, image, sum := read()
+		if sum == last {
+			continue
+		}
+		last = sum
+		if image == nil && strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		addMu.Lock()
+		var err error
+		if image != nil {
+			err = uploadImage(ctx, image, quietSpinner(), "clipboard")
+		} else {
+			err = uploadTextContent(ctx, text, quietSpinner())
+		}
+		res := addResult
+		addMu.Unlock()
+		id := res.ID
+		if err == nil {
+			d.added(ctx, res)
+		}
+		switch {
+		case err != nil:
+			d.logf(true, "Clipboard upload failed: %v", err)
+		case image != nil:
+			d.uploaded("Uploaded clipboard image: %s", id)
+		default:
+			d.uploaded("Uploaded clipboard text: %s (%s)", id, util.Truncate(util.ReplaceNewlines(text), 40))
+		}
+	}
+}
+
+// captureOnSchedule takes and uploads a full-screen screenshot every
+// --capture-every.
+func captureOnSchedule(ctx context.Context, d *daemonState) {
+	tick := time.NewTicker(daemonCaptureEvery)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+		data, err := platform.CaptureScreenshot(false, true)
+		if err != nil {
+			d.logf(true, "Screenshot failed: %v", err)
+			continue
+		}
+		addMu.Lock()
+		err = uploadImage(ctx, data, quietSpinner(), "screenshot")
+		res := addResult
+		addMu.Unlock()
+		if err != nil {
+			d.logf(true, "Screenshot upload failed: %v", err)
+			continue
+		}
+		d.uploaded("Uploaded screenshot: %s", res.ID)
+		d.added(ctx, res)
+	}
+}
+
+// daemonReporter sends the events of an upload the daemon makes to its log.
+type daemonReporter struct {
+	d    *daemonState
+	name string
+}
+
+func (r *daemonReporter) Status(msg string)                               {}
+func (r *daemonReporter) Infof(format string, args ...any)                {}
+func (r *daemonReporter) Progress(completed, total int, done, size int64) {}
+func (r *daemonReporter) Stop()                                           {}
+
+func (r *daemonReporter) Warnf(format string, args ...any) {
+	r.d.logf(false, "%s: %s", r.name, fmt.Sprintf(format, args...))
+}
+
+// watchFolder uploads the files added to --watch-dir, as nk watch does.
+func watchFolder(ctx context.Context, d *daemonState) {
+	w := newFolderWatcher(daemonWatchDir)
+	tick := time.NewTicker(daemonPollInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+		for _, name := range w.poll() {
+			path := filepath.Join(daemonWatchDir, name)
+			addMu.Lock()
+			res, err := uploadFile(ctx, path, &daemonReporter{d: d, name: name})
+			addMu.Unlock()
+			if err != nil {
+				d.logf(true, "Upload of %s failed: %v", name, err)
+				w.failed(name)
+				continue
+			}
+			w.uploaded(name)
+			d.uploaded("Uploaded %s: %s", name, res.ID)
+			d.added(ctx, *res)
+		}
+	}
+}
+
+// notifyExpiring shows a notification once for each item that will expire
+// within --notify-expiry, checking every few minutes.
+func notifyExpiring(ctx context.Context, d *daemonState) {
+	notified := map[string]bool{}
+	tick := time.NewTicker(daemonExpiryInterval)
+	defer tick.Stop()
+	for {
+		fetched := fetchAllItems(ctx)
+		if fetched.err != nil {
+			d.logf(true, "Expiry check failed: %v", fetched.err)
+		}
+		horizon := time.Now().Add(daemonNotifyExpiry).Unix()
+		for _, item := range fetched.items {
+			if item.ExpiresAt == 0 || item.ExpiresAt > horizon || notified[item.ID] {
+				continue
+			}
+			notified[item.ID] = true
+			name := item.Filename
+			if name == "" {
+				name = util.Truncate(util.ReplaceNewlines(item.Preview), 40)
+			}
+			msg := fmt.Sprintf("%s (%s) expires %s. Keep it with: nk extend %s --ttl 7d",
+				item.ID, name, util.FormatExpiryTime(item.ExpiresAt), item.ID)
+			if err := platform.Notify("nikte item expiring", msg); err != nil {
+				d.logf(false, "%s (notification failed: %v)", msg, err)
+			} else {
+				d.logf(false, "Notified: %s", msg)
+			}
+			d.notifyWebhook(ctx, "expiring", itemResult{ID: item.ID, Type: item.Type, Filename: item.Filename,
+				Size: item.Size, CreatedAt: item.CreatedAt, ExpiresAt: item.ExpiresAt})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
@@ -2,6 +2,8 @@ package cli
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -9,10 +11,15 @@ import (
 
 	"github.com/briandowns/spinner"
 	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/config"
 	"github.com/spf13/cobra"
 )
 
-var deleteForce bool
+// Flags of `nk delete`.
+var (
+	deleteForce   bool
+	deleteNoTrash bool
+)
 
 func addDeleteCommand() {
 	deleteCmd := &cobra.Command{
@@ -22,23 +29,25 @@ func addDeleteCommand() {
 
 Examples:
   nk d <id>                   Delete with confirmation
-    └ --force                  Delete without confirmation`,
+    └ --force                  Delete without confirmation
+    └ --no-trash               Don't keep a copy, with the trash on (see nk trash)`,
 		Aliases: []string{"delete"},
 		Args:    cobra.ExactArgs(1),
 		RunE:    runDelete,
 	}
 
 	deleteCmd.Flags().BoolVarP(&deleteForce, "force", "f", false, "Skip confirmation")
+	deleteCmd.Flags().BoolVar(&deleteNoTrash, "no-trash", false, "Don't keep a copy in the trash")
 
 	rootCmd.AddCommand(deleteCmd)
 }
 
 func runDelete(cmd *cobra.Command, args []string) error {
-	id := args[0]
+	id := config.ResolveAlias(args[0])
 
 	// Skip confirmation if --force flag is provided
 	if !deleteForce {
-		fmt.Printf("Are you sure you want to delete item %q? [y/N]: ", id)
+		fmt.Fprintf(stdout, "Are you sure you want to delete item %q? [y/N]: ", id)
 
 		reader := bufio.NewReader(os.Stdin)
 		response, err := reader.ReadString('\n')
@@ -48,7 +57,7 @@ func runDelete(cmd *cobra.Command, args []string) error {
 
 		response = strings.TrimSpace(strings.ToLower(response))
 		if response != "y" && response != "yes" {
-			fmt.Println("Deletion cancelled")
+			fmt.Fprintln(stdout, "Deletion cancelled")
 			return nil
 		}
 	}
@@ -57,13 +66,22 @@ func runDelete(cmd *cobra.Command, args []string) error {
 	s.Suffix = " Deleting item..."
 	s.Start()
 
-	result := tryDelete(id)
+	result := deleteItem(cmd.Context(), id, !deleteNoTrash)
 
 	s.Stop()
 
 	if result.success {
-		fmt.Println("Item deleted successfully")
-		fmt.Printf("\nItem %q has been deleted.\n", id)
+		noteHistory(itemResult{ID: id, Type: result.source})
+		fmt.Fprintln(stdout, "Item deleted successfully")
+		fmt.Fprintf(stdout, "\nItem %q has been deleted.\n", id)
+		if len(result.aliases) > 0 {
+			fmt.Fprintf(stdout, "Removed alias %s\n", strings.Join(result.aliases, ", "))
+		}
+		if result.trashErr != nil {
+			fmt.Fprintf(stderr, "Warning: failed to keep a copy in the trash: %v\n", result.trashErr)
+		} else if result.trashed != nil && result.trashed.restorable() {
+			fmt.Fprintf(stdout, "Undo with: nk restore %s\n", id)
+		}
 		return nil
 	}
 
@@ -82,29 +100,38 @@ type deleteResult struct {
 	success bool
 	source  string
 	error   string
+
+	// Set by deleteItem: the aliases it removed, and the trash entry it
+	// kept or the error saving it.
+	aliases  []string
+	trashed  *trashEntry
+	trashErr error
 }
 
-func tryDelete(id string) deleteResult {
+func tryDelete(ctx context.Context, id string) deleteResult {
 	// Try as short first (most common)
-	resp, err := api.Delete("/shorts/" + id)
-	if err == nil && (resp.StatusCode == 204 || resp.StatusCode == 200) {
+	_, err := api.Do(ctx, "DELETE", "/shorts/"+id, nil)
+	if err == nil {
 		return deleteResult{success: true, source: "short"}
 	}
+	if errors.Is(err, api.ErrOffline) {
+		return deleteResult{success: false, error: err.Error()}
+	}
 
 	// Try as screenshot
-	resp, err = api.Delete("/screenshots/" + id)
-	if err == nil && (resp.StatusCode == 204 || resp.StatusCode == 200) {
+	_, err = api.Do(ctx, "DELETE", "/screenshots/"+id, nil)
+	if err == nil {
 		return deleteResult{success: true, source: "screenshot"}
 	}
 
 	// Try as file (Pro)
-	resp, err = api.Delete("/files/" + id)
-	if err == nil && (resp.StatusCode == 204 || resp.StatusCode == 200) {
+	_, err = api.Do(ctx, "DELETE", "/files/"+id, nil)
+	if err == nil {
 		return deleteResult{success: true, source: "file"}
 	}
 
 	// Check if it was a 403 (Pro required)
-	if resp != nil && resp.StatusCode == 403 {
+	if errors.Is(err, api.ErrForbidden) {
 		return deleteResult{success: false, error: "pro_required"}
 	}
 
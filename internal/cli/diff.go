@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/crypto"
+	"github.com/spf13/cobra"
+)
+
+// Flags of `nk diff`.
+var (
+	diffContext int
+	diffEncPass string
+)
+
+func addDiffCommand() {
+	diffCmd := &cobra.Command{
+		Use:   "diff <id1> <id2>",
+		Short: "Show a unified diff between two text items",
+		Long: `Compare two text items, such as two versions of a pasted config or log,
+and print a unified diff, colored on a terminal. Encrypted items are
+decrypted first.
+
+Examples:
+  nk diff abc1 abc2                      What changed between two pastes
+  nk diff old new -U 0                   Only the changed lines`,
+		Args: cobra.ExactArgs(2),
+		RunE: runDiff,
+	}
+	diffCmd.Flags().IntVarP(&diffContext, "unified", "U", 3, "Lines of context around each change")
+	diffCmd.Flags().StringVar(&diffEncPass, "enc-pass", "", "Passphrase of encrypted items (else prompt or NIKTE_PASSPHRASE)")
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if diffContext < 0 {
+		return fmt.Errorf("--unified must be 0 or more")
+	}
+
+	var texts [2]string
+	for i, arg := range args {
+		short, err := fetchTextShort(ctx, config.ResolveAlias(arg))
+		if err != nil {
+			return err
+		}
+		texts[i] = short.Content
+		if crypto.IsEncryptedText(texts[i]) {
+			// One prompt covers both items.
+			if diffEncPass, err = resolvePassphrase(diffEncPass, false); err != nil {
+				return err
+			}
+			if texts[i], err = crypto.DecryptText(diffEncPass, texts[i]); err != nil {
+				return fmt.Errorf("%s: %w", arg, err)
+			}
+		}
+	}
+
+	if texts[0] == texts[1] {
+		fmt.Fprintf(info, "%s and %s are identical\n", args[0], args[1])
+		return nil
+	}
+	out, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(texts[0]),
+		B:        diffLines(texts[1]),
+		FromFile: args[0],
+		ToFile:   args[1],
+		Context:  diffContext,
+	})
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		// Only the final newline differs.
+		fmt.Fprintf(info, "%s and %s differ only in a trailing newline\n", args[0], args[1])
+		return nil
+	}
+
+	color := colorOutput()
+	for _, line := range strings.SplitAfter(out, "\n") {
+		if !color || line == "" {
+			fmt.Fprint(stdout, line)
+			continue
+		}
+		code := ""
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			code = "1"
+		case strings.HasPrefix(line, "@@"):
+			code = "36"
+		case strings.HasPrefix(line, "-"):
+			code = "31"
+		case strings.HasPrefix(line, "+"):
+			code = "32"
+		}
+		if code == "" {
+			fmt.Fprint(stdout, line)
+		} else {
+			fmt.Fprintf(stdout, "\x1b[%sm%s\x1b[0m\n", code, strings.TrimSuffix(line, "\n"))
+		}
+	}
+	return nil
+}
+
+// diffLines splits s into lines that each end in a newline, so that the
+// last lines of two texts compare equal whether or not they had one.
+func diffLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/crypto"
+	"github.com/sim4gh/nikte-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// editEncPass is the --enc-pass flag of `nk edit`.
+var editEncPass string
+
+func addEditCommand() {
+	editCmd := &cobra.Command{
+		Use:   "edit <id>",
+		Short: "Edit a text item in $EDITOR and save it back",
+		Long: `Open a text item in $VISUAL or $EDITOR and, when you save and quit, store
+the new content under the same ID. Encrypted items are decrypted for
+editing and encrypted again with the same passphrase.
+
+If the server can't update content in place, the item is replaced by a new
+one with the same remaining lifetime, its aliases are moved over, and the
+old one is deleted; the new ID is printed.
+
+Examples:
+  nk edit abc1                           Fix a typo in a paste
+  EDITOR="code --wait" nk edit notes     Edit an aliased item in VS Code`,
+		Args: cobra.ExactArgs(1),
+		RunE: runEdit,
+	}
+	editCmd.Flags().StringVar(&editEncPass, "enc-pass", "", "Passphrase of an encrypted item (else prompt or NIKTE_PASSPHRASE)")
+
+	rootCmd.AddCommand(editCmd)
+}
+
+func runEdit(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	id := config.ResolveAlias(args[0])
+
+	short, err := fetchTextShort(ctx, id)
+	if err != nil {
+		return err
+	}
+	content, pass := short.Content, ""
+	if crypto.IsEncryptedText(content) {
+		if pass, err = resolvePassphrase(editEncPass, false); err != nil {
+			return err
+		}
+		if content, err = crypto.DecryptText(pass, content); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.CreateTemp("", "nk-"+id+"-*.txt")
+	if err != nil {
+		return err
+	}
+	path := f.Name()
+	_, err = f.WriteString(content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	// Kept if saving fails, so the edits aren't lost.
+	keep := false
+	defer func() {
+		if !keep {
+			os.Remove(path)
+		}
+	}()
+
+	if err := runEditor(path); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	edited := string(data)
+	switch {
+	case edited == content:
+		fmt.Fprintln(stdout, "No changes")
+		return nil
+	case strings.TrimSpace(edited) == "":
+		return fmt.Errorf("the edited content is empty; item %s left unchanged (use nk delete to remove it)", id)
+	}
+
+	newID, err := replaceText(ctx, short, edited, pass)
+	if err != nil {
+		keep = true
+		return fmt.Errorf("%w\nYour edits are saved in %s", err, path)
+	}
+	if newID == id {
+		fmt.Fprintf(stdout, "✓ Updated %s\n", id)
+	} else {
+		fmt.Fprintf(stdout, "✓ Updated %s; it is now %s\n", id, newID)
+	}
+	return nil
+}
+
+// fetchTextShort returns the text short id, with an error suited to the
+// commands that only work on text when it is missing or not text.
+func fetchTextShort(ctx context.Context, id string) (models.Short, error) {
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " Fetching item..."
+	s.Start()
+	resp, err := api.Do(ctx, "GET", "/shorts/"+id, nil)
+	s.Stop()
+	if errors.Is(err, api.ErrNotFound) {
+		return models.Short{}, fmt.Errorf("no text item found with ID %q. The item may have already expired or been deleted", id)
+	}
+	if err != nil {
+		return models.Short{}, err
+	}
+	var short models.Short
+	if err := resp.Unmarshal(&short); err != nil {
+		return models.Short{}, err
+	}
+	if short.Type != "" && short.Type != "text" {
+		return models.Short{}, fmt.Errorf("%s is a %s; this only works on text items", id, short.Type)
+	}
+	if short.Key() == "" {
+		short.ShortID = id
+	}
+	return short, nil
+}
+
+// replaceText stores content as the new content of the text short, first
+// encrypting it with pass when that is set, and returns the item's ID. When
+// the server doesn't update content in place, a new item is created with the
+// same remaining lifetime, aliases are moved to it, and the old one deleted.
+func replaceText(ctx context.Context, short models.Short, content, pass string) (string, error) {
+	if len(content) > maxTextSizeBytes {
+		return "", fmt.Errorf("content exceeds maximum size of %dKB (current: %.2fKB)",
+			maxTextSizeBytes/1024, float64(len(content))/1024)
+	}
+	if pass != "" {
+		encrypted, err := crypto.EncryptText(pass, content)
+		if err != nil {
+			return "", err
+		}
+		content = encrypted
+	}
+	id := short.Key()
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " Saving..."
+	s.Start()
+	defer s.Stop()
+
+	// A server without content updates may accept the PATCH and ignore the
+	// content, so read the item back to tell.
+	_, err := api.Do(ctx, "PATCH", "/shorts/"+id, models.UpdateShortRequest{Content: content})
+	if err == nil {
+		if resp, err := api.Do(ctx, "GET", "/shorts/"+id, nil); err == nil {
+			var stored models.Short
+			if resp.Unmarshal(&stored) == nil && stored.Content == content {
+				return id, nil
+			}
+		}
+	} else if !errors.Is(err, api.ErrBadRequest) && !errors.Is(err, api.ErrNotFound) {
+		return "", err
+	}
+
+	ttl := 0
+	if short.ExpiresAt != 0 {
+		left := time.Until(time.Unix(short.ExpiresAt, 0))
+		if left <= 0 {
+			return "", fmt.Errorf("item %s has expired", id)
+		}
+		ttl = int(left.Seconds()) + 1
+	}
+	resp, err := api.Do(ctx, "POST", "/shorts", models.CreateShortRequest{Content: content, TTL: &ttl})
+	if err != nil {
+		return "", err
+	}
+	var created models.CreateShortResponse
+	if err := resp.Unmarshal(&created); err != nil {
+		return "", err
+	}
+	s.Stop()
+
+	if names, err := config.RetargetAliases(id, created.ShortID); err == nil && len(names) > 0 {
+		fmt.Fprintf(info, "Moved alias %s to %s\n", strings.Join(names, ", "), created.ShortID)
+	}
+	if result := tryDelete(ctx, id); !result.success && result.error != "not_found" {
+		fmt.Fprintf(stderr, "Warning: failed to delete the old item %s: %s\n", id, result.error)
+	}
+	return created.ShortID, nil
+}
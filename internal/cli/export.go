@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/sim4gh/nikte-cli/internal/upload"
+	"github.com/sim4gh/nikte-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// Flags of `nk export`.
+var (
+	exportTar  bool
+	exportType string
+)
+
+// exportManifestName is the manifest file at the root of an export.
+const exportManifestName = "manifest.json"
+
+// exportManifest describes an export: what was saved where.
+type exportManifest struct {
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exportedAt"`
+	CLIVersion string        `json:"cliVersion"`
+	Items      []exportEntry `json:"items"`
+	Failed     []exportEntry `json:"failed,omitempty"`
+}
+
+// exportEntry is one item of an export. Path is relative to the export
+// root; encrypted items are saved as stored, still encrypted.
+type exportEntry struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Path        string `json:"path,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"contentType,omitempty"`
+	Description string `json:"description,omitempty"`
+	CreatedAt   string `json:"createdAt,omitempty"`
+	ExpiresAt   int64  `json:"expiresAt,omitempty"`
+	Encrypted   bool   `json:"encrypted,omitempty"`
+	SHA256      string `json:"sha256,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+func addExportCommand() {
+	exportCmd := &cobra.Command{
+		Use:   "export <dir>",
+		Short: "Download every item into a folder with a manifest",
+		Long: `Download every item into a folder: text as .txt files, files and
+screenshots under their names, and a manifest.json of their metadata (IDs,
+types, expiry, checksums). Encrypted items are saved still encrypted.
+
+Examples:
+  nk export backup                       Export everything to ./backup
+    ├ --tar                               Write backup.tar.gz instead
+    └ --type text                         Only text items
+  nk import backup                       Upload an export again`,
+		Args: cobra.ExactArgs(1),
+		RunE: runExport,
+	}
+
+	exportCmd.Flags().BoolVar(&exportTar, "tar", false, "Write <dir>.tar.gz instead of a folder")
+	exportCmd.Flags().StringVar(&exportType, "type", "", "Only export items of this type (text, file, screenshot, pro)")
+
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	dir := filepath.Clean(args[0])
+	archive := dir + ".tar.gz"
+	if exportTar {
+		if _, err := os.Stat(archive); err == nil {
+			return fmt.Errorf("%s already exists", archive)
+		}
+	}
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%s is not empty; export into a new folder", dir)
+	}
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " Fetching items..."
+	s.Start()
+	fetched := fetchAllItems(ctx)
+	s.Stop()
+	if fetched.err != nil {
+		return fmt.Errorf("cannot export while offline: %w", fetched.err)
+	}
+	items := fetched.items
+	if exportType != "" {
+		items = filterByType(items, exportType)
+	}
+	items = sortItems(items, "date")
+	if len(items) == 0 {
+		return fmt.Errorf("no items to export")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	manifest := exportManifest{Version: 1, ExportedAt: time.Now().UTC(), CLIVersion: Version}
+	var bytes int64
+	for i, item := range items {
+		s.Suffix = fmt.Sprintf(" Exporting %d/%d: %s...", i+1, len(items), item.ID)
+		s.Start()
+		entry, err := exportItem(ctx, dir, item)
+		s.Stop()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			entry.Error = err.Error()
+			manifest.Failed = append(manifest.Failed, entry)
+			fmt.Fprintf(info, "✗ %s: %v\n", item.ID, err)
+			continue
+		}
+		bytes += entry.Size
+		manifest.Items = append(manifest.Items, entry)
+		fmt.Fprintf(info, "✓ %s  %s\n", item.ID, entry.Path)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, exportManifestName), data, 0o600); err != nil {
+		return err
+	}
+
+	out := dir
+	if exportTar {
+		if err := writeTarGz(archive, dir); err != nil {
+			os.Remove(archive)
+			return fmt.Errorf("failed to write %s: %w", archive, err)
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		out = archive
+	}
+
+	fmt.Fprintf(info, "\nExported %d items (%s) to %s\n", len(manifest.Items), util.FormatBytes(bytes), out)
+	if len(manifest.Failed) > 0 {
+		return fmt.Errorf("%d of %d items failed to export (listed under \"failed\" in the manifest)", len(manifest.Failed), len(items))
+	}
+	return nil
+}
+
+// exportItem saves item under dir and returns its manifest entry.
+func exportItem(ctx context.Context, dir string, item Item) (exportEntry, error) {
+	entry := exportEntry{ID: item.ID, Type: item.Type, Filename: item.Filename, Size: item.Size,
+		CreatedAt: item.CreatedAt, ExpiresAt: item.ExpiresAt}
+	d, err := fetchItemDetail(ctx, item)
+	if err != nil {
+		return entry, err
+	}
+	entry.ContentType = d.ContentType
+	entry.Description = d.Description
+	entry.Encrypted = d.Encrypted
+	if d.CreatedAt != "" {
+		entry.CreatedAt = d.CreatedAt
+	}
+
+	var rel string
+	switch {
+	case d.DownloadURL == "":
+		rel = filepath.Join("text", item.ID+".txt")
+	case item.Type == "screenshot":
+		rel = filepath.Join("screenshots", item.ID+"-"+safeFilename(item.Filename, item.ID))
+		if filepath.Ext(rel) == "" {
+			rel += ".png"
+		}
+	default:
+		name := d.Filename
+		if name == "" {
+			name = item.Filename
+		}
+		rel = filepath.Join("files", item.ID, safeFilename(name, item.ID))
+	}
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return entry, err
+	}
+
+	if d.DownloadURL == "" {
+		err = os.WriteFile(path, []byte(d.Content), 0o600)
+	} else {
+		err = upload.DownloadFile(ctx, d.DownloadURL, path)
+	}
+	if err != nil {
+		return entry, err
+	}
+
+	sum, size, err := hashFile(path)
+	if err != nil {
+		return entry, err
+	}
+	entry.Path = filepath.ToSlash(rel)
+	entry.SHA256 = sum
+	entry.Size = size
+	return entry, nil
+}
+
+// safeFilename returns the last element of name, or fallback when that
+// isn't usable as a file name.
+func safeFilename(name, fallback string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == ".." || name == "" {
+		return fallback
+	}
+	return name
+}
+
+// hashFile returns the hex SHA-256 and size of the file at path.
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// writeTarGz archives the contents of dir into a gzipped tarball at path,
+// with entries relative to dir's parent so they unpack into a folder.
+func writeTarGz(path, dir string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	base := filepath.Dir(dir)
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(base, p)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if d.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		src, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
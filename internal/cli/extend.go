@@ -1,11 +1,14 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/briandowns/spinner"
 	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/models"
 	"github.com/sim4gh/nikte-cli/internal/util"
 	"github.com/spf13/cobra"
 )
@@ -37,7 +40,7 @@ Examples:
 }
 
 func runExtend(cmd *cobra.Command, args []string) error {
-	id := args[0]
+	id := config.ResolveAlias(args[0])
 
 	// Validate options
 	if extendTTL == "" && !extendPermanent {
@@ -58,47 +61,33 @@ Examples:
 	s.Start()
 
 	// Build request body
-	var body map[string]interface{}
+	body := models.ExtendRequest{TTL: extendTTL}
 	if extendPermanent {
-		body = map[string]interface{}{"permanent": true}
-	} else {
-		body = map[string]interface{}{"ttl": extendTTL}
-	}
-
-	resp, err := api.Patch("/shorts/"+id, body)
-	if err != nil {
-		s.Stop()
-		return err
+		body = models.ExtendRequest{Permanent: true}
 	}
 
+	resp, err := api.Do(cmd.Context(), "PATCH", "/shorts/"+id, body)
 	s.Stop()
-
-	// Handle success
-	if resp.StatusCode == 200 {
-		var result struct {
-			ExpiresAt int64 `json:"expiresAt"`
-		}
-		resp.Unmarshal(&result)
-
-		if extendPermanent {
-			fmt.Println("Item is now permanent")
-			fmt.Printf("\nItem %q will no longer expire.\n", id)
-		} else {
-			fmt.Println("TTL extended successfully")
-			fmt.Printf("\nItem %q now expires %s\n", id, util.FormatExpiryTime(result.ExpiresAt))
-		}
-		return nil
-	}
-
-	// Handle errors
-	switch resp.StatusCode {
-	case 404:
+	switch {
+	case errors.Is(err, api.ErrNotFound):
 		return fmt.Errorf("no item found with ID %q. The item may have already expired or been deleted", id)
-	case 403:
+	case errors.Is(err, api.ErrForbidden):
 		return fmt.Errorf("extending files requires a Pro subscription")
-	case 400:
-		return fmt.Errorf("invalid TTL format: %s", resp.GetString("message"))
-	default:
-		return fmt.Errorf("failed to extend TTL: %s", resp.GetString("message"))
+	case errors.Is(err, api.ErrBadRequest):
+		return fmt.Errorf("invalid TTL format: %w", err)
+	case err != nil:
+		return fmt.Errorf("failed to extend TTL: %w", err)
+	}
+
+	var result models.ExtendResponse
+	resp.Unmarshal(&result)
+
+	if extendPermanent {
+		fmt.Fprintln(stdout, "Item is now permanent")
+		fmt.Fprintf(stdout, "\nItem %q will no longer expire.\n", id)
+	} else {
+		fmt.Fprintln(stdout, "TTL extended successfully")
+		fmt.Fprintf(stdout, "\nItem %q now expires %s\n", id, util.FormatExpiryTime(result.ExpiresAt))
 	}
+	return nil
 }
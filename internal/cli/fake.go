@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/fakebackend"
+)
+
+// rootFakeBackend is the global --fake-backend flag.
+var rootFakeBackend bool
+
+// fakeBackend is the in-process API started by --fake-backend, if any.
+var fakeBackend *fakebackend.Backend
+
+// startFakeBackend points nk at an in-process fake API instead of the real
+// one, with the items earlier --fake-backend commands left in the config
+// directory.
+func startFakeBackend() error {
+	path, err := config.FakeBackendPath()
+	if err != nil {
+		return err
+	}
+	b := fakebackend.New()
+	if err := b.Load(path); err != nil {
+		return err
+	}
+	api.SetBaseURL(b.Start())
+	// The fake accepts any token; this one keeps the real session out of it.
+	os.Setenv("NIKTE_TOKEN", "fake")
+	fakeBackend = b
+	return nil
+}
+
+// stopFakeBackend saves the fake API's items for the next --fake-backend
+// command and shuts it down.
+func stopFakeBackend() {
+	if fakeBackend == nil {
+		return
+	}
+	path, err := config.FakeBackendPath()
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0o700)
+	}
+	if err == nil {
+		err = fakeBackend.Save(path)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: could not save the fake backend's items: %v\n", err)
+	}
+	fakeBackend.Close()
+	fakeBackend = nil
+}
@@ -0,0 +1,312 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/models"
+	"github.com/sim4gh/nikte-cli/internal/upload"
+	"github.com/sim4gh/nikte-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// Flags of `nk files`.
+var (
+	filesDescription string
+	filesTTL         string
+	filesJSON        bool
+	filesForce       bool
+)
+
+// errProRequired is returned by the /files endpoints to accounts without Pro.
+var errProRequired = errors.New("Pro files require a Pro subscription")
+
+func addFilesCommand() {
+	filesCmd := &cobra.Command{
+		Use:   "files",
+		Short: "Pro files: permanent storage with descriptions",
+		Long: `Manage Pro files. Unlike files added with nk add, Pro files are kept until
+deleted (or until --ttl), carry a description, and can be shared with nk sh.
+
+Examples:
+  nk files add report.pdf -d "Q3 board report"   Upload, kept until deleted
+  nk files add *.png --ttl 30d                   Several files, for 30 days
+  nk files ls                                    Your Pro files with descriptions
+  nk files get <id> -o ~/Downloads               Download one
+  nk files rm <id>                               Delete one`,
+	}
+
+	addCmd := &cobra.Command{
+		Use:   "add <path>...",
+		Short: "Upload files as Pro files",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runFilesAdd,
+	}
+	addCmd.Flags().StringVarP(&filesDescription, "description", "d", "", "Description shown in listings and on share pages")
+	addCmd.Flags().StringVar(&filesTTL, "ttl", "", "Delete after this long (e.g. 30d); default is permanent")
+
+	lsCmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List Pro files",
+		Args:  cobra.NoArgs,
+		RunE:  runFilesList,
+	}
+	lsCmd.Flags().BoolVar(&filesJSON, "json", false, "Output as JSON")
+
+	getCmd := &cobra.Command{
+		Use:   "get <id>",
+		Short: "Download a Pro file",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runFilesGet,
+	}
+	getCmd.Flags().StringVarP(&getOutput, "output", "o", "", "Save to specific directory")
+	getCmd.Flags().BoolVar(&getURL, "url", false, "Get URL only (do not download)")
+	getCmd.Flags().BoolVarP(&getCopy, "copy", "c", false, "Copy download URL to clipboard (do not download)")
+
+	rmCmd := &cobra.Command{
+		Use:     "rm <id>",
+		Aliases: []string{"delete"},
+		Short:   "Delete a Pro file",
+		Args:    cobra.ExactArgs(1),
+		RunE:    runFilesRm,
+	}
+	rmCmd.Flags().BoolVarP(&filesForce, "force", "f", false, "Skip confirmation")
+
+	filesCmd.AddCommand(addCmd, lsCmd, getCmd, rmCmd)
+	rootCmd.AddCommand(filesCmd)
+}
+
+func runFilesAdd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ttl := "permanent"
+	if filesTTL != "" {
+		seconds, err := util.ParseTTL(filesTTL)
+		if err != nil {
+			return fmt.Errorf("--ttl: %w", err)
+		}
+		ttl = fmt.Sprintf("%ds", seconds)
+	}
+
+	var results []itemResult
+	for _, path := range args {
+		r := &spinnerReporter{s: spinner.New(spinner.CharSets[14], 100*time.Millisecond)}
+		res, err := uploadProFile(ctx, path, ttl, r)
+		r.Stop()
+		if err != nil {
+			if len(args) > 1 && !errors.Is(err, errProRequired) && ctx.Err() == nil {
+				fmt.Fprintf(stderr, "✗ %s: %v\n", path, err)
+				continue
+			}
+			return err
+		}
+		noteHistory(*res)
+		results = append(results, *res)
+		expires := "kept until deleted"
+		if res.ExpiresAt > 0 {
+			expires = "expires " + util.FormatExpiryTime(res.ExpiresAt)
+		}
+		fmt.Fprintf(info, "✓ %s → %s (%s, %s)\n", path, res.ID, util.FormatBytes(res.Size), expires)
+	}
+	if len(results) < len(args) {
+		return fmt.Errorf("%d of %d files not uploaded", len(args)-len(results), len(args))
+	}
+	if len(results) == 1 {
+		copyToClipboard(results[0].ID, "ID", autoCopyID)
+		return emitResult(results[0])
+	}
+	return emitResult(results)
+}
+
+// uploadProFile uploads the file at path as a Pro file kept for ttl (a
+// duration such as "3600s", or "permanent").
+func uploadProFile(ctx context.Context, path, ttl string, r uploadReporter) (*itemResult, error) {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fileInfo.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+	if fileInfo.Size() == 0 {
+		return nil, fmt.Errorf("cannot upload empty file")
+	}
+	if fileInfo.Size() > maxFileSizeBytes {
+		return nil, fmt.Errorf("file too large. Maximum size is 10GB, file is %s", util.FormatBytes(fileInfo.Size()))
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	body := models.InitFileUploadRequest{
+		Filename:    filepath.Base(path),
+		ContentType: upload.GetMimeType(path),
+		FileSize:    fileInfo.Size(),
+		PartSize:    config.GetTuning().UploadPartSize,
+		TTL:         ttl,
+		Description: filesDescription,
+	}
+	if body.PartSize == 0 {
+		body.PartSize = upload.PartSizeFor(body.FileSize)
+	}
+
+	r.Status(fmt.Sprintf("Initializing upload of %s...", body.Filename))
+	resp, err := api.Do(ctx, "POST", "/files/init", body)
+	if errors.Is(err, api.ErrForbidden) {
+		return nil, errProRequired
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize upload: %w", err)
+	}
+	var init models.InitFileUploadResponse
+	if err := resp.Unmarshal(&init); err != nil {
+		return nil, err
+	}
+
+	r.Status(fmt.Sprintf("Uploading 0/%d parts...", len(init.PresignedURLs)))
+	parts, err := upload.UploadParts(ctx, init.PresignedURLs, file, body.FileSize, init.PartSize,
+		upload.Options{OnProgress: r.Progress})
+	if err == nil {
+		r.Status("Finalizing upload...")
+		_, err = api.Do(ctx, "POST", "/files/complete", models.CompleteFileUploadRequest{FileID: init.FileID, Parts: parts})
+		if err != nil {
+			err = fmt.Errorf("failed to complete upload: %w", err)
+		}
+	}
+	if err != nil {
+		abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), abortTimeout)
+		defer cancel()
+		if _, aerr := api.Do(abortCtx, "POST", "/files/abort", models.CompleteFileUploadRequest{FileID: init.FileID}); aerr != nil {
+			r.Warnf("Could not discard the unfinished upload %s (%v). Remove it with: nk files rm %s", init.FileID, aerr, init.FileID)
+		}
+		return nil, err
+	}
+
+	return &itemResult{
+		ID:          init.FileID,
+		Type:        "profile",
+		Filename:    body.Filename,
+		Size:        body.FileSize,
+		ContentType: body.ContentType,
+		ExpiresAt:   init.ExpiresAt,
+		Path:        path,
+	}, nil
+}
+
+func runFilesList(cmd *cobra.Command, args []string) error {
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " Fetching files..."
+	s.Start()
+	var files []models.FileItem
+	for resp, err := range api.Paginate(cmd.Context(), "/files", nil) {
+		if errors.Is(err, api.ErrForbidden) {
+			s.Stop()
+			return errProRequired
+		}
+		if err != nil {
+			s.Stop()
+			return err
+		}
+		var page models.FileList
+		if err := resp.Unmarshal(&page); err != nil {
+			s.Stop()
+			return err
+		}
+		files = append(files, page.Files...)
+	}
+	s.Stop()
+
+	if filesJSON && !machineOutput() {
+		outputMode = outputJSON
+	}
+	if machineOutput() {
+		items := []itemResult{}
+		for _, f := range files {
+			items = append(items, itemResult{ID: f.Key(), Type: "profile", Filename: f.Filename, Size: f.Size,
+				ContentType: f.ContentType, CreatedAt: f.CreatedAt, ExpiresAt: f.ExpiresAt})
+		}
+		return emitResult(items)
+	}
+	if len(files) == 0 {
+		fmt.Fprintln(stdout, "No Pro files. Upload one with: nk files add <path>")
+		return nil
+	}
+	for _, f := range files {
+		expires := "permanent"
+		if f.ExpiresAt > 0 {
+			expires = util.FormatExpiryTime(f.ExpiresAt)
+		}
+		fmt.Fprintf(stdout, "%-12s  %9s  %-20s  %s\n", f.Key(), util.FormatBytes(f.Size), expires, f.Filename)
+		if f.Description != "" {
+			fmt.Fprintf(stdout, "%-12s  %s\n", "", util.Truncate(util.ReplaceNewlines(f.Description), 70))
+		}
+	}
+	fmt.Fprintf(stdout, "\n%d Pro files\n", len(files))
+	return nil
+}
+
+func runFilesGet(cmd *cobra.Command, args []string) error {
+	id := config.ResolveAlias(args[0])
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " Fetching file..."
+	s.Start()
+	found, err := getAsFile(cmd.Context(), id, s)
+	s.Stop()
+	if errors.Is(err, api.ErrForbidden) {
+		return errProRequired
+	}
+	if err != nil && !errors.Is(err, api.ErrNotFound) {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("no Pro file found with ID %q. For other items use: nk get %s", id, args[0])
+	}
+	return nil
+}
+
+func runFilesRm(cmd *cobra.Command, args []string) error {
+	id := config.ResolveAlias(args[0])
+	if !filesForce {
+		fmt.Fprintf(stdout, "Are you sure you want to delete Pro file %q? [y/N]: ", id)
+		response, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return err
+		}
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			fmt.Fprintln(stdout, "Deletion cancelled")
+			return nil
+		}
+	}
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " Deleting file..."
+	s.Start()
+	_, err := api.Do(cmd.Context(), "DELETE", "/files/"+id, nil)
+	s.Stop()
+	switch {
+	case errors.Is(err, api.ErrForbidden):
+		return errProRequired
+	case errors.Is(err, api.ErrNotFound):
+		return fmt.Errorf("no Pro file found with ID %q. The file may have already expired or been deleted", id)
+	case err != nil:
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	noteHistory(itemResult{ID: id, Type: "profile"})
+	fmt.Fprintf(stdout, "✓ Deleted Pro file %s\n", id)
+	if names, err := config.RemoveAliasesFor(id); err == nil && len(names) > 0 {
+		fmt.Fprintf(stdout, "Removed alias %s\n", strings.Join(names, ", "))
+	}
+	return nil
+}
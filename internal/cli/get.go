@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,10 +11,14 @@ import (
 	"strings"
 	"time"
 
-	"github.com/atotto/clipboard"
 	"github.com/briandowns/spinner"
 	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/config"
 	"github.com/sim4gh/nikte-cli/internal/crypto"
+	"github.com/sim4gh/nikte-cli/internal/models"
+	"github.com/sim4gh/nikte-cli/internal/platform"
+	"github.com/sim4gh/nikte-cli/internal/transport"
+	"github.com/sim4gh/nikte-cli/internal/upload"
 	"github.com/sim4gh/nikte-cli/internal/util"
 	"github.com/spf13/cobra"
 )
@@ -49,25 +55,26 @@ Examples:
 }
 
 func runGet(cmd *cobra.Command, args []string) error {
-	id := args[0]
+	ctx := cmd.Context()
+	id := config.ResolveAlias(args[0])
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
 	s.Suffix = " Fetching item..."
 	s.Start()
 
 	// Try as short first (most common)
-	if found, err := getAsShort(id, s); found || err != nil {
+	if found, err := getAsShort(ctx, id, s); found || err != nil {
 		return err
 	}
 
 	// Try as screenshot
 	s.Suffix = " Trying as screenshot..."
-	if found, err := getAsScreenshot(id, s); found || err != nil {
+	if found, err := getAsScreenshot(ctx, id, s); found || err != nil {
 		return err
 	}
 
 	// Try as file (Pro)
 	s.Suffix = " Trying as file..."
-	if found, err := getAsFile(id, s); found || err != nil {
+	if found, err := getAsFile(ctx, id, s); found || err != nil {
 		return err
 	}
 
@@ -76,62 +83,68 @@ func runGet(cmd *cobra.Command, args []string) error {
 	return fmt.Errorf("no item found with ID %q. The item may have expired or never existed", id)
 }
 
-func getAsShort(id string, s *spinner.Spinner) (bool, error) {
-	resp, err := api.Get("/shorts/" + id)
+func getAsShort(ctx context.Context, id string, s *spinner.Spinner) (bool, error) {
+	// Text shorts can be large, so decode straight from the response stream
+	// rather than buffering the body first.
+	resp, err := api.GetStream(ctx, "/shorts/"+id)
 	if err != nil {
 		s.Stop()
 		return false, err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
 		return false, nil
 	}
 
 	s.Stop()
-	fmt.Println("Item fetched successfully")
-
-	var result struct {
-		Type        string `json:"type"`
-		Content     string `json:"content"`
-		CreatedAt   string `json:"createdAt"`
-		ExpiresAt   int64  `json:"expiresAt"`
-		Filename    string `json:"filename"`
-		FileSize    int64  `json:"fileSize"`
-		ContentType string `json:"contentType"`
-		DownloadURL string `json:"downloadUrl"`
+	fmt.Fprintln(info, "Item fetched successfully")
+	if resp.Stale {
+		noteStale()
 	}
-	if err := resp.Unmarshal(&result); err != nil {
+
+	var result models.Short
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return true, err
 	}
 
-	fmt.Println()
-	fmt.Println(strings.Repeat("=", 60))
-	fmt.Printf("ID: %s\n", id)
-	fmt.Printf("Type: %s\n", capitalize(result.Type))
+	fmt.Fprintln(info)
+	fmt.Fprintln(info, strings.Repeat("=", 60))
+	fmt.Fprintf(info, "ID: %s\n", id)
+	fmt.Fprintf(info, "Type: %s\n", capitalize(result.Type))
 	if result.CreatedAt != "" {
-		fmt.Printf("Created: %s\n", result.CreatedAt)
+		fmt.Fprintf(info, "Created: %s\n", result.CreatedAt)
 	}
 	if result.ExpiresAt > 0 {
-		fmt.Printf("Expires: %s\n", util.FormatExpiryTime(result.ExpiresAt))
-		fmt.Printf("Expires At: %s\n", time.Unix(result.ExpiresAt, 0).Format(time.RFC3339))
+		fmt.Fprintf(info, "Expires: %s\n", util.FormatExpiryTime(result.ExpiresAt))
+		fmt.Fprintf(info, "Expires At: %s\n", time.Unix(result.ExpiresAt, 0).Format(time.RFC3339))
 	}
-	fmt.Println(strings.Repeat("=", 60))
+	fmt.Fprintln(info, strings.Repeat("=", 60))
 
 	// Handle file type
 	if result.Type == "file" {
-		fmt.Println()
-		fmt.Printf("Filename: %s\n", result.Filename)
-		fmt.Printf("Size: %s\n", util.FormatBytes(result.FileSize))
-		fmt.Printf("Content-Type: %s\n", result.ContentType)
-		fmt.Println()
-
-		return true, handleFileDownload(result.DownloadURL, result.Filename)
+		fmt.Fprintln(info)
+		fmt.Fprintf(info, "Filename: %s\n", result.Filename)
+		fmt.Fprintf(info, "Size: %s\n", util.FormatBytes(result.FileSize))
+		fmt.Fprintf(info, "Content-Type: %s\n", result.ContentType)
+		fmt.Fprintln(info)
+
+		return true, handleFileDownload(ctx, &itemResult{
+			ID:          id,
+			Type:        result.Type,
+			Filename:    result.Filename,
+			Size:        result.FileSize,
+			ContentType: result.ContentType,
+			CreatedAt:   result.CreatedAt,
+			ExpiresAt:   result.ExpiresAt,
+			URL:         result.DownloadURL,
+		})
 	}
 
 	// Handle text type — transparently decrypt client-side encrypted shorts.
 	content := result.Content
 	if crypto.IsEncryptedText(content) {
-		fmt.Println("\nThis item is encrypted.")
+		fmt.Fprintln(info, "\nThis item is encrypted.")
 		pass, err := resolvePassphrase(getEncPass, false)
 		if err != nil {
 			return true, err
@@ -143,20 +156,30 @@ func getAsShort(id string, s *spinner.Spinner) (bool, error) {
 		content = decrypted
 	}
 
-	fmt.Println()
-	fmt.Println(content)
-	fmt.Println()
+	fmt.Fprintln(info)
+	fmt.Fprintln(info, content)
+	fmt.Fprintln(info)
 
 	// Copy content to clipboard
-	if err := clipboard.WriteAll(content); err == nil {
-		fmt.Println("(Content copied to clipboard)")
+	if autoCopyEnabled(autoCopyContent) {
+		if err := platform.CopyText(content); err == nil {
+			fmt.Fprintln(info, "(Content copied to clipboard)")
+		}
 	}
 
-	return true, nil
+	res := itemResult{
+		ID:        id,
+		Type:      result.Type,
+		Content:   content,
+		CreatedAt: result.CreatedAt,
+		ExpiresAt: result.ExpiresAt,
+	}
+	noteHistory(itemResult{ID: id, Type: result.Type, Size: int64(len(content))})
+	return true, emitResult(res)
 }
 
-func getAsScreenshot(id string, s *spinner.Spinner) (bool, error) {
-	resp, err := api.Get("/screenshots/" + id)
+func getAsScreenshot(ctx context.Context, id string, s *spinner.Spinner) (bool, error) {
+	resp, err := api.GetContext(ctx, "/screenshots/"+id)
 	if err != nil {
 		s.Stop()
 		return false, err
@@ -167,26 +190,25 @@ func getAsScreenshot(id string, s *spinner.Spinner) (bool, error) {
 	}
 
 	s.Stop()
-	fmt.Println("Screenshot fetched successfully")
-
-	var result struct {
-		DownloadURL string `json:"downloadUrl"`
-		ExpiresAt   int64  `json:"expiresAt"`
-		ContentType string `json:"contentType"`
+	fmt.Fprintln(info, "Screenshot fetched successfully")
+	if resp.Stale {
+		noteStale()
 	}
+
+	var result models.Screenshot
 	if err := resp.Unmarshal(&result); err != nil {
 		return true, err
 	}
 
-	fmt.Println()
-	fmt.Println(strings.Repeat("=", 60))
-	fmt.Printf("ID: %s\n", id)
-	fmt.Println("Type: Screenshot")
+	fmt.Fprintln(info)
+	fmt.Fprintln(info, strings.Repeat("=", 60))
+	fmt.Fprintf(info, "ID: %s\n", id)
+	fmt.Fprintln(info, "Type: Screenshot")
 	if result.ExpiresAt > 0 {
-		fmt.Printf("Expires: %s\n", util.FormatExpiryTime(result.ExpiresAt))
+		fmt.Fprintf(info, "Expires: %s\n", util.FormatExpiryTime(result.ExpiresAt))
 	}
-	fmt.Println(strings.Repeat("=", 60))
-	fmt.Println()
+	fmt.Fprintln(info, strings.Repeat("=", 60))
+	fmt.Fprintln(info)
 
 	// Determine filename
 	ext := "png"
@@ -195,11 +217,18 @@ func getAsScreenshot(id string, s *spinner.Spinner) (bool, error) {
 	}
 	filename := fmt.Sprintf("screenshot-%s.%s", id, ext)
 
-	return true, handleFileDownload(result.DownloadURL, filename)
+	return true, handleFileDownload(ctx, &itemResult{
+		ID:          id,
+		Type:        "screenshot",
+		Filename:    filename,
+		ContentType: result.ContentType,
+		ExpiresAt:   result.ExpiresAt,
+		URL:         result.DownloadURL,
+	})
 }
 
-func getAsFile(id string, s *spinner.Spinner) (bool, error) {
-	resp, err := api.Get("/files/" + id)
+func getAsFile(ctx context.Context, id string, s *spinner.Spinner) (bool, error) {
+	resp, err := api.GetContext(ctx, "/files/"+id)
 	if err != nil {
 		s.Stop()
 		return false, err
@@ -210,61 +239,69 @@ func getAsFile(id string, s *spinner.Spinner) (bool, error) {
 	}
 
 	s.Stop()
-	fmt.Println("File fetched successfully")
-
-	var result struct {
-		Filename    string `json:"filename"`
-		Size        int64  `json:"size"`
-		ContentType string `json:"contentType"`
-		DownloadURL string `json:"downloadUrl"`
-		Description string `json:"description"`
-		ExpiresAt   int64  `json:"expiresAt"`
+	fmt.Fprintln(info, "File fetched successfully")
+	if resp.Stale {
+		noteStale()
 	}
+
+	var result models.FileItem
 	if err := resp.Unmarshal(&result); err != nil {
 		return true, err
 	}
 
-	fmt.Println()
-	fmt.Println(strings.Repeat("=", 60))
-	fmt.Printf("ID: %s\n", id)
-	fmt.Println("Type: File (Pro)")
-	fmt.Printf("Filename: %s\n", result.Filename)
-	fmt.Printf("Size: %s\n", util.FormatBytes(result.Size))
-	fmt.Printf("Content-Type: %s\n", result.ContentType)
+	fmt.Fprintln(info)
+	fmt.Fprintln(info, strings.Repeat("=", 60))
+	fmt.Fprintf(info, "ID: %s\n", id)
+	fmt.Fprintln(info, "Type: File (Pro)")
+	fmt.Fprintf(info, "Filename: %s\n", result.Filename)
+	fmt.Fprintf(info, "Size: %s\n", util.FormatBytes(result.Size))
+	fmt.Fprintf(info, "Content-Type: %s\n", result.ContentType)
 	if result.Description != "" {
-		fmt.Printf("Description: %s\n", result.Description)
+		fmt.Fprintf(info, "Description: %s\n", result.Description)
 	}
 	if result.ExpiresAt > 0 {
-		fmt.Printf("Expires: %s\n", util.FormatExpiryTime(result.ExpiresAt))
+		fmt.Fprintf(info, "Expires: %s\n", util.FormatExpiryTime(result.ExpiresAt))
 	} else {
-		fmt.Println("Expires: never (permanent)")
-	}
-	fmt.Println(strings.Repeat("=", 60))
-	fmt.Println()
-
-	return true, handleFileDownload(result.DownloadURL, result.Filename)
+		fmt.Fprintln(info, "Expires: never (permanent)")
+	}
+	fmt.Fprintln(info, strings.Repeat("=", 60))
+	fmt.Fprintln(info)
+
+	return true, handleFileDownload(ctx, &itemResult{
+		ID:          id,
+		Type:        "file",
+		Filename:    result.Filename,
+		Size:        result.Size,
+		ContentType: result.ContentType,
+		ExpiresAt:   result.ExpiresAt,
+		URL:         result.DownloadURL,
+	})
 }
 
-func handleFileDownload(downloadURL, filename string) error {
+// handleFileDownload downloads (or, with --url/--copy, only reports) the item
+// described by res and emits res as the command result.
+func handleFileDownload(ctx context.Context, res *itemResult) error {
+	downloadURL, filename := res.URL, res.Filename
+
 	// If --copy flag, copy URL to clipboard and return
 	if getCopy {
-		if err := clipboard.WriteAll(downloadURL); err != nil {
-			fmt.Println("Failed to copy URL to clipboard")
-			fmt.Println("Download URL:", downloadURL)
+		if err := platform.CopyText(downloadURL); err != nil {
+			fmt.Fprintln(stderr, "Failed to copy URL to clipboard")
+			fmt.Fprintln(info, "Download URL:", downloadURL)
 		} else {
-			fmt.Println("Download URL copied to clipboard")
+			fmt.Fprintln(info, "Download URL copied to clipboard")
 		}
-		return nil
+		noteHistory(*res)
+		return emitResult(res)
 	}
 
 	// If --url flag, just show URL
 	if getURL {
-		fmt.Println("Download URL (valid for 1 hour):")
-		fmt.Println(downloadURL)
-		if err := clipboard.WriteAll(downloadURL); err == nil {
-			fmt.Println("\n(URL copied to clipboard)")
-		}
-		return nil
+		fmt.Fprintln(info, "Download URL (valid for 1 hour):")
+		fmt.Fprintln(info, downloadURL)
+		copyToClipboard(downloadURL, "URL", autoCopyURL)
+		noteHistory(*res)
+		return emitResult(res)
 	}
 
 	// Download the file
@@ -277,99 +314,94 @@ func handleFileDownload(downloadURL, filename string) error {
 	s.Suffix = fmt.Sprintf(" Downloading %s...", filename)
 	s.Start()
 
-	if err := downloadFile(downloadURL, outputPath); err != nil {
+	if err := downloadFile(ctx, downloadURL, outputPath); err != nil {
 		s.Stop()
-		fmt.Println()
-		fmt.Println("Download URL (valid for 1 hour):")
-		fmt.Println(downloadURL)
-		if err := clipboard.WriteAll(downloadURL); err == nil {
-			fmt.Println("(Download URL copied to clipboard)")
-		}
+		fmt.Fprintln(info)
+		fmt.Fprintln(info, "Download URL (valid for 1 hour):")
+		fmt.Fprintln(info, downloadURL)
+		copyToClipboard(downloadURL, "Download URL", autoCopyURL)
 		return fmt.Errorf("download failed: %w", err)
 	}
 
 	s.Stop()
-	fmt.Printf("Downloaded: %s\n", outputPath)
+	fmt.Fprintf(info, "Downloaded: %s\n", outputPath)
 
 	// Transparently decrypt client-side encrypted files (marked by the .nkenc
 	// suffix and a magic header).
 	if strings.HasSuffix(outputPath, crypto.FileSuffix) {
-		if err := decryptDownloadedFile(outputPath); err != nil {
+		decrypted, err := decryptDownloadedFile(outputPath)
+		if err != nil {
 			return err
 		}
+		outputPath = decrypted
 	}
 
-	return nil
+	res.Path = outputPath
+	noteHistory(*res)
+	return emitResult(res)
 }
 
 // decryptDownloadedFile decrypts an encrypted file in place: it reads the
 // downloaded ciphertext, prompts for the passphrase, writes the plaintext to the
-// path with the .nkenc suffix stripped, and removes the ciphertext file.
-func decryptDownloadedFile(encPath string) error {
+// path with the .nkenc suffix stripped, and removes the ciphertext file. It
+// returns the path of the resulting file.
+func decryptDownloadedFile(encPath string) (string, error) {
 	data, err := os.ReadFile(encPath)
 	if err != nil {
-		return err
+		return "", err
 	}
 	if !crypto.IsEncryptedBytes(data) {
 		// Suffix present but not actually our format — leave as-is.
-		return nil
+		return encPath, nil
 	}
 
-	fmt.Println("This file is encrypted.")
+	fmt.Fprintln(info, "This file is encrypted.")
 	pass, err := resolvePassphrase(getEncPass, false)
 	if err != nil {
-		return err
+		return "", err
 	}
 	plaintext, err := crypto.DecryptBytes(pass, data)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	outPath := strings.TrimSuffix(encPath, crypto.FileSuffix)
 	if err := os.WriteFile(outPath, plaintext, 0o600); err != nil {
-		return err
+		return "", err
 	}
 	_ = os.Remove(encPath)
-	fmt.Printf("Decrypted: %s\n", outPath)
-	return nil
+	fmt.Fprintf(info, "Decrypted: %s\n", outPath)
+	return outPath, nil
+}
+
+// noteStale warns that an item was read from the cache because the API could
+// not be reached.
+func noteStale() {
+	fmt.Fprintln(stderr, "Offline: showing the cached copy, which may be out of date.")
 }
 
 // downloadBytes fetches a URL into memory. Used when bytes are needed in-process
 // (e.g. forwarding a nikte item over WhatsApp) rather than written to disk.
-func downloadBytes(url string) ([]byte, error) {
-	resp, err := http.Get(url)
+func downloadBytes(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("download failed with status %d", resp.StatusCode)
-	}
-
-	return io.ReadAll(resp.Body)
-}
-
-func downloadFile(url, outputPath string) error {
-	resp, err := http.Get(url)
+	resp, err := upload.DownloadClient().Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("download failed with status %d", resp.StatusCode)
+		return nil, fmt.Errorf("download failed with status %d [request id %s]", resp.StatusCode, transport.RequestID(resp))
 	}
 
-	// Create output file
-	out, err := os.Create(outputPath)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
+	return io.ReadAll(resp.Body)
+}
 
-	_, err = io.Copy(out, resp.Body)
-	return err
+func downloadFile(ctx context.Context, url, outputPath string) error {
+	return upload.DownloadFile(ctx, url, outputPath)
 }
 
 func capitalize(s string) string {
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/ with the current output")
+
+// session runs each command line in turn and returns a transcript of them
+// and their output, with what went to stderr and failures' exit codes
+// marked as such.
+func (n *testNK) session(lines ...[]string) string {
+	n.t.Helper()
+	var b strings.Builder
+	for i, args := range lines {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("$ nk")
+		for _, arg := range args {
+			if strings.ContainsAny(arg, " \n\"'") {
+				arg = strconv.Quote(arg)
+			}
+			b.WriteString(" " + arg)
+		}
+		b.WriteString("\n")
+		out, errOut, code := n.run(append([]string{"--no-clipboard"}, args...)...)
+		b.WriteString(out)
+		if errOut != "" {
+			b.WriteString("[stderr]\n" + errOut)
+		}
+		if code != 0 {
+			fmt.Fprintf(&b, "[exit %d]\n", code)
+		}
+	}
+	return b.String()
+}
+
+// checkGolden compares got with testdata/<name>.golden, or rewrites the file
+// when the tests run with -update.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s (run go test -update if the change is intended)\n--- got:\n%s\n--- want:\n%s", path, got, want)
+	}
+}
+
+func TestListGolden(t *testing.T) {
+	n := newTestNK(t)
+	file := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(file, bytes.Repeat([]byte("abc\n"), 100), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, input := range []string{"hello world", "a first line\nand a second line that is long enough to be cut off", file} {
+		n.run("a", input, "--permanent")
+		n.now = n.now.Add(time.Minute)
+	}
+	checkGolden(t, "ls", n.session(
+		[]string{"ls"},
+		[]string{"ls", "-t", "text", "--sort", "size"},
+		[]string{"ls", "-s", "nothing-matches"},
+	))
+}
+
+func TestGetGolden(t *testing.T) {
+	n := newTestNK(t)
+	checkGolden(t, "get", n.session(
+		[]string{"a", "hello world", "--permanent"},
+		[]string{"g", "t1"},
+		[]string{"g", "t1", "--output-format", "json"},
+	))
+}
+
+func TestErrorsGolden(t *testing.T) {
+	n := newTestNK(t)
+	checkGolden(t, "errors", n.session(
+		[]string{"g", "missing"},
+		[]string{"d", "missing", "-f"},
+		[]string{"ls", "--bogus"},
+		[]string{"frobnicate"},
+		[]string{"a", "some text", "--gzip"},
+		[]string{"g"},
+	))
+}
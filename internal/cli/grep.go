@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/crypto"
+	"github.com/sim4gh/nikte-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// Flags of `nk grep`.
+var (
+	grepIgnoreCase bool
+	grepFixed      bool
+	grepFilesOnly  bool
+	grepCount      bool
+)
+
+// grepWorkers is how many text items `nk grep` fetches at once.
+const grepWorkers = 4
+
+// grepMatch is a matching line, the machine-readable result of `nk grep`.
+type grepMatch struct {
+	ID   string `json:"id"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+func addGrepCommand() {
+	grepCmd := &cobra.Command{
+		Use:   "grep <pattern>",
+		Short: "Search the full content of all text items",
+		Long: `Search the full content of every text item and print the matching lines
+with the item's ID and line number. nk ls --search only sees previews and
+filenames. The pattern is a regular expression (RE2 syntax) unless -F is
+given. Encrypted items are skipped.
+
+Items are revalidated against the local cache, so unchanged ones aren't
+downloaded again.
+
+Examples:
+  nk grep 'api[_-]key'                   Lines mentioning an API key
+  nk grep -i -l todo                     IDs of items with a TODO in any case
+  nk grep -F 'a.b(c)' -c                 Literal text; counts per item`,
+		Args: cobra.ExactArgs(1),
+		RunE: runGrep,
+	}
+	grepCmd.Flags().BoolVarP(&grepIgnoreCase, "ignore-case", "i", false, "Match regardless of case")
+	grepCmd.Flags().BoolVarP(&grepFixed, "fixed-strings", "F", false, "Treat the pattern as literal text")
+	grepCmd.Flags().BoolVarP(&grepFilesOnly, "files-with-matches", "l", false, "Print only the IDs of matching items")
+	grepCmd.Flags().BoolVarP(&grepCount, "count", "c", false, "Print the number of matching lines per item")
+
+	rootCmd.AddCommand(grepCmd)
+}
+
+func runGrep(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	expr := args[0]
+	if grepFixed {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if grepIgnoreCase {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " Fetching items..."
+	s.Start()
+	fetched := fetchShorts(ctx)
+	if fetched.err != nil && len(fetched.items) == 0 {
+		s.Stop()
+		return fetched.err
+	}
+	items := sortItems(filterByType(fetched.items, "text"), "date")
+
+	contents := make([]string, len(items))
+	errs := make([]error, len(items))
+	var (
+		mu   sync.Mutex
+		done int
+		wg   sync.WaitGroup
+	)
+	jobs := make(chan int)
+	for w := 0; w < grepWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				contents[i], errs[i] = fetchTextContent(ctx, items[i].ID)
+				mu.Lock()
+				done++
+				s.Suffix = fmt.Sprintf(" Searching %d/%d items...", done, len(items))
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range items {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	s.Stop()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	color := colorOutput()
+	var matches []grepMatch
+	encrypted, failed, matched := 0, 0, 0
+	for i, item := range items {
+		switch {
+		case errors.Is(errs[i], api.ErrNotFound):
+			continue // expired since the listing
+		case errs[i] != nil:
+			failed++
+			continue
+		case crypto.IsEncryptedText(contents[i]):
+			encrypted++
+			continue
+		}
+		n := 0
+		for j, line := range strings.Split(contents[i], "\n") {
+			line = strings.TrimSuffix(line, "\r")
+			if !re.MatchString(line) {
+				continue
+			}
+			n++
+			matches = append(matches, grepMatch{ID: item.ID, Line: j + 1, Text: line})
+			if grepFilesOnly || grepCount || machineOutput() {
+				continue
+			}
+			if color {
+				line = re.ReplaceAllStringFunc(line, func(m string) string { return "\x1b[1;31m" + m + "\x1b[0m" })
+				fmt.Fprintf(stdout, "\x1b[35m%s\x1b[0m:\x1b[32m%d\x1b[0m:%s\n", item.ID, j+1, line)
+			} else {
+				fmt.Fprintf(stdout, "%s:%d:%s\n", item.ID, j+1, line)
+			}
+		}
+		if n > 0 {
+			matched++
+		}
+		switch {
+		case machineOutput():
+		case grepFilesOnly && n > 0:
+			fmt.Fprintln(stdout, item.ID)
+		case grepCount && n > 0:
+			fmt.Fprintf(stdout, "%s:%d\n", item.ID, n)
+		}
+	}
+
+	if machineOutput() {
+		if matches == nil {
+			matches = []grepMatch{}
+		}
+		if err := emitResult(matches); err != nil {
+			return err
+		}
+	}
+	if encrypted > 0 {
+		fmt.Fprintf(info, "(%d encrypted items not searched)\n", encrypted)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d text items could not be fetched", failed, len(items))
+	}
+	if matched == 0 {
+		return fmt.Errorf("no matches in %d text items", len(items))
+	}
+	return nil
+}
+
+// fetchTextContent returns the full content of the text short id.
+func fetchTextContent(ctx context.Context, id string) (string, error) {
+	resp, err := api.Do(ctx, "GET", "/shorts/"+id, nil)
+	if err != nil {
+		return "", err
+	}
+	var short models.Short
+	if err := resp.Unmarshal(&short); err != nil {
+		return "", err
+	}
+	return short.Content, nil
+}
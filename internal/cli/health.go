@@ -1,45 +1,94 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/models"
+	"github.com/sim4gh/nikte-cli/internal/platform"
 	"github.com/spf13/cobra"
 )
 
 func addHealthCommand() {
-	rootCmd.AddCommand(healthCmd)
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "health",
+		Short: "Check system health status",
+		RunE:  runHealth,
+	})
 }
 
-var healthCmd = &cobra.Command{
-	Use:   "health",
-	Short: "Check system health status",
-	RunE:  runHealth,
-}
+// healthTimeout caps `nk health`, which should answer quickly or not at all,
+// below the general http_timeout.
+const healthTimeout = 10 * time.Second
 
 func runHealth(cmd *cobra.Command, args []string) error {
-	resp, err := api.GetNoAuth("/health")
+	timeout := min(healthTimeout, config.GetTuning().HTTPTimeout)
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+
+	resp, err := api.GetNoAuthContext(ctx, "/health")
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("health check timed out after %s", timeout)
+	}
 	if err != nil {
 		return err
 	}
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("health check failed with status %d", resp.StatusCode)
+		return fmt.Errorf("health check failed with status %d [request id %s]", resp.StatusCode, resp.RequestID)
 	}
 
-	var health struct {
-		Status    string `json:"status"`
-		Message   string `json:"message"`
-		Timestamp string `json:"timestamp"`
-	}
+	var health models.Health
 
 	if err := resp.Unmarshal(&health); err != nil {
 		return fmt.Errorf("failed to parse health response: %w", err)
 	}
 
-	fmt.Printf("Status: %s\n", health.Status)
-	fmt.Printf("Message: %s\n", health.Message)
-	fmt.Printf("Timestamp: %s\n", health.Timestamp)
+	fmt.Fprintf(stdout, "Status: %s\n", health.Status)
+	fmt.Fprintf(stdout, "Message: %s\n", health.Message)
+	fmt.Fprintf(stdout, "Timestamp: %s\n", health.Timestamp)
 
+	printLocalCapabilities()
 	return nil
 }
+
+// printLocalCapabilities reports the screenshot and clipboard backends this
+// machine uses, and what they can do.
+func printLocalCapabilities() {
+	fmt.Fprintln(stdout)
+	if c, caps, ok := platform.ScreenshotCapabilities(); ok {
+		fmt.Fprintf(stdout, "Screenshots: %s (%s)\n", c.Name(), capabilityList(
+			capability{"region", true}, capability{"window", caps.Window},
+			capability{"fullscreen", caps.Fullscreen}, capability{"--display", caps.Displays},
+			capability{"--window=name", caps.Windows}, capability{"--region", caps.Regions}))
+	} else {
+		fmt.Fprintln(stdout, "Screenshots: unavailable")
+	}
+	c := platform.SelectedClipboard()
+	caps := c.Capabilities()
+	fmt.Fprintf(stdout, "Clipboard: %s (%s)\n", c.Name(), capabilityList(
+		capability{"text", true}, capability{"read images", caps.ReadImage},
+		capability{"write images", caps.WriteImage}))
+}
+
+// capability is a feature of a backend and whether it has it.
+type capability struct {
+	name string
+	ok   bool
+}
+
+// capabilityList joins the names of the capabilities a backend has.
+func capabilityList(caps ...capability) string {
+	var names []string
+	for _, c := range caps {
+		if c.ok {
+			names = append(names, c.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,306 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// Flags of `nk history`.
+var (
+	historyCmdFilter string
+	historySince     string
+	historyLimit     int
+	historyJSON      bool
+)
+
+// historyMaxBytes is the size at which the journal drops its older half.
+const historyMaxBytes = 2 << 20
+
+// historyCommands maps the commands recorded in the journal, including the
+// shortcuts, by their path below nk, to the name they are recorded under.
+var historyCommands = map[string]string{
+	"a":         "add",
+	"c":         "add",
+	"sc":        "add",
+	"note":      "add",
+	"watch":     "add",
+	"g":         "get",
+	"d":         "delete",
+	"sh":        "share",
+	"p":         "share",
+	"files add": "add",
+	"files get": "get",
+	"files rm":  "delete",
+}
+
+// historyItems are the items the running command acted on, for the journal.
+var historyItems []itemResult
+
+// historyEntry is one line of the journal: an item a command acted on, or
+// just the command when it failed before getting that far.
+type historyEntry struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	Profile string    `json:"profile,omitempty"`
+	ID      string    `json:"id,omitempty"`
+	Type    string    `json:"type,omitempty"`
+	Name    string    `json:"name,omitempty"`
+	Size    int64     `json:"size,omitempty"`
+	URL     string    `json:"url,omitempty"`
+	Result  string    `json:"result"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// noteHistory records that the running command acted on res.
+func noteHistory(res itemResult) {
+	historyItems = append(historyItems, res)
+}
+
+// recordHistory appends what cmd did to the journal, if it is one of
+// historyCommands. Failing to write the journal never fails the command;
+// NIKTE_NO_HISTORY=1 turns it off.
+func recordHistory(ctx context.Context, cmd *cobra.Command, cmdErr error) {
+	if cmd == nil || os.Getenv("NIKTE_NO_HISTORY") == "1" {
+		return
+	}
+	name, ok := historyCommands[strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" ")]
+	if !ok || (name == "add" && addWatch != "") {
+		return
+	}
+
+	base := historyEntry{Time: time.Now().UTC(), Command: name, Profile: config.ActiveProfile(), Result: "ok"}
+	switch {
+	case cmdErr != nil && ctx.Err() != nil:
+		base.Result = "cancelled"
+	case cmdErr != nil:
+		base.Result = "error"
+		base.Error = cmdErr.Error()
+	}
+
+	var entries []historyEntry
+	for _, res := range historyItems {
+		e := base
+		e.ID, e.Type, e.Size = res.ID, res.Type, res.Size
+		e.Name = res.Filename
+		if res.Path != "" {
+			e.Name = res.Path
+		}
+		e.URL = res.ShareURL
+		entries = append(entries, e)
+	}
+	if len(entries) == 0 {
+		if cmdErr == nil {
+			// Nothing was done, e.g. a cancelled confirmation.
+			return
+		}
+		if args := cmd.Flags().Args(); name != "add" && len(args) > 0 {
+			base.ID = config.ResolveAlias(args[0])
+		}
+		entries = append(entries, base)
+	}
+	appendHistory(entries)
+}
+
+func appendHistory(entries []historyEntry) {
+	path, err := config.HistoryPath()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		enc.Encode(e)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return
+	}
+	f.Write(buf.Bytes())
+	fi, err := f.Stat()
+	f.Close()
+	if err == nil && fi.Size() > historyMaxBytes {
+		trimHistory(path)
+	}
+}
+
+// trimHistory keeps the newer half of the journal.
+func trimHistory(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	data = data[len(data)/2:]
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return
+	}
+	os.Rename(tmp, path)
+}
+
+// readHistory returns the journal, oldest first. Lines that don't parse
+// are skipped.
+func readHistory() ([]historyEntry, error) {
+	path, err := config.HistoryPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []historyEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for sc.Scan() {
+		var e historyEntry
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, sc.Err()
+}
+
+func addHistoryCommand() {
+	historyCmd := &cobra.Command{
+		Use:   "history [search]",
+		Short: "Show what add, get, delete and share did on this machine",
+		Long: `Show the local journal of add, get, delete and share: when, which item,
+its size and name, and whether it worked. The journal is kept in the config
+directory and never leaves this machine; set NIKTE_NO_HISTORY=1 to stop
+recording.
+
+A search matches IDs, names, share URLs and errors.
+
+Examples:
+  nk history                             The last 50 entries
+  nk history report.pdf                  Entries mentioning report.pdf
+    ├ --cmd add                           Only uploads
+    ├ --since 1d                          The last day (or a date: 2026-10-15)
+    └ -n 200                              The last 200 entries
+  nk history clear                       Delete the journal`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runHistory,
+	}
+	historyCmd.Flags().StringVar(&historyCmdFilter, "cmd", "", "Only entries of this command (add, get, delete, share)")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "Only entries newer than this (e.g. 24h, 7d, or a date like 2026-10-15)")
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 50, "Show at most this many of the newest entries (0 for all)")
+	historyCmd.Flags().BoolVar(&historyJSON, "json", false, "Output as JSON")
+
+	clearCmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Delete the journal",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := config.HistoryPath()
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			fmt.Fprintln(stdout, "✓ History cleared")
+			return nil
+		},
+	}
+	historyCmd.AddCommand(clearCmd)
+
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	if historyJSON && !machineOutput() {
+		outputMode = outputJSON
+	}
+	var since time.Time
+	if historySince != "" {
+		if t, err := time.ParseInLocation("2006-01-02", historySince, time.Local); err == nil {
+			since = t
+		} else if secs, err := util.ParseTTL(historySince); err == nil {
+			since = time.Now().Add(-time.Duration(secs) * time.Second)
+		} else {
+			return fmt.Errorf("--since: use a duration like 24h or 7d, or a date like 2026-10-15")
+		}
+	}
+	if historyCmdFilter != "" {
+		valid := false
+		for _, name := range historyCommands {
+			valid = valid || name == historyCmdFilter
+		}
+		if !valid {
+			return fmt.Errorf("--cmd must be add, get, delete or share")
+		}
+	}
+	search := ""
+	if len(args) > 0 {
+		search = strings.ToLower(args[0])
+	}
+
+	all, err := readHistory()
+	if err != nil {
+		return err
+	}
+	entries := []historyEntry{}
+	for _, e := range all {
+		if historyCmdFilter != "" && e.Command != historyCmdFilter {
+			continue
+		}
+		if !since.IsZero() && e.Time.Before(since) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(strings.Join([]string{e.ID, e.Name, e.URL, e.Error}, "\x00")), search) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if historyLimit > 0 && len(entries) > historyLimit {
+		entries = entries[len(entries)-historyLimit:]
+	}
+
+	if machineOutput() {
+		return emitResult(entries)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(stdout, "No history")
+		return nil
+	}
+	for _, e := range entries {
+		id := e.ID
+		if id == "" {
+			id = "-"
+		}
+		size := ""
+		if e.Size > 0 {
+			size = util.FormatBytes(e.Size)
+		}
+		detail := strings.TrimSpace(e.Name + " " + e.URL)
+		if e.Error != "" {
+			detail = strings.TrimSpace(detail + " " + util.Truncate(util.ReplaceNewlines(e.Error), 60))
+		}
+		line := fmt.Sprintf("%s  %-6s  %-10s  %9s  %-9s  %s", e.Time.Local().Format("2006-01-02 15:04"), e.Command, id, size, e.Result, detail)
+		fmt.Fprintln(stdout, strings.TrimRight(line, " "))
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+)
+
+// hooksRun counts the historyItems whose hooks have already run.
+var hooksRun int
+
+// runHooks runs the hooks (see runItemHooks) for the items in historyItems
+// they haven't run for yet, warning on stderr.
+func runHooks(ctx context.Context, name string) {
+	items := historyItems[hooksRun:]
+	hooksRun = len(historyItems)
+	runItemHooks(ctx, name, items, func(format string, args ...any) {
+		fmt.Fprintf(stderr, "Warning: "+format+"\n", args...)
+	})
+}
+
+// runItemHooks runs the post_<name> config hook (post_add, post_share or
+// post_delete) once for each of items, and sends adds and deletes to the
+// webhook. Item fields are inserted shell-quoted and also passed as NK_ID,
+// NK_TYPE, ... environment variables. A failing hook or webhook is reported
+// through warn; NIKTE_NO_HOOKS=1 turns hooks off.
+func runItemHooks(ctx context.Context, name string, items []itemResult, warn func(format string, args ...any)) {
+	if len(items) == 0 || os.Getenv("NIKTE_NO_HOOKS") == "1" {
+		return
+	}
+	if name == "add" || name == "delete" {
+		for i := range items {
+			if err := sendWebhook(ctx, name, &items[i]); err != nil {
+				warn("%v", err)
+			}
+		}
+	}
+	key := "post_" + name
+	cfg := config.Get()
+	if cfg == nil {
+		return
+	}
+	command, _ := config.Lookup(cfg, key)
+	if command == "" {
+		return
+	}
+	tmpl, err := config.ParseHook(key, command)
+	if err != nil {
+		warn("%v", err)
+		return
+	}
+
+	for _, res := range items {
+		fields := hookFields(name, res)
+		quoted := make(map[string]string, len(fields))
+		env := os.Environ()
+		for k, v := range fields {
+			quoted[k] = shellQuote(v)
+			env = append(env, "NK_"+strings.ToUpper(k)+"="+v)
+		}
+		var line strings.Builder
+		if err := tmpl.Execute(&line, quoted); err != nil {
+			warn("%s hook: %v", key, err)
+			continue
+		}
+
+		var c *exec.Cmd
+		if runtime.GOOS == "windows" {
+			c = exec.CommandContext(ctx, "cmd", "/C", line.String())
+		} else {
+			c = exec.CommandContext(ctx, "sh", "-c", line.String())
+		}
+		// Hook output never mixes with results meant for a pipe.
+		c.Env, c.Stdout, c.Stderr = env, stderr, stderr
+		if err := c.Run(); err != nil {
+			warn("%s hook for %s failed: %v", key, res.ID, err)
+		}
+	}
+}
+
+// hookFields are the values of config.HookFields for res.
+func hookFields(name string, res itemResult) map[string]string {
+	url := res.ShareURL
+	if url == "" {
+		url = res.URL
+	}
+	f := map[string]string{
+		"ID":        res.ID,
+		"Type":      res.Type,
+		"Filename":  res.Filename,
+		"Path":      res.Path,
+		"URL":       url,
+		"Command":   name,
+		"Profile":   config.ActiveProfile(),
+		"Size":      "",
+		"ExpiresAt": "",
+	}
+	if res.Size > 0 {
+		f["Size"] = strconv.FormatInt(res.Size, 10)
+	}
+	if res.ExpiresAt > 0 {
+		f["ExpiresAt"] = strconv.FormatInt(res.ExpiresAt, 10)
+	}
+	return f
+}
+
+// shellQuote quotes s as one word for sh, or for cmd on Windows. Plain words
+// are left alone so hooks read naturally in logs.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:@%+=,") == "" {
+		return s
+	}
+	if runtime.GOOS == "windows" {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
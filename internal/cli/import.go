@@ -0,0 +1,385 @@
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/models"
+	"github.com/sim4gh/nikte-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// Flags of `nk import`.
+var (
+	importMap       string
+	importTTL       string
+	importPermanent bool
+	importDryRun    bool
+)
+
+// importResult maps an imported entry to the item created for it.
+type importResult struct {
+	OldID string `json:"oldId,omitempty"`
+	NewID string `json:"newId,omitempty"`
+	Type  string `json:"type"`
+	Path  string `json:"path"`
+	TTL   string `json:"ttl"`
+	Error string `json:"error,omitempty"`
+}
+
+func addImportCommand() {
+	importCmd := &cobra.Command{
+		Use:   "import <dir|export.tar.gz>",
+		Short: "Upload a folder or an nk export, mapping old IDs to new",
+		Long: `Upload every item of an export made with nk export (a folder or its
+.tar.gz), or every file in a plain folder, and print which new ID each old
+one became, as CSV (or JSON with --output-format json).
+
+Items from an export keep their remaining lifetime: permanent ones stay
+permanent, and ones that have since expired get the default 24h. --ttl or
+--permanent override that for everything.
+
+Examples:
+  nk import backup                      Re-upload an export
+    ├ --map ids.csv                      Write the old→new ID map to a file (.csv or .json)
+    ├ --ttl 7d                           Give everything 7 days
+    └ --dry-run                          Show what would be uploaded
+  nk import ~/notes                     Upload every file in a folder`,
+		Args: cobra.ExactArgs(1),
+		RunE: runImport,
+	}
+
+	importCmd.Flags().StringVar(&importMap, "map", "", "Write the old→new ID map to this file (.json for JSON, otherwise CSV)")
+	importCmd.Flags().StringVar(&importTTL, "ttl", "", "TTL for every item (e.g. 7d), instead of each one's remaining lifetime")
+	importCmd.Flags().BoolVar(&importPermanent, "permanent", false, "Make every item permanent")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "List what would be uploaded without uploading")
+
+	rootCmd.AddCommand(importCmd)
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if importTTL != "" && importPermanent {
+		return fmt.Errorf("cannot use both --ttl and --permanent together")
+	}
+	if importTTL != "" {
+		if _, err := util.ParseTTL(importTTL); err != nil {
+			return fmt.Errorf("--ttl: %w", err)
+		}
+	}
+
+	root := args[0]
+	if strings.HasSuffix(root, ".tar.gz") || strings.HasSuffix(root, ".tgz") {
+		tmp, err := os.MkdirTemp("", "nk-import-*")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		dir, err := extractTarGz(root, tmp)
+		if err != nil {
+			return fmt.Errorf("failed to unpack %s: %w", root, err)
+		}
+		root = dir
+	}
+
+	entries, err := importEntries(root)
+	if err != nil {
+		return err
+	}
+
+	var results []importResult
+	failed := 0
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	for i, e := range entries {
+		ttl, permanent := importLifetime(e)
+		res := importResult{OldID: e.ID, Type: e.Type, Path: e.Path, TTL: ttl}
+		if permanent {
+			res.TTL = "permanent"
+		}
+		if importDryRun {
+			fmt.Fprintf(info, "Would upload %s (%s, %s)\n", e.Path, e.Type, res.TTL)
+			results = append(results, res)
+			continue
+		}
+
+		s.Suffix = fmt.Sprintf(" Importing %d/%d: %s...", i+1, len(entries), e.Path)
+		s.Start()
+		newID, err := importEntry(ctx, root, e, ttl, permanent, s)
+		s.Stop()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			failed++
+			res.Error = err.Error()
+			fmt.Fprintf(info, "✗ %s: %v\n", e.Path, err)
+		} else {
+			res.NewID = newID
+			fmt.Fprintf(info, "✓ %s → %s\n", e.Path, newID)
+		}
+		results = append(results, res)
+	}
+
+	if err := writeImportMap(results); err != nil {
+		return err
+	}
+	if !importDryRun {
+		fmt.Fprintf(info, "\nImported %d of %d items\n", len(results)-failed, len(results))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d imports failed", failed, len(results))
+	}
+	return nil
+}
+
+// importEntries lists what to upload from root: the items of its manifest
+// when it is an export, otherwise every file in it as a file item.
+func importEntries(root string) ([]exportEntry, error) {
+	data, err := os.ReadFile(filepath.Join(root, exportManifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		files, err := collectUploadFiles([]string{root})
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]exportEntry, len(files))
+		for i, f := range files {
+			rel, _ := filepath.Rel(root, f)
+			entries[i] = exportEntry{Type: "file", Path: filepath.ToSlash(rel)}
+		}
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m exportManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("bad %s: %w", exportManifestName, err)
+	}
+	if m.Version != 1 {
+		return nil, fmt.Errorf("%s has version %d; this nk reads version 1", exportManifestName, m.Version)
+	}
+	if len(m.Items) == 0 {
+		return nil, fmt.Errorf("%s lists no items", exportManifestName)
+	}
+	for _, e := range m.Items {
+		if !filepath.IsLocal(filepath.FromSlash(e.Path)) {
+			return nil, fmt.Errorf("%s: item %s has a path outside the export: %s", exportManifestName, e.ID, e.Path)
+		}
+	}
+	return m.Items, nil
+}
+
+// importLifetime returns the TTL for e: the flags when given, otherwise
+// what was left of its lifetime at export.
+func importLifetime(e exportEntry) (ttl string, permanent bool) {
+	switch {
+	case importPermanent:
+		return "", true
+	case importTTL != "":
+		return importTTL, false
+	case e.ID == "":
+		return defaultTTL, false
+	}
+	return remainingLifetime(e.ExpiresAt)
+}
+
+// remainingLifetime returns the TTL that gives a new item what was left of
+// an old one's lifetime (rounded up to a minute): permanent for a permanent
+// one, and the default for one that has since expired.
+func remainingLifetime(expiresAt int64) (ttl string, permanent bool) {
+	if expiresAt == 0 {
+		return "", true
+	}
+	left := time.Until(time.Unix(expiresAt, 0))
+	if left <= 0 {
+		return defaultTTL, false
+	}
+	return fmt.Sprintf("%dm", int(left.Minutes())+1), false
+}
+
+// importEntry uploads e from the export at root and returns the new ID. File
+// uploads stop s to show their warnings.
+func importEntry(ctx context.Context, root string, e exportEntry, ttl string, permanent bool, s *spinner.Spinner) (string, error) {
+	path := filepath.Join(root, filepath.FromSlash(e.Path))
+	ttlSeconds := 0
+	if !permanent {
+		n, err := util.ParseTTL(ttl)
+		if err != nil {
+			return "", err
+		}
+		ttlSeconds = n
+	}
+
+	switch e.Type {
+	case "text":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		resp, err := api.Do(ctx, "POST", "/shorts", models.CreateShortRequest{Content: string(data), TTL: &ttlSeconds})
+		if err != nil {
+			return "", err
+		}
+		var created models.CreateShortResponse
+		if err := resp.Unmarshal(&created); err != nil {
+			return "", err
+		}
+		return created.ShortID, nil
+
+	case "screenshot":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		contentType := e.ContentType
+		if !strings.HasPrefix(contentType, "image/") {
+			contentType = http.DetectContentType(data)
+		}
+		body := models.CreateScreenshotRequest{ContentType: contentType, Data: base64.StdEncoding.EncodeToString(data), TTL: "permanent"}
+		if !permanent {
+			body.TTL = fmt.Sprintf("%ds", ttlSeconds)
+		}
+		resp, err := api.Do(ctx, "POST", "/screenshots", body)
+		if err != nil {
+			return "", err
+		}
+		var created models.CreateScreenshotResponse
+		if err := resp.Unmarshal(&created); err != nil {
+			return "", err
+		}
+		return created.ScreenshotID, nil
+
+	default:
+		// Files go through the add upload path, which takes its TTL from
+		// the add flags.
+		savedTTL, savedPermanent := addTTL, addPermanent
+		defer func() { addTTL, addPermanent = savedTTL, savedPermanent }()
+		addTTL, addPermanent = ttl, permanent
+		res, err := uploadFile(ctx, path, &batchReporter{s: s, name: e.Path})
+		if err != nil {
+			return "", err
+		}
+		if res.mismatch {
+			return "", fmt.Errorf("stored file %s failed verification", res.ID)
+		}
+		return res.ID, nil
+	}
+}
+
+// writeImportMap writes the old→new IDs to --map, or else prints them: as
+// JSON in the machine output modes and as CSV otherwise.
+func writeImportMap(results []importResult) error {
+	if importMap != "" {
+		f, err := os.Create(importMap)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if strings.HasSuffix(importMap, ".json") {
+			enc := json.NewEncoder(f)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(results)
+		} else {
+			err = writeImportCSV(f, results)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(info, "ID map written to %s\n", importMap)
+		return f.Close()
+	}
+	if machineOutput() {
+		return emitResult(results)
+	}
+	if importDryRun {
+		return nil
+	}
+	fmt.Fprintln(stdout)
+	return writeImportCSV(stdout, results)
+}
+
+func writeImportCSV(w io.Writer, results []importResult) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"old_id", "new_id", "type", "path", "ttl", "error"})
+	for _, r := range results {
+		cw.Write([]string{r.OldID, r.NewID, r.Type, r.Path, r.TTL, r.Error})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// extractTarGz unpacks the archive at path into dir and returns the folder
+// it holds (an export archive has one top-level folder), or dir itself.
+func extractTarGz(path, dir string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return "", err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		name := filepath.FromSlash(hdr.Name)
+		if !filepath.IsLocal(name) {
+			return "", fmt.Errorf("archive entry %q is outside the archive", hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o700); err != nil {
+				return "", err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+				return "", err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+			if err != nil {
+				return "", err
+			}
+			_, err = io.Copy(out, tr)
+			if cerr := out.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		return filepath.Join(dir, entries[0].Name()), nil
+	}
+	return dir, nil
+}
@@ -2,7 +2,9 @@ package cli
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	neturl "net/url"
 	"os"
 	"strings"
 	"time"
@@ -10,6 +12,8 @@ import (
 	"github.com/briandowns/spinner"
 	"github.com/olekukonko/tablewriter"
 	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/models"
+	"github.com/sim4gh/nikte-cli/internal/platform"
 	"github.com/sim4gh/nikte-cli/internal/util"
 	"github.com/spf13/cobra"
 )
@@ -23,22 +27,25 @@ var (
 
 func addLinkCommands() {
 	linkCmd := &cobra.Command{
-		Use:   "link <url>",
+		Use:   "link [url]",
 		Short: "Shorten a URL",
-		Long: `Shorten a long URL into a share.nikte.co short link.
+		Long: `Shorten a long URL into a share.nikte.co short link. With no URL, the one
+on the clipboard is shortened.
 
 The short URL is printed and copied to your clipboard. Links expire after
 48 hours by default; use --ttl to change it or --permanent to keep it forever.
 
 Examples:
   nk link https://example.com/very/long/path   Shorten a URL
+  nk shorten                                    Shorten the URL on the clipboard
   nk link example.com/foo                       https:// is added if missing
   nk link https://example.com --ttl 7d          Custom expiration
   nk link https://example.com --permanent       Never expire
   nk link ls                                    List your short links
   nk link d a3                                  Delete a short link`,
-		Args: cobra.MaximumNArgs(1),
-		RunE: runLinkCreate,
+		Aliases: []string{"shorten"},
+		Args:    cobra.MaximumNArgs(1),
+		RunE:    runLinkCreate,
 	}
 	linkCmd.Flags().StringVar(&linkTTL, "ttl", "", "Expiration: 30s, 60m, 24h, 7d (default 48h)")
 	linkCmd.Flags().BoolVarP(&linkPermanent, "permanent", "p", false, "Never expire")
@@ -74,41 +81,61 @@ func normalizeURL(raw string) string {
 	return s
 }
 
+// looksLikeURL reports whether s is a single http(s) URL with a host, as
+// opposed to other text that happens to be on the clipboard.
+func looksLikeURL(s string) bool {
+	if strings.ContainsAny(s, " \t\n") {
+		return false
+	}
+	u, err := neturl.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && strings.Contains(u.Host, ".")
+}
+
 func runLinkCreate(cmd *cobra.Command, args []string) error {
-	if len(args) == 0 {
-		return cmd.Help()
+	var raw string
+	if len(args) > 0 {
+		raw = args[0]
+	} else {
+		raw, _ = platform.PasteText()
+		raw = strings.TrimSpace(raw)
+		if !looksLikeURL(raw) {
+			return cmd.Help()
+		}
 	}
 
-	url := normalizeURL(args[0])
+	url := normalizeURL(raw)
+	if !looksLikeURL(url) {
+		return fmt.Errorf("%q is not a URL", raw)
+	}
 
-	body := map[string]interface{}{"url": url}
+	body := models.CreateLinkRequest{URL: url, TTL: linkTTL}
 	if linkPermanent {
-		body["ttl"] = "permanent"
-	} else if linkTTL != "" {
-		body["ttl"] = linkTTL
+		body.TTL = "permanent"
 	}
 
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
 	s.Suffix = " Shortening URL..."
 	s.Start()
 
-	resp, err := api.Post("/links", body)
+	resp, err := api.Do(cmd.Context(), "POST", "/links", body)
 	s.Stop()
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to shorten URL: %w", err)
 	}
-	if resp.StatusCode != 201 {
-		return fmt.Errorf("failed to shorten URL: %s", resp.GetString("message"))
+
+	var link models.Link
+	if err := resp.Unmarshal(&link); err != nil {
+		return err
 	}
 
-	shortURL := resp.GetString("shortUrl")
-	fmt.Printf("%s -> %s\n", url, shortURL)
-	if expiresAt := resp.GetInt("expiresAt"); expiresAt > 0 {
-		fmt.Printf("Expires: %s\n", util.FormatExpiry(int64(expiresAt)))
+	shortURL := link.ShortURL
+	fmt.Fprintf(stdout, "%s -> %s\n", url, shortURL)
+	if link.ExpiresAt != nil && *link.ExpiresAt > 0 {
+		fmt.Fprintf(stdout, "Expires: %s\n", util.FormatExpiry(*link.ExpiresAt))
 	} else {
-		fmt.Println("Expires: never (permanent)")
+		fmt.Fprintln(stdout, "Expires: never (permanent)")
 	}
-	copyToClipboard(shortURL, "Short URL")
+	copyToClipboard(shortURL, "Short URL", autoCopyURL)
 	if linkQR {
 		printQR(shortURL)
 	}
@@ -120,33 +147,23 @@ func runLinkList(cmd *cobra.Command, args []string) error {
 	s.Suffix = " Loading links..."
 	s.Start()
 
-	resp, err := api.Get("/links")
+	resp, err := api.Do(cmd.Context(), "GET", "/links", nil)
 	s.Stop()
 	if err != nil {
-		return err
-	}
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("failed to list links: %s", resp.GetString("message"))
+		return fmt.Errorf("failed to list links: %w", err)
 	}
 
-	var result struct {
-		Links []struct {
-			Code      string `json:"code"`
-			URL       string `json:"url"`
-			ShortURL  string `json:"shortUrl"`
-			ExpiresAt *int64 `json:"expiresAt"`
-		} `json:"links"`
-	}
+	var result models.LinkList
 	if err := resp.Unmarshal(&result); err != nil {
 		return err
 	}
 
 	if len(result.Links) == 0 {
-		fmt.Println("No links.")
+		fmt.Fprintln(stdout, "No links.")
 		return nil
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
+	table := tablewriter.NewWriter(stdout)
 	table.SetHeader([]string{"Code", "Short URL", "Destination", "Expires"})
 	table.SetBorder(true)
 	table.SetAutoWrapText(false)
@@ -167,7 +184,7 @@ func runLinkDelete(cmd *cobra.Command, args []string) error {
 	code := args[0]
 
 	if !linkDeleteForce {
-		fmt.Printf("Are you sure you want to delete link %q? [y/N]: ", code)
+		fmt.Fprintf(stdout, "Are you sure you want to delete link %q? [y/N]: ", code)
 		reader := bufio.NewReader(os.Stdin)
 		response, err := reader.ReadString('\n')
 		if err != nil {
@@ -175,7 +192,7 @@ func runLinkDelete(cmd *cobra.Command, args []string) error {
 		}
 		response = strings.TrimSpace(strings.ToLower(response))
 		if response != "y" && response != "yes" {
-			fmt.Println("Deletion cancelled")
+			fmt.Fprintln(stdout, "Deletion cancelled")
 			return nil
 		}
 	}
@@ -184,21 +201,16 @@ func runLinkDelete(cmd *cobra.Command, args []string) error {
 	s.Suffix = " Deleting link..."
 	s.Start()
 
-	resp, err := api.Delete("/links/" + code)
+	_, err := api.Do(cmd.Context(), "DELETE", "/links/"+code, nil)
 	s.Stop()
-	if err != nil {
-		return err
-	}
-
-	switch resp.StatusCode {
-	case 204, 200:
-		fmt.Printf("Link %q deleted.\n", code)
-		return nil
-	case 404:
+	switch {
+	case errors.Is(err, api.ErrNotFound):
 		return fmt.Errorf("no link found with code %q", code)
-	default:
-		return fmt.Errorf("failed to delete link: %s", resp.GetString("message"))
+	case err != nil:
+		return fmt.Errorf("failed to delete link: %w", err)
 	}
+	fmt.Fprintf(stdout, "Link %q deleted.\n", code)
+	return nil
 }
 
 // truncateURL shortens a URL for table display.
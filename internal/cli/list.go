@@ -1,9 +1,9 @@
 package cli
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -12,6 +12,7 @@ import (
 	"github.com/briandowns/spinner"
 	"github.com/olekukonko/tablewriter"
 	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/models"
 	"github.com/sim4gh/nikte-cli/internal/util"
 	"github.com/spf13/cobra"
 )
@@ -69,9 +70,16 @@ func runList(cmd *cobra.Command, args []string) error {
 	s.Suffix = " Fetching items..."
 	s.Start()
 
-	allItems := fetchAllItems()
+	fetched := fetchAllItems(cmd.Context())
 
 	s.Stop()
+	if fetched.err != nil && len(fetched.items) == 0 {
+		return fetched.err
+	}
+	if fetched.stale || fetched.err != nil {
+		fmt.Fprintln(stderr, "Offline: showing cached items, which may be out of date.")
+	}
+	allItems := fetched.items
 
 	// Apply filters
 	if listType != "" {
@@ -87,7 +95,7 @@ func runList(cmd *cobra.Command, args []string) error {
 
 	// Interactive mode: launch the navigable TUI with the filtered/sorted set.
 	if listInteractive {
-		return runListTUI(allItems)
+		return runListTUI(cmd.Context(), allItems)
 	}
 
 	// Apply limit
@@ -103,17 +111,18 @@ func runList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Output as JSON if --raw flag is set
-	if listRaw {
-		data, err := json.MarshalIndent(allItems, "", "  ")
-		if err != nil {
-			return err
+	if listRaw && !machineOutput() {
+		outputMode = outputJSON
+	}
+	if machineOutput() {
+		if allItems == nil {
+			allItems = []Item{}
 		}
-		fmt.Println(string(data))
-		return nil
+		return emitResult(allItems)
 	}
 
 	// Display the table
-	fmt.Println()
+	fmt.Fprintln(stdout)
 	displayItemsTable(allItems)
 
 	// Show summary
@@ -145,7 +154,7 @@ func runList(cmd *cobra.Command, args []string) error {
 	if len(parts) > 0 {
 		summary = strings.Join(parts, ", ")
 	}
-	fmt.Printf("\nTotal: %d items (%s)%s\n", len(allItems), summary, limitInfo)
+	fmt.Fprintf(stdout, "\nTotal: %d items (%s)%s\n", len(allItems), summary, limitInfo)
 
 	// Show active filters
 	var activeFilters []string
@@ -160,170 +169,158 @@ func runList(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(activeFilters) > 0 {
-		fmt.Printf("Filters: %s\n", strings.Join(activeFilters, ", "))
+		fmt.Fprintf(stdout, "Filters: %s\n", strings.Join(activeFilters, ", "))
 	}
 
-	fmt.Println("\nTypes: Text, File, Screenshot, Pro")
+	fmt.Fprintln(stdout, "\nTypes: Text, File, Screenshot, Pro")
 
 	return nil
 }
 
-// fetchAllItems fetches shorts, screenshots, and files concurrently and returns
-// them combined (unfiltered, unsorted).
-func fetchAllItems() []Item {
-	shortsChan := make(chan []Item)
-	screenshotsChan := make(chan []Item)
-	filesChan := make(chan []Item)
-
-	go func() { shortsChan <- fetchShorts() }()
-	go func() { screenshotsChan <- fetchScreenshots() }()
-	go func() { filesChan <- fetchFiles() }()
-
-	shorts := <-shortsChan
-	screenshots := <-screenshotsChan
-	files := <-filesChan
-
-	return append(append(shorts, screenshots...), files...)
+// fetchResult is what the item fetchers found. Other failures (e.g. no Pro
+// subscription for /files) just leave items out, but err is set when the API
+// could not be reached at all.
+type fetchResult struct {
+	items []Item
+	stale bool // some items came from the cache because the API was unreachable
+	err   error
 }
 
-func fetchShorts() []Item {
-	resp, err := api.Get("/shorts")
-	if err != nil || resp.StatusCode != 200 {
-		return nil
-	}
-
-	var result struct {
-		Shorts []struct {
-			ShortID        string `json:"shortId"`
-			ID             string `json:"id"`
-			Type           string `json:"type"`
-			ContentPreview string `json:"contentPreview"`
-			Content        string `json:"content"`
-			Filename       string `json:"filename"`
-			FileSize       int64  `json:"fileSize"`
-			ExpiresAt      int64  `json:"expiresAt"`
-			CreatedAt      string `json:"createdAt"`
-		} `json:"shorts"`
-	}
-	if err := resp.Unmarshal(&result); err != nil {
-		return nil
+// fetchAllItems fetches shorts, screenshots, and files concurrently and returns
+// them combined (unfiltered, unsorted).
+func fetchAllItems(ctx context.Context) fetchResult {
+	shortsChan := make(chan fetchResult)
+	screenshotsChan := make(chan fetchResult)
+	filesChan := make(chan fetchResult)
+
+	go func() { shortsChan <- fetchShorts(ctx) }()
+	go func() { screenshotsChan <- fetchScreenshots(ctx) }()
+	go func() { filesChan <- fetchFiles(ctx) }()
+
+	var all fetchResult
+	for _, ch := range []chan fetchResult{shortsChan, screenshotsChan, filesChan} {
+		r := <-ch
+		all.items = append(all.items, r.items...)
+		all.stale = all.stale || r.stale
+		if all.err == nil {
+			all.err = r.err
+		}
 	}
+	return all
+}
 
-	var items []Item
-	for _, s := range result.Shorts {
-		id := s.ShortID
-		if id == "" {
-			id = s.ID
-		}
-		itemType := "text"
-		if s.Type == "file" {
-			itemType = "file"
-		}
-		preview := s.ContentPreview
-		if preview == "" {
-			preview = s.Content
-		}
-		size := s.FileSize
-		if size == 0 && s.Content != "" {
-			size = int64(len(s.Content))
+func fetchShorts(ctx context.Context) fetchResult {
+	var r fetchResult
+	for resp, err := range api.Paginate(ctx, "/shorts", nil) {
+		if err != nil {
+			if errors.Is(err, api.ErrOffline) {
+				r.err = err
+			}
+			break
 		}
+		r.stale = r.stale || resp.Stale
 
-		items = append(items, Item{
-			ID:        id,
-			Type:      itemType,
-			Preview:   preview,
-			Filename:  s.Filename,
-			Size:      size,
-			ExpiresAt: s.ExpiresAt,
-			CreatedAt: s.CreatedAt,
-			Source:    "short",
-		})
-	}
-	return items
-}
+		var result models.ShortList
+		if err := resp.Unmarshal(&result); err != nil {
+			break
+		}
 
-func fetchScreenshots() []Item {
-	resp, err := api.Get("/screenshots")
-	if err != nil || resp.StatusCode != 200 {
-		return nil
-	}
+		for _, s := range result.Shorts {
+			id := s.Key()
+			itemType := "text"
+			if s.Type == "file" {
+				itemType = "file"
+			}
+			preview := s.ContentPreview
+			if preview == "" {
+				preview = s.Content
+			}
+			size := s.FileSize
+			if size == 0 && s.Content != "" {
+				size = int64(len(s.Content))
+			}
 
-	var result struct {
-		Screenshots []struct {
-			ScreenshotID string `json:"screenshotId"`
-			ID           string `json:"id"`
-			Filename     string `json:"filename"`
-			Size         int64  `json:"size"`
-			ExpiresAt    int64  `json:"expiresAt"`
-			CreatedAt    string `json:"createdAt"`
-		} `json:"screenshots"`
-	}
-	if err := resp.Unmarshal(&result); err != nil {
-		return nil
+			r.items = append(r.items, Item{
+				ID:        id,
+				Type:      itemType,
+				Preview:   preview,
+				Filename:  s.Filename,
+				Size:      size,
+				ExpiresAt: s.ExpiresAt,
+				CreatedAt: s.CreatedAt,
+				Source:    "short",
+			})
+		}
 	}
+	return r
+}
 
-	var items []Item
-	for _, sc := range result.Screenshots {
-		id := sc.ScreenshotID
-		if id == "" {
-			id = sc.ID
+func fetchScreenshots(ctx context.Context) fetchResult {
+	var r fetchResult
+	for resp, err := range api.Paginate(ctx, "/screenshots", nil) {
+		if err != nil {
+			if errors.Is(err, api.ErrOffline) {
+				r.err = err
+			}
+			break
 		}
-		filename := sc.Filename
-		if filename == "" {
-			filename = "screenshot-" + id
+		r.stale = r.stale || resp.Stale
+
+		var result models.ScreenshotList
+		if err := resp.Unmarshal(&result); err != nil {
+			break
 		}
 
-		items = append(items, Item{
-			ID:        id,
-			Type:      "screenshot",
-			Filename:  filename,
-			Size:      sc.Size,
-			ExpiresAt: sc.ExpiresAt,
-			CreatedAt: sc.CreatedAt,
-			Source:    "screenshot",
-		})
-	}
-	return items
-}
+		for _, sc := range result.Screenshots {
+			id := sc.Key()
+			filename := sc.Filename
+			if filename == "" {
+				filename = "screenshot-" + id
+			}
 
-func fetchFiles() []Item {
-	resp, err := api.Get("/files")
-	if err != nil || resp.StatusCode != 200 {
-		return nil
+			r.items = append(r.items, Item{
+				ID:        id,
+				Type:      "screenshot",
+				Filename:  filename,
+				Size:      sc.Size,
+				ExpiresAt: sc.ExpiresAt,
+				CreatedAt: sc.CreatedAt,
+				Source:    "screenshot",
+			})
+		}
 	}
+	return r
+}
 
-	var result struct {
-		Files []struct {
-			FileID    string `json:"fileId"`
-			ID        string `json:"id"`
-			Filename  string `json:"filename"`
-			Size      int64  `json:"size"`
-			ExpiresAt int64  `json:"expiresAt"`
-			CreatedAt string `json:"createdAt"`
-		} `json:"files"`
-	}
-	if err := resp.Unmarshal(&result); err != nil {
-		return nil
-	}
+func fetchFiles(ctx context.Context) fetchResult {
+	var r fetchResult
+	for resp, err := range api.Paginate(ctx, "/files", nil) {
+		if err != nil {
+			if errors.Is(err, api.ErrOffline) {
+				r.err = err
+			}
+			break
+		}
+		r.stale = r.stale || resp.Stale
 
-	var items []Item
-	for _, f := range result.Files {
-		id := f.FileID
-		if id == "" {
-			id = f.ID
+		var result models.FileList
+		if err := resp.Unmarshal(&result); err != nil {
+			break
 		}
 
-		items = append(items, Item{
-			ID:        id,
-			Type:      "profile",
-			Filename:  f.Filename,
-			Size:      f.Size,
-			ExpiresAt: f.ExpiresAt,
-			CreatedAt: f.CreatedAt,
-			Source:    "file",
-		})
+		for _, f := range result.Files {
+			r.items = append(r.items, Item{
+				ID:        f.Key(),
+				Type:      "profile",
+				Filename:  f.Filename,
+				Size:      f.Size,
+				ExpiresAt: f.ExpiresAt,
+				CreatedAt: f.CreatedAt,
+				Source:    "file",
+			})
+		}
 	}
-	return items
+	return r
 }
 
 func filterByType(items []Item, typeFilter string) []Item {
@@ -338,7 +335,7 @@ func filterByType(items []Item, typeFilter string) []Item {
 
 	mappedType, ok := typeMap[typeFilter]
 	if !ok {
-		fmt.Fprintf(os.Stderr, "Invalid type: %s. Valid types: text, file, screenshot, pro\n", typeFilter)
+		fmt.Fprintf(stderr, "Invalid type: %s. Valid types: text, file, screenshot, pro\n", typeFilter)
 		return items
 	}
 
@@ -404,11 +401,11 @@ func countByType(items []Item, itemType string) int {
 
 func displayItemsTable(items []Item) {
 	if len(items) == 0 {
-		fmt.Println("No items found.")
+		fmt.Fprintln(stdout, "No items found.")
 		return
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
+	table := tablewriter.NewWriter(stdout)
 	table.SetHeader([]string{"ID", "Type", "Content / Filename", "Size", "Date", "Expires"})
 	table.SetBorder(true)
 	table.SetAutoWrapText(false)
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/models"
+	"github.com/sim4gh/nikte-cli/internal/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Flags of `nk note`.
+var (
+	noteTitle     string
+	noteTTL       string
+	notePermanent bool
+	noteBlankLine bool
+)
+
+func addNoteCommand() {
+	noteCmd := &cobra.Command{
+		Use:   "note",
+		Short: "Type a multi-line note and save it as a text item",
+		Long: `Type a note line by line, without quoting it for the shell or opening an
+editor, and save it as a text item when you end it with Ctrl-D (Ctrl-Z then
+Enter on Windows), or with an empty line when --blank-line is given.
+Piped input is read the same way.
+
+Examples:
+  nk note -t "Standup"                   Type, then Ctrl-D
+  nk note -b --ttl 7d                    Finish with an empty line; keep 7 days`,
+		Args: cobra.NoArgs,
+		RunE: runNote,
+	}
+	noteCmd.Flags().StringVarP(&noteTitle, "title", "t", "", "Title shown on the item's share page")
+	noteCmd.Flags().StringVar(&noteTTL, "ttl", "", "Time to live (e.g. 30m, 24h, 7d)")
+	noteCmd.Flags().BoolVar(&notePermanent, "permanent", false, "Never expire")
+	noteCmd.Flags().BoolVarP(&noteBlankLine, "blank-line", "b", false, "End the note at the first empty line")
+
+	rootCmd.AddCommand(noteCmd)
+}
+
+func runNote(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if noteTTL != "" && notePermanent {
+		return fmt.Errorf("cannot use both --ttl and --permanent together")
+	}
+	ttlString := noteTTL
+	if ttlString == "" {
+		ttlString = defaultTTL
+	}
+	ttl := 0
+	if !notePermanent {
+		n, err := util.ParseTTL(ttlString)
+		if err != nil {
+			return fmt.Errorf("--ttl: %w", err)
+		}
+		ttl = n
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		end := "Ctrl-D"
+		if runtime.GOOS == "windows" {
+			end = "Ctrl-Z then Enter"
+		}
+		if noteBlankLine {
+			end = "an empty line"
+		}
+		fmt.Fprintf(stderr, "Type your note; end it with %s.\n", end)
+	}
+	content, err := readNote()
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(content) == "" {
+		return fmt.Errorf("the note is empty; nothing was saved")
+	}
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " Saving note..."
+	s.Start()
+	resp, err := api.Do(ctx, "POST", "/shorts", models.CreateShortRequest{Content: content, TTL: &ttl})
+	if err != nil {
+		s.Stop()
+		if errors.Is(err, api.ErrTooLarge) {
+			return fmt.Errorf("note too large: %w", err)
+		}
+		return fmt.Errorf("failed to save the note: %w", err)
+	}
+	var created models.CreateShortResponse
+	if err := resp.Unmarshal(&created); err != nil {
+		s.Stop()
+		return err
+	}
+	if noteTitle != "" {
+		if _, err := api.Do(ctx, "PATCH", "/shorts/"+created.ShortID, models.RenameRequest{Title: noteTitle}); err != nil {
+			fmt.Fprintf(stderr, "Warning: the note was saved, but not its title: %v\n", err)
+		}
+	}
+	s.Stop()
+
+	res := itemResult{ID: created.ShortID, Type: "text", Size: int64(len(content)), ExpiresAt: created.ExpiresAt}
+	noteHistory(res)
+	fmt.Fprintf(info, "✓ Saved note %s (%d lines, %s)\n", created.ShortID, strings.Count(content, "\n")+1, util.FormatBytes(res.Size))
+	if created.ExpiresAt > 0 {
+		fmt.Fprintf(info, "Expires: %s\n", util.FormatExpiryTime(created.ExpiresAt))
+	} else {
+		fmt.Fprintln(info, "Expires: never (permanent)")
+	}
+	copyToClipboard(created.ShortID, "ID", autoCopyID)
+	return emitResult(res)
+}
+
+// readNote reads the note from stdin up to EOF, or with --blank-line up to
+// the first empty line after some text, without the trailing newline.
+func readNote() (string, error) {
+	var b strings.Builder
+	sc := bufio.NewScanner(os.Stdin)
+	sc.Buffer(make([]byte, 64*1024), maxTextSizeBytes+1)
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if noteBlankLine && line == "" {
+			if b.Len() == 0 {
+				continue
+			}
+			break
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(line)
+		if b.Len() > maxTextSizeBytes {
+			return "", fmt.Errorf("note exceeds maximum size of %dKB", maxTextSizeBytes/1024)
+		}
+	}
+	if errors.Is(sc.Err(), bufio.ErrTooLong) {
+		return "", fmt.Errorf("note exceeds maximum size of %dKB", maxTextSizeBytes/1024)
+	}
+	return b.String(), sc.Err()
+}
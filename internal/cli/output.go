@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"golang.org/x/term"
+)
+
+// Render modes for command results (the `output` config key or the global
+// --output-format flag).
+const (
+	outputTable  = "table"
+	outputJSON   = "json"
+	outputNDJSON = "ndjson"
+)
+
+var (
+	// rootOutputFormat is the global --output-format flag
+	rootOutputFormat string
+
+	// outputMode is the resolved render mode for this invocation.
+	outputMode = outputTable
+
+	// stdout and stderr are where commands write. Tests swap them for
+	// buffers to check what a command prints.
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+
+	// info receives human-readable progress and results. It is stdout in
+	// table mode and discarded otherwise, so stdout stays machine-readable
+	// and stderr carries only warnings and errors.
+	info io.Writer = os.Stdout
+)
+
+// resolveOutput picks the render mode from --output-format, then the config,
+// then the table default.
+func resolveOutput() error {
+	mode := rootOutputFormat
+	if mode == "" {
+		if cfg := config.Get(); cfg != nil {
+			mode = cfg.Output
+		}
+	}
+	if mode == "" {
+		mode = outputTable
+	}
+	if err := config.ValidateValue("output", mode); err != nil {
+		return err
+	}
+
+	outputMode = mode
+	if machineOutput() {
+		info = io.Discard
+	} else {
+		info = stdout
+	}
+	return nil
+}
+
+// machineOutput reports whether results are rendered as JSON or NDJSON.
+func machineOutput() bool {
+	return outputMode == outputJSON || outputMode == outputNDJSON
+}
+
+// colorOutput reports whether stdout is a terminal that should get ANSI
+// colors: not redirected, not TERM=dumb, and NO_COLOR unset.
+func colorOutput() bool {
+	return !machineOutput() && isTerminal(stdout) &&
+		os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb"
+}
+
+// isTerminal reports whether w is a terminal, which a test's buffer never is.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// emitResult writes v to stdout in the active machine format. In ndjson mode
+// slices are written one element per line. It is a no-op in table mode, where
+// callers print their own human-readable output.
+func emitResult(v any) error {
+	switch outputMode {
+	case outputJSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, string(data))
+	case outputNDJSON:
+		enc := json.NewEncoder(stdout)
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+			for i := 0; i < rv.Len(); i++ {
+				if err := enc.Encode(rv.Index(i).Interface()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return enc.Encode(v)
+	}
+	return nil
+}
+
+// itemResult is the machine-readable result of `nk g` and `nk a`.
+type itemResult struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Content     string `json:"content,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	CreatedAt   string `json:"createdAt,omitempty"`
+	ExpiresAt   int64  `json:"expiresAt,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Path        string `json:"path,omitempty"`
+	ShareURL    string `json:"shareUrl,omitempty"`
+
+	// mismatch is set by uploadFile when the stored file failed verification.
+	mismatch bool
+}
@@ -10,27 +10,38 @@ import (
 // promptPassphrase reads a hidden passphrase from the terminal. When confirm is
 // true it asks a second time and verifies the two entries match.
 func promptPassphrase(confirm bool) (string, error) {
-	fmt.Fprint(os.Stderr, "Passphrase: ")
-	pass, err := term.ReadPassword(int(os.Stdin.Fd()))
-	fmt.Fprintln(os.Stderr)
+	return promptSecret("passphrase", confirm)
+}
+
+// promptSecret reads a secret from the terminal with echo disabled, so it never
+// lands in shell history or `ps` output. label is used lower-case in messages
+// ("passphrase", "password"). When confirm is true the secret is asked for twice.
+func promptSecret(label string, confirm bool) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("cannot prompt for %s: stdin is not a terminal", label)
+	}
+
+	fmt.Fprintf(stderr, "%s: ", capitalize(label))
+	secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(stderr)
 	if err != nil {
-		return "", fmt.Errorf("failed to read passphrase: %w", err)
+		return "", fmt.Errorf("failed to read %s: %w", label, err)
 	}
-	if len(pass) == 0 {
-		return "", fmt.Errorf("passphrase cannot be empty")
+	if len(secret) == 0 {
+		return "", fmt.Errorf("%s cannot be empty", label)
 	}
 	if confirm {
-		fmt.Fprint(os.Stderr, "Confirm passphrase: ")
+		fmt.Fprintf(stderr, "Confirm %s: ", label)
 		again, err := term.ReadPassword(int(os.Stdin.Fd()))
-		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(stderr)
 		if err != nil {
-			return "", fmt.Errorf("failed to read passphrase: %w", err)
+			return "", fmt.Errorf("failed to read %s: %w", label, err)
 		}
-		if string(pass) != string(again) {
-			return "", fmt.Errorf("passphrases do not match")
+		if string(secret) != string(again) {
+			return "", fmt.Errorf("%ss do not match", label)
 		}
 	}
-	return string(pass), nil
+	return string(secret), nil
 }
 
 // resolvePassphrase returns the encryption passphrase from, in order: the
@@ -1,19 +1,131 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/briandowns/spinner"
 	"github.com/mdp/qrterminal/v3"
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/spf13/cobra"
+	"rsc.io/qr"
 )
 
-// printQR renders a compact half-block QR code for the given URL to stdout,
-// preceded by a blank line. It is a no-op for an empty string so callers can
+// Flags of `nk qr`.
+var (
+	qrShare   bool
+	qrContent bool
+	qrPNG     string
+)
+
+// printQR renders a compact half-block QR code for the given URL to info
+// (stdout in table mode), preceded by a blank line. It is a no-op for an empty string so callers can
 // invoke it unconditionally after producing a share/short URL.
 func printQR(url string) {
 	if url == "" {
 		return
 	}
-	fmt.Println("\nScan to open:")
-	qrterminal.GenerateHalfBlock(url, qrterminal.L, os.Stdout)
+	fmt.Fprintln(info, "\nScan to open:")
+	qrterminal.GenerateHalfBlock(url, qrterminal.L, info)
+}
+
+func addQRCommand() {
+	qrCmd := &cobra.Command{
+		Use:   "qr <id|url>",
+		Short: "Print a QR code of an item's link",
+		Long: `Print a QR code to scan an item on a phone: the download URL of a file or
+screenshot (valid for 1 hour), a new public share link with --share, or a
+text item's content itself with --content. A URL argument is encoded as is.
+
+Examples:
+  nk qr abc1                             Download URL of a file
+  nk qr t1 --share                       Share a text item and show its link
+  nk qr wifi --content                   The text itself (e.g. a Wi-Fi password)
+  nk qr abc1 --png code.png              Also save the code as an image`,
+		Args: cobra.ExactArgs(1),
+		RunE: runQR,
+	}
+	qrCmd.Flags().BoolVar(&qrShare, "share", false, "Create a public share link and encode it")
+	qrCmd.Flags().BoolVar(&qrContent, "content", false, "Encode a text item's content rather than a link")
+	qrCmd.Flags().StringVar(&qrPNG, "png", "", "Also save the QR code as a PNG image to this path")
+
+	rootCmd.AddCommand(qrCmd)
+}
+
+func runQR(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if qrShare && qrContent {
+		return fmt.Errorf("cannot use both --share and --content together")
+	}
+
+	text := args[0]
+	if !looksLikeURL(text) {
+		id := config.ResolveAlias(args[0])
+		s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+		s.Suffix = " Fetching item..."
+		s.Start()
+		res, d, err := lookupItem(ctx, id)
+		s.Stop()
+		if errors.Is(err, api.ErrNotFound) {
+			return fmt.Errorf("no item found with ID %q. The item may have already expired or been deleted", id)
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case qrContent:
+			if res.Type != "text" {
+				return fmt.Errorf("--content is for text items; %s is a %s", id, res.Type)
+			}
+			if d.Encrypted {
+				return fmt.Errorf("%s is encrypted; a QR code of it would be unreadable. Decrypt it with nk get first", id)
+			}
+			text = res.Content
+		case qrShare:
+			s.Suffix = " Creating share..."
+			s.Start()
+			result := shareFile(ctx, id)
+			if !result.success && result.reason == "not_found" {
+				result = shareShort(ctx, id)
+			}
+			s.Stop()
+			switch {
+			case result.reason == "pro_required":
+				return fmt.Errorf("sharing requires a Pro subscription")
+			case !result.success && result.message != "":
+				return fmt.Errorf("failed to share %s: %s", id, result.message)
+			case !result.success:
+				return fmt.Errorf("%s can't be shared", id)
+			}
+			text = result.data.Link()
+		case res.URL != "":
+			text = res.URL
+			fmt.Fprintln(info, "Download URL (valid for 1 hour):")
+		default:
+			return fmt.Errorf("%s is a text item with no link; use --share for a share link or --content for the text", id)
+		}
+	}
+
+	code, err := qr.Encode(text, qr.L)
+	if err != nil {
+		return fmt.Errorf("cannot make a QR code: %w (at most about 2,900 bytes fit)", err)
+	}
+	if qrContent {
+		fmt.Fprintln(info, "Scan to read:")
+	} else {
+		fmt.Fprintln(info, text)
+	}
+	qrterminal.GenerateHalfBlock(text, qrterminal.L, info)
+
+	if qrPNG != "" {
+		if err := os.WriteFile(qrPNG, code.PNG(), 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintf(info, "Saved %s\n", qrPNG)
+	}
+	return emitResult(map[string]string{"text": text})
 }
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// rootWaitOnRateLimit is the global --wait-on-ratelimit flag
+var rootWaitOnRateLimit bool
+
+// waitOutRateLimit is installed as api.RateLimitWait for --wait-on-ratelimit.
+// It counts down on stderr (a single line when stderr isn't a terminal) and
+// returns early if ctx is cancelled.
+func waitOutRateLimit(ctx context.Context, d time.Duration) error {
+	if d < time.Second {
+		d = time.Second
+	}
+	deadline := time.Now().Add(d)
+
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		fmt.Fprintf(stderr, "Rate limited; retrying in %s\n", d.Round(time.Second))
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-t.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+	for {
+		left := time.Until(deadline).Round(time.Second)
+		if left <= 0 {
+			fmt.Fprint(stderr, "\r\033[K")
+			return nil
+		}
+		fmt.Fprintf(stderr, "\r\033[KRate limited; retrying in %s (Ctrl-C to cancel)", left)
+		select {
+		case <-tick.C:
+		case <-ctx.Done():
+			fmt.Fprintln(stderr)
+			return ctx.Err()
+		}
+	}
+}
@@ -11,15 +11,15 @@ import (
 )
 
 var (
-	recDuration     int
-	recSelect       bool
-	recFormat       string
-	recFPS          int
-	recWidth        int
-	recPermanent    bool
-	recTTL          string
-	recPublic       bool
-	recPassword     string
+	recDuration  int
+	recSelect    bool
+	recFormat    string
+	recFPS       int
+	recWidth     int
+	recPermanent bool
+	recTTL       string
+	recPublic    bool
+	recPassword  string
 )
 
 const (
@@ -78,7 +78,7 @@ func runRec(cmd *cobra.Command, args []string) error {
 
 	// Cap duration
 	if recDuration > maxRecDuration {
-		fmt.Printf("Note: duration capped at %d seconds\n", maxRecDuration)
+		fmt.Fprintf(info, "Note: duration capped at %d seconds\n", maxRecDuration)
 		recDuration = maxRecDuration
 	}
 	if recDuration <= 0 {
@@ -87,9 +87,9 @@ func runRec(cmd *cobra.Command, args []string) error {
 
 	// Record
 	if recSelect {
-		fmt.Println("Select area to record (Ctrl+C to stop)...")
+		fmt.Fprintln(info, "Select area to record (Ctrl+C to stop)...")
 	} else {
-		fmt.Printf("Recording fullscreen for %d seconds\n", recDuration)
+		fmt.Fprintf(info, "Recording fullscreen for %d seconds\n", recDuration)
 	}
 
 	movPath, err := platform.RecordScreen(recDuration, recSelect)
@@ -97,12 +97,12 @@ func runRec(cmd *cobra.Command, args []string) error {
 		return err
 	}
 	if movPath == "" {
-		fmt.Println("Recording cancelled")
+		fmt.Fprintln(info, "Recording cancelled")
 		return nil
 	}
 	defer os.Remove(movPath)
 
-	fmt.Println("Recording complete!")
+	fmt.Fprintln(info, "Recording complete!")
 
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
 
@@ -119,7 +119,7 @@ func runRec(cmd *cobra.Command, args []string) error {
 		}
 		defer os.Remove(gifPath)
 		outputPath = gifPath
-		fmt.Println("GIF created!")
+		fmt.Fprintln(info, "GIF created!")
 
 	case "mp4":
 		s.Suffix = " Converting to MP4..."
@@ -131,7 +131,7 @@ func runRec(cmd *cobra.Command, args []string) error {
 		}
 		defer os.Remove(mp4Path)
 		outputPath = mp4Path
-		fmt.Println("MP4 created!")
+		fmt.Fprintln(info, "MP4 created!")
 
 	case "mov":
 		// No conversion needed
@@ -145,5 +145,5 @@ func runRec(cmd *cobra.Command, args []string) error {
 	addPassword = recPassword
 
 	s = spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-	return handleFileUpload(outputPath, s)
+	return handleFileUpload(cmd.Context(), outputPath, s)
 }
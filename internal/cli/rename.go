@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// renameTitle is the --title flag of `nk rename`.
+var renameTitle string
+
+// maxFilenameLength is the longest name `nk rename` accepts, the usual
+// file system limit.
+const maxFilenameLength = 255
+
+// renameEndpoints are where each item type is renamed.
+var renameEndpoints = map[string]string{
+	"text":       "/shorts/",
+	"file":       "/shorts/",
+	"screenshot": "/screenshots/",
+	"profile":    "/files/",
+}
+
+func addRenameCommand() {
+	renameCmd := &cobra.Command{
+		Use:   "rename <id> [name]",
+		Short: "Change the name a file downloads as, or a text item's title",
+		Long: `Change the filename a file or screenshot is downloaded and shared under,
+or the title of a text item shown on its share page. For text items the
+name argument is the title.
+
+Examples:
+  nk rename abc1 "Q3 report.pdf"         New filename for a file
+  nk rename t1 --title "Deploy notes"    New title for a text item`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: runRename,
+	}
+	renameCmd.Flags().StringVar(&renameTitle, "title", "", "New title (text items)")
+
+	rootCmd.AddCommand(renameCmd)
+}
+
+func runRename(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	id := config.ResolveAlias(args[0])
+	name := ""
+	if len(args) > 1 {
+		name = strings.TrimSpace(args[1])
+	}
+	switch {
+	case name == "" && renameTitle == "":
+		return fmt.Errorf("give the new name: nk rename %s <name> (or --title for text items)", args[0])
+	case name != "" && renameTitle != "":
+		return fmt.Errorf("give either a name or --title, not both")
+	}
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " Fetching item..."
+	s.Start()
+	res, _, err := lookupItem(ctx, id)
+	s.Stop()
+	if errors.Is(err, api.ErrNotFound) {
+		return fmt.Errorf("no item found with ID %q. The item may have already expired or been deleted", id)
+	}
+	if err != nil {
+		return err
+	}
+
+	var body models.RenameRequest
+	if res.Type == "text" {
+		body.Title = renameTitle
+		if body.Title == "" {
+			body.Title = name
+		}
+	} else {
+		if renameTitle != "" {
+			return fmt.Errorf("--title is for text items; %s is a %s, rename it with: nk rename %s <filename>", id, res.Type, args[0])
+		}
+		if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+			return fmt.Errorf("invalid filename %q: it must not contain a path", name)
+		}
+		if len(name) > maxFilenameLength {
+			return fmt.Errorf("filename is too long (%d bytes, at most %d)", len(name), maxFilenameLength)
+		}
+		if old := filepath.Ext(res.Filename); old != "" && filepath.Ext(name) == "" {
+			fmt.Fprintf(info, "Note: %q has no extension; the old name ended in %s\n", name, old)
+		}
+		body.Filename = name
+	}
+
+	s.Suffix = " Renaming..."
+	s.Start()
+	_, err = api.Do(ctx, "PATCH", renameEndpoints[res.Type]+id, body)
+	s.Stop()
+	switch {
+	case errors.Is(err, api.ErrForbidden):
+		return fmt.Errorf("renaming Pro files requires a Pro subscription")
+	case errors.Is(err, api.ErrBadRequest):
+		return fmt.Errorf("the server rejected the name: %w", err)
+	case err != nil:
+		return fmt.Errorf("failed to rename: %w", err)
+	}
+
+	switch {
+	case body.Title != "":
+		fmt.Fprintf(stdout, "✓ %s is now titled %q\n", id, body.Title)
+	case res.Filename != "":
+		fmt.Fprintf(stdout, "✓ Renamed %s: %s → %s\n", id, res.Filename, body.Filename)
+	default:
+		fmt.Fprintf(stdout, "✓ Renamed %s to %s\n", id, body.Filename)
+	}
+	return nil
+}
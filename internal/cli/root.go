@@ -1,52 +1,147 @@
 package cli
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
 
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/auth"
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/platform"
+	"github.com/sim4gh/nikte-cli/internal/throttle"
+	"github.com/sim4gh/nikte-cli/internal/transport"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 // Version is set at build time
 var Version = "0.6.0"
 
-// rootCmd represents the base command
-var rootCmd = &cobra.Command{
-	Use:   "nk",
-	Short: "nikte CLI - Ephemeral content management",
-	Long: `nikte CLI - Ephemeral content management
+// rootProfile is the global --profile flag
+var rootProfile string
+
+// rootDebug is the global --debug flag
+var rootDebug bool
+
+// rootProxy is the global --proxy flag
+var rootProxy string
+
+// rootLimitRate is the global --limit-rate flag
+var rootLimitRate string
+
+// rootCmd is the command tree of the current run, built by newRootCommand.
+var rootCmd *cobra.Command
+
+// newRootCommand builds nk's command tree afresh, with every flag at its
+// default, and makes it rootCmd.
+func newRootCommand() *cobra.Command {
+	rootCmd = &cobra.Command{
+		Use:   "nk",
+		Short: "nikte CLI - Ephemeral content management",
+		Long: `nikte CLI - Ephemeral content management
 
 A fast CLI tool for managing ephemeral content with automatic TTL-based deletion.
 Upload text, files, and screenshots with optional sharing capabilities.`,
-	SilenceUsage:  true,
-	SilenceErrors: true,
-}
-
-// Execute runs the root command
-func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, "Error:", err)
-		os.Exit(1)
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := selectProfile(); err != nil {
+				return err
+			}
+			if err := checkConfig(cmd); err != nil {
+				return err
+			}
+			if rootFakeBackend && fakeBackend == nil {
+				if err := startFakeBackend(); err != nil {
+					return err
+				}
+			}
+			if needsTokens(cmd) {
+				if err := config.Unlock(); err != nil {
+					return err
+				}
+			}
+			if rootProxy != "" {
+				if err := config.ValidateProxyURL(rootProxy, false); err != nil {
+					return fmt.Errorf("--proxy %v", err)
+				}
+				transport.SetProxy(rootProxy)
+			}
+			transport.SetDebug(rootDebug)
+			limit := config.GetTuning().LimitRate
+			if rootLimitRate != "" {
+				n, err := config.ParseLimitRate(rootLimitRate)
+				if err != nil {
+					return fmt.Errorf("--limit-rate %v", err)
+				}
+				limit = n
+			}
+			throttle.SetRate(limit)
+			if rootWaitOnRateLimit {
+				api.RateLimitWait = waitOutRateLimit
+			}
+			return resolveOutput()
+		},
 	}
-}
 
-func init() {
 	rootCmd.Version = Version
+	rootCmd.PersistentFlags().StringVar(&rootProfile, "profile", "", "Use the named account profile (or NIKTE_PROFILE)")
+	rootCmd.PersistentFlags().StringVar(&rootProxy, "proxy", "", "Send all requests through this proxy (http://, https:// or socks5://, with optional user:pass@)")
+	rootCmd.PersistentFlags().StringVar(&rootLimitRate, "limit-rate", "", "Cap file upload and download bandwidth per second, like 2MB (overrides limit_rate; 0 for unlimited)")
+	rootCmd.PersistentFlags().BoolVar(&rootNoClipboard, "no-clipboard", false, "Don't copy IDs, URLs or content to the clipboard")
+	rootCmd.PersistentFlags().BoolVar(&rootDebug, "debug", false, "Log every HTTP request and response to stderr, credentials redacted (or NIKTE_DEBUG=1)")
+	rootCmd.PersistentFlags().BoolVar(&rootWaitOnRateLimit, "wait-on-ratelimit", false, "When rate limited, wait for the limit to reset (with a countdown) and retry")
+	rootCmd.PersistentFlags().BoolVar(&rootFakeBackend, "fake-backend", false, "Use an in-process fake API instead of your account, for demos and development (no login needed)")
+	rootCmd.PersistentFlags().StringVar(&rootOutputFormat, "output-format", "", "Render ls/get/add results as table, json or ndjson (overrides the output setting)")
 
 	// Add all subcommands
 	addAuthCommands()
+	addCatCommand()
+	addCpCommand()
 	addHealthCommand()
+	addHistoryCommand()
 	addConfigCommand()
+	addDaemonCommand()
 	addAddCommand()
+	addAliasCommand()
+	addAppendCommand()
 	addGetCommand()
+	addGrepCommand()
 	addListCommand()
+	addNoteCommand()
+	addStatsCommand()
+	addServeCommand()
 	addDeleteCommand()
+	addDiffCommand()
+	addDocsCommand()
+	addEditCommand()
 	addExtendCommand()
+	addFilesCommand()
+	addExportCommand()
+	addImportCommand()
 	addShareCommand()
+	addSyncCommand()
+	addQRCommand()
 	addRecCommand()
+	addRenameCommand()
+	addTrashCommands()
 	addTrustYouCommand()
+	addTUICommand()
+	addUpgradeCommand()
+	addVersionCommand()
 	addShortcutCommands()
 	addWaCommands()
+	addWatchCommand()
+	addWebhookCommand()
 	addLinkCommands()
 
 	// Custom root help with tree structure and inline aliases
@@ -58,10 +153,162 @@ func init() {
 			defaultHelp(cmd, args)
 		}
 	})
+	return rootCmd
+}
+
+func init() {
+	transport.UserAgent = fmt.Sprintf("nikte-cli/%s (%s/%s)", Version, runtime.GOOS, runtime.GOARCH)
+}
+
+// Deps are what a run of nk talks to besides the network and the config
+// directory. Zero fields mean the real ones: the process's stdout and
+// stderr, the default API client and URL, and the clipboard and screenshot
+// backends detected on this machine.
+type Deps struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// Client sends API requests (see api.SetClient) and BaseURL replaces the
+	// API's URL, e.g. a fake backend's.
+	Client    *http.Client
+	BaseURL   string
+	Clipboard platform.Clipboard
+	Capturer  platform.Capturer
+}
+
+// Execute runs nk with the process's arguments and returns the exit code.
+func Execute() int {
+	// Ctrl-C cancels the context every command runs with, aborting in-flight
+	// requests, uploads and polling. Once it fires the default handler is
+	// restored, so a second Ctrl-C kills nk outright.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	return Run(ctx, os.Args[1:], Deps{})
+}
+
+// Run runs nk with args on a freshly built command tree, with deps in place
+// of the real streams, API client and platform backends, and returns the exit
+// code: 0, 1 for an error, 130 when ctx was cancelled, or the command's own
+// (see exitError). Errors are printed to deps' stderr the way nk shows them.
+func Run(ctx context.Context, args []string, deps Deps) int {
+	// Everything a run swaps out, here or in a command (nk serve discards
+	// info, --fake-backend sets the base URL), is put back on return, so
+	// runs in one process don't leak into each other or into the caller.
+	savedStdout, savedStderr, savedInfo := stdout, stderr, info
+	defer func() { stdout, stderr, info = savedStdout, savedStderr, savedInfo }()
+	savedClient := api.DefaultClient
+	defer api.SetClient(savedClient)
+	defer api.SetBaseURL(api.SetBaseURL(deps.BaseURL))
+	defer platform.SetClipboard(platform.SetClipboard(deps.Clipboard))
+	defer platform.SetCapturer(platform.SetCapturer(deps.Capturer))
+
+	stdout, stderr = os.Stdout, os.Stderr
+	if deps.Stdout != nil {
+		stdout = deps.Stdout
+	}
+	if deps.Stderr != nil {
+		stderr = deps.Stderr
+	}
+	info = stdout
+	if deps.Client != nil {
+		api.SetClient(deps.Client)
+	}
+
+	execute := func() (*cobra.Command, error) {
+		historyItems, hooksRun = nil, 0
+		root := newRootCommand()
+		root.SetArgs(markBarePassword(root, args))
+		root.SetOut(stdout)
+		root.SetErr(stderr)
+		return root.ExecuteContextC(ctx)
+	}
+	cmd, err := execute()
+	if err != nil && errors.Is(err, auth.ErrSessionExpired) && offerRelogin(ctx) {
+		cmd, err = execute()
+	}
+	recordHistory(ctx, cmd, err)
+	if cmd != nil && ctx.Err() == nil {
+		// Items noted before a later failure were still added, shared or deleted.
+		if name, ok := historyCommands[strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" ")]; ok && name != "get" {
+			runHooks(ctx, name)
+		}
+	}
+	stopFakeBackend()
+	if err != nil && ctx.Err() != nil {
+		fmt.Fprintln(stderr, "\nCancelled.")
+		return 130
+	}
+	if err != nil {
+		return reportError(err)
+	}
+	return 0
+}
+
+// reportError prints err the way a failed command shows it and returns the
+// exit code nk ends with.
+func reportError(err error) int {
+	var exit *exitError
+	if !errors.As(err, &exit) {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 1
+	}
+	if exit.err != nil {
+		fmt.Fprintln(stderr, "Error:", exit.err)
+	}
+	return exit.code
+}
+
+// exitError makes nk exit with code instead of 1, for commands whose exit
+// status scripts test (see nk cat). With a nil err nothing is printed, for
+// commands that already said why.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// offerRelogin asks whether to log in again after the refresh token was
+// rejected, and runs the device flow if so. It only prompts when both stdin
+// and stdout are terminals; scripts get the plain error.
+func offerRelogin(ctx context.Context) bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !isTerminal(stdout) {
+		return false
+	}
+
+	fmt.Fprint(stdout, "Your session has expired. Log in again now? [Y/n]: ")
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return false
+	}
+	response = strings.TrimSpace(strings.ToLower(response))
+	if response != "" && response != "y" && response != "yes" {
+		return false
+	}
+
+	loginCmd.SetContext(ctx)
+	if err := runLogin(loginCmd, nil); err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return false
+	}
+	fmt.Fprintln(stdout)
+	return true
 }
 
 func printRootHelp() {
-	fmt.Print(`nikte CLI - Ephemeral content management
+	fmt.Fprint(stdout, `nikte CLI - Ephemeral content management
 
 A fast CLI tool for managing ephemeral content with automatic TTL-based deletion.
 Upload text, files, and screenshots with optional sharing capabilities.
@@ -90,21 +337,55 @@ Commands:
     │                         Upload permanently + get share URL
     └   nk a "hello" -p     Add text + share publicly
 
+  alias set|ls|rm             Name items; use the name wherever an ID goes
+  append <id> [text]          Add a line (or stdin) to the end of a text item
   auth                        Authentication commands
+    ├ login --profile <name>  Log in to a named account profile
+    ├ switch <profile>        Change the active profile
+    ├ profiles                List profiles
+    ├ status [--json]         Token validity and expiry
+    ├ export | import <file>  Move a login to another machine (encrypted)
+    └ token set|clear         Personal access token for CI (or NIKTE_TOKEN)
+  cat <id>                    Print a text item's content, nothing else
   config [subcommand]         Manage configuration
+  cp <id>                     Copy content or URL to the clipboard, unprinted
   d, delete <id>              Delete item by ID
+  diff <id1> <id2>            Unified diff of two text items
+  daemon start|stop|status    Background clipboard sync, captures, folder watch
+  docs man|markdown [dir]     Generate man pages or markdown reference docs
+  edit <id>                   Edit a text item in $EDITOR, keeping its ID
+  export <dir> [--tar]        Download every item with a manifest.json
   extend <id>                 Extend TTL or make item permanent
+  files add|ls|get|rm         Pro files: kept until deleted, with descriptions
   g, get <id>                 Get/download item by ID
+  grep <pattern>              Search the full content of all text items
   health                      Check system health status
+  history [search]            What add/get/delete/share did on this machine
+  import <dir> [--map f]      Upload a folder or export, printing old→new IDs
+  link, shorten [url]         Short link for a URL (default: the clipboard's)
   ls, list                    List all items
     └ -i, --interactive       Navigable list (arrows, copy, delete)
+  note [-t title]             Type a multi-line note, end with Ctrl-D
+  qr <id> [--share|--content] QR code of an item's link (--png to save it)
   rec                         Record screen to GIF, MP4, or MOV
+  rename <id> <name>          New filename for a file, or --title for text
+  restore <id>...             Bring deleted text items back from the trash
   sh, share <id>              Share item (Pro only)
     ├ ls                      List your shares with view counts
     ├ --qr                    Print a scannable QR of the share URL
     ├ --max-views <n>         Burn-after-read: delete link after N views
+    ├ --direct                Raw link serving the bytes (embed in markdown)
     └ p <id>                  Quick public share shortcut
+  serve [--listen addr]       Local REST API for editors and scripts
+  stats [--json]              Usage: items, bytes, expiring soon, quota
+  sync <dir> [--watch]        Upload new and changed files in a folder
+  trash [empty]               Items deleted with nk delete in the last 30 days
   trustyou                    Create a link for browser file uploads
+  tui                         Browse items full-screen with a preview pane
+  upgrade [--check]           Update nk to the latest release
+  version [--check]           Version, commit, build date; is there a newer one?
+  watch <dir> [--public]      Upload each file added to a folder, print its link
+  webhook set|unset|test      POST a JSON event on add, delete or expiring soon
   wa                          WhatsApp messaging commands
     ├ link                    Link WhatsApp (scan QR code)
     ├ send <number> [msg]     Send a WhatsApp message
@@ -114,15 +395,62 @@ Commands:
     └ unlink                  Unlink WhatsApp
 
 Flags:
-  -h, --help      help for nk
-  -v, --version   version for nk
+  -h, --help             help for nk
+      --profile <name>   Use a named account profile for this command
+      --proxy <url>      Use an http(s) or socks5 proxy for this command
+      --no-clipboard     Don't copy IDs, URLs or content to the clipboard
+      --output-format <f>
+                         Render ls/get/add results as table, json or ndjson
+      --debug            Log HTTP requests and responses to stderr
+      --wait-on-ratelimit
+                         When rate limited, wait for the reset and retry
+      --fake-backend     Use an in-process fake API (demos, no login needed)
+  -v, --version          version for nk
 
 Use "nk [command] --help" for more information about a command.
 `)
 }
 
-// exitWithError prints an error message and exits
-func exitWithError(msg string) {
-	fmt.Fprintln(os.Stderr, "Error:", msg)
-	os.Exit(1)
+// selectProfile applies --profile (or NIKTE_PROFILE) for this invocation.
+func selectProfile() error {
+	name := rootProfile
+	if name == "" {
+		name = os.Getenv("NIKTE_PROFILE")
+	}
+	if name == "" {
+		// Clear a selection left by an earlier Run in this process.
+		config.SelectProfile("")
+		return nil
+	}
+	if strings.ContainsAny(name, " /\\") {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	config.SelectProfile(name)
+	return nil
+}
+
+// needsTokens reports whether cmd may use the stored tokens, so encrypted ones
+// must be unlocked first. Commands that only read settings skip the prompt.
+func needsTokens(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		switch c.Name() {
+		case "config", "docs", "health", "help", "completion", "version":
+			return false
+		}
+	}
+	return true
+}
+
+// checkConfig loads the config file up front so a malformed file fails with a
+// clear message instead of a confusing error later, and reports non-fatal
+// problems as warnings. `nk config` itself still runs so the file can be fixed.
+func checkConfig(cmd *cobra.Command) error {
+	_, err := config.Load()
+	if err != nil && cmd.Name() != "config" {
+		return fmt.Errorf("%w\nRun \"nk config edit\" or \"nk config reset\" to fix it", err)
+	}
+	for _, w := range config.Warnings() {
+		fmt.Fprintf(stderr, "Warning: %s: %v (ignored)\n", config.Path(), w)
+	}
+	return nil
 }
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/fakebackend"
+	"github.com/sim4gh/nikte-cli/internal/platform"
+)
+
+func TestMain(m *testing.M) {
+	// The config is loaded once per process, so every test shares one
+	// config directory, and none of them touches the developer's.
+	dir, err := os.MkdirTemp("", "nk-cli-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	os.Setenv("HOME", dir)
+	os.Setenv("APPDATA", dir)
+	os.Setenv("NIKTE_TOKEN", "test-token")
+	os.Setenv("NIKTE_NO_HOOKS", "1")
+	os.Unsetenv("NIKTE_PROFILE")
+	os.Setenv("NO_COLOR", "1")
+	// Dates in tables are shown in local time.
+	time.Local = time.UTC
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// memClipboard is a text-only clipboard in memory.
+type memClipboard struct{ text string }
+
+func (c *memClipboard) Name() string    { return "memory" }
+func (c *memClipboard) Available() bool { return true }
+func (c *memClipboard) Capabilities() platform.ClipboardCapabilities {
+	return platform.ClipboardCapabilities{}
+}
+func (c *memClipboard) ReadText() (string, error)   { return c.text, nil }
+func (c *memClipboard) WriteText(text string) error { c.text = text; return nil }
+func (c *memClipboard) HasImage() bool              { return false }
+func (c *memClipboard) ReadImage() ([]byte, error)  { return nil, nil }
+func (c *memClipboard) WriteImage(png []byte) error {
+	return errors.New("memory clipboard holds text only")
+}
+
+// testNK runs nk commands against a fake backend whose clock only moves when
+// the test moves it, so IDs and dates in the output never change.
+type testNK struct {
+	t    *testing.T
+	now  time.Time
+	clip *memClipboard
+	deps Deps
+}
+
+func newTestNK(t *testing.T) *testNK {
+	t.Helper()
+	n := &testNK{t: t, now: time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC), clip: &memClipboard{}}
+	b := fakebackend.New()
+	b.Now = func() time.Time { return n.now }
+	n.deps = Deps{BaseURL: b.Start(), Clipboard: n.clip}
+	t.Cleanup(b.Close)
+	return n
+}
+
+// run runs nk with args and returns what it wrote to stdout and stderr and
+// its exit code.
+func (n *testNK) run(args ...string) (string, string, int) {
+	n.t.Helper()
+	var out, errOut bytes.Buffer
+	deps := n.deps
+	deps.Stdout, deps.Stderr = &out, &errOut
+	code := Run(context.Background(), args, deps)
+	return out.String(), errOut.String(), code
+}
+
+func TestRunExitCodes(t *testing.T) {
+	n := newTestNK(t)
+	n.run("a", "hello", "--permanent")
+	for _, tt := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"cat", "t1"}, 0},
+		{[]string{"cat", "missing"}, catExitNotFound},
+		{[]string{"g", "missing"}, 1},
+		{[]string{"ls", "--bogus"}, 1},
+		{[]string{"health"}, 0},
+	} {
+		if _, errOut, code := n.run(tt.args...); code != tt.code {
+			t.Errorf("nk %s: exit code %d, want %d (stderr %q)", strings.Join(tt.args, " "), code, tt.code, errOut)
+		}
+	}
+}
+
+func TestRunUsesInjectedClipboard(t *testing.T) {
+	n := newTestNK(t)
+	n.clip.text = "from the clipboard"
+
+	if _, errOut, code := n.run("a", "--permanent"); code != 0 {
+		t.Fatalf("nk a: exit code %d: %s", code, errOut)
+	}
+	if n.clip.text != "t1" {
+		t.Errorf("clipboard = %q after nk a, want the new ID", n.clip.text)
+	}
+	if out, _, _ := n.run("cat", "t1"); out != "from the clipboard" {
+		t.Errorf("nk cat t1 = %q", out)
+	}
+	if _, errOut, code := n.run("cp", "t1"); code != 0 || n.clip.text != "from the clipboard" {
+		t.Errorf("nk cp t1: exit code %d, clipboard %q: %s", code, n.clip.text, errOut)
+	}
+}
+
+func TestRunStartsWithDefaultFlags(t *testing.T) {
+	n := newTestNK(t)
+	n.run("a", "some text", "--permanent")
+	n.run("a", "other text", "--permanent", "--no-clipboard")
+	n.clip.text = ""
+
+	// Flags of one run must not leak into the next, which gets a fresh tree.
+	if out, _, _ := n.run("ls", "-s", "other"); !strings.Contains(out, "1 item") {
+		t.Fatalf("nk ls -s other:\n%s", out)
+	}
+	if out, _, _ := n.run("ls"); !strings.Contains(out, "2 items") {
+		t.Fatalf("nk ls after a filtered ls:\n%s", out)
+	}
+	n.run("cp", "t1")
+	if n.clip.text == "" {
+		t.Error("--no-clipboard of an earlier run still applied")
+	}
+}
+
+func TestRunRestoresGlobals(t *testing.T) {
+	n := newTestNK(t)
+	var out, errOut bytes.Buffer
+	outer := &memClipboard{}
+	stdout, stderr, info = &out, &errOut, &out
+	platform.SetClipboard(outer)
+	api.SetBaseURL("http://outer.invalid")
+	t.Cleanup(func() {
+		stdout, stderr, info = os.Stdout, os.Stderr, os.Stdout
+		platform.SetClipboard(nil)
+		api.SetBaseURL("")
+	})
+
+	// nk serve and --output-format json both replace info mid-run.
+	n.run("ls", "--output-format", "json")
+	n.run("--fake-backend", "health")
+
+	if stdout != &out || stderr != &errOut || info != &out {
+		t.Error("Run left its own writers in place")
+	}
+	if platform.SelectedClipboard() != outer {
+		t.Error("Run left its own clipboard in place")
+	}
+	if url := api.SetBaseURL(""); url != "http://outer.invalid" {
+		t.Errorf("base URL after Run = %q", url)
+	}
+}
@@ -0,0 +1,507 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// Flags of `nk serve`.
+var (
+	serveListen string
+	serveToken  string
+	serveNoAuth bool
+)
+
+// defaultServeListen is where `nk serve` listens unless told otherwise.
+const defaultServeListen = "127.0.0.1:7777"
+
+// maxServeImageBytes caps an image/* body, which is held in memory before
+// it is uploaded.
+const maxServeImageBytes = 50 << 20
+
+// serveTypeOrder is the order GET /v1/items/{id} looks an ID up in, the same
+// as `nk g`.
+var serveTypeOrder = []string{"text", "screenshot", "profile"}
+
+func addServeCommand() {
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a local REST API for editors and scripts",
+		Long: `Serve a small REST API backed by your login, so editors, launchers
+(Raycast, Alfred) and browser extensions can add, get, list and delete items
+without shelling out to nk.
+
+Requests must carry the token printed at start, as "Authorization: Bearer
+<token>" (set your own with --token or NIKTE_SERVE_TOKEN). With --no-auth,
+requests must instead be addressed to localhost or a loopback IP, and
+requests from web pages on other sites are refused.
+
+Endpoints:
+  GET    /v1/health                    Liveness and version
+  GET    /v1/items                     List (?type=, ?search=, ?sort=, ?limit=)
+  GET    /v1/items/{id}                Item details, with a download URL
+  GET    /v1/items/{id}/content        Text as text/plain; files redirect
+  POST   /v1/items                     Add: JSON {"content", "ttl", "permanent"},
+                                       text/plain, image/* (up to 50MB) or a file body
+                                       with ?filename= (and ?ttl=)
+  DELETE /v1/items/{id}                Delete
+
+Examples:
+  nk serve                              Listen on 127.0.0.1:7777
+    └ --listen 127.0.0.1:9000            Another port
+  curl -H "Authorization: Bearer $TOKEN" localhost:7777/v1/items`,
+		Args: cobra.NoArgs,
+		RunE: runServe,
+	}
+
+	serveCmd.Flags().StringVar(&serveListen, "listen", defaultServeListen, "Address to listen on")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "Token clients must send (default: NIKTE_SERVE_TOKEN, or a random one)")
+	serveCmd.Flags().BoolVar(&serveNoAuth, "no-auth", false, "Accept requests without a token (only on a loopback address)")
+
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	host, _, err := net.SplitHostPort(serveListen)
+	if err != nil {
+		return fmt.Errorf("--listen %q: %w", serveListen, err)
+	}
+	loopback := host == "localhost"
+	if ip := net.ParseIP(host); ip != nil {
+		loopback = ip.IsLoopback()
+	}
+	if serveNoAuth && !loopback {
+		return fmt.Errorf("--no-auth is only allowed when listening on a loopback address")
+	}
+
+	token := serveToken
+	if token == "" {
+		token = os.Getenv("NIKTE_SERVE_TOKEN")
+	}
+	if token == "" && !serveNoAuth {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return err
+		}
+		token = hex.EncodeToString(b)
+	}
+	if serveNoAuth {
+		token = ""
+	}
+
+	ln, err := net.Listen("tcp", serveListen)
+	if err != nil {
+		return err
+	}
+
+	// The upload helpers report to info and copy IDs to the clipboard;
+	// neither makes sense for requests from other programs.
+	info = io.Discard
+	rootNoClipboard = true
+
+	srv := &http.Server{
+		Handler:           serveLog(serveAuth(token, serveMux())),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return cmd.Context() },
+	}
+	go func() {
+		<-cmd.Context().Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(stdout, "Serving the nikte API on http://%s/v1/\n", ln.Addr())
+	if token != "" {
+		fmt.Fprintf(stdout, "Token: %s\n", token)
+	} else {
+		fmt.Fprintln(stdout, "Warning: no token required; any local program can use your account")
+	}
+	if !loopback {
+		fmt.Fprintln(stdout, "Warning: listening beyond this machine; requests and the token travel unencrypted")
+	}
+	fmt.Fprintln(stdout, "Press Ctrl-C to stop")
+
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func serveMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "version": Version})
+	})
+	mux.HandleFunc("GET /v1/items", serveList)
+	mux.HandleFunc("GET /v1/items/{id}", serveGet)
+	mux.HandleFunc("GET /v1/items/{id}/content", serveContent)
+	mux.HandleFunc("POST /v1/items", serveAdd)
+	mux.HandleFunc("DELETE /v1/items/{id}", serveDelete)
+	return mux
+}
+
+// serveAuth rejects requests without the bearer token. With an empty token
+// it instead rejects requests addressed to anything but a loopback name, so a
+// web page can't reach the API by rebinding its own domain to 127.0.0.1, and
+// requests a browser sent from another site, such as a form posted to
+// http://127.0.0.1:7777/v1/items.
+func serveAuth(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" && !loopbackHost(r.Host) {
+			writeError(w, http.StatusForbidden, "requests without a token must be addressed to localhost")
+			return
+		}
+		if token == "" && crossSite(r) {
+			writeError(w, http.StatusForbidden, "requests without a token can't come from a web page")
+			return
+		}
+		if token != "" {
+			got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				writeError(w, http.StatusUnauthorized, "missing or wrong token")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// loopbackHost reports whether a Host header names this machine: localhost
+// or a loopback IP, with or without a port.
+func loopbackHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// crossSite reports whether a browser sent r on behalf of a page that isn't
+// served from this machine. Programs other than browsers send neither header.
+func crossSite(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "cross-site", "same-site":
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	u, err := url.Parse(origin)
+	return err != nil || !loopbackHost(u.Host)
+}
+
+// statusRecorder remembers the status a handler wrote, for the log.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+// serveLog prints a line per request.
+func serveLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		fmt.Fprintf(stdout, "%s %s %s %d (%s)\n", start.Format("15:04:05"), r.Method, r.URL.Path, rec.status,
+			time.Since(start).Round(time.Millisecond))
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// writeAPIError reports an error from the nikte API with a matching status.
+func writeAPIError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, api.ErrNotFound):
+		writeError(w, http.StatusNotFound, "item not found")
+	case errors.Is(err, api.ErrForbidden):
+		writeError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, api.ErrBadRequest):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, api.ErrTooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+	default:
+		writeError(w, http.StatusBadGateway, err.Error())
+	}
+}
+
+func serveList(w http.ResponseWriter, r *http.Request) {
+	fetched := fetchAllItems(r.Context())
+	if fetched.err != nil && len(fetched.items) == 0 {
+		writeAPIError(w, fetched.err)
+		return
+	}
+	q := r.URL.Query()
+	items := fetched.items
+	if t := q.Get("type"); t != "" {
+		items = filterByType(items, t)
+	}
+	if s := q.Get("search"); s != "" {
+		items = filterBySearch(items, s)
+	}
+	items = sortItems(items, q.Get("sort"))
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a number")
+			return
+		}
+		items = items[:min(n, len(items))]
+	}
+	if items == nil {
+		items = []Item{}
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+// lookupItem finds id among shorts, screenshots and files.
+func lookupItem(ctx context.Context, id string) (itemResult, itemDetail, error) {
+	for _, t := range serveTypeOrder {
+		d, err := fetchItemDetail(ctx, Item{ID: id, Type: t})
+		if errors.Is(err, api.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return itemResult{}, d, err
+		}
+		if t == "text" && d.DownloadURL != "" {
+			t = "file"
+		}
+		return itemResult{
+			ID:          id,
+			Type:        t,
+			Content:     d.Content,
+			Filename:    d.Filename,
+			Size:        d.Size,
+			ContentType: d.ContentType,
+			CreatedAt:   d.CreatedAt,
+			ExpiresAt:   d.ExpiresAt,
+			URL:         d.DownloadURL,
+		}, d, nil
+	}
+	return itemResult{}, itemDetail{}, api.ErrNotFound
+}
+
+func serveGet(w http.ResponseWriter, r *http.Request) {
+	res, _, err := lookupItem(r.Context(), config.ResolveAlias(r.PathValue("id")))
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// serveContent returns a text item's content, and redirects to the
+// download URL of anything else. Encrypted text is returned as stored.
+func serveContent(w http.ResponseWriter, r *http.Request) {
+	res, _, err := lookupItem(r.Context(), config.ResolveAlias(r.PathValue("id")))
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	if res.URL != "" {
+		http.Redirect(w, r, res.URL, http.StatusFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, res.Content)
+}
+
+func serveDelete(w http.ResponseWriter, r *http.Request) {
+	id := config.ResolveAlias(r.PathValue("id"))
+	result := deleteItem(r.Context(), id, true)
+	switch {
+	case result.success:
+		w.WriteHeader(http.StatusNoContent)
+	case result.error == "not_found":
+		writeError(w, http.StatusNotFound, "item not found")
+	case result.error == "pro_required":
+		writeError(w, http.StatusForbidden, "deleting files requires a Pro subscription")
+	default:
+		writeError(w, http.StatusBadGateway, result.error)
+	}
+}
+
+// serveAddRequest is the JSON body of POST /v1/items for text.
+type serveAddRequest struct {
+	Content   string `json:"content"`
+	TTL       string `json:"ttl"`
+	Permanent bool   `json:"permanent"`
+}
+
+// serveAdd creates an item from the request body: text from JSON or
+// text/plain, a screenshot from an image, and a file from anything else.
+func serveAdd(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	q := r.URL.Query()
+	ttl, permanent := q.Get("ttl"), q.Get("permanent") == "true"
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var (
+		text     string
+		image    []byte
+		filePath string
+	)
+	switch {
+	case mediaType == "application/json":
+		var req serveAddRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxTextSizeBytes*2)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "bad JSON: "+err.Error())
+			return
+		}
+		text, ttl, permanent = req.Content, req.TTL, req.Permanent
+	case mediaType == "text/plain":
+		data, err := io.ReadAll(io.LimitReader(r.Body, maxTextSizeBytes+1))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		text = string(data)
+	case strings.HasPrefix(mediaType, "image/"):
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxServeImageBytes))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "image is over 50MB; upload it as a file")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		image = data
+	default:
+		name := filepath.Base(q.Get("filename"))
+		if name == "." || name == string(filepath.Separator) {
+			writeError(w, http.StatusBadRequest, "file uploads need ?filename=")
+			return
+		}
+		dir, err := os.MkdirTemp("", "nk-serve-*")
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		defer os.RemoveAll(dir)
+		filePath = filepath.Join(dir, name)
+		if err := saveBody(filePath, r.Body); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if filePath == "" && image == nil {
+		if strings.TrimSpace(text) == "" {
+			writeError(w, http.StatusBadRequest, "content is empty")
+			return
+		}
+		if len(text) > maxTextSizeBytes {
+			writeError(w, http.StatusRequestEntityTooLarge, "text is over 360KB; upload it as a file")
+			return
+		}
+	}
+
+	addMu.Lock()
+	defer addMu.Unlock()
+	savedTTL, savedPermanent := addTTL, addPermanent
+	defer func() { addTTL, addPermanent = savedTTL, savedPermanent }()
+	addTTL, addPermanent = ttl, permanent
+	addResult = itemResult{}
+
+	var err error
+	rep := &serveReporter{}
+	switch {
+	case filePath != "":
+		var res *itemResult
+		res, err = uploadFile(ctx, filePath, rep)
+		if res != nil {
+			addResult = *res
+		}
+	case image != nil:
+		err = uploadImage(ctx, image, quietSpinner(), "api")
+	default:
+		err = uploadTextContent(ctx, text, quietSpinner())
+	}
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	if addResult.mismatch {
+		writeError(w, http.StatusBadGateway, fmt.Sprintf("the stored file %s does not match the upload; upload it again", addResult.ID))
+		return
+	}
+	writeJSON(w, http.StatusCreated, struct {
+		itemResult
+		Warnings []string `json:"warnings,omitempty"`
+	}{addResult, rep.warnings})
+}
+
+// saveBody writes an uploaded file to path, up to the file size limit.
+func saveBody(path string, body io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(body, maxFileSizeBytes+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n > maxFileSizeBytes {
+		err = fmt.Errorf("file is over the 10GB limit")
+	}
+	return err
+}
+
+// serveReporter drops the progress of uploads made for API requests and keeps
+// their warnings for the response.
+type serveReporter struct {
+	warnings []string
+}
+
+func (*serveReporter) Status(msg string)                               {}
+func (*serveReporter) Infof(format string, args ...any)                {}
+func (*serveReporter) Progress(completed, total int, done, size int64) {}
+func (*serveReporter) Stop()                                           {}
+
+func (r *serveReporter) Warnf(format string, args ...any) {
+	r.warnings = append(r.warnings, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServeAuthHost(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for _, tt := range []struct {
+		token, auth, host string
+		origin, fetchSite string
+		want              int
+	}{
+		{"", "", "localhost:7777", "", "", http.StatusOK},
+		{"", "", "127.0.0.1:7777", "", "", http.StatusOK},
+		{"", "", "[::1]:7777", "", "", http.StatusOK},
+		{"", "", "LOCALHOST", "", "", http.StatusOK},
+		{"", "", "attacker.example:7777", "", "", http.StatusForbidden},
+		{"", "", "192.168.1.5:7777", "", "", http.StatusForbidden},
+		{"", "", "127.0.0.1:7777", "https://attacker.example", "", http.StatusForbidden},
+		{"", "", "127.0.0.1:7777", "null", "", http.StatusForbidden},
+		{"", "", "127.0.0.1:7777", "", "cross-site", http.StatusForbidden},
+		{"", "", "localhost:7777", "", "same-site", http.StatusForbidden},
+		{"", "", "localhost:7777", "http://localhost:7777", "same-origin", http.StatusOK},
+		{"", "", "localhost:7777", "", "none", http.StatusOK},
+		{"secret", "Bearer secret", "attacker.example:7777", "https://attacker.example", "cross-site", http.StatusOK},
+		{"secret", "Bearer wrong", "localhost:7777", "", "", http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest("POST", "/v1/items", nil)
+		req.Host = tt.host
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		if tt.fetchSite != "" {
+			req.Header.Set("Sec-Fetch-Site", tt.fetchSite)
+		}
+		rec := httptest.NewRecorder()
+		serveAuth(tt.token, ok).ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("token %q, Host %q, Origin %q, Sec-Fetch-Site %q: status %d, want %d",
+				tt.token, tt.host, tt.origin, tt.fetchSite, rec.Code, tt.want)
+		}
+	}
+}
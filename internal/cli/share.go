@@ -1,16 +1,23 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
+	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/atotto/clipboard"
 	"github.com/briandowns/spinner"
 	"github.com/olekukonko/tablewriter"
 	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/crypto"
+	"github.com/sim4gh/nikte-cli/internal/models"
+	"github.com/sim4gh/nikte-cli/internal/platform"
 	"github.com/sim4gh/nikte-cli/internal/util"
 	"github.com/spf13/cobra"
 )
@@ -23,9 +30,28 @@ var (
 	shareDesc     string
 	shareQR       bool
 	shareMaxViews int
+	shareDirect   bool
+	shareCombined bool
+	shareEmail    string
+	shareEmailMsg string
+	sharePwPrompt bool
 )
 
-const defaultShareExpiryDays = 1
+const (
+	defaultShareExpiryDays = 1
+
+	// autoPasswordValue asks for a generated password: --password auto
+	autoPasswordValue  = "auto"
+	autoPasswordLength = 20
+
+	// promptPasswordValue is what a bare --password becomes (see
+	// markBarePassword); it asks for the password at a hidden prompt.
+	promptPasswordValue = "\x00prompt"
+
+	// passwordPromptAnnotation marks the --password flags that prompt when
+	// given no value.
+	passwordPromptAnnotation = "nk_password_prompt"
+)
 
 func addShareCommand() {
 	shareCmd := &cobra.Command{
@@ -36,7 +62,11 @@ func addShareCommand() {
 Examples:
   nk sh <id>                  Create public share link
     ├ --password x             Password-protected share
+    ├ --password auto          Generate a strong random password
+    ├ --password               Prompt for the password (hidden input)
     ├ --expires 7d             Share expires in 7 days
+    ├ --direct                 Raw link serving the bytes (for embedding)
+    ├ --email a@b.com          Email the link (with optional --message)
     └ --title "My Doc"         Share with title and description
 
 All shares use share.nikte.co/{id}`,
@@ -46,12 +76,18 @@ All shares use share.nikte.co/{id}`,
 	}
 
 	shareCmd.Flags().BoolVarP(&sharePublic, "public", "p", false, "Public share (default)")
-	shareCmd.Flags().StringVar(&sharePassword, "password", "", "Password-protected share")
+	shareCmd.Flags().StringVar(&sharePassword, "password", "", "Password-protected share (\"auto\" generates one; no value prompts)")
+	allowPasswordPrompt(shareCmd)
+	shareCmd.Flags().BoolVar(&sharePwPrompt, "password-prompt", false, "Read the share password from a hidden prompt")
+	shareCmd.Flags().BoolVar(&shareCombined, "combined", false, "Copy URL and password as one message ready to paste into chat")
+	shareCmd.Flags().StringVar(&shareEmail, "email", "", "Email the share link to this address")
+	shareCmd.Flags().StringVar(&shareEmailMsg, "message", "", "Optional message included with --email")
 	shareCmd.Flags().StringVar(&shareExpires, "expires", "", "Share expiration (default: 24h, e.g., 7d)")
 	shareCmd.Flags().StringVar(&shareTitle, "title", "", "Share title for social previews")
 	shareCmd.Flags().StringVar(&shareDesc, "desc", "", "Share description for social previews")
 	shareCmd.Flags().BoolVar(&shareQR, "qr", false, "Print a scannable QR code of the share URL")
 	shareCmd.Flags().IntVar(&shareMaxViews, "max-views", 0, "Burn-after-read: delete the link after N views")
+	shareCmd.Flags().BoolVar(&shareDirect, "direct", false, "Return a raw link that serves the content directly (for embedding)")
 
 	// nk sh ls — list your shares with view counts (analytics)
 	shareListCmd := &cobra.Command{
@@ -67,33 +103,68 @@ All shares use share.nikte.co/{id}`,
 }
 
 func runShare(cmd *cobra.Command, args []string) error {
-	id := args[0]
+	id := config.ResolveAlias(args[0])
+
+	if err := resolvePasswordFlag(&sharePassword, sharePwPrompt); err != nil {
+		return err
+	}
+
+	generatedPassword := false
+	if sharePassword == autoPasswordValue {
+		pw, err := crypto.GeneratePassword(autoPasswordLength)
+		if err != nil {
+			return fmt.Errorf("failed to generate password: %w", err)
+		}
+		sharePassword = pw
+		generatedPassword = true
+	}
 
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
 	s.Suffix = " Creating share link..."
 	s.Start()
 
+	requestedAt := time.Now()
+
 	// Try to share as a file first
-	result := shareFile(id)
+	result := shareFile(cmd.Context(), id)
 
 	// If file not found, try sharing as a short
 	if !result.success && result.reason == "not_found" {
-		result = shareShort(id)
+		result = shareShort(cmd.Context(), id)
 	}
 
 	s.Stop()
 
 	if result.success {
+		noteHistory(itemResult{ID: id, ShareURL: result.data.ShareURL})
 		displayShareSuccess(result.data)
+		warnIfExpiryRounded(result.data.ExpiresAt, requestedAt)
 
-		// Copy share URL to clipboard
-		if result.data.ShareURL != "" {
-			if err := clipboard.WriteAll(result.data.ShareURL); err == nil {
-				fmt.Println("\n(Share URL copied to clipboard)")
+		// Copy share URL (or the direct link when requested) to clipboard
+		copyURL := result.data.ShareURL
+		if shareDirect && result.data.DirectURL != "" {
+			copyURL = result.data.DirectURL
+		}
+		if generatedPassword {
+			fmt.Fprintln(stdout)
+			fmt.Fprintln(stdout, "Password:")
+			fmt.Fprintln(stdout, sharePassword)
+		}
+		if copyURL != "" && autoCopyEnabled(autoCopyURL) {
+			if shareCombined && sharePassword != "" {
+				msg := fmt.Sprintf("%s\nPassword: %s", copyURL, sharePassword)
+				if err := platform.CopyText(msg); err == nil {
+					fmt.Fprintln(stdout, "\n(Share URL and password copied to clipboard)")
+				}
+			} else if err := platform.CopyText(copyURL); err == nil {
+				fmt.Fprintln(stdout, "\n(Share URL copied to clipboard)")
 			}
 		}
 		if shareQR {
-			printQR(result.data.ShareURL)
+			printQR(copyURL)
+		}
+		if shareEmail != "" {
+			return emailShare(cmd.Context(), result.data, copyURL)
 		}
 		return nil
 	}
@@ -121,167 +192,231 @@ type shareResult struct {
 	success bool
 	reason  string
 	message string
-	data    shareData
-}
-
-type shareData struct {
-	ShareID     string `json:"shareId"`
-	ShareURL    string `json:"shareUrl"`
-	URL         string `json:"url"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	IsPublic    bool   `json:"isPublic"`
-	ExpiresAt   int64  `json:"expiresAt"`
-	Password    string `json:"password"`
-	MaxViews    *int   `json:"maxViews"`
+	data    models.Share
 }
 
-func shareFile(id string) shareResult {
+func shareFile(ctx context.Context, id string) shareResult {
 	body := buildShareBody()
 
-	resp, err := api.Post(fmt.Sprintf("/files/%s/share", id), body)
-	if err != nil {
-		return shareResult{success: false, reason: "error", message: err.Error()}
-	}
-
-	if resp.StatusCode == 403 {
+	resp, err := api.Do(ctx, "POST", fmt.Sprintf("/files/%s/share", id), body)
+	switch {
+	case errors.Is(err, api.ErrForbidden):
 		return shareResult{success: false, reason: "pro_required"}
-	}
-
-	if resp.StatusCode == 404 {
+	case errors.Is(err, api.ErrNotFound):
 		return shareResult{success: false, reason: "not_found"}
+	case err != nil:
+		return shareResult{success: false, reason: "error", message: err.Error()}
 	}
 
-	if resp.StatusCode != 200 && resp.StatusCode != 201 {
-		msg := resp.GetString("message")
-		if msg == "" {
-			msg = resp.GetString("error")
-		}
-		// For server errors (5xx), include status code for clarity
-		if resp.StatusCode >= 500 {
-			if msg == "" {
-				msg = fmt.Sprintf("server error (status %d)", resp.StatusCode)
-			} else {
-				msg = fmt.Sprintf("%s (server error %d)", msg, resp.StatusCode)
-			}
-		} else if msg == "" {
-			msg = fmt.Sprintf("status %d: %s", resp.StatusCode, string(resp.Body))
-		}
-		return shareResult{success: false, reason: "error", message: msg}
-	}
-
-	var data shareData
+	var data models.Share
 	resp.Unmarshal(&data)
 
-	// Handle URL field variations
-	if data.ShareURL == "" && data.URL != "" {
-		data.ShareURL = data.URL
-	}
+	normalizeShareData(&data)
 
 	return shareResult{success: true, data: data}
 }
 
-func shareShort(id string) shareResult {
+func shareShort(ctx context.Context, id string) shareResult {
 	body := buildShareBody()
 
-	resp, err := api.Post(fmt.Sprintf("/shorts/%s/share", id), body)
-	if err != nil {
-		return shareResult{success: false, reason: "error", message: err.Error()}
-	}
-
-	if resp.StatusCode == 403 {
+	resp, err := api.Do(ctx, "POST", fmt.Sprintf("/shorts/%s/share", id), body)
+	switch {
+	case errors.Is(err, api.ErrForbidden):
 		return shareResult{success: false, reason: "pro_required"}
-	}
-
-	if resp.StatusCode == 404 {
+	case errors.Is(err, api.ErrNotFound):
 		return shareResult{success: false, reason: "not_found"}
+	case err != nil:
+		return shareResult{success: false, reason: "error", message: err.Error()}
 	}
 
-	if resp.StatusCode != 200 && resp.StatusCode != 201 {
-		msg := resp.GetString("message")
-		if msg == "" {
-			msg = resp.GetString("error")
-		}
-		// For server errors (5xx), include status code for clarity
-		if resp.StatusCode >= 500 {
-			if msg == "" {
-				msg = fmt.Sprintf("server error (status %d)", resp.StatusCode)
-			} else {
-				msg = fmt.Sprintf("%s (server error %d)", msg, resp.StatusCode)
-			}
-		} else if msg == "" {
-			msg = fmt.Sprintf("status %d: %s", resp.StatusCode, string(resp.Body))
-		}
-		return shareResult{success: false, reason: "error", message: msg}
-	}
-
-	var data shareData
+	var data models.Share
 	resp.Unmarshal(&data)
 
-	// Handle URL field variations
-	if data.ShareURL == "" && data.URL != "" {
-		data.ShareURL = data.URL
-	}
+	normalizeShareData(&data)
 
 	return shareResult{success: true, data: data}
 }
 
-func buildShareBody() map[string]interface{} {
-	isPublic := sharePublic || sharePassword == ""
-	expiresInDays := parseExpiresToDays(shareExpires)
+func buildShareBody() models.ShareRequest {
+	expiresInSeconds, expiresInDays := parseShareExpiry(shareExpires)
 
-	body := map[string]interface{}{
-		"isPublic": isPublic,
+	body := models.ShareRequest{
+		IsPublic:    sharePublic || sharePassword == "",
+		Password:    sharePassword,
+		Title:       shareTitle,
+		Description: shareDesc,
+		MaxViews:    shareMaxViews,
+		Direct:      shareDirect,
 	}
 
 	if sharePassword != "" {
-		body["password"] = sharePassword
-		body["isPublic"] = false
+		body.IsPublic = false
 	}
 
 	if expiresInDays > 0 {
-		body["expiresInDays"] = expiresInDays
+		body.ExpiresInDays = expiresInDays
 	}
 
-	if shareTitle != "" {
-		body["title"] = shareTitle
+	// Backends that understand sub-day expiry use expiresInSeconds and ignore
+	// expiresInDays; older ones fall back to the rounded-up day count.
+	if expiresInSeconds > 0 && expiresInSeconds%86400 != 0 {
+		body.ExpiresInSeconds = expiresInSeconds
 	}
 
-	if shareDesc != "" {
-		body["description"] = shareDesc
-	}
+	return body
+}
 
-	if shareMaxViews > 0 {
-		body["maxViews"] = shareMaxViews
+// normalizeShareData fills ShareURL and DirectURL from the field variations the
+// backend uses. A direct link is only ever one the backend returned.
+func normalizeShareData(data *models.Share) {
+	if data.ShareURL == "" && data.URL != "" {
+		data.ShareURL = data.URL
+	}
+	if data.DirectURL == "" && data.RawURL != "" {
+		data.DirectURL = data.RawURL
 	}
-
-	return body
 }
 
-func parseExpiresToDays(expiresStr string) int {
+// parseShareExpiry converts an --expires value into the exact number of seconds
+// requested plus the whole-day equivalent (rounded up, minimum 1) that older
+// backends expect. An empty or invalid value yields the default expiry.
+func parseShareExpiry(expiresStr string) (seconds, days int) {
 	if expiresStr == "" {
-		return defaultShareExpiryDays
+		return defaultShareExpiryDays * 86400, defaultShareExpiryDays
 	}
 
-	// Handle day format directly
+	// Handle day format directly (allows values beyond the TTL parser's 1y cap)
 	if strings.HasSuffix(expiresStr, "d") {
 		dayStr := strings.TrimSuffix(expiresStr, "d")
-		if days, err := strconv.Atoi(dayStr); err == nil {
-			return days
+		if days, err := strconv.Atoi(dayStr); err == nil && days > 0 {
+			return days * 86400, days
 		}
 	}
 
-	// Convert TTL to days
 	seconds, err := util.ParseTTL(expiresStr)
 	if err != nil {
-		return defaultShareExpiryDays
+		return defaultShareExpiryDays * 86400, defaultShareExpiryDays
 	}
 
-	days := (seconds + 86400 - 1) / 86400 // Round up to nearest day
+	days = (seconds + 86400 - 1) / 86400 // Round up to nearest day
 	if days < 1 {
-		return 1
+		days = 1
+	}
+	return seconds, days
+}
+
+// warnIfExpiryRounded tells the user when the server granted a noticeably
+// longer share lifetime than requested, which happens when the backend only
+// supports whole-day expiry and rounded e.g. "2h" up to one day.
+func warnIfExpiryRounded(expiresAt int64, requestedAt time.Time) {
+	if shareExpires == "" || expiresAt == 0 {
+		return
+	}
+	seconds, _ := parseShareExpiry(shareExpires)
+	wanted := requestedAt.Add(time.Duration(seconds) * time.Second)
+	got := time.Unix(expiresAt, 0)
+	if got.Sub(wanted) > 5*time.Minute {
+		fmt.Fprintf(stderr, "\nNote: requested expiry %s was rounded up by the server; the share expires %s\n",
+			shareExpires, got.Local().Format("Jan 2, 2006 3:04 PM"))
+	}
+}
+
+// allowPasswordPrompt lets a bare --password on cmd ask for the password.
+func allowPasswordPrompt(cmd *cobra.Command) {
+	cmd.Flags().SetAnnotation("password", passwordPromptAnnotation, []string{"true"})
+}
+
+// markBarePassword rewrites a --password given no value (last, or followed by
+// another flag) to --password=promptPasswordValue when the command args run
+// allows it, so it prompts instead of failing or taking the next positional
+// argument as the password. "--password secret" keeps working.
+func markBarePassword(root *cobra.Command, args []string) []string {
+	cmd, _, err := root.Find(args)
+	if err != nil {
+		return args
+	}
+	if f := cmd.Flags().Lookup("password"); f == nil || f.Annotations[passwordPromptAnnotation] == nil {
+		return args
+	}
+	out := slices.Clone(args)
+	for i, arg := range out {
+		if arg == "--" {
+			break
+		}
+		if arg == "--password" && (i == len(out)-1 || (strings.HasPrefix(out[i+1], "-") && out[i+1] != "-")) {
+			out[i] = "--password=" + promptPasswordValue
+		}
+	}
+	return out
+}
+
+// resolvePasswordFlag applies a bare --password or --password-prompt, reading
+// the password from a hidden prompt so it never reaches shell history or ps
+// output.
+func resolvePasswordFlag(password *string, prompt bool) error {
+	if prompt || *password == promptPasswordValue {
+		pw, err := promptSecret("password", true)
+		if err != nil {
+			return err
+		}
+		*password = pw
+	}
+	return nil
+}
+
+// emailShare asks the backend to email the share link. When the backend has no
+// mail endpoint (404 or 501), it falls back to opening a prefilled mailto:
+// link in the user's mail client; any other failure is returned.
+func emailShare(ctx context.Context, share models.Share, link string) error {
+	if share.ShareID == "" {
+		return fmt.Errorf("cannot email the share: the server returned no share ID")
 	}
-	return days
+	body := models.ShareEmailRequest{To: shareEmail, Message: shareEmailMsg}
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " Sending email..."
+	s.Start()
+	_, err := api.Do(ctx, "POST", fmt.Sprintf("/shares/%s/email", share.ShareID), body)
+	s.Stop()
+
+	var apiErr *api.APIError
+	switch {
+	case err == nil:
+		fmt.Fprintf(info, "\nShare link emailed to %s\n", shareEmail)
+		return nil
+	case errors.Is(err, api.ErrNotFound), errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotImplemented:
+		// No mail endpoint on this backend.
+	default:
+		return fmt.Errorf("failed to email the share: %w", err)
+	}
+
+	mailto := buildMailto(shareEmail, share, link)
+	fmt.Fprintln(info, "\nCould not send the email from nikte; opening your mail client instead.")
+	if err := platform.OpenURL(mailto); err != nil {
+		fmt.Fprintln(info, "Open this link to compose the email:")
+		fmt.Fprintln(info, mailto)
+	}
+	return nil
+}
+
+// buildMailto returns a mailto: URL with the subject and body prefilled. The
+// password is deliberately left out so it can travel over a different channel.
+func buildMailto(to string, share models.Share, link string) string {
+	subject := "Shared with you via nikte"
+	if share.Title != "" {
+		subject = share.Title
+	}
+
+	body := link
+	if shareEmailMsg != "" {
+		body = shareEmailMsg + "\n\n" + link
+	}
+
+	q := url.Values{}
+	q.Set("subject", subject)
+	q.Set("body", body)
+	// mailto expects %20 rather than + for spaces
+	return "mailto:" + url.PathEscape(to) + "?" + strings.ReplaceAll(q.Encode(), "+", "%20")
 }
 
 func runShareList(cmd *cobra.Command, args []string) error {
@@ -289,37 +424,23 @@ func runShareList(cmd *cobra.Command, args []string) error {
 	s.Suffix = " Loading shares..."
 	s.Start()
 
-	resp, err := api.Get("/shares")
+	resp, err := api.Do(cmd.Context(), "GET", "/shares", nil)
 	s.Stop()
 	if err != nil {
-		return err
-	}
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("failed to list shares: %s", resp.GetString("message"))
+		return fmt.Errorf("failed to list shares: %w", err)
 	}
 
-	var result struct {
-		Shares []struct {
-			ShareID   string `json:"shareId"`
-			ShareURL  string `json:"shareUrl"`
-			Type      string `json:"type"`
-			Filename  string `json:"filename"`
-			Title     string `json:"title"`
-			ViewCount int    `json:"viewCount"`
-			MaxViews  *int   `json:"maxViews"`
-			ExpiresAt int64  `json:"expiresAt"`
-		} `json:"shares"`
-	}
+	var result models.ShareList
 	if err := resp.Unmarshal(&result); err != nil {
 		return err
 	}
 
 	if len(result.Shares) == 0 {
-		fmt.Println("No shares.")
+		fmt.Fprintln(stdout, "No shares.")
 		return nil
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
+	table := tablewriter.NewWriter(stdout)
 	table.SetHeader([]string{"Share ID", "Type", "Views", "Label", "Expires", "URL"})
 	table.SetBorder(true)
 	table.SetAutoWrapText(false)
@@ -342,30 +463,30 @@ func runShareList(cmd *cobra.Command, args []string) error {
 	}
 	table.Render()
 
-	fmt.Printf("\nTotal: %d shares\n", len(result.Shares))
+	fmt.Fprintf(stdout, "\nTotal: %d shares\n", len(result.Shares))
 	return nil
 }
 
-func displayShareSuccess(share shareData) {
-	fmt.Println("Share created!")
-	fmt.Println()
+func displayShareSuccess(share models.Share) {
+	fmt.Fprintln(stdout, "Share created!")
+	fmt.Fprintln(stdout)
 
-	fmt.Printf("Share ID: %s\n", share.ShareID)
+	fmt.Fprintf(stdout, "Share ID: %s\n", share.ShareID)
 	if share.Title != "" {
-		fmt.Printf("Title: %s\n", share.Title)
+		fmt.Fprintf(stdout, "Title: %s\n", share.Title)
 	}
 	if share.Description != "" {
-		fmt.Printf("Description: %s\n", share.Description)
+		fmt.Fprintf(stdout, "Description: %s\n", share.Description)
 	}
 
 	shareType := "Public"
 	if !share.IsPublic {
 		shareType = "Password Protected"
 	}
-	fmt.Printf("Type: %s\n", shareType)
+	fmt.Fprintf(stdout, "Type: %s\n", shareType)
 
 	if share.ExpiresAt > 0 {
-		fmt.Printf("Expires: %s\n", time.Unix(share.ExpiresAt, 0).Format("Jan 2, 2006 3:04 PM"))
+		fmt.Fprintf(stdout, "Expires: %s\n", time.Unix(share.ExpiresAt, 0).Format("Jan 2, 2006 3:04 PM"))
 	}
 
 	if share.MaxViews != nil && *share.MaxViews > 0 {
@@ -373,10 +494,24 @@ func displayShareSuccess(share shareData) {
 		if *share.MaxViews == 1 {
 			noun = "view"
 		}
-		fmt.Printf("Burn after: %d %s\n", *share.MaxViews, noun)
+		fmt.Fprintf(stdout, "Burn after: %d %s\n", *share.MaxViews, noun)
 	}
 
-	fmt.Println()
-	fmt.Println("Share URL:")
-	fmt.Println(share.ShareURL)
+	fmt.Fprintln(stdout)
+	fmt.Fprintln(stdout, "Share URL:")
+	fmt.Fprintln(stdout, share.ShareURL)
+
+	if share.DirectURL != "" {
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Direct URL:")
+		fmt.Fprintln(stdout, share.DirectURL)
+		if strings.HasPrefix(share.ContentType, "image/") {
+			fmt.Fprintln(stdout)
+			fmt.Fprintln(stdout, "Markdown:")
+			fmt.Fprintf(stdout, "![](%s)\n", share.DirectURL)
+		}
+	} else if shareDirect {
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Direct links aren't available for this share; the server returned only the share page above.")
+	}
 }
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/models"
+)
+
+func TestParseShareExpiry(t *testing.T) {
+	for _, tt := range []struct {
+		in            string
+		seconds, days int
+	}{
+		{"", 86400, 1},
+		{"7d", 7 * 86400, 7},
+		{"400d", 400 * 86400, 400}, // beyond the TTL parser's 1y cap
+		{"30s", 30, 1},
+		{"90m", 5400, 1},
+		{"2h", 7200, 1},
+		{"36h", 36 * 3600, 2}, // rounded up to whole days
+		{"24h", 86400, 1},
+		{"0d", 86400, 1},
+		{"-3d", 86400, 1},
+		{"soon", 86400, 1},
+		{"5w", 86400, 1},
+		{"9000h", 86400, 1},
+	} {
+		seconds, days := parseShareExpiry(tt.in)
+		if seconds != tt.seconds || days != tt.days {
+			t.Errorf("parseShareExpiry(%q) = %d, %d; want %d, %d", tt.in, seconds, days, tt.seconds, tt.days)
+		}
+	}
+}
+
+func TestWarnIfExpiryRounded(t *testing.T) {
+	requested := time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)
+	oldStderr := stderr
+	t.Cleanup(func() { shareExpires, stderr = "", oldStderr })
+	for _, tt := range []struct {
+		expires   string
+		expiresAt time.Time
+		warn      bool
+	}{
+		{"2h", requested.Add(24 * time.Hour), true},
+		{"2h", requested.Add(2 * time.Hour), false},
+		{"2h", requested.Add(2*time.Hour + 4*time.Minute), false}, // clock skew
+		{"7d", requested.Add(7 * 24 * time.Hour), false},
+		{"36h", requested.Add(48 * time.Hour), true},
+		{"", requested.Add(24 * time.Hour), false}, // the default was asked for
+		{"2h", time.Unix(0, 0), false},             // no expiry returned
+	} {
+		var buf bytes.Buffer
+		shareExpires, stderr = tt.expires, &buf
+		warnIfExpiryRounded(tt.expiresAt.Unix(), requested)
+		if got := strings.Contains(buf.String(), "rounded up"); got != tt.warn {
+			t.Errorf("expires %q, got %s: warned = %v, want %v (%q)", tt.expires, tt.expiresAt.Sub(requested), got, tt.warn, buf.String())
+		}
+	}
+}
+
+func TestPasswordFlagKeepsPositionalArgs(t *testing.T) {
+	n := newTestNK(t)
+	if _, errOut, code := n.run("a", "--password", "hunter2", "my note", "--permanent"); code != 0 {
+		t.Fatalf("nk a --password hunter2 \"my note\": exit code %d: %s", code, errOut)
+	}
+	if out, _, _ := n.run("cat", "t1"); out != "my note" {
+		t.Errorf("content = %q, want the positional argument", out)
+	}
+	if _, errOut, code := n.run("sh", "--password", "secret", "t1"); code != 0 {
+		t.Errorf("nk sh --password secret t1: exit code %d: %s", code, errOut)
+	}
+}
+
+func TestEmailShareReturnsErrors(t *testing.T) {
+	status := http.StatusAccepted
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/shares/s1/email" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	defer api.SetBaseURL(api.SetBaseURL(srv.URL))
+	var out bytes.Buffer
+	savedInfo := info
+	info = &out
+	defer func() { info = savedInfo }()
+	shareEmail = "bob@example.com"
+	defer func() { shareEmail = "" }()
+
+	share := models.Share{ShareID: "s1"}
+	if err := emailShare(context.Background(), share, "https://nikte.co/s/s1"); err != nil {
+		t.Fatalf("202: %v", err)
+	}
+	if !strings.Contains(out.String(), "emailed to bob@example.com") {
+		t.Errorf("202: printed %q", out.String())
+	}
+
+	// Only a missing endpoint falls back to a mailto: link.
+	for _, status = range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError} {
+		out.Reset()
+		if err := emailShare(context.Background(), share, "https://nikte.co/s/s1"); err == nil {
+			t.Errorf("%d: no error", status)
+		}
+		if strings.Contains(out.String(), "mailto:") {
+			t.Errorf("%d: fell back to a mailto: link", status)
+		}
+	}
+
+	if err := emailShare(context.Background(), models.Share{}, "https://nikte.co/s/s1"); err == nil {
+		t.Error("empty share ID: no error")
+	}
+}
+
+func TestBarePasswordFlagPrompts(t *testing.T) {
+	n := newTestNK(t)
+	n.run("a", "hello", "--permanent")
+	for _, args := range [][]string{
+		{"sh", "t1", "--password"},
+		{"sh", "t1", "--password", "--expires", "7d"},
+		{"a", "note", "--password"},
+	} {
+		// Tests have no terminal, so reaching the prompt fails.
+		_, errOut, code := n.run(args...)
+		if code == 0 || !strings.Contains(errOut, "cannot prompt for password") {
+			t.Errorf("nk %s: exit code %d, stderr %q; want a password prompt", strings.Join(args, " "), code, errOut)
+		}
+	}
+	if _, errOut, code := n.run("sh", "t1", "--password", "pw"); code != 0 {
+		t.Errorf("nk sh t1 --password pw: exit code %d: %s", code, errOut)
+	}
+}
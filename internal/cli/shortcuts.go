@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -15,7 +16,7 @@ func addShortcutCommands() {
 		Short: "Quick add from clipboard (alias for \"nk a\")",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-			return handleClipboard(s)
+			return handleClipboard(cmd.Context(), s)
 		},
 	}
 
@@ -36,7 +37,7 @@ func addShortcutCommands() {
 		Short: "Quick screenshot (alias for \"nk a sc\")",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-			return handleScreenshot(s)
+			return handleScreenshot(cmd.Context(), s)
 		},
 	}
 
@@ -44,8 +45,11 @@ func addShortcutCommands() {
 	scCmd.Flags().StringVar(&addTTL, "ttl", defaultTTL, "Custom TTL (e.g., 1h, 7d)")
 	scCmd.Flags().BoolVarP(&addPublic, "public", "p", false, "Create public share on add (Pro)")
 	scCmd.Flags().StringVar(&addPassword, "password", "", "Password-protected share (Pro)")
-	scCmd.Flags().BoolVarP(&addWindow, "window", "w", false, "Capture specific window")
+	scCmd.Flags().StringVarP(&addWindow, "window", "w", "", "Capture a window (=N, =name or =list to skip picking)")
+	scCmd.Flags().Lookup("window").NoOptDefVal = windowPick
 	scCmd.Flags().BoolVarP(&addFullscreen, "fullscreen", "f", false, "Capture full screen")
+	scCmd.Flags().StringVar(&addDisplay, "display", "", "Capture display N in full (\"list\" shows them)")
+	addRegionFlags(scCmd)
 	scCmd.Flags().StringVar(&addWatch, "watch", "", "Continuous capture mode (optional: interval in seconds)")
 	scCmd.Flags().BoolVar(&addQR, "qr", false, "Print a scannable QR code of the share URL (with --public/--password)")
 	scCmd.Flags().IntVar(&addMaxViews, "max-views", 0, "Burn-after-read: delete the share after N views (with --public/--password)")
@@ -68,19 +72,20 @@ func addShortcutCommands() {
 	pCmd.Flags().StringVar(&shareDesc, "desc", "", "Share description for social previews")
 	pCmd.Flags().BoolVar(&shareQR, "qr", false, "Print a scannable QR code of the share URL")
 	pCmd.Flags().IntVar(&shareMaxViews, "max-views", 0, "Burn-after-read: delete the link after N views")
+	pCmd.Flags().BoolVar(&shareDirect, "direct", false, "Return a raw link that serves the content directly (for embedding)")
 
 	rootCmd.AddCommand(pCmd)
 }
 
-func handleScreenshotShortcut(s *spinner.Spinner) error {
+func handleScreenshotShortcut(ctx context.Context, s *spinner.Spinner) error {
 	if !platform.IsScreenshotSupported() {
 		return errScreenshotNotSupported
 	}
 
-	return handleScreenshot(s)
+	return handleScreenshot(ctx, s)
 }
 
-var errScreenshotNotSupported = &screenshotError{msg: "screenshot capture is only supported on macOS"}
+var errScreenshotNotSupported = &screenshotError{msg: platform.ErrScreenshotUnsupported.Error()}
 
 type screenshotError struct {
 	msg string
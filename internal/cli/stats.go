@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// statsJSON is the --json flag of `nk stats`.
+var statsJSON bool
+
+// statsLargest is how many of the largest items `nk stats` lists.
+const statsLargest = 5
+
+// usageStats is the summary `nk stats` prints.
+type usageStats struct {
+	Items       int                  `json:"items"`
+	Bytes       int64                `json:"bytes"`
+	ByType      map[string]typeStats `json:"byType"`
+	Expiring24h typeStats            `json:"expiring24h"`
+	Expiring7d  typeStats            `json:"expiring7d"`
+	Permanent   int                  `json:"permanent"`
+	Largest     []Item               `json:"largest"`
+	Quota       *quotaStats          `json:"quota,omitempty"`
+}
+
+// typeStats counts items and their bytes.
+type typeStats struct {
+	Count int   `json:"count"`
+	Bytes int64 `json:"bytes"`
+}
+
+func (t *typeStats) add(item Item) {
+	t.Count++
+	t.Bytes += item.Size
+}
+
+// quotaStats is the account's storage quota, from GET /account.
+type quotaStats struct {
+	Plan      string `json:"plan,omitempty"`
+	Used      int64  `json:"used"`
+	Total     int64  `json:"total"`
+	Remaining int64  `json:"remaining"`
+}
+
+func addStatsCommand() {
+	statsCmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Summarize account usage",
+		Long: `Summarize account usage: items and bytes by type, what expires soon,
+the largest items, and the storage quota remaining.
+
+Examples:
+  nk stats                    Usage dashboard
+    └ --json                   JSON output for scripting`,
+		Args: cobra.NoArgs,
+		RunE: runStats,
+	}
+
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Output as JSON")
+
+	rootCmd.AddCommand(statsCmd)
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " Fetching items..."
+	s.Start()
+
+	fetched := fetchAllItems(cmd.Context())
+	quota := fetchQuota(cmd.Context())
+
+	s.Stop()
+	if fetched.err != nil && len(fetched.items) == 0 {
+		return fetched.err
+	}
+	if fetched.stale || fetched.err != nil {
+		fmt.Fprintln(stderr, "Offline: counting cached items, which may be out of date.")
+	}
+
+	stats := computeStats(fetched.items, time.Now())
+	stats.Quota = quota
+
+	if statsJSON && !machineOutput() {
+		outputMode = outputJSON
+	}
+	if machineOutput() {
+		return emitResult(stats)
+	}
+	printStats(stats)
+	return nil
+}
+
+// computeStats summarizes items as of now.
+func computeStats(items []Item, now time.Time) usageStats {
+	stats := usageStats{ByType: map[string]typeStats{}, Largest: []Item{}}
+	day := now.Add(24 * time.Hour).Unix()
+	week := now.Add(7 * 24 * time.Hour).Unix()
+	for _, item := range items {
+		stats.Items++
+		stats.Bytes += item.Size
+		t := stats.ByType[item.Type]
+		t.add(item)
+		stats.ByType[item.Type] = t
+
+		switch {
+		case item.ExpiresAt == 0:
+			stats.Permanent++
+		case item.ExpiresAt <= day:
+			stats.Expiring24h.add(item)
+			stats.Expiring7d.add(item)
+		case item.ExpiresAt <= week:
+			stats.Expiring7d.add(item)
+		}
+	}
+
+	sorted := make([]Item, 0, len(items))
+	for _, item := range items {
+		if item.Size > 0 {
+			sorted = append(sorted, item)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Size > sorted[j].Size })
+	if len(sorted) > statsLargest {
+		sorted = sorted[:statsLargest]
+	}
+	stats.Largest = append(stats.Largest, sorted...)
+	return stats
+}
+
+// fetchQuota returns the account's storage quota, or nil if it is unknown.
+// It's informational only, so failures just leave it out.
+func fetchQuota(ctx context.Context) *quotaStats {
+	resp, err := api.GetContext(ctx, "/account")
+	if err != nil || resp.StatusCode != 200 {
+		return nil
+	}
+	var info accountInfo
+	if err := resp.Unmarshal(&info); err != nil || info.StorageQuota <= 0 {
+		return nil
+	}
+	return &quotaStats{
+		Plan:      info.Plan,
+		Used:      info.StorageUsed,
+		Total:     info.StorageQuota,
+		Remaining: max(info.StorageQuota-info.StorageUsed, 0),
+	}
+}
+
+func printStats(stats usageStats) {
+	fmt.Fprintln(stdout)
+	fmt.Fprintf(stdout, "Items: %d (%s)\n", stats.Items, util.FormatBytes(stats.Bytes))
+	for _, kind := range []struct{ typ, label string }{
+		{"text", "Text"}, {"file", "Files"}, {"screenshot", "Screenshots"}, {"profile", "Pro files"},
+	} {
+		if t, ok := stats.ByType[kind.typ]; ok {
+			fmt.Fprintf(stdout, "  %-12s %5d  %s\n", kind.label+":", t.Count, util.FormatBytes(t.Bytes))
+		}
+	}
+
+	fmt.Fprintln(stdout, "\nExpiring:")
+	fmt.Fprintf(stdout, "  %-12s %5d  %s\n", "in 24h:", stats.Expiring24h.Count, util.FormatBytes(stats.Expiring24h.Bytes))
+	fmt.Fprintf(stdout, "  %-12s %5d  %s\n", "in 7 days:", stats.Expiring7d.Count, util.FormatBytes(stats.Expiring7d.Bytes))
+	fmt.Fprintf(stdout, "  %-12s %5d\n", "never:", stats.Permanent)
+
+	if len(stats.Largest) > 0 {
+		fmt.Fprintln(stdout, "\nLargest:")
+		for _, item := range stats.Largest {
+			name := item.Filename
+			if name == "" {
+				name = item.Preview
+			}
+			fmt.Fprintf(stdout, "  %-10s %9s  %s\n", item.ID, util.FormatBytes(item.Size), util.Truncate(util.ReplaceNewlines(name), 40))
+		}
+	}
+
+	if q := stats.Quota; q != nil {
+		pct := float64(q.Used) / float64(q.Total) * 100
+		fmt.Fprintf(stdout, "\nStorage: %s of %s used (%.1f%%), %s remaining\n",
+			util.FormatBytes(q.Used), util.FormatBytes(q.Total), pct, util.FormatBytes(q.Remaining))
+		fmt.Fprintf(stdout, "         %s\n", util.CreateProgressBar(q.Used, q.Total, 30))
+	}
+}
@@ -0,0 +1,361 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// Flags of `nk sync`.
+var (
+	syncDelete    bool
+	syncWatch     bool
+	syncInterval  time.Duration
+	syncTTL       string
+	syncPermanent bool
+	syncDryRun    bool
+)
+
+// syncSettle is how long a file must go unmodified before --watch uploads
+// it, so files still being written aren't uploaded half done.
+const syncSettle = 2 * time.Second
+
+// syncState is what `nk sync` has uploaded from a folder, keyed by path
+// relative to it.
+type syncState struct {
+	Dir   string                `json:"dir"`
+	Files map[string]syncedFile `json:"files"`
+
+	file string
+}
+
+// syncedFile is the item a file was last uploaded as. Size and ModTime let
+// unchanged files be skipped without hashing them again.
+type syncedFile struct {
+	ID        string    `json:"id"`
+	SHA256    string    `json:"sha256"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"modTime"`
+	ExpiresAt int64     `json:"expiresAt,omitempty"`
+	SyncedAt  time.Time `json:"syncedAt"`
+}
+
+// syncSummary counts what a sync pass did.
+type syncSummary struct {
+	Uploaded  int `json:"uploaded"`
+	Updated   int `json:"updated"`
+	Deleted   int `json:"deleted"`
+	Unchanged int `json:"unchanged"`
+	Failed    int `json:"failed"`
+}
+
+func addSyncCommand() {
+	syncCmd := &cobra.Command{
+		Use:   "sync <dir>",
+		Short: "Upload new and changed files in a folder",
+		Long: `Keep a folder uploaded: files that are new or changed since the last sync
+are uploaded, and with --delete the items of files since deleted or
+replaced are removed. Sync is one way; nothing is downloaded.
+
+What was uploaded is tracked per folder and profile in the config directory.
+Items that have expired are uploaded again.
+
+Examples:
+  nk sync ~/notes                       Upload what changed since last time
+    ├ --delete                           Also delete items of removed or replaced files
+    ├ --watch                            Keep syncing until interrupted
+    ├ --ttl 7d | --permanent             TTL of uploaded items (default 24h)
+    └ --dry-run                          Show what would change`,
+		Args: cobra.ExactArgs(1),
+		RunE: runSync,
+	}
+
+	syncCmd.Flags().BoolVar(&syncDelete, "delete", false, "Delete the items of files that were removed or replaced")
+	syncCmd.Flags().BoolVar(&syncWatch, "watch", false, "Keep syncing until interrupted")
+	syncCmd.Flags().DurationVar(&syncInterval, "interval", 5*time.Second, "How often --watch checks the folder")
+	syncCmd.Flags().StringVar(&syncTTL, "ttl", "", "TTL of uploaded items (e.g. 7d)")
+	syncCmd.Flags().BoolVar(&syncPermanent, "permanent", false, "Make uploaded items permanent")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "List what would be uploaded or deleted")
+
+	rootCmd.AddCommand(syncCmd)
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if syncTTL != "" && syncPermanent {
+		return fmt.Errorf("cannot use both --ttl and --permanent together")
+	}
+	if syncTTL != "" {
+		if _, err := util.ParseTTL(syncTTL); err != nil {
+			return fmt.Errorf("--ttl: %w", err)
+		}
+	}
+	if syncWatch && syncDryRun {
+		return fmt.Errorf("cannot use both --watch and --dry-run together")
+	}
+	if syncInterval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s")
+	}
+
+	dir, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return fmt.Errorf("%s is not a directory", args[0])
+	}
+	state, err := loadSyncState(dir)
+	if err != nil {
+		return err
+	}
+
+	// Uploads take their TTL from the add flags.
+	addTTL, addPermanent = syncTTL, syncPermanent
+
+	if !syncWatch {
+		sum := syncPass(ctx, state)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if machineOutput() {
+			if err := emitResult(sum); err != nil {
+				return err
+			}
+		} else if !syncDryRun {
+			printSyncSummary(sum)
+		}
+		if sum.Failed > 0 {
+			return fmt.Errorf("%d files failed to sync", sum.Failed)
+		}
+		return nil
+	}
+
+	fmt.Fprintf(info, "Watching %s (Ctrl+C to stop)\n", dir)
+	tick := time.NewTicker(syncInterval)
+	defer tick.Stop()
+	for {
+		sum := syncPass(ctx, state)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if sum.Uploaded+sum.Updated+sum.Deleted+sum.Failed > 0 {
+			printSyncSummary(sum)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick.C:
+		}
+	}
+}
+
+func printSyncSummary(sum syncSummary) {
+	msg := fmt.Sprintf("Synced: %d uploaded, %d updated, %d unchanged", sum.Uploaded, sum.Updated, sum.Unchanged)
+	if syncDelete {
+		msg += fmt.Sprintf(", %d deleted", sum.Deleted)
+	}
+	if sum.Failed > 0 {
+		msg += fmt.Sprintf(", %d failed", sum.Failed)
+	}
+	fmt.Fprintln(info, msg)
+}
+
+// syncPass brings the items in line with the folder once: it uploads new,
+// changed and expired files and forgets (or with --delete, deletes) the
+// items of files that are gone. State is saved after every change, so an
+// interrupted pass loses nothing.
+func syncPass(ctx context.Context, state *syncState) syncSummary {
+	var sum syncSummary
+	files, err := collectUploadFiles([]string{state.Dir})
+	if err != nil {
+		fmt.Fprintf(info, "✗ %v\n", err)
+		sum.Failed++
+		return sum
+	}
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	present := map[string]bool{}
+	for _, path := range files {
+		if ctx.Err() != nil {
+			return sum
+		}
+		rel, _ := filepath.Rel(state.Dir, path)
+		rel = filepath.ToSlash(rel)
+		present[rel] = true
+
+		fi, err := os.Stat(path)
+		if err != nil || fi.Size() == 0 {
+			continue
+		}
+		if syncWatch && time.Since(fi.ModTime()) < syncSettle {
+			// Still being written; look again next pass.
+			continue
+		}
+		prev, known := state.Files[rel]
+		expired := known && prev.ExpiresAt != 0 && prev.ExpiresAt <= time.Now().Unix()
+		if known && !expired && prev.Size == fi.Size() && prev.ModTime.Equal(fi.ModTime()) {
+			sum.Unchanged++
+			continue
+		}
+		hash, _, err := hashFile(path)
+		if err != nil {
+			fmt.Fprintf(info, "✗ %s: %v\n", rel, err)
+			sum.Failed++
+			continue
+		}
+		if known && !expired && prev.SHA256 == hash {
+			// Touched but not changed.
+			if !syncDryRun {
+				prev.ModTime = fi.ModTime()
+				state.Files[rel] = prev
+				state.save()
+			}
+			sum.Unchanged++
+			continue
+		}
+
+		if syncDryRun {
+			if known && !expired {
+				fmt.Fprintf(info, "Would update %s (%s)\n", rel, prev.ID)
+			} else {
+				fmt.Fprintf(info, "Would upload %s\n", rel)
+			}
+			continue
+		}
+
+		s.Suffix = fmt.Sprintf(" Uploading %s...", rel)
+		s.Start()
+		res, err := uploadFile(ctx, path, &batchReporter{s: s, name: rel})
+		s.Stop()
+		if err != nil {
+			if ctx.Err() != nil {
+				return sum
+			}
+			fmt.Fprintf(info, "✗ %s: %v\n", rel, err)
+			sum.Failed++
+			continue
+		}
+		if res.mismatch {
+			// Not recorded, so the next pass uploads it again.
+			fmt.Fprintf(info, "✗ %s: stored file %s failed verification\n", rel, res.ID)
+			sum.Failed++
+			continue
+		}
+		state.Files[rel] = syncedFile{ID: res.ID, SHA256: hash, Size: fi.Size(), ModTime: fi.ModTime(),
+			ExpiresAt: res.ExpiresAt, SyncedAt: time.Now().UTC()}
+		if err := state.save(); err != nil {
+			fmt.Fprintf(stderr, "✗ failed to save sync state: %v\n", err)
+		}
+		switch {
+		case !known || expired:
+			sum.Uploaded++
+			fmt.Fprintf(info, "✓ %s → %s\n", rel, res.ID)
+		default:
+			sum.Updated++
+			fmt.Fprintf(info, "✓ %s → %s (was %s)\n", rel, res.ID, prev.ID)
+			if syncDelete && prev.ID != res.ID && syncRemove(ctx, prev.ID, "old "+rel) {
+				sum.Deleted++
+			}
+		}
+	}
+
+	var gone []string
+	for rel := range state.Files {
+		if !present[rel] {
+			gone = append(gone, rel)
+		}
+	}
+	sort.Strings(gone)
+	for _, rel := range gone {
+		id := state.Files[rel].ID
+		if syncDryRun {
+			if syncDelete {
+				fmt.Fprintf(info, "Would delete %s (%s)\n", id, rel)
+			}
+			continue
+		}
+		if syncDelete {
+			if !syncRemove(ctx, id, rel) {
+				sum.Failed++
+				continue
+			}
+			sum.Deleted++
+		}
+		delete(state.Files, rel)
+		state.save()
+	}
+	return sum
+}
+
+// syncRemove deletes the item id of the file rel, reporting whether it is
+// gone. An item already deleted or expired counts as gone.
+func syncRemove(ctx context.Context, id, rel string) bool {
+	result := deleteItem(ctx, id, true)
+	if !result.success && result.error != "not_found" {
+		fmt.Fprintf(stderr, "✗ failed to delete %s (%s): %s\n", id, rel, result.error)
+		return false
+	}
+	fmt.Fprintf(info, "✓ deleted %s (%s)\n", id, rel)
+	return true
+}
+
+// syncStateFile returns where the state for dir is kept. State is kept per
+// profile, since item IDs belong to one account.
+func syncStateFile(dir string) (string, error) {
+	stateDir, err := config.SyncStateDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(config.ActiveProfile() + "\x00" + dir))
+	return filepath.Join(stateDir, hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// loadSyncState returns the state for dir, empty if it was never synced.
+func loadSyncState(dir string) (*syncState, error) {
+	file, err := syncStateFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	state := &syncState{Dir: dir, Files: map[string]syncedFile{}, file: file}
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("bad sync state %s: %w", file, err)
+	}
+	if state.Files == nil {
+		state.Files = map[string]syncedFile{}
+	}
+	state.Dir = dir
+	return state, nil
+}
+
+func (s *syncState) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.file), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.file)
+}
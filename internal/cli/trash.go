@@ -0,0 +1,378 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/models"
+	"github.com/sim4gh/nikte-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// Flags of `nk trash` and `nk restore`.
+var (
+	trashJSON        bool
+	trashForce       bool
+	restoreTTL       string
+	restorePermanent bool
+)
+
+// trashRetention is how long deleted items are kept in the trash.
+const trashRetention = 30 * 24 * time.Hour
+
+// trashEntry is what `nk delete` keeps of an item it deleted: its metadata,
+// and for text its content (still encrypted if it was), so it can be
+// restored as a new item. The content of files and screenshots is gone with
+// the item.
+type trashEntry struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	Filename    string    `json:"filename,omitempty"`
+	Size        int64     `json:"size,omitempty"`
+	ContentType string    `json:"contentType,omitempty"`
+	CreatedAt   string    `json:"createdAt,omitempty"`
+	ExpiresAt   int64     `json:"expiresAt,omitempty"`
+	DeletedAt   time.Time `json:"deletedAt"`
+	Aliases     []string  `json:"aliases,omitempty"`
+	Content     string    `json:"content,omitempty"`
+}
+
+// restorable reports whether the entry's content was kept.
+func (e trashEntry) restorable() bool {
+	return e.Type == "text" && e.Content != ""
+}
+
+// trashProfileDir returns the active profile's trash directory.
+func trashProfileDir() (string, error) {
+	dir, err := config.TrashDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, config.ActiveProfile()), nil
+}
+
+// trashEnabled reports whether deleted items are kept in the trash, which
+// the trash config key turns on.
+func trashEnabled() bool {
+	cfg := config.Get()
+	return cfg != nil && cfg.Trash == "on"
+}
+
+// deleteItem deletes id like tryDelete and removes its aliases. With the
+// trash on and keep set, the item is looked up first and kept in the trash
+// once deleted. Every command that deletes items for the user goes through
+// here.
+func deleteItem(ctx context.Context, id string, keep bool) deleteResult {
+	var trashed *trashEntry
+	if keep && trashEnabled() {
+		trashed = trashItemBeforeDelete(ctx, id)
+	}
+	result := tryDelete(ctx, id)
+	if !result.success {
+		return result
+	}
+	result.aliases, _ = config.RemoveAliasesFor(id)
+	if trashed != nil {
+		if result.trashErr = putInTrash(trashed, result.aliases); result.trashErr == nil {
+			result.trashed = trashed
+		}
+	}
+	return result
+}
+
+// trashItemBeforeDelete looks id up so that, once deleted, it can be put in
+// the trash with putInTrash. It returns nil when the item can't be read.
+func trashItemBeforeDelete(ctx context.Context, id string) *trashEntry {
+	res, d, err := lookupItem(ctx, id)
+	if err != nil {
+		return nil
+	}
+	e := &trashEntry{ID: id, Type: res.Type, Filename: res.Filename, Size: res.Size,
+		ContentType: res.ContentType, CreatedAt: res.CreatedAt, ExpiresAt: res.ExpiresAt}
+	if res.Type == "text" && d.Content != "" {
+		e.Content = d.Content
+		e.Size = int64(len(d.Content))
+	}
+	return e
+}
+
+// putInTrash saves e, now deleted, and drops entries past trashRetention.
+func putInTrash(e *trashEntry, aliases []string) error {
+	dir, err := trashProfileDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	e.DeletedAt = time.Now().UTC()
+	e.Aliases = aliases
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, e.ID+".json"), data, 0o600); err != nil {
+		return err
+	}
+
+	entries, _ := readTrash()
+	for _, old := range entries {
+		if time.Since(old.DeletedAt) > trashRetention {
+			os.Remove(filepath.Join(dir, old.ID+".json"))
+		}
+	}
+	return nil
+}
+
+// readTrash returns the active profile's trash, most recently deleted first.
+func readTrash() ([]trashEntry, error) {
+	dir, err := trashProfileDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []trashEntry
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			continue
+		}
+		var e trashEntry
+		if json.Unmarshal(data, &e) == nil && e.ID != "" {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].DeletedAt.After(entries[j].DeletedAt) })
+	return entries, nil
+}
+
+func addTrashCommands() {
+	trashCmd := &cobra.Command{
+		Use:   "trash",
+		Short: "List items deleted with nk delete",
+		Long: `List items deleted in the last 30 days. Text items can be restored with
+nk restore, as new items; for files and screenshots only their details are
+kept, since their content is deleted on the server.
+
+The trash is off unless turned on with: nk config set trash on
+It is kept per profile in the config directory. Text is stored as it was
+on the server (encrypted items stay encrypted), so password-protected
+shares' content ends up on disk too; nk delete --no-trash keeps nothing.
+
+Examples:
+  nk trash                               What was deleted, and whether it can come back
+  nk restore t1                          Restore text item t1 (it gets a new ID)
+  nk trash empty                         Forget everything in the trash`,
+		Args: cobra.NoArgs,
+		RunE: runTrashList,
+	}
+	trashCmd.Flags().BoolVar(&trashJSON, "json", false, "Output as JSON")
+
+	emptyCmd := &cobra.Command{
+		Use:   "empty",
+		Short: "Delete everything in the trash",
+		Args:  cobra.NoArgs,
+		RunE:  runTrashEmpty,
+	}
+	emptyCmd.Flags().BoolVarP(&trashForce, "force", "f", false, "Skip confirmation")
+	trashCmd.AddCommand(emptyCmd)
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore <id>...",
+		Short: "Re-upload deleted text items from the trash",
+		Long: `Re-upload text items from the trash as new items, with their old aliases
+and what was left of their lifetime (or --ttl / --permanent). Files and
+screenshots can't be restored; their content was deleted on the server.
+
+Examples:
+  nk restore t1                          Restore one item; prints its new ID
+  nk restore t1 t2 --ttl 7d              Restore two, for 7 days`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runRestore,
+	}
+	restoreCmd.Flags().StringVar(&restoreTTL, "ttl", "", "TTL of the restored items (e.g. 7d)")
+	restoreCmd.Flags().BoolVar(&restorePermanent, "permanent", false, "Make the restored items permanent")
+
+	rootCmd.AddCommand(trashCmd, restoreCmd)
+}
+
+func runTrashList(cmd *cobra.Command, args []string) error {
+	if trashJSON && !machineOutput() {
+		outputMode = outputJSON
+	}
+	entries, err := readTrash()
+	if err != nil {
+		return err
+	}
+	if machineOutput() {
+		if entries == nil {
+			entries = []trashEntry{}
+		}
+		return emitResult(entries)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(stdout, "The trash is empty")
+		return nil
+	}
+	for _, e := range entries {
+		name := e.Filename
+		if e.Type == "text" {
+			name = util.Truncate(util.ReplaceNewlines(e.Content), 40)
+		}
+		restore := "restorable"
+		if !e.restorable() {
+			restore = "details only"
+		}
+		fmt.Fprintf(stdout, "%-10s  %-10s  %9s  deleted %s  %-12s  %s\n", e.ID, e.Type, util.FormatBytes(e.Size),
+			e.DeletedAt.Local().Format("2006-01-02 15:04"), restore, name)
+	}
+	return nil
+}
+
+func runTrashEmpty(cmd *cobra.Command, args []string) error {
+	entries, err := readTrash()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(stdout, "The trash is empty")
+		return nil
+	}
+	if !trashForce {
+		fmt.Fprintf(stdout, "Forget the %d deleted items in the trash for good? [y/N]: ", len(entries))
+		response, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return err
+		}
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			fmt.Fprintln(stdout, "Cancelled")
+			return nil
+		}
+	}
+	dir, err := trashProfileDir()
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "✓ Emptied the trash")
+	return nil
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if restoreTTL != "" && restorePermanent {
+		return fmt.Errorf("cannot use both --ttl and --permanent together")
+	}
+	if restoreTTL != "" {
+		if _, err := util.ParseTTL(restoreTTL); err != nil {
+			return fmt.Errorf("--ttl: %w", err)
+		}
+	}
+	entries, err := readTrash()
+	if err != nil {
+		return err
+	}
+	byID := map[string]trashEntry{}
+	for _, e := range entries {
+		byID[e.ID] = e
+	}
+	dir, err := trashProfileDir()
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, id := range args {
+		e, ok := byID[id]
+		switch {
+		case !ok:
+			fmt.Fprintf(stdout, "✗ %s is not in the trash\n", id)
+			failed++
+			continue
+		case !e.restorable():
+			name := e.Filename
+			if name == "" {
+				name = e.Type
+			}
+			fmt.Fprintf(stdout, "✗ %s (%s) can't be restored: the content of a %s is deleted with it\n", id, name, e.Type)
+			failed++
+			continue
+		}
+
+		newID, err := restoreText(ctx, e)
+		if err != nil {
+			fmt.Fprintf(stdout, "✗ %s: %v\n", id, err)
+			failed++
+			continue
+		}
+		os.Remove(filepath.Join(dir, id+".json"))
+		msg := fmt.Sprintf("✓ Restored %s as %s", id, newID)
+		for _, name := range e.Aliases {
+			if _, err := config.SetAlias(name, newID); err == nil {
+				msg += fmt.Sprintf(", alias %s", name)
+			}
+		}
+		fmt.Fprintln(stdout, msg)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d items not restored", failed, len(args))
+	}
+	return nil
+}
+
+// restoreText uploads the content of the trashed text item e as a new item
+// and returns its ID.
+func restoreText(ctx context.Context, e trashEntry) (string, error) {
+	ttl, permanent := remainingLifetime(e.ExpiresAt)
+	switch {
+	case restorePermanent:
+		permanent = true
+	case restoreTTL != "":
+		ttl, permanent = restoreTTL, false
+	}
+	ttlSeconds := 0
+	if !permanent {
+		n, err := util.ParseTTL(ttl)
+		if err != nil {
+			return "", err
+		}
+		ttlSeconds = n
+	}
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = fmt.Sprintf(" Restoring %s...", e.ID)
+	s.Start()
+	defer s.Stop()
+	resp, err := api.Do(ctx, "POST", "/shorts", models.CreateShortRequest{Content: e.Content, TTL: &ttlSeconds})
+	if err != nil {
+		return "", err
+	}
+	var created models.CreateShortResponse
+	if err := resp.Unmarshal(&created); err != nil {
+		return "", err
+	}
+	return created.ShortID, nil
+}
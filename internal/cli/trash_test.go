@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrashIsOptIn(t *testing.T) {
+	n := newTestNK(t)
+	t.Cleanup(func() {
+		n.run("config", "unset", "trash")
+		n.run("trash", "empty", "-f")
+	})
+	n.run("a", "first", "--permanent")
+	n.run("a", "second", "--permanent")
+
+	if out, errOut, code := n.run("d", "t1", "-f"); code != 0 || strings.Contains(out, "nk restore") {
+		t.Fatalf("nk d t1 with the trash off: exit code %d\n%s%s", code, out, errOut)
+	}
+	if out, _, _ := n.run("trash"); strings.Contains(out, "t1") {
+		t.Errorf("t1 was kept with the trash off:\n%s", out)
+	}
+
+	n.run("config", "set", "trash", "on")
+	if out, errOut, code := n.run("d", "t2", "-f"); code != 0 || !strings.Contains(out, "nk restore t2") {
+		t.Fatalf("nk d t2 with the trash on: exit code %d\n%s%s", code, out, errOut)
+	}
+	if out, _, _ := n.run("trash"); !strings.Contains(out, "t2") {
+		t.Errorf("t2 is not in the trash:\n%s", out)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/briandowns/spinner"
 	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/models"
 	"github.com/sim4gh/nikte-cli/internal/util"
 	"github.com/spf13/cobra"
 )
@@ -73,50 +74,38 @@ func runTrustYou(cmd *cobra.Command, args []string) error {
 	s.Suffix = " Creating upload link..."
 	s.Start()
 
-	body := map[string]interface{}{
-		"title":          trustTitle,
-		"maxFileSize":    maxFileSize,
-		"maxSubmissions": trustMax,
-		"expiresInHours": expiresInHours,
-	}
-	if trustPassword != "" {
-		body["password"] = trustPassword
-	}
-	if trustFrom != "" {
-		body["ownerLabel"] = trustFrom
+	body := models.RequestLinkRequest{
+		Title:          trustTitle,
+		MaxFileSize:    maxFileSize,
+		MaxSubmissions: trustMax,
+		ExpiresInHours: expiresInHours,
+		Password:       trustPassword,
+		OwnerLabel:     trustFrom,
 	}
 
-	resp, err := api.Post("/request-links", body)
+	resp, err := api.Do(cmd.Context(), "POST", "/request-links", body)
 	s.Stop()
-
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to create upload link: %w", err)
 	}
 
-	if resp.StatusCode != 200 && resp.StatusCode != 201 {
-		msg := resp.GetString("message")
-		if msg == "" {
-			msg = resp.GetString("error")
-		}
-		if msg == "" {
-			msg = fmt.Sprintf("unexpected status %d", resp.StatusCode)
-		}
-		return fmt.Errorf("failed to create upload link: %s", msg)
+	var link models.RequestLink
+	if err := resp.Unmarshal(&link); err != nil {
+		return err
 	}
-
-	uploadURL := resp.GetString("url")
-
-	fmt.Println("Upload link created!")
-	fmt.Println()
-	fmt.Printf("Upload link:  %s\n", uploadURL)
-	fmt.Printf("Max uploads:  %d\n", trustMax)
-	fmt.Printf("Max file size: %s\n", util.FormatBytes(maxFileSize))
-	fmt.Printf("Expires:      in %dh\n", expiresInHours)
+	uploadURL := link.URL
+
+	fmt.Fprintln(stdout, "Upload link created!")
+	fmt.Fprintln(stdout)
+	fmt.Fprintf(stdout, "Upload link:  %s\n", uploadURL)
+	fmt.Fprintf(stdout, "Max uploads:  %d\n", trustMax)
+	fmt.Fprintf(stdout, "Max file size: %s\n", util.FormatBytes(maxFileSize))
+	fmt.Fprintf(stdout, "Expires:      in %dh\n", expiresInHours)
 	if trustPassword != "" {
-		fmt.Println("Password:     set")
+		fmt.Fprintln(stdout, "Password:     set")
 	}
 
-	copyToClipboard(uploadURL, "Upload link")
+	copyToClipboard(uploadURL, "Upload link", autoCopyURL)
 
 	return nil
 }
@@ -1,12 +1,13 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
-	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/sim4gh/nikte-cli/internal/platform"
 	"github.com/sim4gh/nikte-cli/internal/util"
 )
 
@@ -20,6 +21,7 @@ var (
 
 // tuiModel is the Bubble Tea model backing `nk ls -i`.
 type tuiModel struct {
+	ctx           context.Context
 	items         []Item
 	cursor        int
 	status        string
@@ -36,10 +38,12 @@ type deletedMsg struct {
 // refreshedMsg carries a freshly fetched item list.
 type refreshedMsg struct {
 	items []Item
+	stale bool
+	err   error
 }
 
-func newTUIModel(items []Item) tuiModel {
-	return tuiModel{items: items, status: "c copy ID · enter copy & quit · d delete · r refresh · q quit"}
+func newTUIModel(ctx context.Context, items []Item) tuiModel {
+	return tuiModel{ctx: ctx, items: items, status: "c copy ID · enter copy & quit · d delete · r refresh · q quit"}
 }
 
 func (m tuiModel) Init() tea.Cmd { return nil }
@@ -61,7 +65,7 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if item, ok := m.selected(); ok {
 					m.pendingDelete = false
 					m.status = "Deleting " + item.ID + "..."
-					return m, deleteItemCmd(item.ID)
+					return m, deleteItemCmd(m.ctx, item.ID)
 				}
 			default:
 				m.pendingDelete = false
@@ -88,7 +92,7 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.cursor = len(m.items) - 1
 		case "c":
 			if item, ok := m.selected(); ok {
-				if err := clipboard.WriteAll(item.ID); err == nil {
+				if err := platform.CopyText(item.ID); err == nil {
 					m.status = "Copied ID " + item.ID
 				} else {
 					m.status = "Failed to copy ID"
@@ -96,7 +100,7 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "enter":
 			if item, ok := m.selected(); ok {
-				_ = clipboard.WriteAll(item.ID)
+				_ = platform.CopyText(item.ID)
 				m.status = item.ID
 				m.quitting = true
 				return m, tea.Quit
@@ -108,7 +112,7 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "r":
 			m.status = "Refreshing..."
-			return m, refreshItemsCmd()
+			return m, refreshItemsCmd(m.ctx)
 		}
 
 	case deletedMsg:
@@ -120,6 +124,10 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case refreshedMsg:
+		if msg.err != nil && len(msg.items) == 0 {
+			m.status = "Refresh failed: " + msg.err.Error()
+			break
+		}
 		m.items = msg.items
 		if m.cursor >= len(m.items) {
 			m.cursor = len(m.items) - 1
@@ -128,6 +136,9 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.cursor = 0
 		}
 		m.status = fmt.Sprintf("Refreshed · %d items", len(m.items))
+		if msg.stale || msg.err != nil {
+			m.status = fmt.Sprintf("Offline · showing %d cached items", len(m.items))
+		}
 	}
 
 	return m, nil
@@ -212,9 +223,9 @@ func tuiTypeLabel(t string) string {
 }
 
 // deleteItemCmd deletes an item by ID off the UI thread.
-func deleteItemCmd(id string) tea.Cmd {
+func deleteItemCmd(ctx context.Context, id string) tea.Cmd {
 	return func() tea.Msg {
-		result := tryDelete(id)
+		result := deleteItem(ctx, id, true)
 		if result.success {
 			return deletedMsg{id: id}
 		}
@@ -223,15 +234,16 @@ func deleteItemCmd(id string) tea.Cmd {
 }
 
 // refreshItemsCmd re-fetches all items off the UI thread.
-func refreshItemsCmd() tea.Cmd {
+func refreshItemsCmd(ctx context.Context) tea.Cmd {
 	return func() tea.Msg {
-		return refreshedMsg{items: fetchAllItems()}
+		r := fetchAllItems(ctx)
+		return refreshedMsg{items: r.items, stale: r.stale, err: r.err}
 	}
 }
 
 // runListTUI launches the interactive list with a pre-fetched item set.
-func runListTUI(items []Item) error {
-	p := tea.NewProgram(newTUIModel(items))
+func runListTUI(ctx context.Context, items []Item) error {
+	p := tea.NewProgram(newTUIModel(ctx, items))
 	finalModel, err := p.Run()
 	if err != nil {
 		return err
@@ -239,7 +251,7 @@ func runListTUI(items []Item) error {
 	// If the user pressed enter, print the selected ID so it can be piped/copied.
 	if fm, ok := finalModel.(tuiModel); ok {
 		if item, ok := fm.selected(); ok && fm.status == item.ID {
-			fmt.Println(item.ID)
+			fmt.Fprintln(stdout, item.ID)
 		}
 	}
 	return nil
@@ -0,0 +1,661 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/crypto"
+	"github.com/sim4gh/nikte-cli/internal/models"
+	"github.com/sim4gh/nikte-cli/internal/platform"
+	"github.com/sim4gh/nikte-cli/internal/upload"
+	"github.com/sim4gh/nikte-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// previewDelay is how long the cursor has to rest on an item before its
+// preview is fetched, so scrolling through the list doesn't fire a request
+// per row.
+const previewDelay = 150 * time.Millisecond
+
+// browserHelp is the key summary shown in the status line.
+const browserHelp = "d download · x delete · e extend · s share · u copy URL · c copy ID · / filter · r refresh · q quit"
+
+var tuiPaneStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("241")).Padding(0, 1)
+
+func addTUICommand() {
+	tuiCmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Browse items full-screen",
+		Long: `Browse items full-screen: a navigable list with a preview of the selected
+item, and keys to act on it without remembering IDs or flags.
+
+Keys:
+  ↑/↓ j/k  g/G                Move, jump to top/bottom
+  d                           Download to the current directory
+  x                           Delete (asks to confirm)
+  e                           Extend TTL (7d, 24h, ...) or make permanent
+  s                           Create a public share link and copy it
+  u                           Copy the download URL (text: the content)
+  c                           Copy the ID
+  /                           Filter by ID, name or content
+  r, q                        Refresh, quit`,
+		Args: cobra.NoArgs,
+		RunE: runBrowser,
+	}
+
+	rootCmd.AddCommand(tuiCmd)
+}
+
+func runBrowser(cmd *cobra.Command, args []string) error {
+	fetched := fetchAllItems(cmd.Context())
+	if fetched.err != nil && len(fetched.items) == 0 {
+		return fetched.err
+	}
+	items := sortItems(fetched.items, "date")
+	m := newBrowserModel(cmd.Context(), items)
+	if fetched.stale {
+		m.status = fmt.Sprintf("Offline · showing %d cached items", len(items))
+	}
+	_, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
+
+// itemDetail is the full record of one item, fetched for the preview pane.
+type itemDetail struct {
+	Content     string // text items only
+	Encrypted   bool
+	Filename    string
+	ContentType string
+	Description string
+	Size        int64
+	CreatedAt   string
+	ExpiresAt   int64
+	DownloadURL string
+}
+
+// fetchItemDetail fetches item from the endpoint for its type. A missing
+// item is api.ErrNotFound.
+func fetchItemDetail(ctx context.Context, item Item) (itemDetail, error) {
+	switch item.Type {
+	case "screenshot":
+		resp, err := api.Do(ctx, "GET", "/screenshots/"+item.ID, nil)
+		if err != nil {
+			return itemDetail{}, err
+		}
+		var sc models.Screenshot
+		if err := resp.Unmarshal(&sc); err != nil {
+			return itemDetail{}, err
+		}
+		return itemDetail{Filename: item.Filename, ContentType: sc.ContentType, Size: sc.Size,
+			CreatedAt: sc.CreatedAt, ExpiresAt: sc.ExpiresAt, DownloadURL: sc.DownloadURL}, nil
+	case "profile":
+		resp, err := api.Do(ctx, "GET", "/files/"+item.ID, nil)
+		if err != nil {
+			return itemDetail{}, err
+		}
+		var f models.FileItem
+		if err := resp.Unmarshal(&f); err != nil {
+			return itemDetail{}, err
+		}
+		return itemDetail{Filename: f.Filename, ContentType: f.ContentType, Description: f.Description,
+			Size: f.Size, CreatedAt: f.CreatedAt, ExpiresAt: f.ExpiresAt, DownloadURL: f.DownloadURL}, nil
+	default:
+		resp, err := api.Do(ctx, "GET", "/shorts/"+item.ID, nil)
+		if err != nil {
+			return itemDetail{}, err
+		}
+		var s models.Short
+		if err := resp.Unmarshal(&s); err != nil {
+			return itemDetail{}, err
+		}
+		return itemDetail{Content: s.Content, Encrypted: crypto.IsEncryptedText(s.Content),
+			Filename: s.Filename, ContentType: s.ContentType, Size: s.FileSize,
+			CreatedAt: s.CreatedAt, ExpiresAt: s.ExpiresAt, DownloadURL: s.DownloadURL}, nil
+	}
+}
+
+// browserModel is the Bubble Tea model backing `nk tui`.
+type browserModel struct {
+	ctx      context.Context
+	all      []Item // every item; items is all narrowed by filter
+	items    []Item
+	filter   string
+	cursor   int
+	offset   int // first visible row
+	width    int
+	height   int
+	details  map[string]itemDetail
+	loading  map[string]bool
+	failed   map[string]error
+	status   string
+	prompt   string // "delete", "extend" or "filter" while asking for input
+	input    string
+	quitting bool
+}
+
+// previewTickMsg fires previewDelay after the cursor lands on id.
+type previewTickMsg struct{ id string }
+
+// detailMsg carries a fetched preview.
+type detailMsg struct {
+	id     string
+	detail itemDetail
+	err    error
+}
+
+// actionMsg reports the outcome of an action on id; status replaces the
+// status line, and refresh re-fetches the list afterwards.
+type actionMsg struct {
+	id      string
+	status  string
+	refresh bool
+}
+
+func newBrowserModel(ctx context.Context, items []Item) browserModel {
+	return browserModel{
+		ctx:     ctx,
+		all:     items,
+		items:   items,
+		width:   100,
+		height:  24,
+		details: map[string]itemDetail{},
+		loading: map[string]bool{},
+		failed:  map[string]error{},
+		status:  browserHelp,
+	}
+}
+
+func (m browserModel) Init() tea.Cmd { return m.schedulePreview() }
+
+func (m browserModel) selected() (Item, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.items) {
+		return Item{}, false
+	}
+	return m.items[m.cursor], true
+}
+
+// schedulePreview asks for the selected item's preview after previewDelay,
+// unless it is already loaded.
+func (m browserModel) schedulePreview() tea.Cmd {
+	item, ok := m.selected()
+	if !ok {
+		return nil
+	}
+	if _, ok := m.details[item.ID]; ok || m.loading[item.ID] {
+		return nil
+	}
+	return tea.Tick(previewDelay, func(time.Time) tea.Msg { return previewTickMsg{item.ID} })
+}
+
+func (m browserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.clampCursor()
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.prompt != "" {
+			return m.updatePrompt(msg)
+		}
+		return m.updateKey(msg)
+
+	case previewTickMsg:
+		item, ok := m.selected()
+		if !ok || item.ID != msg.id || m.loading[item.ID] {
+			return m, nil
+		}
+		if _, ok := m.details[item.ID]; ok {
+			return m, nil
+		}
+		m.loading[item.ID] = true
+		delete(m.failed, item.ID)
+		return m, fetchDetailCmd(m.ctx, item)
+
+	case detailMsg:
+		delete(m.loading, msg.id)
+		if msg.err != nil {
+			m.failed[msg.id] = msg.err
+		} else {
+			m.details[msg.id] = msg.detail
+		}
+
+	case actionMsg:
+		m.status = msg.status
+		if msg.refresh {
+			delete(m.details, msg.id)
+			return m, refreshItemsCmd(m.ctx)
+		}
+
+	case refreshedMsg:
+		if msg.err != nil && len(msg.items) == 0 {
+			m.status = "Refresh failed: " + msg.err.Error()
+			break
+		}
+		m.all = sortItems(msg.items, "date")
+		m.applyFilter()
+		if m.status == "Refreshing..." {
+			m.status = fmt.Sprintf("Refreshed · %d items", len(m.all))
+		}
+		if msg.stale || msg.err != nil {
+			m.status = fmt.Sprintf("Offline · showing %d cached items", len(m.all))
+		}
+		return m, m.schedulePreview()
+	}
+	return m, nil
+}
+
+// updateKey handles a keystroke in the list.
+func (m browserModel) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	item, ok := m.selected()
+	switch msg.String() {
+	case "q", "esc", "ctrl+c":
+		if msg.String() == "esc" && m.filter != "" {
+			m.filter = ""
+			m.applyFilter()
+			m.status = browserHelp
+			return m, m.schedulePreview()
+		}
+		m.quitting = true
+		return m, tea.Quit
+	case "up", "k":
+		m.cursor--
+	case "down", "j":
+		m.cursor++
+	case "pgup":
+		m.cursor -= m.listHeight()
+	case "pgdown":
+		m.cursor += m.listHeight()
+	case "g", "home":
+		m.cursor = 0
+	case "G", "end":
+		m.cursor = len(m.items) - 1
+	case "r":
+		m.status = "Refreshing..."
+		return m, refreshItemsCmd(m.ctx)
+	case "/":
+		m.prompt, m.input = "filter", m.filter
+		return m, nil
+	case "c":
+		if ok {
+			m.status = copyStatus(platform.CopyText(item.ID), "ID "+item.ID)
+		}
+		return m, nil
+	case "u":
+		if ok {
+			return m, m.copyURL(item)
+		}
+		return m, nil
+	case "d":
+		if ok {
+			m.status = "Downloading " + item.ID + "..."
+			return m, downloadItemCmd(m.ctx, item)
+		}
+		return m, nil
+	case "x":
+		if ok {
+			m.prompt = "delete"
+		}
+		return m, nil
+	case "e":
+		if ok {
+			m.prompt, m.input = "extend", ""
+		}
+		return m, nil
+	case "s":
+		if ok {
+			m.status = "Creating share link for " + item.ID + "..."
+			return m, shareItemCmd(m.ctx, item)
+		}
+		return m, nil
+	default:
+		return m, nil
+	}
+	m.clampCursor()
+	return m, m.schedulePreview()
+}
+
+// updatePrompt handles a keystroke while the status line asks for input.
+func (m browserModel) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	item, ok := m.selected()
+	if m.prompt == "delete" {
+		m.prompt = ""
+		if (msg.String() == "y" || msg.String() == "Y") && ok {
+			m.status = "Deleting " + item.ID + "..."
+			return m, m.deleteCmd(item.ID)
+		}
+		m.status = "Delete cancelled"
+		return m, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.prompt = ""
+		m.status = browserHelp
+		return m, nil
+	case tea.KeyBackspace:
+		if r := []rune(m.input); len(r) > 0 {
+			m.input = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.input += string(msg.Runes)
+	case tea.KeyEnter:
+		prompt, input := m.prompt, strings.TrimSpace(m.input)
+		m.prompt, m.input = "", ""
+		switch prompt {
+		case "filter":
+			m.filter = input
+			m.applyFilter()
+			m.status = browserHelp
+			if m.filter != "" {
+				m.status = fmt.Sprintf("%d matching %q · esc clears", len(m.items), m.filter)
+			}
+			return m, m.schedulePreview()
+		case "extend":
+			if input == "" || !ok {
+				m.status = "Extend cancelled"
+				return m, nil
+			}
+			m.status = "Extending " + item.ID + "..."
+			return m, extendItemCmd(m.ctx, item.ID, input)
+		}
+	}
+	if m.prompt == "filter" {
+		m.filter = m.input
+		m.applyFilter()
+	}
+	return m, nil
+}
+
+// applyFilter narrows the list to items matching filter, keeping the
+// selection on the same item where possible.
+func (m *browserModel) applyFilter() {
+	current, _ := m.selected()
+	m.items = m.all
+	if m.filter != "" {
+		m.items = filterBySearch(m.all, m.filter)
+	}
+	m.cursor = 0
+	for i, item := range m.items {
+		if item.ID == current.ID {
+			m.cursor = i
+			break
+		}
+	}
+	m.clampCursor()
+}
+
+// clampCursor keeps the cursor on an item and scrolls it into view.
+func (m *browserModel) clampCursor() {
+	m.cursor = max(0, min(m.cursor, len(m.items)-1))
+	h := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+h {
+		m.offset = m.cursor - h + 1
+	}
+	m.offset = max(0, min(m.offset, len(m.items)-h))
+}
+
+// listHeight is how many rows fit in the list pane.
+func (m browserModel) listHeight() int {
+	// Header, blank line, status line and the pane borders.
+	return max(1, m.height-6)
+}
+
+func (m browserModel) deleteCmd(id string) tea.Cmd {
+	ctx := m.ctx
+	return func() tea.Msg {
+		result := deleteItem(ctx, id, true)
+		if !result.success {
+			return actionMsg{id: id, status: "Delete failed: " + result.error}
+		}
+		return actionMsg{id: id, status: "Deleted " + id, refresh: true}
+	}
+}
+
+// copyURL copies the selected item's download URL, or a text item's
+// content, fetching the item first if its preview hasn't loaded.
+func (m *browserModel) copyURL(item Item) tea.Cmd {
+	copyDetail := func(d itemDetail) actionMsg {
+		if d.DownloadURL != "" {
+			return actionMsg{id: item.ID, status: copyStatus(platform.CopyText(d.DownloadURL), "download URL (valid for 1 hour)")}
+		}
+		if d.Content != "" {
+			return actionMsg{id: item.ID, status: copyStatus(platform.CopyText(d.Content), "content of "+item.ID)}
+		}
+		return actionMsg{id: item.ID, status: item.ID + " has no URL to copy"}
+	}
+	if d, ok := m.details[item.ID]; ok {
+		msg := copyDetail(d)
+		m.status = msg.status
+		return nil
+	}
+	ctx := m.ctx
+	return func() tea.Msg {
+		d, err := fetchItemDetail(ctx, item)
+		if err != nil {
+			return actionMsg{id: item.ID, status: "Copy failed: " + err.Error()}
+		}
+		return copyDetail(d)
+	}
+}
+
+func copyStatus(err error, what string) string {
+	if err != nil {
+		return "Failed to copy " + what
+	}
+	return "Copied " + what
+}
+
+func fetchDetailCmd(ctx context.Context, item Item) tea.Cmd {
+	return func() tea.Msg {
+		d, err := fetchItemDetail(ctx, item)
+		return detailMsg{id: item.ID, detail: d, err: err}
+	}
+}
+
+// downloadItemCmd saves item to the current directory: files under their
+// name, text as <id>.txt. Encrypted items are saved as they are stored.
+func downloadItemCmd(ctx context.Context, item Item) tea.Cmd {
+	return func() tea.Msg {
+		d, err := fetchItemDetail(ctx, item)
+		if err != nil {
+			return actionMsg{id: item.ID, status: "Download failed: " + err.Error()}
+		}
+		if d.DownloadURL == "" {
+			path := item.ID + ".txt"
+			if err := os.WriteFile(path, []byte(d.Content), 0o644); err != nil {
+				return actionMsg{id: item.ID, status: "Download failed: " + err.Error()}
+			}
+			return actionMsg{id: item.ID, status: "Saved " + path}
+		}
+		path := d.Filename
+		if path == "" {
+			path = item.Filename
+		}
+		if path == "" {
+			path = item.ID
+		}
+		if err := upload.DownloadFile(ctx, d.DownloadURL, path); err != nil {
+			return actionMsg{id: item.ID, status: "Download failed: " + err.Error()}
+		}
+		status := "Downloaded " + path
+		if strings.HasSuffix(path, crypto.FileSuffix) {
+			status += " (encrypted: decrypt with nk g " + item.ID + ")"
+		}
+		return actionMsg{id: item.ID, status: status}
+	}
+}
+
+// extendItemCmd sets item id's TTL to ttl from now, or makes it permanent.
+func extendItemCmd(ctx context.Context, id, ttl string) tea.Cmd {
+	return func() tea.Msg {
+		body := models.ExtendRequest{TTL: ttl}
+		if ttl == "permanent" || ttl == "p" {
+			body = models.ExtendRequest{Permanent: true}
+		}
+		resp, err := api.Do(ctx, "PATCH", "/shorts/"+id, body)
+		switch {
+		case errors.Is(err, api.ErrForbidden):
+			return actionMsg{id: id, status: "Extending files requires a Pro subscription"}
+		case errors.Is(err, api.ErrBadRequest):
+			return actionMsg{id: id, status: fmt.Sprintf("Invalid TTL %q (try 24h, 7d or permanent)", ttl)}
+		case err != nil:
+			return actionMsg{id: id, status: "Extend failed: " + err.Error()}
+		}
+		if body.Permanent {
+			return actionMsg{id: id, status: id + " is now permanent", refresh: true}
+		}
+		var result models.ExtendResponse
+		resp.Unmarshal(&result)
+		return actionMsg{id: id, status: id + " now expires " + util.FormatExpiryTime(result.ExpiresAt), refresh: true}
+	}
+}
+
+// shareItemCmd creates a public share link for item, as `nk sh <id>` with
+// no flags does, and copies it.
+func shareItemCmd(ctx context.Context, item Item) tea.Cmd {
+	return func() tea.Msg {
+		result := shareFile(ctx, item.ID)
+		if !result.success && result.reason == "not_found" {
+			result = shareShort(ctx, item.ID)
+		}
+		switch {
+		case result.success:
+			url := result.data.ShareURL
+			return actionMsg{id: item.ID, status: copyStatus(platform.CopyText(url), "share link "+url)}
+		case result.reason == "pro_required":
+			return actionMsg{id: item.ID, status: "Sharing requires a Pro subscription"}
+		case result.message != "":
+			return actionMsg{id: item.ID, status: "Share failed: " + result.message}
+		default:
+			return actionMsg{id: item.ID, status: "Share failed"}
+		}
+	}
+}
+
+func (m browserModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	listWidth := min(max(m.width*2/5, 30), 60)
+	previewWidth := max(m.width-listWidth-4, 20)
+	paneHeight := m.listHeight()
+
+	header := tuiHeaderStyle.Render("nikte") + tuiDimStyle.Render(fmt.Sprintf("  %d items", len(m.all)))
+	if m.filter != "" {
+		header += tuiDimStyle.Render(fmt.Sprintf(" · %d matching %q", len(m.items), m.filter))
+	}
+
+	list := tuiPaneStyle.Width(listWidth).Height(paneHeight).Render(m.renderList(listWidth - 2))
+	preview := tuiPaneStyle.Width(previewWidth).Height(paneHeight).Render(m.renderPreview(previewWidth-2, paneHeight))
+
+	status := tuiStatusStyle.Render(m.status)
+	switch m.prompt {
+	case "delete":
+		status = tuiStatusStyle.Render("Delete this item? press y to confirm, any other key to cancel")
+	case "extend":
+		status = tuiStatusStyle.Render("Extend to (e.g. 24h, 7d, permanent): " + m.input + "█")
+	case "filter":
+		status = tuiStatusStyle.Render("Filter: " + m.input + "█")
+	}
+
+	return header + "\n\n" + lipgloss.JoinHorizontal(lipgloss.Top, list, preview) + "\n" + status
+}
+
+// renderList renders the visible rows of the list pane.
+func (m browserModel) renderList(width int) string {
+	if len(m.items) == 0 {
+		return tuiDimStyle.Render("No items.")
+	}
+	var b strings.Builder
+	end := min(m.offset+m.listHeight(), len(m.items))
+	for i := m.offset; i < end; i++ {
+		item := m.items[i]
+		name := item.Preview
+		if name == "" {
+			name = item.Filename
+		}
+		line := fmt.Sprintf("%-5s %-10s %s", item.ID, tuiTypeLabel(item.Type), util.ReplaceNewlines(name))
+		line = util.Truncate(line, width-2)
+		if i == m.cursor {
+			b.WriteString(tuiSelectedStyle.Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		if i < end-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// renderPreview renders the selected item's details and, for text, as much
+// of its content as fits.
+func (m browserModel) renderPreview(width, height int) string {
+	item, ok := m.selected()
+	if !ok {
+		return ""
+	}
+	field := func(label, value string) string {
+		return tuiDimStyle.Render(fmt.Sprintf("%-9s", label)) + value + "\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(field("ID", item.ID))
+	b.WriteString(field("Type", tuiTypeLabel(item.Type)))
+	if item.Filename != "" {
+		b.WriteString(field("Name", item.Filename))
+	}
+	if item.Size > 0 {
+		b.WriteString(field("Size", util.FormatBytes(item.Size)))
+	}
+	if item.CreatedAt != "" {
+		b.WriteString(field("Created", item.CreatedAt))
+	}
+	if item.ExpiresAt > 0 {
+		b.WriteString(field("Expires", util.FormatExpiryTime(item.ExpiresAt)))
+	} else {
+		b.WriteString(field("Expires", "never (permanent)"))
+	}
+
+	d, loaded := m.details[item.ID]
+	switch {
+	case m.failed[item.ID] != nil:
+		b.WriteString("\n" + tuiDimStyle.Render("Preview unavailable: "+m.failed[item.ID].Error()))
+	case !loaded:
+		b.WriteString("\n" + tuiDimStyle.Render("Loading preview..."))
+	default:
+		if d.ContentType != "" {
+			b.WriteString(field("Content", d.ContentType))
+		}
+		if d.Description != "" {
+			b.WriteString(field("About", d.Description))
+		}
+		switch {
+		case d.Encrypted:
+			b.WriteString("\n" + tuiDimStyle.Render("Encrypted: view with nk g "+item.ID))
+		case d.Content != "":
+			b.WriteString("\n" + d.Content)
+		case d.DownloadURL != "":
+			b.WriteString("\n" + tuiDimStyle.Render("d downloads it to the current directory"))
+		}
+	}
+
+	// Wrap to the pane and cut what doesn't fit.
+	lines := strings.Split(lipgloss.NewStyle().Width(width).Render(b.String()), "\n")
+	if len(lines) > height {
+		lines = append(lines[:height-1], tuiDimStyle.Render("…"))
+	}
+	return strings.Join(lines, "\n")
+}
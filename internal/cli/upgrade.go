@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/sim4gh/nikte-cli/internal/selfupdate"
+	"github.com/spf13/cobra"
+)
+
+// upgradeCheck is the --check flag of `nk upgrade`: report without installing.
+var upgradeCheck bool
+
+func addUpgradeCommand() {
+	upgradeCmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Update nk to the latest release",
+		Long: `Update nk to the latest GitHub release. The archive for this OS and
+architecture is checked against the release's checksums.txt before the
+running executable is replaced. Homebrew installs are left to brew.
+
+Examples:
+  nk upgrade                  Install the latest release
+    └ --check                  Only report whether an update is available`,
+		Args: cobra.NoArgs,
+		RunE: runUpgrade,
+	}
+
+	upgradeCmd.Flags().BoolVar(&upgradeCheck, "check", false, "Only check for a newer release; don't install it")
+
+	rootCmd.AddCommand(upgradeCmd)
+}
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " Checking for updates..."
+	s.Start()
+	rel, err := selfupdate.Latest(cmd.Context())
+	s.Stop()
+	if err != nil {
+		return err
+	}
+
+	latest := rel.Version()
+	if !selfupdate.Newer(Version, latest) {
+		fmt.Fprintf(stdout, "nk %s is up to date (latest release: %s)\n", Version, latest)
+		return nil
+	}
+	fmt.Fprintf(stdout, "Update available: %s → %s\n", Version, latest)
+	if rel.URL != "" {
+		fmt.Fprintf(stdout, "Release notes: %s\n", rel.URL)
+	}
+	if upgradeCheck {
+		return nil
+	}
+
+	exe, err := selfupdate.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot find the nk executable: %w", err)
+	}
+	if selfupdate.ManagedBy(exe) == "homebrew" {
+		return fmt.Errorf("nk was installed with Homebrew; upgrade with: brew upgrade nikte")
+	}
+
+	s.Suffix = fmt.Sprintf(" Downloading nk %s...", latest)
+	s.Start()
+	binary, err := rel.Download(cmd.Context())
+	s.Stop()
+	if err != nil {
+		return err
+	}
+	if err := selfupdate.Replace(exe, binary); err != nil {
+		return fmt.Errorf("%w (try running with sudo, or download from https://github.com/%s/releases)", err, selfupdate.Repo)
+	}
+	fmt.Fprintf(stdout, "✓ Upgraded nk to %s (%s)\n", latest, exe)
+	return nil
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/sim4gh/nikte-cli/internal/selfupdate"
+	"github.com/spf13/cobra"
+)
+
+// Commit and BuildDate are set at build time, like Version. Left empty, they
+// are taken from the VCS information go build records.
+var (
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Flags of `nk version`.
+var (
+	versionCheck bool
+	versionJSON  bool
+)
+
+// versionInfo is what `nk version` reports.
+type versionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"buildDate,omitempty"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+	Latest    string `json:"latest,omitempty"`
+	Update    *bool  `json:"updateAvailable,omitempty"`
+}
+
+func addVersionCommand() {
+	versionCmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show version, commit, build date and Go version",
+		Long: `Show the version of nk with the commit and date it was built from and the
+Go version and platform it was built for.
+
+Examples:
+  nk version                             Build details
+  nk version --check                     Also ask GitHub whether a newer release is out`,
+		Args: cobra.NoArgs,
+		RunE: runVersion,
+	}
+	versionCmd.Flags().BoolVar(&versionCheck, "check", false, "Check whether a newer release is available")
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Output as JSON")
+
+	rootCmd.AddCommand(versionCmd)
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	if versionJSON && !machineOutput() {
+		outputMode = outputJSON
+	}
+	v := buildInfo()
+
+	var checkErr error
+	if versionCheck {
+		s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+		s.Suffix = " Checking for updates..."
+		if !machineOutput() {
+			s.Start()
+		}
+		rel, err := selfupdate.Latest(cmd.Context())
+		s.Stop()
+		if err == nil {
+			newer := selfupdate.Newer(Version, rel.Version())
+			v.Latest, v.Update = rel.Version(), &newer
+		}
+		checkErr = err
+	}
+
+	if machineOutput() {
+		if err := emitResult(v); err != nil {
+			return err
+		}
+		return checkErr
+	}
+
+	fmt.Fprintf(stdout, "nk %s\n", v.Version)
+	if v.Commit != "" {
+		fmt.Fprintf(stdout, "  commit:     %s\n", v.Commit)
+	}
+	if v.BuildDate != "" {
+		fmt.Fprintf(stdout, "  built:      %s\n", v.BuildDate)
+	}
+	fmt.Fprintf(stdout, "  go version: %s\n", v.GoVersion)
+	fmt.Fprintf(stdout, "  platform:   %s\n", v.Platform)
+
+	switch {
+	case checkErr != nil:
+		return checkErr
+	case v.Update == nil:
+	case *v.Update:
+		fmt.Fprintf(stdout, "\nUpdate available: %s → %s (run: nk upgrade)\n", Version, v.Latest)
+	default:
+		fmt.Fprintf(stdout, "\nUp to date (latest release: %s)\n", v.Latest)
+	}
+	return nil
+}
+
+// buildInfo collects the details `nk version` shows, falling back to what
+// go build embedded for a commit and date not set with -ldflags.
+func buildInfo() versionInfo {
+	v := versionInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return v
+	}
+	modified := false
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if v.Commit == "" {
+				v.Commit = s.Value
+			}
+		case "vcs.time":
+			if v.BuildDate == "" {
+				v.BuildDate = s.Value
+			}
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if Commit == "" && v.Commit != "" && modified {
+		v.Commit += " (modified)"
+	}
+	return v
+}
@@ -10,10 +10,11 @@ import (
 	"sync"
 	"time"
 
-	"github.com/atotto/clipboard"
 	"github.com/briandowns/spinner"
 	"github.com/mdp/qrterminal/v3"
 	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/models"
 	"github.com/sim4gh/nikte-cli/internal/platform"
 	"github.com/sim4gh/nikte-cli/internal/upload"
 	"github.com/sim4gh/nikte-cli/internal/util"
@@ -60,7 +61,7 @@ Examples:
 		Long: `Send a WhatsApp message, image, video or document to a phone number.
 
 The second argument is auto-detected:
-  - "sc"                      capture a screenshot and send it (macOS)
+  - "sc"                      capture a screenshot and send it (macOS, Linux)
   - an existing file path     send that file (image/video/audio/document)
   - anything else             send it as a text message
   - omitted                   send clipboard content (image if present, else text)
@@ -128,8 +129,8 @@ func runWaLink(cmd *cobra.Command, args []string) error {
 	defer client.Disconnect()
 
 	if client.Store.ID != nil {
-		fmt.Println("WhatsApp is already linked.")
-		fmt.Println("Run \"nk wa unlink\" first to re-link.")
+		fmt.Fprintln(stdout, "WhatsApp is already linked.")
+		fmt.Fprintln(stdout, "Run \"nk wa unlink\" first to re-link.")
 		return nil
 	}
 
@@ -157,7 +158,7 @@ func runWaLink(cmd *cobra.Command, args []string) error {
 		}
 	})
 
-	qrChan, err := client.GetQRChannel(context.Background())
+	qrChan, err := client.GetQRChannel(cmd.Context())
 	if err != nil {
 		return fmt.Errorf("failed to get QR channel: %w", err)
 	}
@@ -167,9 +168,9 @@ func runWaLink(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to connect: %w", err)
 	}
 
-	fmt.Println("\nScan this QR code with WhatsApp:")
-	fmt.Println("  WhatsApp > Settings > Linked Devices > Link a Device")
-	fmt.Println()
+	fmt.Fprintln(stdout, "\nScan this QR code with WhatsApp:")
+	fmt.Fprintln(stdout, "  WhatsApp > Settings > Linked Devices > Link a Device")
+	fmt.Fprintln(stdout)
 
 	for {
 		select {
@@ -178,7 +179,7 @@ func runWaLink(cmd *cobra.Command, args []string) error {
 				// QR channel closed — check if we paired via event handler
 				select {
 				case <-pairSuccess:
-					fmt.Println("\nWhatsApp linked successfully!")
+					fmt.Fprintln(stdout, "\nWhatsApp linked successfully!")
 					return nil
 				default:
 					return fmt.Errorf("connection closed unexpectedly")
@@ -186,9 +187,9 @@ func runWaLink(cmd *cobra.Command, args []string) error {
 			}
 			switch evt.Event {
 			case "code":
-				qrterminal.GenerateHalfBlock(evt.Code, qrterminal.L, os.Stdout)
+				qrterminal.GenerateHalfBlock(evt.Code, qrterminal.L, stdout)
 			case "login":
-				fmt.Println("\nWhatsApp linked successfully!")
+				fmt.Fprintln(stdout, "\nWhatsApp linked successfully!")
 				return nil
 			case "timeout":
 				return fmt.Errorf("QR code expired. Run \"nk wa link\" again")
@@ -197,13 +198,16 @@ func runWaLink(cmd *cobra.Command, args []string) error {
 			}
 
 		case <-pairSuccess:
-			fmt.Println("\nWhatsApp linked successfully!")
+			fmt.Fprintln(stdout, "\nWhatsApp linked successfully!")
 			// Wait briefly for the session to be fully saved
 			time.Sleep(1 * time.Second)
 			return nil
 
 		case pairErr := <-pairError:
 			return pairErr
+
+		case <-cmd.Context().Done():
+			return cmd.Context().Err()
 		}
 	}
 }
@@ -238,9 +242,9 @@ func runWaSend(cmd *cobra.Command, args []string) error {
 	var desc string
 	if waSendItem != "" {
 		caption := strings.Join(args[1:], " ")
-		msg, desc, err = buildWaItemMessage(client, waSendItem, caption)
+		msg, desc, err = buildWaItemMessage(cmd.Context(), client, config.ResolveAlias(waSendItem), caption)
 	} else {
-		msg, desc, err = buildWaSendMessage(client, args[1:])
+		msg, desc, err = buildWaSendMessage(cmd.Context(), client, args[1:])
 	}
 	if err != nil {
 		return err
@@ -252,35 +256,35 @@ func runWaSend(cmd *cobra.Command, args []string) error {
 	s.Suffix = " Sending " + desc + "..."
 	s.Start()
 
-	_, err = client.SendMessage(context.Background(), jid, msg)
+	_, err = client.SendMessage(cmd.Context(), jid, msg)
 	s.Stop()
 
 	if err != nil {
 		return fmt.Errorf("failed to send: %w", err)
 	}
 
-	fmt.Printf("%s sent to %s\n", capitalize(desc), number)
+	fmt.Fprintf(stdout, "%s sent to %s\n", capitalize(desc), number)
 	return nil
 }
 
 // buildWaSendMessage resolves the WhatsApp message to send from the arguments
 // after the phone number, auto-detecting screenshots, files, and text.
 // It returns the message, a short human-readable description, and any error.
-func buildWaSendMessage(client *whatsmeow.Client, rest []string) (*waE2E.Message, string, error) {
+func buildWaSendMessage(ctx context.Context, client *whatsmeow.Client, rest []string) (*waE2E.Message, string, error) {
 	// No argument: send clipboard content (image if present, otherwise text).
 	if len(rest) == 0 {
 		if platform.ClipboardHasImage() {
 			data, err := platform.GetClipboardImage()
 			if err == nil && len(data) > 0 {
-				fmt.Println("Sending clipboard image")
-				return buildWaMedia(client, data, "image/png", "", "clipboard.png")
+				fmt.Fprintln(stdout, "Sending clipboard image")
+				return buildWaMedia(ctx, client, data, "image/png", "", "clipboard.png")
 			}
 		}
-		text, clipErr := clipboard.ReadAll()
+		text, clipErr := platform.PasteText()
 		if clipErr != nil || strings.TrimSpace(text) == "" {
 			return nil, "", fmt.Errorf("no message provided and clipboard is empty")
 		}
-		fmt.Printf("Sending clipboard content (%d chars)\n", len(text))
+		fmt.Fprintf(stdout, "Sending clipboard content (%d chars)\n", len(text))
 		return &waE2E.Message{Conversation: proto.String(text)}, "message", nil
 	}
 
@@ -290,10 +294,10 @@ func buildWaSendMessage(client *whatsmeow.Client, rest []string) (*waE2E.Message
 	// "sc": capture a screenshot and send it.
 	if first == "sc" {
 		if !platform.IsScreenshotSupported() {
-			return nil, "", fmt.Errorf("screenshot capture is only supported on macOS")
+			return nil, "", platform.ErrScreenshotUnsupported
 		}
 		if !waSendFullscreen {
-			fmt.Println("Select area for screenshot...")
+			fmt.Fprintln(stdout, "Select area for screenshot...")
 		}
 		data, err := platform.CaptureScreenshot(false, waSendFullscreen)
 		if err != nil {
@@ -302,7 +306,7 @@ func buildWaSendMessage(client *whatsmeow.Client, rest []string) (*waE2E.Message
 		if data == nil {
 			return nil, "", fmt.Errorf("screenshot cancelled")
 		}
-		return buildWaMedia(client, data, "image/png", caption, "screenshot.png")
+		return buildWaMedia(ctx, client, data, "image/png", caption, "screenshot.png")
 	}
 
 	// Existing file: send as media (image/video/audio/document).
@@ -317,7 +321,7 @@ func buildWaSendMessage(client *whatsmeow.Client, rest []string) (*waE2E.Message
 		if err != nil {
 			return nil, "", err
 		}
-		return buildWaMedia(client, data, upload.GetMimeType(path), caption, filepath.Base(path))
+		return buildWaMedia(ctx, client, data, upload.GetMimeType(path), caption, filepath.Base(path))
 	}
 
 	// Otherwise: plain text message (join all remaining args).
@@ -327,46 +331,37 @@ func buildWaSendMessage(client *whatsmeow.Client, rest []string) (*waE2E.Message
 // buildWaItemMessage forwards an existing nikte item (looked up by ID) over
 // WhatsApp. Text shorts are sent as a message; file shorts, screenshots, and
 // Pro files are downloaded and sent as media. The caption applies to media only.
-func buildWaItemMessage(client *whatsmeow.Client, id, caption string) (*waE2E.Message, string, error) {
+func buildWaItemMessage(ctx context.Context, client *whatsmeow.Client, id, caption string) (*waE2E.Message, string, error) {
 	// Try as a short first (text or file).
-	if resp, err := api.Get("/shorts/" + id); err == nil && resp.StatusCode == 200 {
-		var item struct {
-			Type        string `json:"type"`
-			Content     string `json:"content"`
-			Filename    string `json:"filename"`
-			ContentType string `json:"contentType"`
-			DownloadURL string `json:"downloadUrl"`
-		}
+	if resp, err := api.GetContext(ctx, "/shorts/"+id); err == nil && resp.StatusCode == 200 {
+		var item models.Short
 		if err := resp.Unmarshal(&item); err != nil {
 			return nil, "", err
 		}
 		if item.Type == "file" {
-			data, err := downloadBytes(item.DownloadURL)
+			data, err := downloadBytes(ctx, item.DownloadURL)
 			if err != nil {
 				return nil, "", fmt.Errorf("failed to download item %q: %w", id, err)
 			}
-			fmt.Printf("Forwarding %s\n", item.Filename)
-			return buildWaMedia(client, data, item.ContentType, caption, item.Filename)
+			fmt.Fprintf(stdout, "Forwarding %s\n", item.Filename)
+			return buildWaMedia(ctx, client, data, item.ContentType, caption, item.Filename)
 		}
 		// Text short: send the content as a message.
 		text := item.Content
 		if caption != "" {
 			text = caption + "\n" + text
 		}
-		fmt.Printf("Forwarding text item %q\n", id)
+		fmt.Fprintf(stdout, "Forwarding text item %q\n", id)
 		return &waE2E.Message{Conversation: proto.String(text)}, "message", nil
 	}
 
 	// Try as a screenshot.
-	if resp, err := api.Get("/screenshots/" + id); err == nil && resp.StatusCode == 200 {
-		var item struct {
-			ContentType string `json:"contentType"`
-			DownloadURL string `json:"downloadUrl"`
-		}
+	if resp, err := api.GetContext(ctx, "/screenshots/"+id); err == nil && resp.StatusCode == 200 {
+		var item models.Screenshot
 		if err := resp.Unmarshal(&item); err != nil {
 			return nil, "", err
 		}
-		data, err := downloadBytes(item.DownloadURL)
+		data, err := downloadBytes(ctx, item.DownloadURL)
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to download screenshot %q: %w", id, err)
 		}
@@ -374,26 +369,22 @@ func buildWaItemMessage(client *whatsmeow.Client, id, caption string) (*waE2E.Me
 		if contentType == "" {
 			contentType = "image/png"
 		}
-		fmt.Printf("Forwarding screenshot %q\n", id)
-		return buildWaMedia(client, data, contentType, caption, "screenshot-"+id+".png")
+		fmt.Fprintf(stdout, "Forwarding screenshot %q\n", id)
+		return buildWaMedia(ctx, client, data, contentType, caption, "screenshot-"+id+".png")
 	}
 
 	// Try as a Pro file.
-	if resp, err := api.Get("/files/" + id); err == nil && resp.StatusCode == 200 {
-		var item struct {
-			Filename    string `json:"filename"`
-			ContentType string `json:"contentType"`
-			DownloadURL string `json:"downloadUrl"`
-		}
+	if resp, err := api.GetContext(ctx, "/files/"+id); err == nil && resp.StatusCode == 200 {
+		var item models.FileItem
 		if err := resp.Unmarshal(&item); err != nil {
 			return nil, "", err
 		}
-		data, err := downloadBytes(item.DownloadURL)
+		data, err := downloadBytes(ctx, item.DownloadURL)
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to download file %q: %w", id, err)
 		}
-		fmt.Printf("Forwarding %s\n", item.Filename)
-		return buildWaMedia(client, data, item.ContentType, caption, item.Filename)
+		fmt.Fprintf(stdout, "Forwarding %s\n", item.Filename)
+		return buildWaMedia(ctx, client, data, item.ContentType, caption, item.Filename)
 	}
 
 	return nil, "", fmt.Errorf("no nikte item found with ID %q (it may have expired)", id)
@@ -401,7 +392,7 @@ func buildWaItemMessage(client *whatsmeow.Client, id, caption string) (*waE2E.Me
 
 // buildWaMedia uploads media bytes to WhatsApp and builds the matching message
 // type based on the MIME type (image, video, audio, or document fallback).
-func buildWaMedia(client *whatsmeow.Client, data []byte, mimeType, caption, filename string) (*waE2E.Message, string, error) {
+func buildWaMedia(ctx context.Context, client *whatsmeow.Client, data []byte, mimeType, caption, filename string) (*waE2E.Message, string, error) {
 	kind := mimeType
 	if i := strings.Index(kind, "/"); i != -1 {
 		kind = kind[:i]
@@ -419,8 +410,8 @@ func buildWaMedia(client *whatsmeow.Client, data []byte, mimeType, caption, file
 		mediaType = whatsmeow.MediaDocument
 	}
 
-	fmt.Printf("Uploading %s (%s)\n", filename, util.FormatBytes(int64(len(data))))
-	resp, err := client.Upload(context.Background(), data, mediaType)
+	fmt.Fprintf(stdout, "Uploading %s (%s)\n", filename, util.FormatBytes(int64(len(data))))
+	resp, err := client.Upload(ctx, data, mediaType)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to upload media: %w", err)
 	}
@@ -596,6 +587,9 @@ func runWaLs(cmd *cobra.Command, args []string) error {
 		// Give a brief extra window for any trailing history sync
 		time.Sleep(2 * time.Second)
 	case <-time.After(15 * time.Second):
+	case <-cmd.Context().Done():
+		s.Stop()
+		return cmd.Context().Err()
 	}
 	s.Stop()
 
@@ -636,9 +630,9 @@ func runWaLs(cmd *cobra.Command, args []string) error {
 
 	if len(display) == 0 {
 		if waLsAll {
-			fmt.Println("No conversations found.")
+			fmt.Fprintln(stdout, "No conversations found.")
 		} else {
-			fmt.Println("No unread messages.")
+			fmt.Fprintln(stdout, "No unread messages.")
 		}
 		return nil
 	}
@@ -686,9 +680,9 @@ func printChat(chat *chatMessages) {
 
 	// Header line
 	if unreadCount > 0 {
-		fmt.Printf("\n%s — %d unread\n", chat.Name, unreadCount)
+		fmt.Fprintf(stdout, "\n%s — %d unread\n", chat.Name, unreadCount)
 	} else {
-		fmt.Printf("\n%s\n", chat.Name)
+		fmt.Fprintf(stdout, "\n%s\n", chat.Name)
 	}
 
 	for _, msg := range chat.Messages {
@@ -697,16 +691,16 @@ func printChat(chat *chatMessages) {
 		}
 		ts := msg.Time.Local().Format("15:04")
 		if chat.IsGroup && msg.Sender != "" {
-			fmt.Printf("  [%s] %s: %s\n", ts, msg.Sender, truncateMsg(msg.Text, 70))
+			fmt.Fprintf(stdout, "  [%s] %s: %s\n", ts, msg.Sender, truncateMsg(msg.Text, 70))
 		} else {
-			fmt.Printf("  [%s] %s\n", ts, truncateMsg(msg.Text, 70))
+			fmt.Fprintf(stdout, "  [%s] %s\n", ts, truncateMsg(msg.Text, 70))
 		}
 	}
 }
 
 func runWaUnlink(cmd *cobra.Command, args []string) error {
 	if !whatsapp.IsLinked() {
-		fmt.Println("WhatsApp is not linked.")
+		fmt.Fprintln(stdout, "WhatsApp is not linked.")
 		return nil
 	}
 
@@ -716,7 +710,7 @@ func runWaUnlink(cmd *cobra.Command, args []string) error {
 		if delErr := whatsapp.DeleteDB(); delErr != nil {
 			return fmt.Errorf("failed to delete session: %w", delErr)
 		}
-		fmt.Println("WhatsApp session cleared.")
+		fmt.Fprintln(stdout, "WhatsApp session cleared.")
 		return nil
 	}
 
@@ -732,14 +726,14 @@ func runWaUnlink(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
 
-	fmt.Println("WhatsApp unlinked successfully.")
+	fmt.Fprintln(stdout, "WhatsApp unlinked successfully.")
 	return nil
 }
 
 func runWaStatus(cmd *cobra.Command, args []string) error {
 	if !whatsapp.IsLinked() {
-		fmt.Println("WhatsApp: Not linked")
-		fmt.Println("Run \"nk wa link\" to connect your WhatsApp account.")
+		fmt.Fprintln(stdout, "WhatsApp: Not linked")
+		fmt.Fprintln(stdout, "Run \"nk wa link\" to connect your WhatsApp account.")
 		return nil
 	}
 
@@ -749,13 +743,13 @@ func runWaStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	if client.Store.ID == nil {
-		fmt.Println("WhatsApp: Not linked (empty session)")
-		fmt.Println("Run \"nk wa link\" to connect your WhatsApp account.")
+		fmt.Fprintln(stdout, "WhatsApp: Not linked (empty session)")
+		fmt.Fprintln(stdout, "Run \"nk wa link\" to connect your WhatsApp account.")
 		return nil
 	}
 
-	fmt.Println("WhatsApp: Linked")
-	fmt.Printf("  Device: %s\n", client.Store.ID.String())
+	fmt.Fprintln(stdout, "WhatsApp: Linked")
+	fmt.Fprintf(stdout, "  Device: %s\n", client.Store.ID.String())
 
 	// Try a quick connect to verify session is still valid
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
@@ -766,9 +760,9 @@ func runWaStatus(cmd *cobra.Command, args []string) error {
 	s.Stop()
 
 	if err != nil {
-		fmt.Println("  Status: Session expired (re-link with \"nk wa link\")")
+		fmt.Fprintln(stdout, "  Status: Session expired (re-link with \"nk wa link\")")
 	} else {
-		fmt.Println("  Status: Connected")
+		fmt.Fprintln(stdout, "  Status: Connected")
 		client.Disconnect()
 	}
 
@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/sim4gh/nikte-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// Flags of `nk watch`.
+var (
+	watchTTL       string
+	watchPermanent bool
+	watchPublic    bool
+	watchInterval  time.Duration
+)
+
+func addWatchCommand() {
+	watchCmd := &cobra.Command{
+		Use:   "watch <dir>",
+		Short: "Upload each new file added to a folder until Ctrl+C",
+		Long: `Watch a folder and upload every file added to it, printing its ID (or,
+with --public, a share URL) and copying it to the clipboard. Point macOS's
+screenshot location (Cmd+Shift+5 → Options) at the folder to get a link for
+every screenshot.
+
+Files already in the folder, hidden files and subfolders are left alone. A
+file is uploaded once its size stops changing, so large copies finish first;
+one that is replaced or rewritten is uploaded again, and a failed upload is
+retried after 30 seconds. To watch in the background, use nk daemon start
+--watch-dir.
+
+The folder is checked every --interval rather than through file system
+events: a file has to be seen at the same size twice before it is uploaded
+anyway, and polling also works on network and synced folders, where events
+are often missed.
+
+Examples:
+  nk watch ~/Screenshots --ttl 24h --public
+  nk watch ~/Drop --permanent`,
+		Args: cobra.ExactArgs(1),
+		RunE: runWatch,
+	}
+	watchCmd.Flags().StringVar(&watchTTL, "ttl", "", "Time to live of the uploads (e.g. 24h, 7d)")
+	watchCmd.Flags().BoolVar(&watchPermanent, "permanent", false, "Make the uploads permanent")
+	watchCmd.Flags().BoolVarP(&watchPublic, "public", "p", false, "Create a public share link for each upload")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", time.Second, "How often to check the folder")
+
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if watchTTL != "" && watchPermanent {
+		return fmt.Errorf("cannot use both --ttl and --permanent together")
+	}
+	if watchTTL != "" {
+		if _, err := util.ParseTTL(watchTTL); err != nil {
+			return fmt.Errorf("--ttl: %w", err)
+		}
+	}
+	if watchInterval < 100*time.Millisecond {
+		return fmt.Errorf("--interval must be at least 100ms")
+	}
+	dir := args[0]
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	// Uploads take their TTL from the add flags.
+	addTTL, addPermanent = watchTTL, watchPermanent
+
+	w := newFolderWatcher(dir)
+	fmt.Fprintf(info, "Watching %s for new files (Ctrl+C to stop)\n", dir)
+	tick := time.NewTicker(watchInterval)
+	defer tick.Stop()
+	uploaded := 0
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Fprintf(info, "\nStopped; uploaded %d files\n", uploaded)
+			return nil
+		case <-tick.C:
+		}
+		for _, name := range w.poll() {
+			if watchUpload(ctx, filepath.Join(dir, name)) {
+				w.uploaded(name)
+				uploaded++
+			} else {
+				w.failed(name)
+			}
+		}
+	}
+}
+
+// watchUpload uploads one file found by `nk watch` and reports it, sharing
+// it with --public. It returns whether the upload succeeded.
+func watchUpload(ctx context.Context, path string) bool {
+	name := filepath.Base(path)
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = fmt.Sprintf(" Uploading %s...", name)
+	r := watchReporter{&spinnerReporter{s: s}}
+	s.Start()
+	res, err := uploadFile(ctx, path, r)
+	r.Stop()
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintf(stderr, "✗ %s: %v\n", name, err)
+		}
+		return false
+	}
+	res.Path = path
+
+	if watchPublic {
+		result := shareShort(ctx, res.ID)
+		switch {
+		case result.success:
+			res.ShareURL = result.data.ShareURL
+		case result.reason == "pro_required":
+			fmt.Fprintf(stderr, "Warning: %s uploaded as %s, but sharing requires a Pro subscription\n", name, res.ID)
+		default:
+			fmt.Fprintf(stderr, "Warning: %s uploaded as %s, but not shared: %s\n", name, res.ID, result.message)
+		}
+	}
+	noteHistory(*res)
+	if res.ShareURL != "" {
+		fmt.Fprintf(info, "✓ %s → %s  %s\n", name, res.ID, res.ShareURL)
+		copyToClipboard(res.ShareURL, "Share URL", autoCopyURL)
+	} else {
+		fmt.Fprintf(info, "✓ %s → %s\n", name, res.ID)
+		copyToClipboard(res.ID, "ID", autoCopyID)
+	}
+	if err := emitResult(res); err != nil {
+		fmt.Fprintln(stderr, err)
+	}
+	// Watching ends with Ctrl-C, so hooks can't wait for the command to finish.
+	runHooks(ctx, "add")
+	return true
+}
+
+// watchReporter shows an upload of `nk watch` with a spinner, leaving out
+// the file details so each upload ends up as a single line.
+type watchReporter struct{ *spinnerReporter }
+
+func (watchReporter) Infof(format string, args ...any) {}
+
+// watchRetryDelay is how long a file whose upload failed waits before it is
+// tried again.
+const watchRetryDelay = 30 * time.Second
+
+// folderWatcher finds regular files added to a folder (not its subfolders,
+// and not hidden files) once their size and modification time are the same
+// in two polls in a row, so files still being written aren't picked up half
+// done. Files there when it is created are ignored. A file counts as handled once the caller reports
+// it uploaded; one that is replaced or rewritten, or fails to upload, is
+// offered again.
+type folderWatcher struct {
+	dir     string
+	seen    map[string]fileStamp
+	pending map[string]fileStamp
+	retryAt map[string]time.Time
+}
+
+// fileStamp tells one version of a file from another.
+type fileStamp struct {
+	size    int64
+	modTime int64 // in nanoseconds
+}
+
+func newFolderWatcher(dir string) *folderWatcher {
+	w := &folderWatcher{dir: dir, pending: map[string]fileStamp{}, retryAt: map[string]time.Time{}}
+	w.seen = w.stamps()
+	return w
+}
+
+func (w *folderWatcher) stamps() map[string]fileStamp {
+	out := map[string]fileStamp{}
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return out
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") || !e.Type().IsRegular() {
+			continue
+		}
+		if fi, err := e.Info(); err == nil {
+			out[e.Name()] = fileStamp{fi.Size(), fi.ModTime().UnixNano()}
+		}
+	}
+	return out
+}
+
+// poll returns the names of the files that have settled since the last
+// call and aren't handled yet, in name order. Report each with uploaded or
+// failed.
+func (w *folderWatcher) poll() []string {
+	stamps := w.stamps()
+	for _, m := range []map[string]fileStamp{w.seen, w.pending} {
+		for name := range m {
+			if _, ok := stamps[name]; !ok {
+				delete(m, name)
+			}
+		}
+	}
+	for name := range w.retryAt {
+		if _, ok := stamps[name]; !ok {
+			delete(w.retryAt, name)
+		}
+	}
+
+	var ready []string
+	now := time.Now()
+	for name, stamp := range stamps {
+		if prev, ok := w.seen[name]; ok {
+			if prev == stamp {
+				continue
+			}
+			delete(w.seen, name)
+		}
+		if now.Before(w.retryAt[name]) {
+			continue
+		}
+		if prev, ok := w.pending[name]; !ok || prev != stamp || stamp.size == 0 {
+			w.pending[name] = stamp
+			continue
+		}
+		ready = append(ready, name)
+	}
+	sort.Strings(ready)
+	return ready
+}
+
+// uploaded marks name, as last returned by poll, as handled.
+func (w *folderWatcher) uploaded(name string) {
+	w.seen[name] = w.pending[name]
+	delete(w.pending, name)
+	delete(w.retryAt, name)
+}
+
+// failed holds name back for watchRetryDelay before poll offers it again.
+func (w *folderWatcher) failed(name string) {
+	delete(w.pending, name)
+	w.retryAt[name] = time.Now().Add(watchRetryDelay)
+}
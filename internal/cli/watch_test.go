@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFolderWatcherRetriesAndReuploads(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string, mod time.Time) {
+		t.Helper()
+		path := filepath.Join(dir, "shot.png")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	settle := func(w *folderWatcher) []string {
+		w.poll()
+		return w.poll()
+	}
+	want := []string{"shot.png"}
+	start := time.Now().Add(-time.Hour)
+
+	w := newFolderWatcher(dir)
+	write("first", start)
+	if got := settle(w); !slices.Equal(got, want) {
+		t.Fatalf("new file: poll = %v, want %v", got, want)
+	}
+
+	w.failed("shot.png")
+	if got := settle(w); len(got) != 0 {
+		t.Fatalf("right after a failure: poll = %v, want none", got)
+	}
+	w.retryAt["shot.png"] = time.Now().Add(-time.Second)
+	if got := settle(w); !slices.Equal(got, want) {
+		t.Fatalf("after the retry delay: poll = %v, want %v", got, want)
+	}
+
+	w.uploaded("shot.png")
+	if got := settle(w); len(got) != 0 {
+		t.Fatalf("after the upload: poll = %v, want none", got)
+	}
+
+	// Overwritten with a file of the same size.
+	write("other", start.Add(time.Minute))
+	if got := settle(w); !slices.Equal(got, want) {
+		t.Fatalf("replaced file: poll = %v, want %v", got, want)
+	}
+}
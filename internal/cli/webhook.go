@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/transport"
+	"github.com/sim4gh/nikte-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// webhookEvent is the JSON body POSTed to webhook_url. Text is a one-line
+// summary, which is what Slack-style incoming webhooks display.
+type webhookEvent struct {
+	Event   string      `json:"event"`
+	Time    time.Time   `json:"time"`
+	Profile string      `json:"profile,omitempty"`
+	Item    *itemResult `json:"item,omitempty"`
+	Text    string      `json:"text"`
+}
+
+func addWebhookCommand() {
+	webhookCmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "POST a JSON event to a URL when items are added, deleted or expiring",
+		Long: `Send events to your own endpoint, such as a Slack incoming webhook or a
+personal automation hub. Each item added (by nk a, nk sc, nk note, nk watch or
+the daemon) or deleted is POSTed as a JSON event, and so is each item about to
+expire while nk daemon runs with --notify-expiry. Without a subcommand the
+current URL is shown.
+
+Event body:
+  {"event": "add", "time": "...", "profile": "work",
+   "item": {"id": "abc1", "type": "file", ...}, "text": "Added abc1 (report.pdf)"}
+
+Examples:
+  nk webhook set https://hooks.slack.com/services/...   Send events there
+  nk webhook test                                       Send a test event
+  nk webhook unset                                      Stop sending events`,
+		Args: cobra.NoArgs,
+		RunE: runWebhookShow,
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set <url>",
+		Short: "Send events to url",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runWebhookSet,
+	}
+	unsetCmd := &cobra.Command{
+		Use:   "unset",
+		Short: "Stop sending events",
+		Args:  cobra.NoArgs,
+		RunE:  runWebhookUnset,
+	}
+	testCmd := &cobra.Command{
+		Use:   "test",
+		Short: "Send a test event",
+		Args:  cobra.NoArgs,
+		RunE:  runWebhookTest,
+	}
+
+	webhookCmd.AddCommand(setCmd, unsetCmd, testCmd)
+	rootCmd.AddCommand(webhookCmd)
+}
+
+func runWebhookShow(cmd *cobra.Command, args []string) error {
+	if u := webhookURL(); u != "" {
+		fmt.Fprintln(stdout, u)
+		return nil
+	}
+	fmt.Fprintln(stdout, "No webhook set. Set one with: nk webhook set <url>")
+	return nil
+}
+
+func runWebhookSet(cmd *cobra.Command, args []string) error {
+	if err := config.ValidateValue("webhook_url", args[0]); err != nil {
+		return err
+	}
+	if err := config.Set("webhook_url", args[0]); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "✓ Events will be sent to", args[0])
+	fmt.Fprintln(stdout, "Check it with: nk webhook test")
+	return nil
+}
+
+func runWebhookUnset(cmd *cobra.Command, args []string) error {
+	if err := config.Unset("webhook_url"); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "✓ Webhook removed")
+	return nil
+}
+
+func runWebhookTest(cmd *cobra.Command, args []string) error {
+	u := webhookURL()
+	if u == "" {
+		return fmt.Errorf("no webhook set. Set one with: nk webhook set <url>")
+	}
+	if err := sendWebhook(cmd.Context(), "test", nil); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "✓ Test event sent to", u)
+	return nil
+}
+
+// webhookURL is the configured webhook_url, or "" when there is none.
+func webhookURL() string {
+	cfg := config.Get()
+	if cfg == nil {
+		return ""
+	}
+	return cfg.WebhookURL
+}
+
+// sendWebhook POSTs event about res (nil for none) to webhook_url. Without a
+// webhook_url, or with NIKTE_NO_HOOKS=1, it does nothing.
+func sendWebhook(ctx context.Context, event string, res *itemResult) error {
+	u := webhookURL()
+	if u == "" || os.Getenv("NIKTE_NO_HOOKS") == "1" {
+		return nil
+	}
+	body, err := json.Marshal(webhookEvent{
+		Event:   event,
+		Time:    time.Now().UTC(),
+		Profile: config.ActiveProfile(),
+		Item:    res,
+		Text:    webhookText(event, res),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := transport.Client(config.GetTuning().HTTPTimeout).Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	transport.DrainAndClose(resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: %s answered %s", u, resp.Status)
+	}
+	return nil
+}
+
+// webhookText is the one-line summary of an event.
+func webhookText(event string, res *itemResult) string {
+	if res == nil {
+		return "Test event from nk"
+	}
+	what := res.ID
+	if name := res.Filename; name != "" {
+		what += " (" + name + ")"
+	}
+	switch event {
+	case "add":
+		if res.ShareURL != "" {
+			return fmt.Sprintf("Added %s: %s", what, res.ShareURL)
+		}
+		return "Added " + what
+	case "delete":
+		return "Deleted " + what
+	case "expiring":
+		return fmt.Sprintf("%s expires %s", what, util.FormatExpiryTime(res.ExpiresAt))
+	}
+	return event + " " + what
+}
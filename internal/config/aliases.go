@@ -0,0 +1,202 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// aliasName is what an alias may look like: it starts with a letter so it
+// reads as a name, and needs no quoting in a shell.
+var aliasName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9._-]{0,63}$`)
+
+// aliasFile holds the aliases of every profile, by profile name, since item
+// IDs belong to one account.
+type aliasFile map[string]map[string]string
+
+// AliasesPath returns the path of the file holding item aliases.
+func AliasesPath() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "aliases.json"), nil
+}
+
+func readAliases(path string) (aliasFile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return aliasFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var all aliasFile
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("bad aliases file %s: %w", path, err)
+	}
+	if all == nil {
+		all = aliasFile{}
+	}
+	return all, nil
+}
+
+// Aliases returns the active profile's aliases, name to item ID.
+func Aliases() (map[string]string, error) {
+	path, err := AliasesPath()
+	if err != nil {
+		return nil, err
+	}
+	all, err := readAliases(path)
+	if err != nil {
+		return nil, err
+	}
+	aliases := all[ActiveProfile()]
+	if aliases == nil {
+		aliases = map[string]string{}
+	}
+	return aliases, nil
+}
+
+// ResolveAlias returns the item ID s is an alias for in the active profile,
+// or s itself when it isn't one.
+func ResolveAlias(s string) string {
+	if !aliasName.MatchString(s) {
+		return s
+	}
+	aliases, err := Aliases()
+	if err != nil {
+		return s
+	}
+	if id, ok := aliases[s]; ok {
+		return id
+	}
+	return s
+}
+
+// updateAliases runs fn on the active profile's aliases under the file lock
+// and saves the result.
+func updateAliases(fn func(aliases map[string]string) error) error {
+	path, err := AliasesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	all, err := readAliases(path)
+	if err != nil {
+		return err
+	}
+	profile := ActiveProfile()
+	if all[profile] == nil {
+		all[profile] = map[string]string{}
+	}
+	if err := fn(all[profile]); err != nil {
+		return err
+	}
+	if len(all[profile]) == 0 {
+		delete(all, profile)
+	}
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// SetAlias makes name an alias for the item id, replacing what it named
+// before, which is returned.
+func SetAlias(name, id string) (previous string, err error) {
+	if !aliasName.MatchString(name) {
+		return "", fmt.Errorf("invalid alias %q: use up to 64 letters, digits, '.', '_' or '-', starting with a letter", name)
+	}
+	if id == "" {
+		return "", fmt.Errorf("item ID must not be empty")
+	}
+	err = updateAliases(func(aliases map[string]string) error {
+		previous = aliases[name]
+		aliases[name] = id
+		return nil
+	})
+	return previous, err
+}
+
+// RemoveAlias removes the alias name, reporting whether it existed.
+func RemoveAlias(name string) (bool, error) {
+	found := false
+	err := updateAliases(func(aliases map[string]string) error {
+		_, found = aliases[name]
+		delete(aliases, name)
+		return nil
+	})
+	return found, err
+}
+
+// hasAlias reports whether any alias names the item id. Most items have
+// none, so callers check this before rewriting the file.
+func hasAlias(id string) (bool, error) {
+	aliases, err := Aliases()
+	if err != nil {
+		return false, err
+	}
+	for _, target := range aliases {
+		if target == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RemoveAliasesFor removes every alias of the item id, returning their names.
+func RemoveAliasesFor(id string) ([]string, error) {
+	if named, err := hasAlias(id); err != nil || !named {
+		return nil, err
+	}
+
+	var removed []string
+	err := updateAliases(func(aliases map[string]string) error {
+		for name, target := range aliases {
+			if target == id {
+				removed = append(removed, name)
+				delete(aliases, name)
+			}
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// RetargetAliases points every alias of the item from at the item to
+// instead, for when an item is replaced by a new one, returning their names.
+func RetargetAliases(from, to string) ([]string, error) {
+	if named, err := hasAlias(from); err != nil || !named {
+		return nil, err
+	}
+
+	var moved []string
+	err := updateAliases(func(aliases map[string]string) error {
+		for name, target := range aliases {
+			if target == from {
+				moved = append(moved, name)
+				aliases[name] = to
+			}
+		}
+		return nil
+	})
+	return moved, err
+}
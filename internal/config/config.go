@@ -1,10 +1,14 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"sync"
 )
 
@@ -14,66 +18,363 @@ type Config struct {
 	IDToken      string `json:"id_token,omitempty"`
 	AccessToken  string `json:"access_token,omitempty"`
 	RefreshToken string `json:"refresh_token,omitempty"`
+	APIToken     string `json:"api_token,omitempty"`
 	LoggedInAt   string `json:"logged_in_at,omitempty"`
 	DefaultTTL   string `json:"default_ttl,omitempty"`
 	Quiet        bool   `json:"quiet,omitempty"`
+
+	// Output is the default render mode for ls/get/add: "table" (the
+	// default), "json" or "ndjson".
+	Output string `json:"output,omitempty"`
+
+	// AutoCopy limits what commands copy to the clipboard on their own: a
+	// comma-separated list of "id", "url" and "content", or "off". Unset
+	// copies everything.
+	AutoCopy string `json:"auto_copy,omitempty"`
+
+	// Trash is "on" to keep a local copy of deleted items for nk restore.
+	// Unset or "off" keeps nothing.
+	Trash string `json:"trash,omitempty"`
+
+	// TokenRefreshBuffer is how long before expiry tokens are refreshed, as a
+	// Go duration ("60s", "5m"). ClockSkew is the measured offset in seconds
+	// of the identity provider's clock from ours.
+	TokenRefreshBuffer string `json:"token_refresh_buffer,omitempty"`
+	ClockSkew          int64  `json:"clock_skew,omitempty"`
+
+	// Network and transfer tuning; see GetTuning for defaults.
+	HTTPTimeout         string `json:"http_timeout,omitempty"`
+	UploadTimeout       string `json:"upload_timeout,omitempty"`
+	DownloadTimeout     string `json:"download_timeout,omitempty"`
+	RetryCount          *int   `json:"retry_count,omitempty"`
+	UploadRetryCount    *int   `json:"upload_retry_count,omitempty"`
+	RetryBackoff        string `json:"retry_backoff,omitempty"`
+	UploadConcurrency   int    `json:"upload_concurrency,omitempty"`
+	UploadPartSize      string `json:"upload_part_size,omitempty"`
+	DownloadConcurrency int    `json:"download_concurrency,omitempty"`
+	LimitRate           string `json:"limit_rate,omitempty"`
+
+	// Proxy URLs, with optional user:password for authenticating proxies.
+	// Unset values fall back to the HTTP_PROXY/HTTPS_PROXY/NO_PROXY
+	// environment variables.
+	HTTPProxy   string `json:"http_proxy,omitempty"`
+	HTTPSProxy  string `json:"https_proxy,omitempty"`
+	SOCKS5Proxy string `json:"socks5_proxy,omitempty"`
+	NoProxy     string `json:"no_proxy,omitempty"`
+
+	// StorageProxy routes presigned storage uploads and downloads: empty
+	// uses the proxies above, "direct" bypasses them, and a URL sends
+	// storage traffic through that proxy instead.
+	StorageProxy string `json:"storage_proxy,omitempty"`
+
+	// Identity provider overrides for self-hosted or staging deployments.
+	// Empty values fall back to the built-in nikte endpoints.
+	AuthDomain         string `json:"auth_domain,omitempty"`
+	AuthClientID       string `json:"auth_client_id,omitempty"`
+	AuthTokenEndpoint  string `json:"auth_token_endpoint,omitempty"`
+	AuthDeviceEndpoint string `json:"auth_device_endpoint,omitempty"`
+
+	// Hooks: shell commands run after each item a successful add, share or
+	// delete acted on, as templates over HookFields.
+	PostAdd    string `json:"post_add,omitempty"`
+	PostShare  string `json:"post_share,omitempty"`
+	PostDelete string `json:"post_delete,omitempty"`
+
+	// WebhookURL receives a JSON event for each item added or deleted, and
+	// from the daemon for items about to expire.
+	WebhookURL string `json:"webhook_url,omitempty"`
+
+	// Regions are named screen areas for `nk sc --region preset:NAME`, as
+	// "x,y,width,height". They are shared by all profiles.
+	Regions map[string]string `json:"regions,omitempty"`
+
+	// ActiveProfile names the profile used when --profile is not given. The
+	// top-level credentials above form the implicit "default" profile.
+	ActiveProfile string              `json:"active_profile,omitempty"`
+	Profiles      map[string]*Profile `json:"profiles,omitempty"`
+
+	// Encryption is set when the tokens are encrypted at rest (see Unlock).
+	Encryption *Encryption `json:"encryption,omitempty"`
+}
+
+// Profile holds the credentials and endpoints for one named account, plus
+// optional overrides of the top-level defaults. Unset defaults are inherited.
+type Profile struct {
+	BaseURL      string `json:"baseurl,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	APIToken     string `json:"api_token,omitempty"`
+	LoggedInAt   string `json:"logged_in_at,omitempty"`
+
+	AuthDomain         string `json:"auth_domain,omitempty"`
+	AuthClientID       string `json:"auth_client_id,omitempty"`
+	AuthTokenEndpoint  string `json:"auth_token_endpoint,omitempty"`
+	AuthDeviceEndpoint string `json:"auth_device_endpoint,omitempty"`
+
+	DefaultTTL         string `json:"default_ttl,omitempty"`
+	Quiet              *bool  `json:"quiet,omitempty"`
+	TokenRefreshBuffer string `json:"token_refresh_buffer,omitempty"`
 }
 
+// DefaultProfile is the name of the implicit profile stored at the top level.
+const DefaultProfile = "default"
+
 var (
 	instance *Config
 	once     sync.Once
 	mu       sync.RWMutex
 	filePath string
+
+	// loadErr is the error from the first load, kept so every Load reports it.
+	// loadWarnings are the non-fatal problems found by Validate.
+	loadErr      error
+	loadWarnings []error
+
+	// selectedProfile overrides ActiveProfile for this process (--profile flag
+	// or NIKTE_PROFILE). Empty means use the stored active profile.
+	selectedProfile string
 )
 
 // AllowedKeys are keys that users can modify
-var AllowedKeys = []string{"baseurl", "default_ttl", "quiet"}
+var AllowedKeys = []string{"baseurl", "default_ttl", "quiet", "output", "auto_copy", "trash", "token_refresh_buffer",
+	"http_timeout", "upload_timeout", "download_timeout", "retry_count", "upload_retry_count", "retry_backoff", "upload_concurrency", "upload_part_size", "download_concurrency", "limit_rate",
+	"http_proxy", "https_proxy", "socks5_proxy", "no_proxy", "storage_proxy",
+	"auth_domain", "auth_client_id", "auth_token_endpoint", "auth_device_endpoint",
+	"post_add", "post_share", "post_delete", "webhook_url"}
 
 // ProtectedKeys are read-only keys
-var ProtectedKeys = []string{"id_token", "access_token", "refresh_token", "logged_in_at"}
+var ProtectedKeys = []string{"id_token", "access_token", "refresh_token", "api_token", "logged_in_at", "clock_skew"}
 
-// Load loads the configuration from disk
+// Load loads the configuration from disk and returns the active profile's view
+// of it (see Get).
 func Load() (*Config, error) {
-	var err error
+	cfg, err := loadFile()
+	if cfg == nil {
+		return nil, err
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	return viewLocked(), err
+}
+
+// loadFile reads the config file once and returns the raw file contents.
+func loadFile() (*Config, error) {
 	once.Do(func() {
-		filePath, err = GetConfigPath()
-		if err != nil {
+		filePath, loadErr = GetConfigPath()
+		if loadErr != nil {
 			return
 		}
 
-		instance = &Config{}
-
-		data, readErr := os.ReadFile(filePath)
-		if readErr != nil {
-			if os.IsNotExist(readErr) {
-				// Config doesn't exist yet, that's fine
-				return
-			}
-			err = readErr
-			return
+		instance, loadErr = readFile(filePath)
+		if instance == nil {
+			instance = &Config{}
 		}
-
-		if len(data) > 0 {
-			if jsonErr := json.Unmarshal(data, instance); jsonErr != nil {
-				err = jsonErr
-				return
-			}
+		if loadErr != nil {
+			loadErr = fmt.Errorf("config file %s is invalid: %w", filePath, loadErr)
 		}
 	})
 
-	return instance, err
+	return instance, loadErr
 }
 
-// Get returns the current configuration
-func Get() *Config {
+// Warnings returns the problems found when the config file was loaded that
+// didn't stop it from loading: unknown keys and out-of-range values. Invalid
+// values are ignored in favor of the defaults.
+func Warnings() []error {
 	mu.RLock()
 	defer mu.RUnlock()
+	return loadWarnings
+}
+
+// readFile parses the config file at path. A missing file is an empty config.
+// Problems that don't prevent parsing are recorded in loadWarnings.
+func readFile(path string) (*Config, error) {
+	cfg := &Config{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Config doesn't exist yet, that's fine
+			return cfg, nil
+		}
+		return nil, err
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, err
+		}
+		loadWarnings = Validate(data)
+		if len(loadWarnings) > 0 {
+			dropInvalidValues(cfg)
+		}
+	}
+	if cfg.Encryption != nil && tokenKey != "" {
+		if err := openTokens(cfg, tokenKey); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
 
+// dropInvalidValues clears settings that fail ValidateValue so the defaults
+// apply instead of a bad value failing later in a confusing way.
+func dropInvalidValues(cfg *Config) {
+	for _, key := range AllowedKeys {
+		if value, _ := Lookup(cfg, key); value != "" && ValidateValue(key, value) != nil {
+			_ = setField(cfg, key, "")
+		}
+	}
+	for _, p := range cfg.Profiles {
+		if p == nil {
+			continue
+		}
+		if p.DefaultTTL != "" && ValidateValue("default_ttl", p.DefaultTTL) != nil {
+			p.DefaultTTL = ""
+		}
+		if p.TokenRefreshBuffer != "" && ValidateValue("token_refresh_buffer", p.TokenRefreshBuffer) != nil {
+			p.TokenRefreshBuffer = ""
+		}
+	}
+}
+
+// Get returns the configuration as seen by the active profile: for the default
+// profile this is the file itself, for a named profile a copy of it with that
+// profile's credentials and base URL in place of the top-level ones. Changes
+// to the returned value aren't saved; make them through Update or Set.
+func Get() *Config {
 	if instance == nil {
 		cfg, _ := Load()
 		return cfg
 	}
-	return instance
+
+	mu.RLock()
+	defer mu.RUnlock()
+	return viewLocked()
+}
+
+// viewLocked builds the active profile's view. Caller must hold mu.
+func viewLocked() *Config {
+	name := activeProfileLocked()
+	if name == DefaultProfile || instance == nil {
+		return instance
+	}
+
+	view := *instance
+	view.Profiles = nil
+	p := instance.Profiles[name]
+	if p == nil {
+		p = &Profile{}
+	}
+	overlayProfile(&view, p)
+	return &view
+}
+
+// overlayProfile replaces view's per-profile fields with p's and applies p's
+// default overrides.
+func overlayProfile(view *Config, p *Profile) {
+	view.BaseURL = p.BaseURL
+	view.IDToken = p.IDToken
+	view.AccessToken = p.AccessToken
+	view.RefreshToken = p.RefreshToken
+	view.APIToken = p.APIToken
+	view.LoggedInAt = p.LoggedInAt
+	view.AuthDomain = p.AuthDomain
+	view.AuthClientID = p.AuthClientID
+	view.AuthTokenEndpoint = p.AuthTokenEndpoint
+	view.AuthDeviceEndpoint = p.AuthDeviceEndpoint
+	if p.DefaultTTL != "" {
+		view.DefaultTTL = p.DefaultTTL
+	}
+	if p.Quiet != nil {
+		view.Quiet = *p.Quiet
+	}
+	if p.TokenRefreshBuffer != "" {
+		view.TokenRefreshBuffer = p.TokenRefreshBuffer
+	}
+}
+
+// profileFrom extracts the per-profile credentials and endpoints of a config
+// view. Default overrides aren't included: a view holds inherited values too,
+// so they're only changed through Set and Unset.
+func profileFrom(cfg *Config) *Profile {
+	return &Profile{
+		BaseURL:      cfg.BaseURL,
+		IDToken:      cfg.IDToken,
+		AccessToken:  cfg.AccessToken,
+		RefreshToken: cfg.RefreshToken,
+		APIToken:     cfg.APIToken,
+		LoggedInAt:   cfg.LoggedInAt,
+
+		AuthDomain:         cfg.AuthDomain,
+		AuthClientID:       cfg.AuthClientID,
+		AuthTokenEndpoint:  cfg.AuthTokenEndpoint,
+		AuthDeviceEndpoint: cfg.AuthDeviceEndpoint,
+	}
+}
+
+// SelectProfile makes name the active profile for this process only, without
+// changing the stored active_profile. An empty name keeps the stored one.
+func SelectProfile(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	selectedProfile = name
+}
+
+// ActiveProfile returns the name of the profile in use.
+func ActiveProfile() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return activeProfileLocked()
+}
+
+func activeProfileLocked() string {
+	if selectedProfile != "" {
+		return selectedProfile
+	}
+	if instance != nil && instance.ActiveProfile != "" {
+		return instance.ActiveProfile
+	}
+	return DefaultProfile
+}
+
+// ProfileNames returns all known profiles, default first, the rest sorted.
+func ProfileNames() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := []string{DefaultProfile}
+	if instance == nil {
+		return names
+	}
+	var named []string
+	for name := range instance.Profiles {
+		named = append(named, name)
+	}
+	sort.Strings(named)
+	return append(names, named...)
+}
+
+// SwitchProfile stores name as the active profile. The profile must exist
+// (i.e. have been logged into) unless it is the default profile.
+func SwitchProfile(name string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return modifyLocked(func() error {
+		if name != DefaultProfile {
+			if _, ok := instance.Profiles[name]; !ok {
+				return fmt.Errorf("unknown profile %q. Log in with \"nk auth login --profile %s\" first", name, name)
+			}
+		}
+
+		instance.ActiveProfile = name
+		if name == DefaultProfile {
+			instance.ActiveProfile = ""
+		}
+		return nil
+	})
 }
 
 // Save persists the configuration to disk
@@ -84,12 +385,61 @@ func Save() error {
 	return saveLocked()
 }
 
-// saveLocked persists the configuration to disk. Caller must hold mu.
+// saveLocked persists the configuration to disk under the config file lock,
+// replacing whatever the file holds. Only Save, Replace and Clear want that;
+// changes go through modifyLocked. Caller must hold mu.
 func saveLocked() error {
-	if instance == nil {
-		return errors.New("config not loaded")
+	// Don't overwrite a file we couldn't parse with the empty config we fell
+	// back to; only Clear and Replace may do that.
+	if loadErr != nil {
+		return loadErr
+	}
+	if err := ensurePathLocked(); err != nil {
+		return err
+	}
+
+	unlock, err := lockFile(filePath)
+	if err != nil {
+		return err
 	}
+	defer unlock()
 
+	return writeLocked()
+}
+
+// modifyLocked is the read-modify-write behind every change to the config:
+// it takes the config file lock, re-reads the file so changes other nk
+// processes made since Load (such as refreshed tokens) aren't lost, runs fn
+// on instance, and saves. If fn returns an error nothing is written. Caller
+// must hold mu.
+func modifyLocked(fn func() error) error {
+	// Don't overwrite a file we couldn't parse.
+	if loadErr != nil {
+		return loadErr
+	}
+	if err := ensurePathLocked(); err != nil {
+		return err
+	}
+	unlock, err := lockFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	fresh, err := readFile(filePath)
+	if err != nil {
+		return err
+	}
+	instance = fresh
+	if err := fn(); err != nil {
+		return err
+	}
+	return writeLocked()
+}
+
+// ensurePathLocked resolves filePath and creates its directory. Caller must
+// hold mu.
+func ensurePathLocked() error {
 	if filePath == "" {
 		var err error
 		filePath, err = GetConfigPath()
@@ -99,68 +449,378 @@ func saveLocked() error {
 	}
 
 	// Ensure directory exists
-	dir := filepath.Dir(filePath)
-	if err := os.MkdirAll(dir, 0700); err != nil {
+	return os.MkdirAll(filepath.Dir(filePath), 0700)
+}
+
+// writeLocked writes instance to a temp file and renames it over the config
+// file, so readers never see a partial write. Caller must hold mu and the file
+// lock.
+func writeLocked() error {
+	if instance == nil {
+		return errors.New("config not loaded")
+	}
+
+	out, err := sealedCopyLocked()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(instance, "", "  ")
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".config-*.tmp")
 	if err != nil {
 		return err
 	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
 
-	return os.WriteFile(filePath, data, 0600)
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
 }
 
-// Set sets a configuration value
+// Update performs a read-modify-write of the active profile's view (see Get)
+// that is safe against other nk processes: it takes the config file lock,
+// re-reads the file so changes made since Load aren't lost, runs fn, and saves.
+// If fn returns an error nothing is written.
+//
+// fn must not call other functions in this package.
+func Update(fn func(cfg *Config) error) error {
+	if _, err := loadFile(); err != nil && instance == nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	return modifyLocked(func() error {
+		view := viewLocked()
+		if err := fn(view); err != nil {
+			return err
+		}
+		storeViewLocked(view)
+		return nil
+	})
+}
+
+// defaultKeys are top-level defaults that a named profile may override.
+var defaultKeys = []string{"default_ttl", "quiet", "token_refresh_buffer"}
+
+// profileKeys are stored per profile rather than at the top level.
+var profileKeys = []string{"baseurl", "id_token", "access_token", "refresh_token", "api_token", "logged_in_at",
+	"auth_domain", "auth_client_id", "auth_token_endpoint", "auth_device_endpoint"}
+
+// Set sets a configuration value. Per-profile keys (credentials, baseurl) are
+// written to the active profile.
 func Set(key, value string) error {
 	mu.Lock()
 	defer mu.Unlock()
 
-	if instance == nil {
-		instance = &Config{}
+	return modifyLocked(func() error {
+		if name := activeProfileLocked(); name != DefaultProfile && contains(defaultKeys, key) {
+			p := profileLocked(name)
+			switch key {
+			case "default_ttl":
+				p.DefaultTTL = value
+			case "quiet":
+				quiet := value == "true"
+				p.Quiet = &quiet
+			case "token_refresh_buffer":
+				p.TokenRefreshBuffer = value
+			}
+			return nil
+		}
+
+		if name := activeProfileLocked(); name != DefaultProfile && contains(profileKeys, key) {
+			view := viewLocked()
+			if err := setField(view, key, value); err != nil {
+				return err
+			}
+			setProfileLocked(name, profileFrom(view))
+			return nil
+		}
+
+		return setField(instance, key, value)
+	})
+}
+
+// Unset clears a key. In a named profile, clearing one of the defaults removes
+// the profile's override so the top-level value applies again.
+func Unset(key string) error {
+	mu.Lock()
+	name := activeProfileLocked()
+	if name != DefaultProfile && contains(defaultKeys, key) {
+		defer mu.Unlock()
+		if instance == nil || instance.Profiles[name] == nil {
+			return nil
+		}
+		return modifyLocked(func() error {
+			p := instance.Profiles[name]
+			if p == nil {
+				return nil
+			}
+			switch key {
+			case "default_ttl":
+				p.DefaultTTL = ""
+			case "quiet":
+				p.Quiet = nil
+			case "token_refresh_buffer":
+				p.TokenRefreshBuffer = ""
+			}
+			return nil
+		})
+	}
+	mu.Unlock()
+
+	if key == "quiet" {
+		return Set(key, "false")
 	}
+	return Set(key, "")
+}
+
+// Inherited reports whether key's value in the active profile comes from the
+// top-level defaults rather than the profile itself.
+func Inherited(key string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	name := activeProfileLocked()
+	if name == DefaultProfile || !contains(defaultKeys, key) {
+		return false
+	}
+	p := instance.Profiles[name]
+	if p == nil {
+		return true
+	}
+	switch key {
+	case "default_ttl":
+		return p.DefaultTTL == ""
+	case "quiet":
+		return p.Quiet == nil
+	case "token_refresh_buffer":
+		return p.TokenRefreshBuffer == ""
+	}
+	return false
+}
+
+// profileLocked returns the named profile, creating it if needed. Caller must
+// hold mu.
+func profileLocked(name string) *Profile {
+	if instance.Profiles == nil {
+		instance.Profiles = make(map[string]*Profile)
+	}
+	p := instance.Profiles[name]
+	if p == nil {
+		p = &Profile{}
+		instance.Profiles[name] = p
+	}
+	return p
+}
+
+// setProfileLocked replaces the named profile's credentials and endpoints,
+// keeping its default overrides. Caller must hold mu.
+func setProfileLocked(name string, p *Profile) {
+	old := profileLocked(name)
+	p.DefaultTTL = old.DefaultTTL
+	p.Quiet = old.Quiet
+	p.TokenRefreshBuffer = old.TokenRefreshBuffer
+	instance.Profiles[name] = p
+}
 
+// setField assigns a single key on cfg.
+func setField(cfg *Config, key, value string) error {
 	switch key {
 	case "baseurl":
-		instance.BaseURL = value
+		cfg.BaseURL = value
 	case "id_token":
-		instance.IDToken = value
+		cfg.IDToken = value
 	case "access_token":
-		instance.AccessToken = value
+		cfg.AccessToken = value
 	case "refresh_token":
-		instance.RefreshToken = value
+		cfg.RefreshToken = value
+	case "api_token":
+		cfg.APIToken = value
 	case "logged_in_at":
-		instance.LoggedInAt = value
+		cfg.LoggedInAt = value
 	case "default_ttl":
-		instance.DefaultTTL = value
+		cfg.DefaultTTL = value
 	case "quiet":
-		instance.Quiet = value == "true"
+		cfg.Quiet = value == "true"
+	case "output":
+		cfg.Output = value
+	case "auto_copy":
+		cfg.AutoCopy = value
+	case "trash":
+		cfg.Trash = value
+	case "token_refresh_buffer":
+		cfg.TokenRefreshBuffer = value
+	case "clock_skew":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid clock_skew %q", value)
+		}
+		cfg.ClockSkew = n
+	case "auth_domain":
+		cfg.AuthDomain = value
+	case "auth_client_id":
+		cfg.AuthClientID = value
+	case "auth_token_endpoint":
+		cfg.AuthTokenEndpoint = value
+	case "auth_device_endpoint":
+		cfg.AuthDeviceEndpoint = value
+	case "http_proxy":
+		cfg.HTTPProxy = value
+	case "https_proxy":
+		cfg.HTTPSProxy = value
+	case "socks5_proxy":
+		cfg.SOCKS5Proxy = value
+	case "no_proxy":
+		cfg.NoProxy = value
+	case "storage_proxy":
+		cfg.StorageProxy = value
+	case "post_add":
+		cfg.PostAdd = value
+	case "post_share":
+		cfg.PostShare = value
+	case "post_delete":
+		cfg.PostDelete = value
+	case "webhook_url":
+		cfg.WebhookURL = value
 	default:
+		if contains(tuningKeys, key) {
+			return setTuningField(cfg, key, value)
+		}
 		return errors.New("unknown config key: " + key)
 	}
+	return nil
+}
 
-	return saveLocked()
+// storeViewLocked writes a view back into instance. Caller must hold mu.
+func storeViewLocked(cfg *Config) {
+	name := activeProfileLocked()
+	if name != DefaultProfile {
+		if instance == nil {
+			instance = &Config{}
+		}
+		setProfileLocked(name, profileFrom(cfg))
+		return
+	}
+
+	// Profile bookkeeping and token encryption aren't part of the default
+	// profile's view of the world; keep them when a caller hands back a config
+	// built from scratch.
+	if instance != nil && cfg != instance {
+		cfg.ActiveProfile = instance.ActiveProfile
+		cfg.Profiles = instance.Profiles
+		cfg.Encryption = instance.Encryption
+	}
+	instance = cfg
 }
 
-// SetConfig updates the entire config at once and saves
-func SetConfig(cfg *Config) error {
+// Replace validates data as a complete config file (see Validate) and, if it
+// is valid, saves it in place of the current configuration.
+func Replace(data []byte) error {
+	if errs := Validate(data); len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	cfg := &Config{}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return err
+		}
+	}
+
 	mu.Lock()
 	defer mu.Unlock()
 
+	if cfg.Encryption != nil && tokenKey != "" {
+		if err := openTokens(cfg, tokenKey); err != nil {
+			return err
+		}
+	}
 	instance = cfg
+	loadErr = nil
 	return saveLocked()
 }
 
-// Clear removes all configuration
+// Clear removes all configuration, including every profile
 func Clear() error {
 	mu.Lock()
 	defer mu.Unlock()
 
 	instance = &Config{}
+	loadErr = nil
+	tokenKey = ""
 	return saveLocked()
 }
 
+// ClearProfile logs out of the active profile. A named profile is removed
+// unless it has endpoint or default overrides to keep; the default profile
+// loses only its credentials, keeping its settings and the other profiles.
+func ClearProfile() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return modifyLocked(func() error {
+		// Identity provider overrides describe a deployment rather than a
+		// session, so they (and the base URL that goes with them) survive a
+		// logout, as do a named profile's default overrides.
+		name := activeProfileLocked()
+		if name != DefaultProfile {
+			p := instance.Profiles[name]
+			if p == nil || (!p.hasAuthOverrides() && !p.hasDefaultOverrides()) {
+				delete(instance.Profiles, name)
+				if instance.ActiveProfile == name {
+					instance.ActiveProfile = ""
+				}
+				return nil
+			}
+			instance.Profiles[name] = &Profile{
+				BaseURL:            p.BaseURL,
+				AuthDomain:         p.AuthDomain,
+				AuthClientID:       p.AuthClientID,
+				AuthTokenEndpoint:  p.AuthTokenEndpoint,
+				AuthDeviceEndpoint: p.AuthDeviceEndpoint,
+				DefaultTTL:         p.DefaultTTL,
+				Quiet:              p.Quiet,
+				TokenRefreshBuffer: p.TokenRefreshBuffer,
+			}
+			return nil
+		}
+
+		kept := *instance
+		kept.IDToken, kept.AccessToken, kept.RefreshToken, kept.APIToken = "", "", "", ""
+		kept.LoggedInAt, kept.ClockSkew = "", 0
+		if !profileFrom(instance).hasAuthOverrides() {
+			kept.BaseURL = ""
+		}
+		instance = &kept
+		return nil
+	})
+}
+
+func (p *Profile) hasDefaultOverrides() bool {
+	return p.DefaultTTL != "" || p.Quiet != nil || p.TokenRefreshBuffer != ""
+}
+
+func (p *Profile) hasAuthOverrides() bool {
+	return p.AuthDomain != "" || p.AuthClientID != "" || p.AuthTokenEndpoint != "" || p.AuthDeviceEndpoint != ""
+}
+
 // Path returns the config file path
 func Path() string {
 	if filePath == "" {
@@ -171,17 +831,16 @@ func Path() string {
 
 // IsProtectedKey checks if a key is protected
 func IsProtectedKey(key string) bool {
-	for _, k := range ProtectedKeys {
-		if k == key {
-			return true
-		}
-	}
-	return false
+	return contains(ProtectedKeys, key)
 }
 
 // IsAllowedKey checks if a key is user-modifiable
 func IsAllowedKey(key string) bool {
-	for _, k := range AllowedKeys {
+	return contains(AllowedKeys, key)
+}
+
+func contains(list []string, key string) bool {
+	for _, k := range list {
 		if k == key {
 			return true
 		}
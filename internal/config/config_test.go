@@ -0,0 +1,117 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// useTempConfig points the package at a config file of its own holding
+// initial, as another nk process would have left it, and loads it.
+func useTempConfig(t *testing.T, initial map[string]any) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("APPDATA", dir)
+	t.Setenv("HOME", dir)
+	reset := func() {
+		once = sync.Once{}
+		instance, filePath, loadErr, loadWarnings = nil, "", nil, nil
+		selectedProfile, tokenKey = "", ""
+	}
+	reset()
+	t.Cleanup(reset)
+
+	path, err := GetConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeConfigFile(t, path, initial)
+	if _, err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writeConfigFile(t *testing.T, path string, values map[string]any) {
+	t.Helper()
+	data, err := json.Marshal(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readConfigFile(t *testing.T, path string) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]any{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		t.Fatal(err)
+	}
+	return values
+}
+
+func TestChangesKeepOtherProcessesWrites(t *testing.T) {
+	path := useTempConfig(t, map[string]any{"refresh_token": "old", "access_token": "old"})
+
+	// Another nk process refreshes the tokens after this one loaded the file.
+	writeConfigFile(t, path, map[string]any{"refresh_token": "refreshed", "access_token": "refreshed"})
+	if err := Set("default_ttl", "7d"); err != nil {
+		t.Fatal(err)
+	}
+	writeConfigFile(t, path, map[string]any{"refresh_token": "again", "access_token": "again", "default_ttl": "7d"})
+	if err := Update(func(cfg *Config) error {
+		cfg.IDToken = "id"
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set("clock_skew", "3"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readConfigFile(t, path)
+	for key, want := range map[string]any{"refresh_token": "again", "access_token": "again", "default_ttl": "7d",
+		"id_token": "id", "clock_skew": float64(3)} {
+		if got[key] != want {
+			t.Errorf("%s = %v, want %v (file %v)", key, got[key], want, got)
+		}
+	}
+}
+
+func TestClearProfileKeepsSettings(t *testing.T) {
+	path := useTempConfig(t, map[string]any{
+		"refresh_token": "r", "access_token": "a", "id_token": "i", "api_token": "nk_x",
+		"logged_in_at": "2025-03-14T09:30:00Z", "clock_skew": 3, "baseurl": "https://api.example",
+		"https_proxy": "http://proxy:8080", "trash": "on", "webhook_url": "https://hooks.example/x",
+		"post_add": "notify {{.ID}}", "output": "json", "retry_count": 2, "default_ttl": "7d",
+	})
+	if err := ClearProfile(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readConfigFile(t, path)
+	for _, key := range []string{"refresh_token", "access_token", "id_token", "api_token", "logged_in_at", "clock_skew", "baseurl"} {
+		if _, ok := got[key]; ok {
+			t.Errorf("%s survived the logout", key)
+		}
+	}
+	for key, want := range map[string]any{"https_proxy": "http://proxy:8080", "trash": "on",
+		"webhook_url": "https://hooks.example/x", "post_add": "notify {{.ID}}", "output": "json",
+		"retry_count": float64(2), "default_ttl": "7d"} {
+		if got[key] != want {
+			t.Errorf("%s = %v after logout, want %v", key, got[key], want)
+		}
+	}
+}
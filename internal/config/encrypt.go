@@ -0,0 +1,293 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sim4gh/nikte-cli/internal/crypto"
+	"github.com/sim4gh/nikte-cli/internal/platform"
+)
+
+// Ways the stored tokens can be encrypted at rest (nk config encrypt).
+const (
+	EncryptPassphrase = "passphrase"
+	EncryptKeychain   = "keychain"
+)
+
+// PassphraseEnvVar supplies the passphrase for encrypted tokens without a
+// prompt.
+const PassphraseEnvVar = "NIKTE_CONFIG_PASSPHRASE"
+
+// keychainAccount is the OS keychain entry holding the random key used in
+// keychain mode.
+const keychainAccount = "config-key"
+
+// ErrLocked is returned when encrypted tokens are needed (or would be
+// written) before Unlock.
+var ErrLocked = errors.New("stored tokens are encrypted; unlock them first")
+
+// Encryption records how the tokens in the config file are protected. While
+// it is set, every profile's tokens are kept only in Tokens, sealed with
+// crypto.EncryptBytes, and the plaintext token fields stay empty on disk.
+type Encryption struct {
+	Mode   string `json:"mode"`
+	Tokens string `json:"tokens,omitempty"`
+}
+
+// PassphrasePrompt asks the user for the passphrase in passphrase mode. The
+// CLI sets it to a terminal prompt; without it only PassphraseEnvVar works.
+var PassphrasePrompt func() (string, error)
+
+// tokenKey is the passphrase or keychain key the tokens were unlocked with,
+// kept to re-seal them on save. Empty while the tokens are locked.
+var tokenKey string
+
+// tokenSet is one profile's sealed credentials.
+type tokenSet struct {
+	IDToken      string `json:"id_token,omitempty"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	APIToken     string `json:"api_token,omitempty"`
+}
+
+func (t tokenSet) empty() bool {
+	return t == tokenSet{}
+}
+
+// IsEncrypted reports whether the stored tokens are encrypted, and how.
+func IsEncrypted() (bool, string) {
+	_, _ = loadFile()
+	mu.RLock()
+	defer mu.RUnlock()
+	if instance == nil || instance.Encryption == nil {
+		return false, ""
+	}
+	return true, instance.Encryption.Mode
+}
+
+// Locked reports whether the stored tokens are encrypted and not yet unlocked
+// in this process. Token fields read as empty while locked.
+func Locked() bool {
+	encrypted, _ := IsEncrypted()
+	return encrypted && tokenKey == ""
+}
+
+// Unlock decrypts the stored tokens, getting the key from the keychain,
+// PassphraseEnvVar or PassphrasePrompt. It is a no-op if they aren't
+// encrypted or are already unlocked.
+func Unlock() error {
+	if _, err := loadFile(); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	if instance.Encryption == nil || tokenKey != "" {
+		return nil
+	}
+	key, err := resolveKey(instance.Encryption.Mode)
+	if err != nil {
+		return err
+	}
+	if err := openTokens(instance, key); err != nil {
+		return err
+	}
+	tokenKey = key
+	return nil
+}
+
+// Encrypt starts encrypting the stored tokens. In passphrase mode key is the
+// passphrase; in keychain mode it is ignored and a random key is created and
+// stored in the OS keychain.
+func Encrypt(mode, key string) error {
+	if _, err := loadFile(); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	if instance.Encryption != nil {
+		return fmt.Errorf("tokens are already encrypted (%s)", instance.Encryption.Mode)
+	}
+
+	switch mode {
+	case EncryptPassphrase:
+		if key == "" {
+			return errors.New("passphrase cannot be empty")
+		}
+	case EncryptKeychain:
+		raw := make([]byte, 32)
+		if _, err := rand.Read(raw); err != nil {
+			return err
+		}
+		key = base64.StdEncoding.EncodeToString(raw)
+		if err := platform.KeychainSet(keychainAccount, key); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown encryption mode %q", mode)
+	}
+
+	err := modifyLocked(func() error {
+		if instance.Encryption != nil {
+			return fmt.Errorf("tokens are already encrypted (%s)", instance.Encryption.Mode)
+		}
+		instance.Encryption = &Encryption{Mode: mode}
+		tokenKey = key
+		return nil
+	})
+	if err != nil {
+		if tokenKey == key {
+			// fn ran but the write failed.
+			instance.Encryption = nil
+		}
+		tokenKey = ""
+		if mode == EncryptKeychain {
+			_ = platform.KeychainDelete(keychainAccount)
+		}
+		return err
+	}
+	return nil
+}
+
+// Decrypt stores the tokens in plaintext again. They must be unlocked first.
+func Decrypt() error {
+	if err := Unlock(); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	if instance.Encryption == nil {
+		return errors.New("tokens are not encrypted")
+	}
+	mode := instance.Encryption.Mode
+	err := modifyLocked(func() error {
+		instance.Encryption = nil
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	tokenKey = ""
+	if mode == EncryptKeychain {
+		_ = platform.KeychainDelete(keychainAccount)
+	}
+	return nil
+}
+
+// resolveKey gets the key for mode without touching config state.
+func resolveKey(mode string) (string, error) {
+	switch mode {
+	case EncryptKeychain:
+		key, err := platform.KeychainGet(keychainAccount)
+		if err != nil {
+			return "", fmt.Errorf("cannot read the config key from the OS keychain: %w", err)
+		}
+		return key, nil
+	case EncryptPassphrase:
+		if env := os.Getenv(PassphraseEnvVar); env != "" {
+			return env, nil
+		}
+		if PassphrasePrompt == nil {
+			return "", fmt.Errorf("%w: set %s", ErrLocked, PassphraseEnvVar)
+		}
+		pass, err := PassphrasePrompt()
+		if err != nil {
+			return "", fmt.Errorf("%w: %v (or set %s)", ErrLocked, err, PassphraseEnvVar)
+		}
+		return pass, nil
+	}
+	return "", fmt.Errorf("unknown encryption mode %q", mode)
+}
+
+// openTokens decrypts cfg.Encryption.Tokens with key into cfg's token fields.
+func openTokens(cfg *Config, key string) error {
+	if cfg.Encryption.Tokens == "" {
+		return nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(cfg.Encryption.Tokens)
+	if err != nil {
+		return fmt.Errorf("encrypted tokens are corrupt: %w", err)
+	}
+	plaintext, err := crypto.DecryptBytes(key, sealed)
+	if err != nil {
+		return fmt.Errorf("cannot decrypt stored tokens: %w", err)
+	}
+	var sets map[string]tokenSet
+	if err := json.Unmarshal(plaintext, &sets); err != nil {
+		return fmt.Errorf("encrypted tokens are corrupt: %w", err)
+	}
+
+	for name, t := range sets {
+		if name == DefaultProfile {
+			cfg.IDToken, cfg.AccessToken, cfg.RefreshToken, cfg.APIToken =
+				t.IDToken, t.AccessToken, t.RefreshToken, t.APIToken
+			continue
+		}
+		if p := cfg.Profiles[name]; p != nil {
+			p.IDToken, p.AccessToken, p.RefreshToken, p.APIToken =
+				t.IDToken, t.AccessToken, t.RefreshToken, t.APIToken
+		}
+	}
+	return nil
+}
+
+// sealedCopyLocked returns what to write for instance: instance itself when
+// the tokens aren't encrypted, otherwise a copy with every profile's tokens
+// moved into Encryption.Tokens. While locked the sealed tokens read from disk
+// are written back unchanged. Caller must hold mu.
+func sealedCopyLocked() (*Config, error) {
+	if instance.Encryption == nil {
+		return instance, nil
+	}
+
+	out := *instance
+	out.Encryption = &Encryption{Mode: instance.Encryption.Mode, Tokens: instance.Encryption.Tokens}
+	sets := map[string]tokenSet{}
+	if t := (tokenSet{instance.IDToken, instance.AccessToken, instance.RefreshToken, instance.APIToken}); !t.empty() {
+		sets[DefaultProfile] = t
+	}
+	out.IDToken, out.AccessToken, out.RefreshToken, out.APIToken = "", "", "", ""
+
+	if instance.Profiles != nil {
+		out.Profiles = make(map[string]*Profile, len(instance.Profiles))
+		for name, p := range instance.Profiles {
+			if p == nil {
+				continue
+			}
+			cp := *p
+			if t := (tokenSet{p.IDToken, p.AccessToken, p.RefreshToken, p.APIToken}); !t.empty() {
+				sets[name] = t
+			}
+			cp.IDToken, cp.AccessToken, cp.RefreshToken, cp.APIToken = "", "", "", ""
+			out.Profiles[name] = &cp
+		}
+	}
+
+	if tokenKey == "" {
+		if len(sets) > 0 {
+			return nil, ErrLocked
+		}
+		return &out, nil
+	}
+
+	out.Encryption.Tokens = ""
+	if len(sets) > 0 {
+		plaintext, err := json.Marshal(sets)
+		if err != nil {
+			return nil, err
+		}
+		sealed, err := crypto.EncryptBytes(tokenKey, plaintext)
+		if err != nil {
+			return nil, err
+		}
+		out.Encryption.Tokens = base64.StdEncoding.EncodeToString(sealed)
+	}
+	instance.Encryption.Tokens = out.Encryption.Tokens
+	return &out, nil
+}
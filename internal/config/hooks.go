@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// HookFields are the item fields the post_add, post_share and post_delete
+// hook commands can use, as {{.ID}} etc.
+var HookFields = []string{"ID", "Type", "Filename", "Path", "Size", "URL", "ExpiresAt", "Command", "Profile"}
+
+// ParseHook parses a hook command as a text/template over HookFields,
+// rejecting fields that don't exist.
+func ParseHook(key, value string) (*template.Template, error) {
+	tmpl, err := template.New(key).Option("missingkey=error").Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a valid template: %v", key, err)
+	}
+	sample := make(map[string]string, len(HookFields))
+	for _, f := range HookFields {
+		sample[f] = ""
+	}
+	if err := tmpl.Execute(io.Discard, sample); err != nil {
+		return nil, fmt.Errorf("%q may only use the fields %s", key, "{{."+strings.Join(HookFields, "}}, {{.")+"}}")
+	}
+	return tmpl, nil
+}
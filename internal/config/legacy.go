@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// LegacyPaths returns the places the Node.js oio CLI may have kept its config,
+// most likely first: its own config directory, then the default location of
+// the `conf` package it was built on.
+func LegacyPaths() []string {
+	var paths []string
+	if dir, err := appConfigDir("oio"); err == nil {
+		paths = append(paths, filepath.Join(dir, "config.json"))
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return paths
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		paths = append(paths, filepath.Join(home, "Library", "Preferences", "oio-nodejs", "config.json"))
+	case "windows":
+		appData := os.Getenv("APPDATA")
+		if appData == "" {
+			appData = filepath.Join(home, "AppData", "Roaming")
+		}
+		paths = append(paths, filepath.Join(appData, "oio-nodejs", "Config", "config.json"))
+	default:
+		if dir, err := appConfigDir("oio-nodejs"); err == nil {
+			paths = append(paths, filepath.Join(dir, "config.json"))
+		}
+	}
+	return paths
+}
+
+// FindLegacy returns the first of LegacyPaths that exists.
+func FindLegacy() (string, bool) {
+	for _, path := range LegacyPaths() {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// legacyKeys maps each migrated setting to the spellings the Node CLI used
+// over its versions.
+var legacyKeys = map[string][]string{
+	"baseurl":       {"baseurl", "baseUrl", "baseURL"},
+	"id_token":      {"id_token", "idToken"},
+	"access_token":  {"access_token", "accessToken"},
+	"refresh_token": {"refresh_token", "refreshToken"},
+	"logged_in_at":  {"logged_in_at", "loggedInAt"},
+	"default_ttl":   {"default_ttl", "defaultTtl", "defaultTTL"},
+	"quiet":         {"quiet"},
+}
+
+// ReadLegacy reads a Node CLI config file into a Config. Unknown keys are
+// ignored, as are values that wouldn't pass `nk config set`.
+func ReadLegacy(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s is not valid JSON: %w", path, err)
+	}
+
+	cfg := &Config{}
+	for key, names := range legacyKeys {
+		for _, name := range names {
+			v, ok := raw[name]
+			if !ok {
+				continue
+			}
+			value := fmt.Sprint(v)
+			if IsAllowedKey(key) && ValidateValue(key, value) != nil {
+				break
+			}
+			if err := setField(cfg, key, value); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	return cfg, nil
+}
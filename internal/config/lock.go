@@ -0,0 +1,26 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// lockFile takes an exclusive advisory lock on path+".lock", blocking until
+// other nk processes release it. The returned function releases the lock.
+//
+// The lock only coordinates processes: within one process, mu serializes
+// access, and taking the file lock twice from the same process deadlocks.
+func lockFile(path string) (func(), error) {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config lock: %w", err)
+	}
+	if err := lockFD(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock config: %w", err)
+	}
+	return func() {
+		_ = unlockFD(f)
+		f.Close()
+	}, nil
+}
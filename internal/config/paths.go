@@ -8,19 +8,25 @@ import (
 
 // getConfigDir returns the platform-specific configuration directory
 func getConfigDir() (string, error) {
+	return appConfigDir("nikte")
+}
+
+// appConfigDir returns the platform-specific configuration directory for the
+// named application.
+func appConfigDir(name string) (string, error) {
 	var configDir string
 
 	switch runtime.GOOS {
 	case "darwin":
-		// macOS: ~/Library/Application Support/oio
+		// macOS: ~/Library/Application Support/<name>
 		home, err := os.UserHomeDir()
 		if err != nil {
 			return "", err
 		}
-		configDir = filepath.Join(home, "Library", "Application Support", "nikte")
+		configDir = filepath.Join(home, "Library", "Application Support", name)
 
 	case "windows":
-		// Windows: %APPDATA%/oio
+		// Windows: %APPDATA%/<name>
 		appData := os.Getenv("APPDATA")
 		if appData == "" {
 			home, err := os.UserHomeDir()
@@ -29,10 +35,10 @@ func getConfigDir() (string, error) {
 			}
 			appData = filepath.Join(home, "AppData", "Roaming")
 		}
-		configDir = filepath.Join(appData, "nikte")
+		configDir = filepath.Join(appData, name)
 
 	default:
-		// Linux and others: ~/.config/oio
+		// Linux and others: ~/.config/<name>
 		configHome := os.Getenv("XDG_CONFIG_HOME")
 		if configHome == "" {
 			home, err := os.UserHomeDir()
@@ -41,7 +47,7 @@ func getConfigDir() (string, error) {
 			}
 			configHome = filepath.Join(home, ".config")
 		}
-		configDir = filepath.Join(configHome, "nikte")
+		configDir = filepath.Join(configHome, name)
 	}
 
 	return configDir, nil
@@ -55,3 +61,72 @@ func GetConfigPath() (string, error) {
 	}
 	return filepath.Join(dir, "config.json"), nil
 }
+
+// CacheDir returns the directory holding cached API responses.
+func CacheDir() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cache"), nil
+}
+
+// UploadStateDir returns the directory holding progress files for
+// interrupted multipart uploads.
+func UploadStateDir() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "uploads"), nil
+}
+
+// DaemonDir returns the directory holding the background daemon's control
+// socket and log.
+func DaemonDir() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "daemon"), nil
+}
+
+// SyncStateDir returns the directory holding what `nk sync` has uploaded
+// from each folder.
+func SyncStateDir() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sync"), nil
+}
+
+// HistoryPath returns the path of the local activity journal kept by
+// `nk history`.
+func HistoryPath() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.jsonl"), nil
+}
+
+// TrashDir returns the directory holding what `nk delete` saved of deleted
+// items, for `nk restore`.
+func TrashDir() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "trash"), nil
+}
+
+// FakeBackendPath returns where `nk --fake-backend` keeps its items between
+// commands.
+func FakeBackendPath() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "fake-backend.json"), nil
+}
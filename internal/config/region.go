@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Region is a screen area to capture, in screen coordinates.
+type Region struct {
+	X, Y, Width, Height int
+}
+
+func (r Region) String() string {
+	return fmt.Sprintf("%d,%d,%d,%d", r.X, r.Y, r.Width, r.Height)
+}
+
+// ParseRegion parses "x,y,width,height", as taken by --region.
+func ParseRegion(s string) (Region, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return Region{}, fmt.Errorf("region %q must be x,y,width,height", s)
+	}
+	var n [4]int
+	for i, p := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return Region{}, fmt.Errorf("region %q must be x,y,width,height in whole numbers", s)
+		}
+		n[i] = v
+	}
+	r := Region{X: n[0], Y: n[1], Width: n[2], Height: n[3]}
+	if r.Width <= 0 || r.Height <= 0 {
+		return Region{}, fmt.Errorf("region %q must have a positive width and height", s)
+	}
+	return r, nil
+}
+
+// validRegionName checks a preset name for a Region.
+func validRegionName(name string) error {
+	if name == "" || strings.ContainsAny(name, " ,:/\\") {
+		return fmt.Errorf("invalid region name %q", name)
+	}
+	return nil
+}
+
+// LookupRegion returns the region saved as name.
+func LookupRegion(name string) (Region, error) {
+	cfg := Get()
+	if cfg == nil || cfg.Regions[name] == "" {
+		return Region{}, fmt.Errorf("no region named %q (see --region list)", name)
+	}
+	return ParseRegion(cfg.Regions[name])
+}
+
+// RegionNames returns the names of the saved regions, sorted.
+func RegionNames() []string {
+	cfg := Get()
+	if cfg == nil {
+		return nil
+	}
+	names := make([]string, 0, len(cfg.Regions))
+	for name := range cfg.Regions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SaveRegion saves r as name, replacing any region of that name.
+func SaveRegion(name string, r Region) error {
+	if err := validRegionName(name); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	return modifyLocked(func() error {
+		if instance.Regions == nil {
+			instance.Regions = make(map[string]string)
+		}
+		instance.Regions[name] = r.String()
+		return nil
+	})
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/util"
+)
+
+// Tuning holds the network and transfer settings, with defaults applied.
+type Tuning struct {
+	HTTPTimeout         time.Duration // per API request
+	UploadTimeout       time.Duration // per upload part attempt
+	DownloadTimeout     time.Duration // per download request; 0 means none
+	RetryCount          int           // attempts after the first, for retryable API failures
+	UploadRetryCount    int           // attempts after the first, for each upload part
+	RetryBackoff        time.Duration // base delay, doubled (with jitter) on each retry
+	UploadConcurrency   int           // parts uploaded in parallel
+	UploadPartSize      int64         // requested part size in bytes; 0 picks one from the file size
+	DownloadConcurrency int           // ranged requests per download; 1 disables ranging
+	LimitRate           int64         // upload and download bandwidth in bytes per second; 0 means unlimited
+}
+
+// DefaultTuning is used for any tuning key that isn't set.
+var DefaultTuning = Tuning{
+	HTTPTimeout:         60 * time.Second,
+	UploadTimeout:       5 * time.Minute,
+	DownloadTimeout:     0,
+	RetryCount:          3,
+	UploadRetryCount:    7,
+	RetryBackoff:        2 * time.Second,
+	UploadConcurrency:   2,
+	UploadPartSize:      0,
+	DownloadConcurrency: 1,
+	LimitRate:           0,
+}
+
+// tuningKeys are the user-settable keys read by GetTuning.
+var tuningKeys = []string{"http_timeout", "upload_timeout", "download_timeout", "retry_count", "upload_retry_count", "retry_backoff",
+	"upload_concurrency", "upload_part_size", "download_concurrency", "limit_rate"}
+
+// GetTuning returns the configured tuning values. Invalid or unset values fall
+// back to DefaultTuning; `nk config set` validates them on the way in.
+func GetTuning() Tuning {
+	t := DefaultTuning
+	cfg := Get()
+	if cfg == nil {
+		return t
+	}
+
+	if d, err := time.ParseDuration(cfg.HTTPTimeout); err == nil && d > 0 {
+		t.HTTPTimeout = d
+	}
+	if d, err := time.ParseDuration(cfg.UploadTimeout); err == nil && d > 0 {
+		t.UploadTimeout = d
+	}
+	if d, err := time.ParseDuration(cfg.DownloadTimeout); err == nil && d >= 0 {
+		t.DownloadTimeout = d
+	}
+	if cfg.RetryCount != nil && *cfg.RetryCount >= 0 {
+		t.RetryCount = *cfg.RetryCount
+	}
+	if cfg.UploadRetryCount != nil && *cfg.UploadRetryCount >= 0 {
+		t.UploadRetryCount = *cfg.UploadRetryCount
+	}
+	if d, err := time.ParseDuration(cfg.RetryBackoff); err == nil && d >= 0 {
+		t.RetryBackoff = d
+	}
+	if cfg.UploadConcurrency > 0 {
+		t.UploadConcurrency = cfg.UploadConcurrency
+	}
+	if n, err := util.ParseBytes(cfg.UploadPartSize); err == nil && n > 0 {
+		t.UploadPartSize = n
+	}
+	if cfg.DownloadConcurrency > 0 {
+		t.DownloadConcurrency = cfg.DownloadConcurrency
+	}
+	if n, err := ParseLimitRate(cfg.LimitRate); err == nil {
+		t.LimitRate = n
+	}
+	return t
+}
+
+// minLimitRate keeps a bandwidth cap from being so low that part uploads
+// time out long before they finish.
+const minLimitRate = 16 << 10
+
+// ParseLimitRate parses a bandwidth cap such as "2MB" (per second) into
+// bytes per second. An empty value or 0 means unlimited.
+func ParseLimitRate(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := util.ParseBytes(s)
+	if err != nil || (n != 0 && n < minLimitRate) {
+		return 0, fmt.Errorf("must be a rate per second of at least 16KB, like \"2MB\" (0 for unlimited)")
+	}
+	return n, nil
+}
+
+// setTuningField assigns one of tuningKeys on cfg.
+func setTuningField(cfg *Config, key, value string) error {
+	atoi := func() (int, error) {
+		if value == "" {
+			return 0, nil
+		}
+		return strconv.Atoi(value)
+	}
+
+	switch key {
+	case "http_timeout":
+		cfg.HTTPTimeout = value
+	case "upload_timeout":
+		cfg.UploadTimeout = value
+	case "download_timeout":
+		cfg.DownloadTimeout = value
+	case "retry_backoff":
+		cfg.RetryBackoff = value
+	case "upload_part_size":
+		cfg.UploadPartSize = value
+	case "limit_rate":
+		cfg.LimitRate = value
+	case "retry_count", "upload_retry_count":
+		field := &cfg.RetryCount
+		if key == "upload_retry_count" {
+			field = &cfg.UploadRetryCount
+		}
+		if value == "" {
+			*field = nil
+			return nil
+		}
+		n, err := atoi()
+		if err != nil {
+			return err
+		}
+		*field = &n
+	case "upload_concurrency":
+		n, err := atoi()
+		if err != nil {
+			return err
+		}
+		cfg.UploadConcurrency = n
+	case "download_concurrency":
+		n, err := atoi()
+		if err != nil {
+			return err
+		}
+		cfg.DownloadConcurrency = n
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/util"
+)
+
+// Validate parses a config file and checks it against the schema: JSON syntax,
+// known keys, value types, and the same value rules as ValidateValue. It
+// returns every problem found, or nil if the file is valid.
+func Validate(data []byte) []error {
+	var errs []error
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	if !json.Valid(data) {
+		var raw map[string]json.RawMessage
+		err := json.Unmarshal(data, &raw)
+		return []error{fmt.Errorf("invalid JSON: %w", err)}
+	}
+
+	// Types first: a value of the wrong type makes the rest meaningless.
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return []error{fmt.Errorf("%s", strings.TrimPrefix(err.Error(), "json: "))}
+	}
+
+	// Unknown keys, reported alongside any value problems.
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&Config{}); err != nil {
+		errs = append(errs, fmt.Errorf("%s", strings.TrimPrefix(err.Error(), "json: ")))
+	}
+
+	check := func(where string, c *Config) {
+		for _, key := range AllowedKeys {
+			value, _ := Lookup(c, key)
+			if value == "" || (key == "quiet" && value == "false") {
+				continue
+			}
+			if err := ValidateValue(key, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s%w", where, err))
+			}
+		}
+	}
+	check("", &cfg)
+	for name, p := range cfg.Profiles {
+		if p == nil {
+			continue
+		}
+		if strings.ContainsAny(name, " /\\") || name == "" || name == DefaultProfile {
+			errs = append(errs, fmt.Errorf("invalid profile name %q", name))
+		}
+		// Only the profile's own values; inherited ones were checked above.
+		var view Config
+		overlayProfile(&view, p)
+		check(fmt.Sprintf("profile %q: ", name), &view)
+	}
+	for name, value := range cfg.Regions {
+		if err := validRegionName(name); err != nil {
+			errs = append(errs, err)
+		} else if _, err := ParseRegion(value); err != nil {
+			errs = append(errs, fmt.Errorf("region %q: %w", name, err))
+		}
+	}
+	if cfg.ActiveProfile != "" {
+		if _, ok := cfg.Profiles[cfg.ActiveProfile]; !ok {
+			errs = append(errs, fmt.Errorf("active_profile %q does not exist", cfg.ActiveProfile))
+		}
+	}
+	return errs
+}
+
+// Lookup returns the raw (unmasked) value of key in cfg, or "" if unset.
+func Lookup(cfg *Config, key string) (string, error) {
+	var value string
+	switch key {
+	case "baseurl":
+		value = cfg.BaseURL
+	case "default_ttl":
+		value = cfg.DefaultTTL
+	case "quiet":
+		value = fmt.Sprintf("%v", cfg.Quiet)
+	case "output":
+		value = cfg.Output
+	case "auto_copy":
+		value = cfg.AutoCopy
+	case "trash":
+		value = cfg.Trash
+	case "token_refresh_buffer":
+		value = cfg.TokenRefreshBuffer
+	case "http_timeout":
+		value = cfg.HTTPTimeout
+	case "upload_timeout":
+		value = cfg.UploadTimeout
+	case "download_timeout":
+		value = cfg.DownloadTimeout
+	case "retry_count":
+		value = optionalInt(cfg.RetryCount)
+	case "upload_retry_count":
+		value = optionalInt(cfg.UploadRetryCount)
+	case "retry_backoff":
+		value = cfg.RetryBackoff
+	case "upload_concurrency":
+		value = positiveInt(cfg.UploadConcurrency)
+	case "upload_part_size":
+		value = cfg.UploadPartSize
+	case "download_concurrency":
+		value = positiveInt(cfg.DownloadConcurrency)
+	case "limit_rate":
+		value = cfg.LimitRate
+	case "http_proxy":
+		value = cfg.HTTPProxy
+	case "https_proxy":
+		value = cfg.HTTPSProxy
+	case "socks5_proxy":
+		value = cfg.SOCKS5Proxy
+	case "no_proxy":
+		value = cfg.NoProxy
+	case "storage_proxy":
+		value = cfg.StorageProxy
+	case "post_add":
+		value = cfg.PostAdd
+	case "post_share":
+		value = cfg.PostShare
+	case "post_delete":
+		value = cfg.PostDelete
+	case "webhook_url":
+		value = cfg.WebhookURL
+	case "clock_skew":
+		if cfg.ClockSkew != 0 {
+			value = fmt.Sprintf("%ds", cfg.ClockSkew)
+		}
+	case "auth_domain":
+		value = cfg.AuthDomain
+	case "auth_client_id":
+		value = cfg.AuthClientID
+	case "auth_token_endpoint":
+		value = cfg.AuthTokenEndpoint
+	case "auth_device_endpoint":
+		value = cfg.AuthDeviceEndpoint
+	case "logged_in_at":
+		value = cfg.LoggedInAt
+	case "id_token":
+		value = cfg.IDToken
+	case "access_token":
+		value = cfg.AccessToken
+	case "refresh_token":
+		value = cfg.RefreshToken
+	case "api_token":
+		value = cfg.APIToken
+	default:
+		return "", fmt.Errorf("unknown key %q", key)
+	}
+	return value, nil
+}
+
+// optionalInt formats an unset-able integer setting for display.
+func optionalInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+// positiveInt formats an integer setting where 0 means unset.
+func positiveInt(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// ValidateValue checks that value is acceptable for key. It doesn't check
+// whether the key is user-settable; see IsAllowedKey and IsProtectedKey.
+func ValidateValue(key, value string) error {
+	// Validate specific keys
+	switch key {
+	case "baseurl":
+		if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("\"baseurl\" must be an http(s) URL like \"https://api.example.com\"")
+		}
+	case "webhook_url":
+		if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("\"webhook_url\" must be an http(s) URL like \"https://hooks.example.com/nikte\"")
+		}
+	case "token_refresh_buffer":
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 || d > time.Hour {
+			return fmt.Errorf("\"token_refresh_buffer\" must be a duration between 0s and 1h, like \"60s\" or \"5m\"")
+		}
+	case "http_timeout", "upload_timeout":
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			return fmt.Errorf("%q must be a positive duration like \"30s\" or \"2m\"", key)
+		}
+	case "retry_backoff", "download_timeout":
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			return fmt.Errorf("%q must be a duration like \"30s\" or \"2m\" (0 for none)", key)
+		}
+	case "retry_count", "upload_retry_count":
+		if n, err := strconv.Atoi(value); err != nil || n < 0 || n > 20 {
+			return fmt.Errorf("%q must be a number from 0 to 20", key)
+		}
+	case "upload_concurrency", "download_concurrency":
+		if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 16 {
+			return fmt.Errorf("%q must be a number from 1 to 16", key)
+		}
+	case "upload_part_size":
+		// S3 multipart limits: parts are 5MB-5GB (except the last).
+		if n, err := util.ParseBytes(value); err != nil || n < 5<<20 || n > 5<<30 {
+			return fmt.Errorf("\"upload_part_size\" must be a size from 5MB to 5GB, like \"16MB\"")
+		}
+	case "limit_rate":
+		if _, err := ParseLimitRate(value); err != nil {
+			return fmt.Errorf("\"limit_rate\" %v", err)
+		}
+	case "auth_token_endpoint", "auth_device_endpoint":
+		if u, err := url.Parse(value); err != nil || (value != "" && (u.Scheme == "" || u.Host == "")) {
+			return fmt.Errorf("%q must be an absolute URL", key)
+		}
+	case "http_proxy", "https_proxy", "socks5_proxy":
+		if err := ValidateProxyURL(value, key == "socks5_proxy"); err != nil {
+			return fmt.Errorf("%q %v", key, err)
+		}
+	case "storage_proxy":
+		if value != StorageProxyDirect {
+			if err := ValidateProxyURL(value, false); err != nil {
+				return fmt.Errorf("%q must be \"direct\" or a proxy URL like \"http://proxy:8080\"", key)
+			}
+		}
+	case "auth_domain":
+		if strings.Contains(value, "/") {
+			return fmt.Errorf("\"auth_domain\" must be a host name like \"auth.example.com\"")
+		}
+	case "quiet":
+		if value != "true" && value != "false" {
+			return fmt.Errorf("\"quiet\" must be \"true\" or \"false\"")
+		}
+	case "output":
+		if value != "table" && value != "json" && value != "ndjson" {
+			return fmt.Errorf("\"output\" must be \"table\", \"json\" or \"ndjson\"")
+		}
+	case "trash":
+		if value != "on" && value != "off" {
+			return fmt.Errorf("\"trash\" must be \"on\" or \"off\"")
+		}
+	case "auto_copy":
+		if value == "off" {
+			return nil
+		}
+		for _, kind := range strings.Split(value, ",") {
+			switch strings.TrimSpace(kind) {
+			case "id", "url", "content":
+			default:
+				return fmt.Errorf("\"auto_copy\" must be \"off\" or a list of \"id\", \"url\" and \"content\", like \"id,url\"")
+			}
+		}
+	case "post_add", "post_share", "post_delete":
+		if _, err := ParseHook(key, value); err != nil {
+			return err
+		}
+	case "default_ttl":
+		if !util.IsValidTTL(value) {
+			return fmt.Errorf("\"default_ttl\" must be in format like \"30s\", \"60m\", \"24h\", or \"7d\"")
+		}
+	}
+	return nil
+}
@@ -161,3 +161,29 @@ func DecryptBytes(passphrase string, data []byte) ([]byte, error) {
 	}
 	return decryptRaw(passphrase, data[len(fileMagic):])
 }
+
+// passwordAlphabet excludes visually ambiguous characters (0/O, 1/l/I) so
+// generated passwords survive being read aloud or retyped from a screenshot.
+const passwordAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// GeneratePassword returns a cryptographically random password of the given
+// length drawn uniformly from passwordAlphabet.
+func GeneratePassword(length int) (string, error) {
+	if length <= 0 {
+		return "", errors.New("password length must be positive")
+	}
+	out := make([]byte, length)
+	limit := 256 - 256%len(passwordAlphabet) // rejection bound avoids modulo bias
+	buf := make([]byte, 1)
+	for i := 0; i < length; {
+		if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+			return "", err
+		}
+		if int(buf[0]) >= limit {
+			continue
+		}
+		out[i] = passwordAlphabet[int(buf[0])%len(passwordAlphabet)]
+		i++
+	}
+	return string(out), nil
+}
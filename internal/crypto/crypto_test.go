@@ -91,3 +91,25 @@ func TestIsEncryptedBytes(t *testing.T) {
 		t.Fatal("short buffer reported as encrypted")
 	}
 }
+
+func TestGeneratePassword(t *testing.T) {
+	a, err := GeneratePassword(20)
+	if err != nil {
+		t.Fatalf("GeneratePassword: %v", err)
+	}
+	if len(a) != 20 {
+		t.Fatalf("length = %d, want 20", len(a))
+	}
+	for _, c := range a {
+		if !bytes.ContainsRune([]byte(passwordAlphabet), c) {
+			t.Fatalf("unexpected character %q in %q", c, a)
+		}
+	}
+	b, _ := GeneratePassword(20)
+	if a == b {
+		t.Fatal("two generated passwords are identical")
+	}
+	if _, err := GeneratePassword(0); err == nil {
+		t.Fatal("expected error for zero length")
+	}
+}
@@ -0,0 +1,152 @@
+// Package daemon is the control channel between the `nk daemon` commands and
+// the background process: a unix socket in the config directory carrying
+// one JSON request and one JSON response per connection.
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+)
+
+// Commands the daemon answers.
+const (
+	CmdStatus = "status"
+	CmdStop   = "stop"
+)
+
+// callTimeout bounds a whole request/response exchange.
+const callTimeout = 5 * time.Second
+
+// ErrNotRunning is returned by Call when no daemon is listening.
+var ErrNotRunning = errors.New("the daemon is not running")
+
+// Request is sent by the CLI.
+type Request struct {
+	Cmd string `json:"cmd"`
+}
+
+// Response is the daemon's answer.
+type Response struct {
+	OK     bool    `json:"ok"`
+	Error  string  `json:"error,omitempty"`
+	Status *Status `json:"status,omitempty"`
+}
+
+// Status describes a running daemon.
+type Status struct {
+	Running bool      `json:"running"`
+	PID     int       `json:"pid"`
+	Started time.Time `json:"started"`
+	Tasks   []string  `json:"tasks"`
+	Uploads int       `json:"uploads"`
+	Errors  int       `json:"errors"`
+	Recent  []Event   `json:"recent,omitempty"`
+	Log     string    `json:"log,omitempty"`
+}
+
+// Event is something the daemon did, kept for `nk daemon status`.
+type Event struct {
+	At      time.Time `json:"at"`
+	Message string    `json:"message"`
+}
+
+// SocketPath returns the control socket's path.
+func SocketPath() (string, error) {
+	dir, err := config.DaemonDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "daemon.sock"), nil
+}
+
+// LogPath returns the file a detached daemon writes its output to.
+func LogPath() (string, error) {
+	dir, err := config.DaemonDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "daemon.log"), nil
+}
+
+// Listen opens the control socket. A socket left behind by a daemon that
+// died is removed; one that still answers means a daemon is already running.
+func Listen() (net.Listener, error) {
+	path, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		if _, err := Call(Request{Cmd: CmdStatus}); err == nil {
+			return nil, fmt.Errorf("the daemon is already running")
+		}
+		os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	// Only the owner may control the daemon.
+	os.Chmod(path, 0o600)
+	return ln, nil
+}
+
+// Serve answers requests on ln with handle until ctx is done, then closes
+// ln, which removes the socket.
+func Serve(ctx context.Context, ln net.Listener, handle func(Request) Response) {
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(callTimeout))
+			var req Request
+			if err := json.NewDecoder(conn).Decode(&req); err != nil {
+				json.NewEncoder(conn).Encode(Response{Error: "bad request: " + err.Error()})
+				return
+			}
+			json.NewEncoder(conn).Encode(handle(req))
+		}()
+	}
+}
+
+// Call sends req to the running daemon and returns its response.
+func Call(req Request) (*Response, error) {
+	path, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("unix", path, callTimeout)
+	if err != nil {
+		return nil, ErrNotRunning
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(callTimeout))
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, err
+	}
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("no answer from the daemon: %w", err)
+	}
+	if !resp.OK {
+		return &resp, fmt.Errorf("daemon: %s", resp.Error)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,18 @@
+package daemon
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detachedProcess is DETACHED_PROCESS: start without a console.
+const detachedProcess = 0x00000008
+
+// Detach makes cmd start without a console in its own process group, so it
+// outlives the terminal that started it.
+func Detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: detachedProcess | syscall.CREATE_NEW_PROCESS_GROUP,
+		HideWindow:    true,
+	}
+}
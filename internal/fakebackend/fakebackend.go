@@ -0,0 +1,790 @@
+// Package fakebackend is an in-memory stand-in for the nikte API, for unit
+// tests and for trying nk without an account (nk --fake-backend).
+//
+// It implements the /shorts, /screenshots and /files endpoints, sharing, and
+// the presigned storage URLs that uploads and downloads go through. Items
+// expire by their TTL against Backend.Now. Any bearer token is accepted and
+// every account is Pro.
+package fakebackend
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/models"
+	"github.com/sim4gh/nikte-cli/internal/util"
+)
+
+// defaultTTL is how long items live when a request doesn't say.
+const defaultTTL = 24 * time.Hour
+
+// defaultPartSize is the upload part size when an upload doesn't ask for one.
+const defaultPartSize = 8 << 20
+
+// Backend is a fake nikte API. The zero value is not usable; see New.
+type Backend struct {
+	// Now is the backend's clock, for expiring items. Tests may replace it.
+	Now func() time.Time
+
+	mu     sync.Mutex
+	state  state
+	srv    *httptest.Server
+	mux    *http.ServeMux
+	public string // URL that storage and share links point at
+}
+
+// state is everything the backend stores, as Save writes it.
+type state struct {
+	Next   int               `json:"next"`
+	Items  map[string]*item  `json:"items"`
+	Shares map[string]*share `json:"shares"`
+}
+
+// item is a stored short, screenshot or Pro file. Kind is the endpoint it
+// lives under ("shorts", "screenshots" or "files"); Type is what nk calls it
+// ("text", "file", "screenshot" or "profile").
+type item struct {
+	ID          string         `json:"id"`
+	Kind        string         `json:"kind"`
+	Type        string         `json:"type"`
+	Content     string         `json:"content,omitempty"`
+	Filename    string         `json:"filename,omitempty"`
+	Title       string         `json:"title,omitempty"`
+	Description string         `json:"description,omitempty"`
+	ContentType string         `json:"contentType,omitempty"`
+	Data        []byte         `json:"data,omitempty"`
+	Size        int64          `json:"size"`
+	PartSize    int            `json:"partSize,omitempty"`
+	Parts       map[int][]byte `json:"parts,omitempty"`
+	Complete    bool           `json:"complete"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	ExpiresAt   int64          `json:"expiresAt"`
+}
+
+// share is a share link of an item.
+type share struct {
+	models.Share
+	ItemID   string `json:"itemId"`
+	Password string `json:"password,omitempty"`
+}
+
+// New returns an empty backend; serve it with Start or as an http.Handler.
+func New() *Backend {
+	b := &Backend{Now: time.Now, state: state{Items: map[string]*item{}, Shares: map[string]*share{}}}
+	b.routes()
+	return b
+}
+
+// Start serves b on a local port and returns its base URL, for
+// api.SetBaseURL. Stop it with Close.
+func (b *Backend) Start() string {
+	b.srv = httptest.NewServer(b)
+	b.public = b.srv.URL
+	return b.srv.URL
+}
+
+// Close stops the server started by Start.
+func (b *Backend) Close() {
+	if b.srv != nil {
+		b.srv.Close()
+	}
+}
+
+// Load replaces the stored items with those Save wrote to path. A missing
+// file leaves the backend empty.
+func (b *Backend) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("fake backend state %s: %w", path, err)
+	}
+	if st.Items == nil {
+		st.Items = map[string]*item{}
+	}
+	if st.Shares == nil {
+		st.Shares = map[string]*share{}
+	}
+	b.mu.Lock()
+	b.state = st
+	b.mu.Unlock()
+	return nil
+}
+
+// Save writes the stored items to path, so a later backend can Load them.
+func (b *Backend) Save(path string) error {
+	b.mu.Lock()
+	b.expireLocked()
+	data, err := json.Marshal(b.state)
+	b.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func (b *Backend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	open := r.URL.Path == "/health" || strings.HasPrefix(r.URL.Path, "/_storage/") || strings.HasPrefix(r.URL.Path, "/s/")
+	if !open && !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	b.mu.Lock()
+	if b.public == "" {
+		// Served by the caller, e.g. from its own httptest server.
+		b.public = "http://" + r.Host
+	}
+	b.expireLocked()
+	b.mu.Unlock()
+	b.mux.ServeHTTP(w, r)
+}
+
+func (b *Backend) routes() {
+	m := http.NewServeMux()
+	m.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, models.Health{Status: "ok", Message: "fake backend", Timestamp: b.Now().UTC().Format(time.RFC3339)})
+	})
+	m.HandleFunc("GET /account", b.account)
+
+	m.HandleFunc("GET /shorts", b.list("shorts"))
+	m.HandleFunc("POST /shorts", b.createText)
+	m.HandleFunc("POST /shorts/file/init", b.initUpload("shorts"))
+	m.HandleFunc("POST /shorts/file/parts", b.presignParts)
+	m.HandleFunc("POST /shorts/file/complete", b.completeUpload)
+	m.HandleFunc("POST /shorts/file/abort", b.abortUpload)
+	m.HandleFunc("POST /screenshots", b.createScreenshot)
+	m.HandleFunc("GET /screenshots", b.list("screenshots"))
+	m.HandleFunc("GET /files", b.list("files"))
+	m.HandleFunc("POST /files/init", b.initUpload("files"))
+	m.HandleFunc("POST /files/complete", b.completeUpload)
+	m.HandleFunc("POST /files/abort", b.abortUpload)
+	for _, kind := range []string{"shorts", "screenshots", "files"} {
+		m.HandleFunc("GET /"+kind+"/{id}", b.get(kind))
+		m.HandleFunc("PATCH /"+kind+"/{id}", b.update(kind))
+		m.HandleFunc("DELETE /"+kind+"/{id}", b.remove(kind))
+		m.HandleFunc("POST /"+kind+"/{id}/share", b.createShare(kind))
+	}
+	m.HandleFunc("GET /shares", b.listShares)
+	m.HandleFunc("GET /s/{id}", b.openShare)
+
+	m.HandleFunc("PUT /_storage/{id}/{part}", b.putPart)
+	m.HandleFunc("GET /_storage/{id}", b.download)
+	b.mux = m
+}
+
+// expireLocked drops expired items and their shares. Caller must hold mu.
+func (b *Backend) expireLocked() {
+	now := b.Now().Unix()
+	for id, it := range b.state.Items {
+		if it.ExpiresAt > 0 && it.ExpiresAt <= now {
+			delete(b.state.Items, id)
+		}
+	}
+	for id, s := range b.state.Shares {
+		if b.state.Items[s.ItemID] == nil || (s.ExpiresAt > 0 && s.ExpiresAt <= now) {
+			delete(b.state.Shares, id)
+		}
+	}
+}
+
+// newItemLocked stores a new item of kind and typ expiring after ttl (0 for
+// never). Caller must hold mu.
+func (b *Backend) newItemLocked(kind, typ string, ttl time.Duration) *item {
+	b.state.Next++
+	it := &item{
+		ID:        fmt.Sprintf("%s%d", typ[:1], b.state.Next),
+		Kind:      kind,
+		Type:      typ,
+		CreatedAt: b.Now().UTC(),
+		Complete:  true,
+	}
+	if ttl > 0 {
+		it.ExpiresAt = b.Now().Add(ttl).Unix()
+	}
+	b.state.Items[it.ID] = it
+	return it
+}
+
+// findLocked returns the complete item id stored under kind, or nil. Caller
+// must hold mu.
+func (b *Backend) findLocked(kind, id string) *item {
+	it := b.state.Items[id]
+	if it == nil || it.Kind != kind || !it.Complete {
+		return nil
+	}
+	return it
+}
+
+// parseTTL reads a TTL as the API takes it: "permanent", a duration like
+// "3600s" or "7d", or "" for the default.
+func parseTTL(s string) (time.Duration, error) {
+	switch s {
+	case "":
+		return defaultTTL, nil
+	case "permanent":
+		return 0, nil
+	}
+	n, err := util.ParseTTL(s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(n) * time.Second, nil
+}
+
+func (b *Backend) account(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var used int64
+	count, counts := 0, map[string]int{}
+	for _, it := range b.state.Items {
+		if it.Complete {
+			used += it.Size
+			count++
+			counts[it.Type]++
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"plan":         "pro",
+		"storageUsed":  used,
+		"storageQuota": int64(100 << 30),
+		"itemCount":    count,
+		"itemCounts":   counts,
+	})
+}
+
+func (b *Backend) list(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		var items []*item
+		for _, it := range b.state.Items {
+			if it.Kind == kind && it.Complete {
+				items = append(items, it)
+			}
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].CreatedAt.After(items[j].CreatedAt) })
+
+		switch kind {
+		case "shorts":
+			list := models.ShortList{Shorts: []models.Short{}}
+			for _, it := range items {
+				s := b.short(it)
+				s.Content, s.DownloadURL = "", ""
+				list.Shorts = append(list.Shorts, s)
+			}
+			writeJSON(w, http.StatusOK, list)
+		case "screenshots":
+			list := models.ScreenshotList{Screenshots: []models.Screenshot{}}
+			for _, it := range items {
+				list.Screenshots = append(list.Screenshots, b.screenshot(it))
+			}
+			writeJSON(w, http.StatusOK, list)
+		default:
+			list := models.FileList{Files: []models.FileItem{}}
+			for _, it := range items {
+				list.Files = append(list.Files, b.file(it))
+			}
+			writeJSON(w, http.StatusOK, list)
+		}
+	}
+}
+
+func (b *Backend) get(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		it := b.findLocked(kind, r.PathValue("id"))
+		if it == nil {
+			writeError(w, http.StatusNotFound, "Item not found")
+			return
+		}
+		switch kind {
+		case "shorts":
+			writeJSON(w, http.StatusOK, b.short(it))
+		case "screenshots":
+			writeJSON(w, http.StatusOK, b.screenshot(it))
+		default:
+			writeJSON(w, http.StatusOK, b.file(it))
+		}
+	}
+}
+
+func (b *Backend) short(it *item) models.Short {
+	s := models.Short{
+		ShortID:     it.ID,
+		Type:        it.Type,
+		Content:     it.Content,
+		Filename:    it.Filename,
+		FileSize:    it.Size,
+		ContentType: it.ContentType,
+		CreatedAt:   it.CreatedAt.Format(time.RFC3339),
+		ExpiresAt:   it.ExpiresAt,
+	}
+	if it.Type == "text" {
+		s.ContentPreview = preview(it.Content)
+	} else {
+		s.DownloadURL = b.storageURL(it)
+	}
+	return s
+}
+
+func (b *Backend) screenshot(it *item) models.Screenshot {
+	return models.Screenshot{
+		ScreenshotID: it.ID,
+		Filename:     it.Filename,
+		Size:         it.Size,
+		ContentType:  it.ContentType,
+		DownloadURL:  b.storageURL(it),
+		CreatedAt:    it.CreatedAt.Format(time.RFC3339),
+		ExpiresAt:    it.ExpiresAt,
+	}
+}
+
+func (b *Backend) file(it *item) models.FileItem {
+	return models.FileItem{
+		FileID:      it.ID,
+		Filename:    it.Filename,
+		Size:        it.Size,
+		ContentType: it.ContentType,
+		Description: it.Description,
+		DownloadURL: b.storageURL(it),
+		CreatedAt:   it.CreatedAt.Format(time.RFC3339),
+		ExpiresAt:   it.ExpiresAt,
+	}
+}
+
+func (b *Backend) storageURL(it *item) string {
+	return b.public + "/_storage/" + it.ID
+}
+
+// preview is the start of a text item, as lists show it.
+func preview(s string) string {
+	if r := []rune(s); len(r) > 100 {
+		return string(r[:100])
+	}
+	return s
+}
+
+func (b *Backend) createText(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateShortRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Content == "" {
+		writeError(w, http.StatusBadRequest, "content is required")
+		return
+	}
+	ttl := defaultTTL
+	if req.TTL != nil {
+		ttl = time.Duration(*req.TTL) * time.Second
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	it := b.newItemLocked("shorts", "text", ttl)
+	it.Content, it.Size = req.Content, int64(len(req.Content))
+	writeJSON(w, http.StatusOK, models.CreateShortResponse{ShortID: it.ID, ExpiresAt: it.ExpiresAt})
+}
+
+func (b *Backend) createScreenshot(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateScreenshotRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	data, err := base64.StdEncoding.DecodeString(req.Data)
+	if err != nil || len(data) == 0 {
+		writeError(w, http.StatusBadRequest, "data must be base64 image data")
+		return
+	}
+	ttl, err := parseTTL(req.TTL)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	it := b.newItemLocked("screenshots", "screenshot", ttl)
+	it.Data, it.Size, it.ContentType = data, int64(len(data)), req.ContentType
+	it.Filename = it.ID + ".png"
+	if req.ContentType == "image/jpeg" {
+		it.Filename = it.ID + ".jpg"
+	}
+	writeJSON(w, http.StatusOK, models.CreateScreenshotResponse{ScreenshotID: it.ID, ExpiresAt: it.ExpiresAt})
+}
+
+// initUpload starts a multipart upload of a file short or a Pro file.
+func (b *Backend) initUpload(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.InitFileUploadRequest
+		if !readJSON(w, r, &req) {
+			return
+		}
+		if req.Filename == "" || req.FileSize <= 0 {
+			writeError(w, http.StatusBadRequest, "filename and fileSize are required")
+			return
+		}
+		if kind == "files" && req.TTL == "" {
+			req.TTL = "permanent"
+		}
+		ttl, err := parseTTL(req.TTL)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		partSize := int(req.PartSize)
+		if partSize <= 0 {
+			partSize = defaultPartSize
+		}
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		typ := "file"
+		if kind == "files" {
+			typ = "profile"
+		}
+		it := b.newItemLocked(kind, typ, ttl)
+		it.Filename, it.ContentType, it.Description = req.Filename, req.ContentType, req.Description
+		it.Size, it.PartSize, it.Parts, it.Complete = req.FileSize, partSize, map[int][]byte{}, false
+
+		parts := make([]int, 0, (req.FileSize+int64(partSize)-1)/int64(partSize))
+		for n := 1; int64(n-1)*int64(partSize) < req.FileSize; n++ {
+			parts = append(parts, n)
+		}
+		urls := b.presignedURLs(it, parts)
+		if kind == "files" {
+			writeJSON(w, http.StatusOK, models.InitFileUploadResponse{FileID: it.ID, PresignedURLs: urls, PartSize: partSize, ExpiresAt: it.ExpiresAt})
+			return
+		}
+		writeJSON(w, http.StatusOK, models.InitUploadResponse{ShortID: it.ID, PresignedURLs: urls, PartSize: partSize, ExpiresAt: it.ExpiresAt})
+	}
+}
+
+func (b *Backend) presignedURLs(it *item, parts []int) []models.PresignedURL {
+	urls := make([]models.PresignedURL, 0, len(parts))
+	for _, n := range parts {
+		urls = append(urls, models.PresignedURL{PartNumber: n, URL: fmt.Sprintf("%s/_storage/%s/%d", b.public, it.ID, n)})
+	}
+	return urls
+}
+
+// uploadID is the ID of an upload in the body of the parts, complete and
+// abort requests, which call it shortId or fileId.
+type uploadID struct {
+	ShortID     string                 `json:"shortId"`
+	FileID      string                 `json:"fileId"`
+	PartNumbers []int                  `json:"partNumbers"`
+	Parts       []models.CompletedPart `json:"parts"`
+}
+
+func (u uploadID) id() string {
+	if u.FileID != "" {
+		return u.FileID
+	}
+	return u.ShortID
+}
+
+// pendingLocked returns the unfinished upload id, or nil. Caller must hold
+// mu.
+func (b *Backend) pendingLocked(id string) *item {
+	it := b.state.Items[id]
+	if it == nil || it.Complete {
+		return nil
+	}
+	return it
+}
+
+func (b *Backend) presignParts(w http.ResponseWriter, r *http.Request) {
+	var req uploadID
+	if !readJSON(w, r, &req) {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	it := b.pendingLocked(req.id())
+	if it == nil {
+		writeError(w, http.StatusNotFound, "Upload not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, models.InitUploadResponse{ShortID: it.ID, PresignedURLs: b.presignedURLs(it, req.PartNumbers),
+		PartSize: it.PartSize, ExpiresAt: it.ExpiresAt})
+}
+
+// completeUpload joins the uploaded parts, and answers with the composite
+// ETag S3 would give the object.
+func (b *Backend) completeUpload(w http.ResponseWriter, r *http.Request) {
+	var req uploadID
+	if !readJSON(w, r, &req) {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	it := b.pendingLocked(req.id())
+	if it == nil {
+		writeError(w, http.StatusNotFound, "Upload not found")
+		return
+	}
+	sort.Slice(req.Parts, func(i, j int) bool { return req.Parts[i].PartNumber < req.Parts[j].PartNumber })
+	var data, sums []byte
+	for _, p := range req.Parts {
+		part, ok := it.Parts[p.PartNumber]
+		if !ok {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("part %d was not uploaded", p.PartNumber))
+			return
+		}
+		sum := md5.Sum(part)
+		if strings.Trim(p.ETag, `"`) != hex.EncodeToString(sum[:]) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("part %d has the wrong ETag", p.PartNumber))
+			return
+		}
+		data = append(data, part...)
+		sums = append(sums, sum[:]...)
+	}
+	if int64(len(data)) != it.Size {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("uploaded %d bytes, expected %d", len(data), it.Size))
+		return
+	}
+	it.Data, it.Parts, it.Complete = data, nil, true
+	sum := md5.Sum(sums)
+	etag := fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), len(req.Parts))
+	writeJSON(w, http.StatusOK, models.CompleteUploadResponse{ETag: etag, Size: it.Size})
+}
+
+func (b *Backend) abortUpload(w http.ResponseWriter, r *http.Request) {
+	var req uploadID
+	if !readJSON(w, r, &req) {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pendingLocked(req.id()) == nil {
+		writeError(w, http.StatusNotFound, "Upload not found")
+		return
+	}
+	delete(b.state.Items, req.id())
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Upload aborted"})
+}
+
+func (b *Backend) putPart(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(r.PathValue("part"))
+	if err != nil || n < 1 {
+		http.Error(w, "bad part number", http.StatusBadRequest)
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	it := b.pendingLocked(r.PathValue("id"))
+	if it == nil {
+		http.Error(w, "no such upload", http.StatusNotFound)
+		return
+	}
+	it.Parts[n] = data
+	sum := md5.Sum(data)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (b *Backend) download(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	it := b.state.Items[r.PathValue("id")]
+	if it == nil || !it.Complete || it.Type == "text" {
+		b.mu.Unlock()
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	data, contentType, name := it.Data, it.ContentType, it.Filename
+	b.mu.Unlock()
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(data))
+}
+
+// update handles PATCH, which edits a text item's content, renames, or
+// changes the TTL, depending on the fields given.
+func (b *Backend) update(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Content   *string `json:"content"`
+			Filename  string  `json:"filename"`
+			Title     string  `json:"title"`
+			TTL       string  `json:"ttl"`
+			Permanent bool    `json:"permanent"`
+		}
+		if !readJSON(w, r, &req) {
+			return
+		}
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		it := b.findLocked(kind, r.PathValue("id"))
+		if it == nil {
+			writeError(w, http.StatusNotFound, "Item not found")
+			return
+		}
+		if req.Content != nil {
+			if it.Type != "text" {
+				writeError(w, http.StatusBadRequest, "only text items have content")
+				return
+			}
+			it.Content, it.Size = *req.Content, int64(len(*req.Content))
+		}
+		if req.Filename != "" {
+			it.Filename = req.Filename
+		}
+		if req.Title != "" {
+			it.Title = req.Title
+		}
+		switch {
+		case req.Permanent:
+			it.ExpiresAt = 0
+		case req.TTL != "":
+			n, err := util.ParseTTL(req.TTL)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			it.ExpiresAt = b.Now().Add(time.Duration(n) * time.Second).Unix()
+		}
+		switch kind {
+		case "shorts":
+			writeJSON(w, http.StatusOK, b.short(it))
+		case "screenshots":
+			writeJSON(w, http.StatusOK, b.screenshot(it))
+		default:
+			writeJSON(w, http.StatusOK, b.file(it))
+		}
+	}
+}
+
+func (b *Backend) remove(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		id := r.PathValue("id")
+		if b.findLocked(kind, id) == nil {
+			writeError(w, http.StatusNotFound, "Item not found")
+			return
+		}
+		delete(b.state.Items, id)
+		b.expireLocked()
+		writeJSON(w, http.StatusOK, map[string]string{"message": "Deleted"})
+	}
+}
+
+func (b *Backend) createShare(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.ShareRequest
+		if !readJSON(w, r, &req) {
+			return
+		}
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		it := b.findLocked(kind, r.PathValue("id"))
+		if it == nil {
+			writeError(w, http.StatusNotFound, "Item not found")
+			return
+		}
+		b.state.Next++
+		id := fmt.Sprintf("sh%d", b.state.Next)
+		s := &share{ItemID: it.ID, Password: req.Password, Share: models.Share{
+			ShareID:     id,
+			ShareURL:    b.public + "/s/" + id,
+			Type:        it.Type,
+			Filename:    it.Filename,
+			Title:       req.Title,
+			Description: req.Description,
+			ContentType: it.ContentType,
+			IsPublic:    req.IsPublic,
+			ExpiresAt:   it.ExpiresAt,
+		}}
+		if req.Direct && it.Type != "text" {
+			s.DirectURL = b.storageURL(it)
+		}
+		if req.MaxViews > 0 {
+			s.MaxViews = &req.MaxViews
+		}
+		if d := time.Duration(req.ExpiresInSeconds)*time.Second + time.Duration(req.ExpiresInDays)*24*time.Hour; d > 0 {
+			s.ExpiresAt = b.Now().Add(d).Unix()
+		}
+		b.state.Shares[id] = s
+		writeJSON(w, http.StatusOK, s.Share)
+	}
+}
+
+func (b *Backend) listShares(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	list := models.ShareList{Shares: []models.Share{}}
+	for _, s := range b.state.Shares {
+		list.Shares = append(list.Shares, s.Share)
+	}
+	sort.Slice(list.Shares, func(i, j int) bool { return list.Shares[i].ShareID < list.Shares[j].ShareID })
+	writeJSON(w, http.StatusOK, list)
+}
+
+// openShare is the share page: a text item's content, or a redirect to the
+// file.
+func (b *Backend) openShare(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.state.Shares[r.PathValue("id")]
+	if s == nil || (s.MaxViews != nil && s.ViewCount >= *s.MaxViews) {
+		http.Error(w, "This share does not exist or has expired", http.StatusNotFound)
+		return
+	}
+	if s.Password != "" && r.URL.Query().Get("password") != s.Password {
+		http.Error(w, "Password required (?password=...)", http.StatusUnauthorized)
+		return
+	}
+	s.ViewCount++
+	it := b.state.Items[s.ItemID]
+	if it.Type == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, it.Content)
+		return
+	}
+	http.Redirect(w, r, b.storageURL(it), http.StatusFound)
+}
+
+// readJSON decodes the request body into v, answering 400 if it can't.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers with an error body as the API does.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"message": msg})
+}
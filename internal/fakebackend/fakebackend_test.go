@@ -0,0 +1,161 @@
+package fakebackend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/api"
+	"github.com/sim4gh/nikte-cli/internal/models"
+	"github.com/sim4gh/nikte-cli/internal/upload"
+)
+
+// newTestBackend points the api package at a fresh fake backend whose clock
+// the test controls.
+func newTestBackend(t *testing.T) (*Backend, *time.Time) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("NIKTE_TOKEN", "test-token")
+	now := time.Unix(1_700_000_000, 0)
+	b := New()
+	b.Now = func() time.Time { return now }
+	api.SetBaseURL(b.Start())
+	t.Cleanup(func() {
+		api.SetBaseURL("")
+		b.Close()
+	})
+	return b, &now
+}
+
+func TestTextItemExpires(t *testing.T) {
+	_, now := newTestBackend(t)
+	ctx := context.Background()
+
+	ttl := 60
+	resp, err := api.Do(ctx, "POST", "/shorts", models.CreateShortRequest{Content: "hello", TTL: &ttl})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	var created models.CreateShortResponse
+	if err := resp.Unmarshal(&created); err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Unix() + 60; created.ExpiresAt != want {
+		t.Errorf("expiresAt = %d, want %d", created.ExpiresAt, want)
+	}
+
+	resp, err = api.Do(ctx, "GET", "/shorts/"+created.ShortID, nil)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	var short models.Short
+	if err := resp.Unmarshal(&short); err != nil || short.Content != "hello" || short.Type != "text" {
+		t.Fatalf("short = %+v, %v", short, err)
+	}
+
+	*now = now.Add(time.Minute)
+	if _, err := api.Do(ctx, "GET", "/shorts/"+created.ShortID, nil); !errors.Is(err, api.ErrNotFound) {
+		t.Fatalf("after expiry: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMultipartUploadAndDownload(t *testing.T) {
+	newTestBackend(t)
+	ctx := context.Background()
+	data := bytes.Repeat([]byte("0123456789"), 1500)
+
+	resp, err := api.Do(ctx, "POST", "/shorts/file/init", models.InitUploadRequest{
+		Filename: "data.bin", ContentType: "application/octet-stream", FileSize: int64(len(data)), PartSize: 4096,
+	})
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	var init models.InitUploadResponse
+	if err := resp.Unmarshal(&init); err != nil {
+		t.Fatal(err)
+	}
+	if len(init.PresignedURLs) != 4 {
+		t.Fatalf("%d presigned URLs, want 4", len(init.PresignedURLs))
+	}
+
+	parts, err := upload.UploadParts(ctx, init.PresignedURLs, bytes.NewReader(data), int64(len(data)), init.PartSize, upload.Options{})
+	if err != nil {
+		t.Fatalf("UploadParts: %v", err)
+	}
+	resp, err = api.Do(ctx, "POST", "/shorts/file/complete", models.CompleteUploadRequest{ShortID: init.ShortID, Parts: parts})
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	var completed models.CompleteUploadResponse
+	if err := resp.Unmarshal(&completed); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := upload.VerifyETag(bytes.NewReader(data), int64(len(data)), init.PartSize, completed.ETag); !ok {
+		t.Errorf("ETag %s does not match the data", completed.ETag)
+	}
+
+	resp, err = api.Do(ctx, "GET", "/shorts/"+init.ShortID, nil)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	var short models.Short
+	if err := resp.Unmarshal(&short); err != nil {
+		t.Fatal(err)
+	}
+	dl, err := http.Get(short.DownloadURL)
+	if err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	defer dl.Body.Close()
+	got, _ := io.ReadAll(dl.Body)
+	if !bytes.Equal(got, data) || short.Filename != "data.bin" || short.FileSize != int64(len(data)) {
+		t.Fatalf("downloaded %d bytes of %s (%d), want %d", len(got), short.Filename, short.FileSize, len(data))
+	}
+}
+
+func TestShareAndSaveLoad(t *testing.T) {
+	b, _ := newTestBackend(t)
+	ctx := context.Background()
+
+	resp, err := api.Do(ctx, "POST", "/shorts", models.CreateShortRequest{Content: "shared"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := resp.GetString("shortId")
+	resp, err = api.Do(ctx, "POST", "/shorts/"+id+"/share", models.ShareRequest{IsPublic: true})
+	if err != nil {
+		t.Fatalf("share: %v", err)
+	}
+	var sh models.Share
+	if err := resp.Unmarshal(&sh); err != nil {
+		t.Fatal(err)
+	}
+	page, err := http.Get(sh.Link())
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(page.Body)
+	page.Body.Close()
+	if string(body) != "shared" {
+		t.Fatalf("share page = %q", body)
+	}
+
+	path := t.TempDir() + "/state.json"
+	if err := b.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	b2, _ := newTestBackend(t)
+	if err := b2.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.Do(ctx, "GET", "/shorts/"+id, nil); err != nil {
+		t.Fatalf("after Load: %v", err)
+	}
+	if _, err := api.Do(ctx, "GET", "/shorts/missing", nil); !errors.Is(err, api.ErrNotFound) {
+		t.Fatalf("missing: err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,53 @@
+package models
+
+// FileItem is a Pro file from /files.
+type FileItem struct {
+	FileID      string `json:"fileId"`
+	ID          string `json:"id"` // older responses
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"contentType"`
+	Description string `json:"description"`
+	DownloadURL string `json:"downloadUrl"`
+	CreatedAt   string `json:"createdAt"`
+	ExpiresAt   int64  `json:"expiresAt"`
+}
+
+// Key returns the file's ID from whichever field the backend set.
+func (f *FileItem) Key() string {
+	if f.FileID != "" {
+		return f.FileID
+	}
+	return f.ID
+}
+
+// FileList is one page of GET /files.
+type FileList struct {
+	Files []FileItem `json:"files"`
+}
+
+// InitFileUploadRequest starts a multipart upload of a Pro file with POST
+// /files/init. TTL is empty or "permanent" for files kept until deleted.
+type InitFileUploadRequest struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	FileSize    int64  `json:"fileSize"`
+	PartSize    int64  `json:"partSize,omitempty"`
+	TTL         string `json:"ttl,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// InitFileUploadResponse is the response of POST /files/init.
+type InitFileUploadResponse struct {
+	FileID        string         `json:"fileId"`
+	PresignedURLs []PresignedURL `json:"presignedUrls"`
+	PartSize      int            `json:"partSize"`
+	ExpiresAt     int64          `json:"expiresAt"`
+}
+
+// CompleteFileUploadRequest finishes a Pro file upload with POST
+// /files/complete, or discards it with POST /files/abort when Parts is empty.
+type CompleteFileUploadRequest struct {
+	FileID string          `json:"fileId"`
+	Parts  []CompletedPart `json:"parts,omitempty"`
+}
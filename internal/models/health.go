@@ -0,0 +1,8 @@
+package models
+
+// Health is the response to GET /health.
+type Health struct {
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	Timestamp string `json:"timestamp"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// CreateLinkRequest is the body of POST /links. TTL is a duration such as
+// "7d" or "permanent"; empty leaves it to the server's default.
+type CreateLinkRequest struct {
+	URL string `json:"url"`
+	TTL string `json:"ttl,omitempty"`
+}
+
+// Link is a shortened URL from /links. ExpiresAt is nil for permanent links.
+type Link struct {
+	Code      string `json:"code"`
+	URL       string `json:"url"`
+	ShortURL  string `json:"shortUrl"`
+	ExpiresAt *int64 `json:"expiresAt"`
+}
+
+// LinkList is GET /links.
+type LinkList struct {
+	Links []Link `json:"links"`
+}
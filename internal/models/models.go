@@ -0,0 +1,6 @@
+// Package models defines the request and response bodies of the nikte API,
+// shared by the commands and their tests.
+//
+// Field names follow the API's JSON. Where the backend has used more than one
+// name for a field over time, both are kept and a method picks the one set.
+package models
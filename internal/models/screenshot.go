@@ -0,0 +1,40 @@
+package models
+
+// Screenshot is an image from /screenshots.
+type Screenshot struct {
+	ScreenshotID string `json:"screenshotId"`
+	ID           string `json:"id"` // older responses
+	Filename     string `json:"filename"`
+	Size         int64  `json:"size"`
+	ContentType  string `json:"contentType"`
+	DownloadURL  string `json:"downloadUrl"`
+	CreatedAt    string `json:"createdAt"`
+	ExpiresAt    int64  `json:"expiresAt"`
+}
+
+// Key returns the screenshot's ID from whichever field the backend set.
+func (s *Screenshot) Key() string {
+	if s.ScreenshotID != "" {
+		return s.ScreenshotID
+	}
+	return s.ID
+}
+
+// ScreenshotList is one page of GET /screenshots.
+type ScreenshotList struct {
+	Screenshots []Screenshot `json:"screenshots"`
+}
+
+// CreateScreenshotRequest is the body of POST /screenshots. Data is the
+// base64-encoded image and TTL a duration such as "24h" or "permanent".
+type CreateScreenshotRequest struct {
+	ContentType string `json:"contentType"`
+	Data        string `json:"data"`
+	TTL         string `json:"ttl,omitempty"`
+}
+
+// CreateScreenshotResponse is the response to POST /screenshots.
+type CreateScreenshotResponse struct {
+	ScreenshotID string `json:"screenshotId"`
+	ExpiresAt    int64  `json:"expiresAt"`
+}
@@ -0,0 +1,69 @@
+package models
+
+// ShareRequest is the body of POST /shorts/{id}/share,
+// /screenshots/{id}/share and /files/{id}/share. Backends that understand
+// sub-day expiry use ExpiresInSeconds and ignore ExpiresInDays.
+type ShareRequest struct {
+	IsPublic         bool   `json:"isPublic"`
+	Password         string `json:"password,omitempty"`
+	Title            string `json:"title,omitempty"`
+	Description      string `json:"description,omitempty"`
+	MaxViews         int    `json:"maxViews,omitempty"`
+	ExpiresInDays    int    `json:"expiresInDays,omitempty"`
+	ExpiresInSeconds int    `json:"expiresInSeconds,omitempty"`
+	Direct           bool   `json:"direct,omitempty"`
+}
+
+// Share is a share link, as returned when creating one or by GET /shares.
+type Share struct {
+	ShareID     string `json:"shareId"`
+	ShareURL    string `json:"shareUrl"`
+	URL         string `json:"url"` // older responses
+	DirectURL   string `json:"directUrl"`
+	RawURL      string `json:"rawUrl"`
+	Type        string `json:"type"`
+	Filename    string `json:"filename"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ContentType string `json:"contentType"`
+	IsPublic    bool   `json:"isPublic"`
+	Password    string `json:"password"`
+	ViewCount   int    `json:"viewCount"`
+	MaxViews    *int   `json:"maxViews"`
+	ExpiresAt   int64  `json:"expiresAt"`
+}
+
+// Link returns the share's URL from whichever field the backend set.
+func (s *Share) Link() string {
+	if s.ShareURL != "" {
+		return s.ShareURL
+	}
+	return s.URL
+}
+
+// ShareList is GET /shares.
+type ShareList struct {
+	Shares []Share `json:"shares"`
+}
+
+// RequestLinkRequest is the body of POST /request-links, which creates a link
+// others can upload files through.
+type RequestLinkRequest struct {
+	Title          string `json:"title"`
+	MaxFileSize    int64  `json:"maxFileSize"`
+	MaxSubmissions int    `json:"maxSubmissions"`
+	ExpiresInHours int    `json:"expiresInHours"`
+	Password       string `json:"password,omitempty"`
+	OwnerLabel     string `json:"ownerLabel,omitempty"`
+}
+
+// RequestLink is the response to POST /request-links.
+type RequestLink struct {
+	URL string `json:"url"`
+}
+
+// ShareEmailRequest is the body of POST /shares/{id}/email.
+type ShareEmailRequest struct {
+	To      string `json:"to"`
+	Message string `json:"message,omitempty"`
+}
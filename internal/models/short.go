@@ -0,0 +1,68 @@
+package models
+
+// Short is a text or file item from /shorts. List responses carry a
+// ContentPreview; fetching a single short returns the full Content.
+type Short struct {
+	ShortID        string `json:"shortId"`
+	ID             string `json:"id"`   // older responses
+	Type           string `json:"type"` // "text" or "file"
+	Content        string `json:"content"`
+	ContentPreview string `json:"contentPreview"`
+	Filename       string `json:"filename"`
+	FileSize       int64  `json:"fileSize"`
+	ContentType    string `json:"contentType"`
+	DownloadURL    string `json:"downloadUrl"`
+	CreatedAt      string `json:"createdAt"`
+	ExpiresAt      int64  `json:"expiresAt"`
+}
+
+// Key returns the short's ID from whichever field the backend set.
+func (s *Short) Key() string {
+	if s.ShortID != "" {
+		return s.ShortID
+	}
+	return s.ID
+}
+
+// ShortList is one page of GET /shorts.
+type ShortList struct {
+	Shorts []Short `json:"shorts"`
+}
+
+// CreateShortRequest is the body of POST /shorts. TTL is in seconds, and 0
+// makes the short permanent; nil leaves it to the server's default.
+type CreateShortRequest struct {
+	Content string `json:"content"`
+	TTL     *int   `json:"ttl,omitempty"`
+}
+
+// CreateShortResponse is the response to POST /shorts.
+type CreateShortResponse struct {
+	ShortID   string `json:"shortId"`
+	ExpiresAt int64  `json:"expiresAt"`
+}
+
+// UpdateShortRequest is the body of PATCH /shorts/{id} replacing a text
+// short's content.
+type UpdateShortRequest struct {
+	Content string `json:"content"`
+}
+
+// RenameRequest is the body of PATCH /shorts/{id}, /screenshots/{id} or
+// /files/{id} changing the name downloads are saved under, or a text item's
+// title.
+type RenameRequest struct {
+	Filename string `json:"filename,omitempty"`
+	Title    string `json:"title,omitempty"`
+}
+
+// ExtendRequest is the body of PATCH /shorts/{id}.
+type ExtendRequest struct {
+	TTL       string `json:"ttl,omitempty"`
+	Permanent bool   `json:"permanent,omitempty"`
+}
+
+// ExtendResponse is the response to PATCH /shorts/{id}.
+type ExtendResponse struct {
+	ExpiresAt int64 `json:"expiresAt"`
+}
@@ -0,0 +1,65 @@
+package models
+
+// InitUploadRequest is the body of POST /shorts/file/init, which starts a
+// multipart upload. PartSize of 0 lets the server choose; TTL is a duration
+// such as "24h" or "permanent". Compression ("gzip" or "zstd") and
+// OriginalSize are set when the file was compressed before upload.
+type InitUploadRequest struct {
+	Filename     string `json:"filename"`
+	ContentType  string `json:"contentType"`
+	FileSize     int64  `json:"fileSize"`
+	PartSize     int64  `json:"partSize,omitempty"`
+	TTL          string `json:"ttl,omitempty"`
+	Compression  string `json:"compression,omitempty"`
+	OriginalSize int64  `json:"originalSize,omitempty"`
+}
+
+// InitUploadResponse is the response to POST /shorts/file/init: where to PUT
+// each part.
+type InitUploadResponse struct {
+	ShortID       string         `json:"shortId"`
+	PresignedURLs []PresignedURL `json:"presignedUrls"`
+	PartSize      int            `json:"partSize"`
+	ExpiresAt     int64          `json:"expiresAt"`
+}
+
+// PresignedURL is where to upload one part.
+type PresignedURL struct {
+	PartNumber int    `json:"partNumber"`
+	URL        string `json:"url"`
+}
+
+// CompletedPart is an uploaded part and the ETag storage returned for it.
+type CompletedPart struct {
+	PartNumber int    `json:"partNumber"`
+	ETag       string `json:"etag"`
+}
+
+// UploadPartsRequest is the body of POST /shorts/file/parts, which checks an
+// unfinished multipart upload is still open and presigns URLs for the given
+// parts, so an interrupted upload can be resumed. The response is an
+// InitUploadResponse.
+type UploadPartsRequest struct {
+	ShortID     string `json:"shortId"`
+	PartNumbers []int  `json:"partNumbers"`
+}
+
+// AbortUploadRequest is the body of POST /shorts/file/abort, which discards an
+// unfinished multipart upload and the parts stored so far.
+type AbortUploadRequest struct {
+	ShortID string `json:"shortId"`
+}
+
+// CompleteUploadRequest is the body of POST /shorts/file/complete.
+type CompleteUploadRequest struct {
+	ShortID string          `json:"shortId"`
+	Parts   []CompletedPart `json:"parts"`
+}
+
+// CompleteUploadResponse is the response to POST /shorts/file/complete. ETag
+// is the stored object's, which for a multipart upload is S3's composite
+// "<md5 of part md5s>-<parts>"; servers that don't report it leave it empty.
+type CompleteUploadResponse struct {
+	ETag string `json:"etag"`
+	Size int64  `json:"size"`
+}
@@ -0,0 +1,74 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// grimBackend captures on wlroots-based Wayland compositors with grim,
+// asking slurp for the region.
+type grimBackend struct{}
+
+func (grimBackend) Name() string { return "grim" }
+
+func (grimBackend) Available() bool {
+	return runtime.GOOS == "linux" && os.Getenv("WAYLAND_DISPLAY") != "" &&
+		hasCommands("grim", "slurp")
+}
+
+// Capabilities leaves out clicking a window: slurp selects regions only, so
+// window mode selects a region too.
+func (grimBackend) Capabilities() CaptureCapabilities {
+	return CaptureCapabilities{Fullscreen: true, Displays: true, Regions: true}
+}
+
+func (b grimBackend) Capture(window, fullscreen bool) ([]byte, error) {
+	if fullscreen {
+		return b.run()
+	}
+	out, err := exec.Command("slurp").Output()
+	geometry := strings.TrimSpace(string(out))
+	if err != nil || geometry == "" {
+		return nil, nil // User cancelled
+	}
+	return b.run("-g", geometry)
+}
+
+// CaptureDisplay captures the output d with grim -o.
+func (b grimBackend) CaptureDisplay(d Display) ([]byte, error) {
+	return b.run("-o", d.Name)
+}
+
+// CaptureRegion captures r with grim -g.
+func (b grimBackend) CaptureRegion(r image.Rectangle) ([]byte, error) {
+	return b.run("-g", fmt.Sprintf("%d,%d %dx%d", r.Min.X, r.Min.Y, r.Dx(), r.Dy()))
+}
+
+func (grimBackend) run(args ...string) ([]byte, error) {
+	tempFile := tempScreenshotFile()
+	defer os.Remove(tempFile)
+	if err := exec.Command("grim", append(args, tempFile)...).Run(); err != nil {
+		return nil, fmt.Errorf("grim failed: %w", err)
+	}
+	return os.ReadFile(tempFile)
+}
+
+// hasCommands reports whether all the named programs are installed.
+func hasCommands(names ...string) bool {
+	for _, name := range names {
+		if _, err := exec.LookPath(name); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func isExitError(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr)
+}
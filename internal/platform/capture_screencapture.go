@@ -0,0 +1,81 @@
+package platform
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// screencaptureBackend captures with screencapture, which ships with macOS.
+type screencaptureBackend struct{}
+
+func (screencaptureBackend) Name() string { return "screencapture" }
+
+func (screencaptureBackend) Available() bool { return runtime.GOOS == "darwin" }
+
+func (screencaptureBackend) Capabilities() CaptureCapabilities {
+	return CaptureCapabilities{Window: true, Fullscreen: true, Displays: true, Windows: true, Regions: true}
+}
+
+func (b screencaptureBackend) Capture(window, fullscreen bool) ([]byte, error) {
+	var args []string
+	if window {
+		args = append(args, "-w")
+	} else if !fullscreen {
+		// Interactive selection (default)
+		args = append(args, "-i")
+	}
+	return b.run(args...)
+}
+
+// CaptureDisplay captures d with screencapture -D, which numbers displays
+// as ListDisplays does.
+func (b screencaptureBackend) CaptureDisplay(d Display) ([]byte, error) {
+	data, err := b.run("-D", fmt.Sprint(d.Number))
+	if err != nil {
+		return nil, fmt.Errorf("no display %d (see --display list): %w", d.Number, err)
+	}
+	return data, nil
+}
+
+// CaptureWindow captures w with screencapture -l, without having to click
+// it.
+func (b screencaptureBackend) CaptureWindow(w Window) ([]byte, error) {
+	data, err := b.run("-l", w.ID)
+	if err != nil {
+		return nil, fmt.Errorf("capturing window %s: %w", w.ID, err)
+	}
+	return data, nil
+}
+
+// CaptureRegion captures r with screencapture -R.
+func (b screencaptureBackend) CaptureRegion(r image.Rectangle) ([]byte, error) {
+	return b.run("-R", fmt.Sprintf("%d,%d,%d,%d", r.Min.X, r.Min.Y, r.Dx(), r.Dy()))
+}
+
+// run runs screencapture with args and the file to write, returning the
+// capture, or nil if the user cancelled and no file was written.
+func (screencaptureBackend) run(args ...string) ([]byte, error) {
+	tempFile := tempScreenshotFile()
+	defer os.Remove(tempFile)
+
+	cmd := exec.Command("screencapture", append(args, tempFile)...)
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	// Check if file was created (user might have cancelled)
+	imageData, err := os.ReadFile(tempFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(imageData) == 0 {
+		return nil, nil // User cancelled
+	}
+	return imageData, nil
+}
@@ -0,0 +1,108 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// commandBackend is a Linux screenshot program that writes a capture to a
+// file given on its command line.
+type commandBackend struct {
+	name    string
+	wayland bool // works under Wayland
+	x11     bool // works under X11
+	caps    CaptureCapabilities
+	// args returns the command line writing a capture to file.
+	args func(file string, window, fullscreen bool) []string
+}
+
+var gnomeScreenshotBackend = commandBackend{
+	name: "gnome-screenshot", wayland: true, x11: true,
+	caps: CaptureCapabilities{Window: true, Fullscreen: true},
+	args: func(file string, window, fullscreen bool) []string {
+		switch {
+		case window:
+			return []string{"-w", "-f", file}
+		case fullscreen:
+			return []string{"-f", file}
+		}
+		return []string{"-a", "-f", file}
+	},
+}
+
+var spectacleBackend = commandBackend{
+	name: "spectacle", wayland: true, x11: true,
+	caps: CaptureCapabilities{Window: true, Fullscreen: true},
+	args: func(file string, window, fullscreen bool) []string {
+		mode := "-r"
+		switch {
+		case window:
+			mode = "-a"
+		case fullscreen:
+			mode = "-f"
+		}
+		return []string{"-b", "-n", mode, "-o", file}
+	},
+}
+
+var maimBackend = commandBackend{
+	// Clicking instead of dragging in -s mode selects a whole window.
+	name: "maim", x11: true,
+	caps: CaptureCapabilities{Window: true, Fullscreen: true},
+	args: func(file string, window, fullscreen bool) []string {
+		if fullscreen {
+			return []string{file}
+		}
+		return []string{"-s", file}
+	},
+}
+
+var scrotBackend = commandBackend{
+	name: "scrot", x11: true,
+	caps: CaptureCapabilities{Window: true, Fullscreen: true},
+	args: func(file string, window, fullscreen bool) []string {
+		switch {
+		case window:
+			return []string{"-o", "-u", file}
+		case fullscreen:
+			return []string{"-o", file}
+		}
+		return []string{"-o", "-s", file}
+	},
+}
+
+func (b commandBackend) Name() string { return b.name }
+
+func (b commandBackend) Available() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	wayland := os.Getenv("WAYLAND_DISPLAY") != ""
+	x11 := os.Getenv("DISPLAY") != ""
+	if !(wayland && b.wayland) && !(x11 && b.x11) {
+		return false
+	}
+	return hasCommands(b.name)
+}
+
+func (b commandBackend) Capabilities() CaptureCapabilities { return b.caps }
+
+func (b commandBackend) Capture(window, fullscreen bool) ([]byte, error) {
+	tempFile := tempScreenshotFile()
+	defer os.Remove(tempFile)
+
+	err := exec.Command(b.name, b.args(tempFile, window, fullscreen)...).Run()
+
+	// Most tools exit non-zero when the selection is cancelled, leaving no
+	// file, so a missing file means cancelled rather than failed.
+	imageData, readErr := os.ReadFile(tempFile)
+	if readErr != nil || len(imageData) == 0 {
+		if err != nil && !isExitError(err) {
+			return nil, fmt.Errorf("%s failed: %w", b.name, err)
+		}
+		return nil, nil // User cancelled
+	}
+	return imageData, nil
+}
@@ -1,34 +1,202 @@
 package platform
 
 import (
+	"errors"
+	"fmt"
+	"os"
 	"os/exec"
-	"runtime"
+	"path/filepath"
 	"strings"
+	"time"
+
+	"github.com/pkg/browser"
 )
 
-// IsScreenshotSupported returns true if screenshot capture is supported on this platform
-func IsScreenshotSupported() bool {
-	return runtime.GOOS == "darwin"
+// ClipboardCapabilities is what a Clipboard backend can do beyond text.
+type ClipboardCapabilities struct {
+	ReadImage  bool `json:"read_image"`
+	WriteImage bool `json:"write_image"`
+}
+
+// Clipboard is a clipboard backend.
+type Clipboard interface {
+	Name() string
+	// Available reports whether the backend works here: on this OS and
+	// desktop session, with its programs installed.
+	Available() bool
+	Capabilities() ClipboardCapabilities
+	ReadText() (string, error)
+	WriteText(text string) error
+	HasImage() bool
+	// ReadImage returns the image on the clipboard, as PNG when offered
+	// that way, or nil data if there is none.
+	ReadImage() ([]byte, error)
+	WriteImage(png []byte) error
+}
+
+// clipboards are the clipboard backends in order of preference; the first
+// available one is used. The system backend is always available.
+var clipboards = []Clipboard{
+	osc52Clipboard{},
+	wslClipboard{},
+	macClipboard{},
+	wlClipboard,
+	xclipClipboard,
+	windowsClipboard{},
+	systemClipboard{},
+}
+
+// errNoImageClipboard is returned for clipboard images when the selected
+// backend can't handle them.
+var errNoImageClipboard = errors.New("clipboard images need macOS, Windows, or on Linux wl-clipboard (Wayland) or xclip (X11)")
+
+// clipboardOverride replaces the detected clipboard backend; see
+// SetClipboard.
+var clipboardOverride Clipboard
+
+// SetClipboard makes nk use c instead of the clipboard backend it detects,
+// e.g. an in-memory one in tests. Passing nil restores detection. It
+// returns the previous override, for putting it back.
+func SetClipboard(c Clipboard) (previous Clipboard) {
+	previous, clipboardOverride = clipboardOverride, c
+	return previous
+}
+
+// SelectedClipboard returns the clipboard backend in use.
+func SelectedClipboard() Clipboard {
+	if clipboardOverride != nil {
+		return clipboardOverride
+	}
+	for _, c := range clipboards {
+		if c.Available() {
+			return c
+		}
+	}
+	return systemClipboard{}
+}
+
+// CopyText puts text on the clipboard. Inside WSL it goes to the Windows
+// clipboard, as there is usually no X11 or Wayland one to write to, and over
+// SSH to the local terminal's.
+func CopyText(text string) error {
+	return SelectedClipboard().WriteText(text)
+}
+
+// PasteText returns the text on the clipboard, from the Windows clipboard
+// inside WSL.
+func PasteText() (string, error) {
+	return SelectedClipboard().ReadText()
 }
 
-// ClipboardHasImage checks if clipboard contains image data (macOS only)
+// ClipboardHasImage checks if clipboard contains image data
 func ClipboardHasImage() bool {
-	if runtime.GOOS != "darwin" {
-		return false
+	return SelectedClipboard().HasImage()
+}
+
+// GetClipboardImage extracts image from clipboard, as PNG when the copying
+// application offers it. It returns nil data if there is no image.
+func GetClipboardImage() ([]byte, error) {
+	return SelectedClipboard().ReadImage()
+}
+
+// SetClipboardImage puts PNG data on the clipboard.
+func SetClipboardImage(data []byte) error {
+	return SelectedClipboard().WriteImage(data)
+}
+
+// OpenURL opens url in the default browser (or mail client, for mailto
+// links), the Windows one inside WSL.
+func OpenURL(url string) error {
+	if IsWSL() {
+		return wslOpenURL(url)
 	}
+	return browser.OpenURL(url)
+}
 
-	cmd := exec.Command("osascript", "-e", "clipboard info")
-	output, err := cmd.Output()
-	if err != nil {
-		return false
+// clipboardImageTypes are the image formats asked for, most preferred
+// first. Anything else offered as image/* is taken as a last resort.
+var clipboardImageTypes = []string{"image/png", "image/jpeg", "image/webp", "image/gif", "image/bmp"}
+
+// preferredImageType picks the image format to request from the MIME types
+// the clipboard offers, or "" if none is an image.
+func preferredImageType(offered []string) string {
+	for _, want := range clipboardImageTypes {
+		for _, t := range offered {
+			if t == want {
+				return t
+			}
+		}
+	}
+	for _, t := range offered {
+		if strings.HasPrefix(t, "image/") {
+			return t
+		}
 	}
+	return ""
+}
+
+// tempImageFile returns a path for a clipboard image, written with data
+// unless it is nil. The caller removes it.
+func tempImageFile(data []byte) (string, error) {
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("nk-clipboard-%d.png", time.Now().UnixNano()))
+	if data == nil {
+		return path, nil
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
 
-	imageTypes := []string{"PNGf", "JPEG", "TIFF", "GIF", "jp2 ", "BMP", "AVIF"}
-	outputStr := string(output)
-	for _, t := range imageTypes {
-		if strings.Contains(outputStr, t) {
-			return true
+// powershell runs a PowerShell script for the Windows clipboard, which needs
+// a single-threaded apartment, hence -STA.
+func powershell(script string) ([]byte, error) {
+	cmd := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-STA", "-Command",
+		"Add-Type -AssemblyName System.Windows.Forms, System.Drawing; "+script)
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("powershell.exe failed: %w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
 		}
+		return nil, fmt.Errorf("powershell.exe failed: %w", err)
+	}
+	return out, nil
+}
+
+// psQuote quotes s as a PowerShell string literal.
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// powershellHasImage reports whether the Windows clipboard holds an image.
+func powershellHasImage() bool {
+	out, err := powershell("[System.Windows.Forms.Clipboard]::ContainsImage()")
+	return err == nil && strings.TrimSpace(string(out)) == "True"
+}
+
+// powershellGetImage saves the image on the Windows clipboard as PNG to the
+// file at winPath (a Windows path), if there is one.
+func powershellGetImage(winPath string) error {
+	_, err := powershell("$img = [System.Windows.Forms.Clipboard]::GetImage(); " +
+		"if ($img) { $img.Save(" + psQuote(winPath) + ", [System.Drawing.Imaging.ImageFormat]::Png); $img.Dispose() }")
+	return err
+}
+
+// powershellSetImage puts the image in the file at winPath (a Windows path)
+// on the Windows clipboard.
+func powershellSetImage(winPath string) error {
+	_, err := powershell("$img = [System.Drawing.Image]::FromFile(" + psQuote(winPath) + "); " +
+		"[System.Windows.Forms.Clipboard]::SetImage($img); $img.Dispose()")
+	return err
+}
+
+// readImageFile returns the image a backend wrote to path, or nil if it
+// wrote none.
+func readImageFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) || (err == nil && len(data) == 0) {
+		return nil, nil
 	}
-	return false
+	return data, err
 }
@@ -0,0 +1,105 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// macClipboard is the macOS clipboard: text through pbcopy and pbpaste,
+// images through osascript.
+type macClipboard struct{ systemClipboard }
+
+func (macClipboard) Name() string    { return "macos" }
+func (macClipboard) Available() bool { return runtime.GOOS == "darwin" }
+
+func (macClipboard) Capabilities() ClipboardCapabilities {
+	return ClipboardCapabilities{ReadImage: true, WriteImage: true}
+}
+
+func (macClipboard) HasImage() bool {
+	cmd := exec.Command("osascript", "-e", "clipboard info")
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+
+	imageTypes := []string{"PNGf", "JPEG", "TIFF", "GIF", "jp2 ", "BMP", "AVIF"}
+	outputStr := string(output)
+	for _, t := range imageTypes {
+		if strings.Contains(outputStr, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// clipboardWriteScript writes the clipboard, coerced to the class given as
+// the second argument, to the file named by the first.
+const clipboardWriteScript = `on run argv
+	set f to open for access (POSIX file (item 1 of argv)) with write permission
+	try
+		set eof f to 0
+		if item 2 of argv is "TIFF" then
+			write (the clipboard as «class TIFF») to f
+		else
+			write (the clipboard as «class PNGf») to f
+		end if
+	on error errMsg
+		close access f
+		error errMsg
+	end try
+	close access f
+end run`
+
+// writeClipboardAs writes the clipboard image as class ("PNGf" or "TIFF")
+// to file, reporting whether there was one of that class.
+func writeClipboardAs(class, file string) bool {
+	cmd := exec.Command("osascript", "-e", clipboardWriteScript, file, class)
+	if err := cmd.Run(); err != nil {
+		return false
+	}
+	fi, err := os.Stat(file)
+	return err == nil && fi.Size() > 0
+}
+
+// ReadImage extracts the image as PNG. Images only offered as TIFF (such as
+// those copied from Preview) are converted with sips.
+func (macClipboard) ReadImage() ([]byte, error) {
+	pngFile, err := tempImageFile(nil)
+	if err != nil {
+		return nil, err
+	}
+	tiffFile := strings.TrimSuffix(pngFile, ".png") + ".tiff"
+	defer os.Remove(pngFile)
+	defer os.Remove(tiffFile)
+
+	if !writeClipboardAs("PNGf", pngFile) {
+		if !writeClipboardAs("TIFF", tiffFile) {
+			return nil, nil
+		}
+		cmd := exec.Command("sips", "-s", "format", "png", tiffFile, "--out", pngFile)
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("converting clipboard image to PNG: %w", err)
+		}
+	}
+	return readImageFile(pngFile)
+}
+
+func (macClipboard) WriteImage(png []byte) error {
+	path, err := tempImageFile(png)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	script := `on run argv
+	set the clipboard to (read (POSIX file (item 1 of argv)) as «class PNGf»)
+end run`
+	if err := exec.Command("osascript", "-e", script, path).Run(); err != nil {
+		return fmt.Errorf("copying image to clipboard: %w", err)
+	}
+	return nil
+}
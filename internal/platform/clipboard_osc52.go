@@ -0,0 +1,49 @@
+package platform
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// osc52Clipboard copies text to the clipboard of the terminal nk is shown
+// in, through the OSC 52 escape sequence. Over SSH that is the local
+// machine's clipboard, which the remote one has no other way to reach.
+// Reading isn't possible this way, so reads go to the remote clipboard.
+type osc52Clipboard struct{ systemClipboard }
+
+func (osc52Clipboard) Name() string { return "osc52" }
+
+// Available reports an SSH session without a forwarded X11 or Wayland
+// display.
+func (osc52Clipboard) Available() bool {
+	ssh := os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+	return ssh && os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == ""
+}
+
+// WriteText writes the sequence to the controlling terminal rather than
+// stdout, so it works with output redirected.
+func (osc52Clipboard) WriteText(text string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("no terminal to copy through: %w", err)
+	}
+	defer tty.Close()
+	_, err = tty.WriteString(osc52Sequence(text, os.Getenv("TMUX") != "", strings.HasPrefix(os.Getenv("TERM"), "screen")))
+	return err
+}
+
+// osc52Sequence returns the OSC 52 sequence setting the clipboard to text.
+// tmux and screen only pass it on to the outer terminal wrapped in a DCS
+// sequence, with tmux also needing its escapes doubled.
+func osc52Sequence(text string, tmux, screen bool) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	switch {
+	case tmux:
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case screen:
+		return "\x1bP" + seq + "\x1b\\"
+	}
+	return seq
+}
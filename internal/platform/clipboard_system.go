@@ -0,0 +1,56 @@
+package platform
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/atotto/clipboard"
+)
+
+// systemClipboard handles text only, with whatever the clipboard package
+// finds (pbcopy, xclip, xsel, wl-clipboard or the Windows API). It is the
+// fallback when no other backend is available.
+type systemClipboard struct{}
+
+func (systemClipboard) Name() string                        { return "system" }
+func (systemClipboard) Available() bool                     { return true }
+func (systemClipboard) Capabilities() ClipboardCapabilities { return ClipboardCapabilities{} }
+func (systemClipboard) ReadText() (string, error)           { return clipboard.ReadAll() }
+func (systemClipboard) WriteText(text string) error         { return clipboard.WriteAll(text) }
+func (systemClipboard) HasImage() bool                      { return false }
+func (systemClipboard) ReadImage() ([]byte, error)          { return nil, errNoImageClipboard }
+func (systemClipboard) WriteImage(png []byte) error         { return errNoImageClipboard }
+
+// windowsClipboard is the Windows clipboard: text through the Windows API,
+// images through PowerShell.
+type windowsClipboard struct{ systemClipboard }
+
+func (windowsClipboard) Name() string    { return "windows" }
+func (windowsClipboard) Available() bool { return runtime.GOOS == "windows" }
+
+func (windowsClipboard) Capabilities() ClipboardCapabilities {
+	return ClipboardCapabilities{ReadImage: true, WriteImage: true}
+}
+
+func (windowsClipboard) HasImage() bool { return powershellHasImage() }
+
+func (windowsClipboard) ReadImage() ([]byte, error) {
+	path, err := tempImageFile(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+	if err := powershellGetImage(path); err != nil {
+		return nil, err
+	}
+	return readImageFile(path)
+}
+
+func (windowsClipboard) WriteImage(png []byte) error {
+	path, err := tempImageFile(png)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+	return powershellSetImage(path)
+}
@@ -0,0 +1,36 @@
+package platform
+
+import "testing"
+
+func TestOSC52Sequence(t *testing.T) {
+	tests := []struct {
+		name         string
+		tmux, screen bool
+		want         string
+	}{
+		{"plain", false, false, "\x1b]52;c;aGk=\a"},
+		{"tmux", true, false, "\x1bPtmux;\x1b\x1b]52;c;aGk=\a\x1b\\"},
+		{"screen", false, true, "\x1bP\x1b]52;c;aGk=\a\x1b\\"},
+	}
+	for _, tt := range tests {
+		if got := osc52Sequence("hi", tt.tmux, tt.screen); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPreferredImageType(t *testing.T) {
+	tests := []struct {
+		offered []string
+		want    string
+	}{
+		{[]string{"text/plain", "image/jpeg", "image/png"}, "image/png"},
+		{[]string{"TARGETS", "image/tiff"}, "image/tiff"},
+		{[]string{"text/plain", "UTF8_STRING"}, ""},
+	}
+	for _, tt := range tests {
+		if got := preferredImageType(tt.offered); got != tt.want {
+			t.Errorf("preferredImageType(%q) = %q, want %q", tt.offered, got, tt.want)
+		}
+	}
+}
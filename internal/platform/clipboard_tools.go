@@ -0,0 +1,132 @@
+package platform
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardTool is a Linux clipboard backend built on a pair of programs
+// that read and write typed clipboard contents.
+type clipboardTool struct {
+	name     string
+	env      string // set in the sessions it works in
+	paste    string
+	copy     string
+	listArgs []string // lists the offered MIME types, one per line
+	// pasteArgs and copyArgs return the command lines printing the
+	// contents, or copying stdin, as mimeType ("" for text).
+	pasteArgs func(mimeType string) []string
+	copyArgs  func(mimeType string) []string
+}
+
+// wlClipboard is the Wayland clipboard, through wl-clipboard.
+var wlClipboard = clipboardTool{
+	name:     "wl-clipboard",
+	env:      "WAYLAND_DISPLAY",
+	paste:    "wl-paste",
+	copy:     "wl-copy",
+	listArgs: []string{"--list-types"},
+	pasteArgs: func(mimeType string) []string {
+		if mimeType == "" {
+			return []string{"--no-newline"}
+		}
+		return []string{"--no-newline", "--type", mimeType}
+	},
+	copyArgs: func(mimeType string) []string {
+		if mimeType == "" {
+			return nil
+		}
+		return []string{"--type", mimeType}
+	},
+}
+
+// xclipClipboard is the X11 clipboard, through xclip. (xsel only handles
+// text, so the system backend covers it.)
+var xclipClipboard = clipboardTool{
+	name:     "xclip",
+	env:      "DISPLAY",
+	paste:    "xclip",
+	copy:     "xclip",
+	listArgs: []string{"-selection", "clipboard", "-t", "TARGETS", "-o"},
+	pasteArgs: func(mimeType string) []string {
+		if mimeType == "" {
+			return []string{"-selection", "clipboard", "-o"}
+		}
+		return []string{"-selection", "clipboard", "-t", mimeType, "-o"}
+	},
+	copyArgs: func(mimeType string) []string {
+		if mimeType == "" {
+			return []string{"-selection", "clipboard", "-i"}
+		}
+		return []string{"-selection", "clipboard", "-t", mimeType, "-i"}
+	},
+}
+
+func (c clipboardTool) Name() string { return c.name }
+
+func (c clipboardTool) Available() bool {
+	return runtime.GOOS == "linux" && os.Getenv(c.env) != "" && hasCommands(c.paste, c.copy)
+}
+
+func (c clipboardTool) Capabilities() ClipboardCapabilities {
+	return ClipboardCapabilities{ReadImage: true, WriteImage: true}
+}
+
+func (c clipboardTool) ReadText() (string, error) {
+	out, err := exec.Command(c.paste, c.pasteArgs("")...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w", c.paste, err)
+	}
+	return string(out), nil
+}
+
+func (c clipboardTool) WriteText(text string) error {
+	return c.write("", []byte(text))
+}
+
+// imageType returns the image type the clipboard offers, or "".
+func (c clipboardTool) imageType() string {
+	out, err := exec.Command(c.paste, c.listArgs...).Output()
+	if err != nil {
+		return ""
+	}
+	return preferredImageType(strings.Fields(string(out)))
+}
+
+func (c clipboardTool) HasImage() bool {
+	return c.imageType() != ""
+}
+
+func (c clipboardTool) ReadImage() ([]byte, error) {
+	imageType := c.imageType()
+	if imageType == "" {
+		return nil, nil
+	}
+	data, err := exec.Command(c.paste, c.pasteArgs(imageType)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", c.paste, err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return data, nil
+}
+
+func (c clipboardTool) WriteImage(png []byte) error {
+	return c.write("image/png", png)
+}
+
+// write copies data as mimeType. Both programs fork a process that keeps
+// serving the clipboard, so Run returns once the data is handed over.
+func (c clipboardTool) write(mimeType string, data []byte) error {
+	cmd := exec.Command(c.copy, c.copyArgs(mimeType)...)
+	cmd.Stdin = bytes.NewReader(data)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", c.copy, err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package platform
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode/utf16"
+)
+
+var (
+	wslOnce sync.Once
+	wslSeen bool
+)
+
+// IsWSL reports whether this is Linux running under the Windows Subsystem
+// for Linux, where the clipboard and browser belong to Windows.
+func IsWSL() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	wslOnce.Do(func() {
+		if os.Getenv("WSL_DISTRO_NAME") != "" || os.Getenv("WSL_INTEROP") != "" {
+			wslSeen = true
+			return
+		}
+		release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+		wslSeen = err == nil && strings.Contains(strings.ToLower(string(release)), "microsoft")
+	})
+	return wslSeen
+}
+
+// wslClipboard is the Windows clipboard seen from WSL, as there is usually
+// no X11 or Wayland one to use.
+type wslClipboard struct{}
+
+func (wslClipboard) Name() string    { return "wsl" }
+func (wslClipboard) Available() bool { return IsWSL() }
+
+func (wslClipboard) Capabilities() ClipboardCapabilities {
+	return ClipboardCapabilities{ReadImage: true, WriteImage: true}
+}
+
+// WriteText copies text with clip.exe. clip.exe reads the console code page
+// unless given UTF-16 with a byte order mark, so text is sent that way to
+// keep non-ASCII characters intact.
+func (wslClipboard) WriteText(text string) error {
+	units := utf16.Encode([]rune(text))
+	buf := make([]byte, 2, 2+2*len(units))
+	buf[0], buf[1] = 0xff, 0xfe
+	for _, u := range units {
+		buf = append(buf, byte(u), byte(u>>8))
+	}
+	cmd := exec.Command("clip.exe")
+	cmd.Stdin = bytes.NewReader(buf)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("clip.exe failed: %w", err)
+	}
+	return nil
+}
+
+// ReadText reads the clipboard through PowerShell, asking for UTF-8 output.
+func (wslClipboard) ReadText() (string, error) {
+	out, err := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-Command",
+		"[Console]::OutputEncoding = [Text.Encoding]::UTF8; Get-Clipboard -Raw").Output()
+	if err != nil {
+		return "", fmt.Errorf("powershell.exe failed: %w", err)
+	}
+	// PowerShell ends its output with a newline of its own.
+	text := strings.TrimSuffix(string(out), "\n")
+	text = strings.TrimSuffix(text, "\r")
+	return strings.ReplaceAll(text, "\r\n", "\n"), nil
+}
+
+func (wslClipboard) HasImage() bool { return powershellHasImage() }
+
+// ReadImage has PowerShell save the image to a temporary file, given by its
+// Windows path.
+func (wslClipboard) ReadImage() ([]byte, error) {
+	path, err := tempImageFile(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+	winPath, err := wslWindowsPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := powershellGetImage(winPath); err != nil {
+		return nil, err
+	}
+	return readImageFile(path)
+}
+
+// WriteImage has PowerShell read the image from a temporary file, given by
+// its Windows path.
+func (wslClipboard) WriteImage(png []byte) error {
+	path, err := tempImageFile(png)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+	winPath, err := wslWindowsPath(path)
+	if err != nil {
+		return err
+	}
+	return powershellSetImage(winPath)
+}
+
+// wslWindowsPath returns the Windows path of a Linux path inside WSL.
+func wslWindowsPath(path string) (string, error) {
+	out, err := exec.Command("wslpath", "-w", path).Output()
+	if err != nil {
+		return "", fmt.Errorf("wslpath failed: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// wslOpenURL opens url with wslview (from wslu) when installed, or else
+// with explorer.exe.
+func wslOpenURL(url string) error {
+	if _, err := exec.LookPath("wslview"); err == nil {
+		return exec.Command("wslview", url).Run()
+	}
+	// explorer.exe exits with 1 even when it opened the URL.
+	err := exec.Command("explorer.exe", url).Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return fmt.Errorf("explorer.exe failed: %w", err)
+	}
+	return nil
+}
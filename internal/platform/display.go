@@ -0,0 +1,178 @@
+package platform
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Display is a monitor that can be captured with CaptureDisplay.
+type Display struct {
+	Number int    `json:"number"` // 1-based, as given to --display
+	Name   string `json:"name"`   // e.g. "Color LCD" or "HDMI-1"
+	Width  int    `json:"width"`  // in pixels
+	Height int    `json:"height"`
+	X      int    `json:"x"` // position on the desktop, where known
+	Y      int    `json:"y"`
+	Main   bool   `json:"main"`
+}
+
+func (d Display) String() string {
+	s := fmt.Sprintf("%d: %s (%dx%d)", d.Number, d.Name, d.Width, d.Height)
+	if d.Main {
+		s += " main"
+	}
+	return s
+}
+
+// findDisplay returns display n of displays.
+func findDisplay(displays []Display, n int) (Display, error) {
+	for _, d := range displays {
+		if d.Number == n {
+			return d, nil
+		}
+	}
+	return Display{}, fmt.Errorf("no display %d (there are %d; see --display list)", n, len(displays))
+}
+
+// ListDisplays returns the connected displays: on macOS numbered as
+// screencapture -D numbers them, the main display first; on Linux from
+// wlr-randr under Wayland or xrandr under X11.
+func ListDisplays() ([]Display, error) {
+	switch {
+	case runtime.GOOS == "darwin":
+		return listMacDisplays()
+	case runtime.GOOS != "linux":
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		out, err := exec.Command("wlr-randr").Output()
+		if err != nil {
+			return nil, fmt.Errorf("listing displays needs wlr-randr on Wayland: %w", err)
+		}
+		return parseWlrRandr(out), nil
+	case os.Getenv("DISPLAY") != "":
+		out, err := exec.Command("xrandr", "--listactivemonitors").Output()
+		if err != nil {
+			return nil, fmt.Errorf("listing displays needs xrandr: %w", err)
+		}
+		return parseXrandrMonitors(out), nil
+	}
+	return nil, ErrScreenshotUnsupported
+}
+
+// listMacDisplays lists the displays system_profiler reports.
+func listMacDisplays() ([]Display, error) {
+	out, err := exec.Command("system_profiler", "SPDisplaysDataType", "-json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing displays: %w", err)
+	}
+	var report struct {
+		Graphics []struct {
+			Displays []struct {
+				Name   string `json:"_name"`
+				Pixels string `json:"_spdisplays_pixels"`
+				Main   string `json:"spdisplays_main"`
+			} `json:"spdisplays_ndrvs"`
+		} `json:"SPDisplaysDataType"`
+	}
+	if err := json.Unmarshal(out, &report); err != nil {
+		return nil, fmt.Errorf("listing displays: %w", err)
+	}
+
+	var main, others []Display
+	for _, g := range report.Graphics {
+		for _, d := range g.Displays {
+			display := Display{Name: d.Name, Main: d.Main == "spdisplays_yes"}
+			fmt.Sscanf(d.Pixels, "%d x %d", &display.Width, &display.Height)
+			if display.Main {
+				main = append(main, display)
+			} else {
+				others = append(others, display)
+			}
+		}
+	}
+	displays := append(main, others...)
+	for i := range displays {
+		displays[i].Number = i + 1
+	}
+	return displays, nil
+}
+
+// parseXrandrMonitors parses `xrandr --listactivemonitors`, whose monitor
+// lines look like " 0: +*eDP-1 1920/344x1080/193+0+0  eDP-1" (* marks the
+// primary one).
+func parseXrandrMonitors(out []byte) []Display {
+	var displays []Display
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 3 || !strings.HasSuffix(fields[0], ":") {
+			continue
+		}
+		d := Display{Name: strings.TrimLeft(fields[1], "+*"), Main: strings.Contains(fields[1], "*")}
+		var wmm, hmm int
+		if _, err := fmt.Sscanf(fields[2], "%d/%dx%d/%d+%d+%d", &d.Width, &wmm, &d.Height, &hmm, &d.X, &d.Y); err != nil {
+			continue
+		}
+		d.Number = len(displays) + 1
+		displays = append(displays, d)
+	}
+	return displays
+}
+
+// parseWlrRandr parses wlr-randr's output: an unindented line per output
+// starting with its name, followed by indented properties including the
+// modes (the current one marked) and position.
+func parseWlrRandr(out []byte) []Display {
+	var displays []Display
+	var cur *Display
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		line := sc.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+		case !strings.HasPrefix(line, " "):
+			displays = append(displays, Display{Number: len(displays) + 1, Name: strings.Fields(line)[0]})
+			cur = &displays[len(displays)-1]
+		case cur == nil:
+		case strings.Contains(trimmed, "current"):
+			fmt.Sscanf(trimmed, "%dx%d", &cur.Width, &cur.Height)
+		case strings.HasPrefix(trimmed, "Position:"):
+			fmt.Sscanf(strings.TrimPrefix(trimmed, "Position:"), " %d,%d", &cur.X, &cur.Y)
+		}
+	}
+	if len(displays) > 0 {
+		displays[0].Main = true
+	}
+	return displays
+}
+
+// cropPNG cuts r out of a PNG image.
+func cropPNG(data []byte, r image.Rectangle) ([]byte, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("reading screenshot: %w", err)
+	}
+	sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	})
+	if !ok {
+		return nil, fmt.Errorf("cannot crop screenshot")
+	}
+	r = r.Intersect(img.Bounds())
+	if r.Empty() {
+		return nil, fmt.Errorf("display is outside the captured screen")
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, sub.SubImage(r)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
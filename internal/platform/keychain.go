@@ -0,0 +1,9 @@
+package platform
+
+import "errors"
+
+// keychainService is the service name nikte's secrets are stored under.
+const keychainService = "nikte"
+
+// ErrKeychainUnavailable is returned when the OS has no usable secret store.
+var ErrKeychainUnavailable = errors.New("no OS keychain available")
@@ -0,0 +1,32 @@
+package platform
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// KeychainGet reads a secret from the login keychain.
+func KeychainGet(account string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password",
+		"-s", keychainService, "-a", account, "-w").Output()
+	if err != nil {
+		return "", fmt.Errorf("keychain item %q not found: %w", account, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// KeychainSet stores a secret in the login keychain, replacing any existing one.
+func KeychainSet(account, secret string) error {
+	if err := exec.Command("security", "add-generic-password", "-U",
+		"-s", keychainService, "-a", account, "-w", secret).Run(); err != nil {
+		return fmt.Errorf("failed to store keychain item: %w", err)
+	}
+	return nil
+}
+
+// KeychainDelete removes a secret from the login keychain.
+func KeychainDelete(account string) error {
+	return exec.Command("security", "delete-generic-password",
+		"-s", keychainService, "-a", account).Run()
+}
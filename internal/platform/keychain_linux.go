@@ -0,0 +1,41 @@
+package platform
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// KeychainGet reads a secret from the Secret Service (GNOME Keyring, KWallet)
+// via secret-tool.
+func KeychainGet(account string) (string, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return "", fmt.Errorf("%w: install secret-tool (libsecret)", ErrKeychainUnavailable)
+	}
+	out, err := exec.Command("secret-tool", "lookup",
+		"service", keychainService, "account", account).Output()
+	if err != nil || len(out) == 0 {
+		return "", fmt.Errorf("keychain item %q not found", account)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// KeychainSet stores a secret in the Secret Service, replacing any existing one.
+func KeychainSet(account, secret string) error {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return fmt.Errorf("%w: install secret-tool (libsecret)", ErrKeychainUnavailable)
+	}
+	cmd := exec.Command("secret-tool", "store", "--label", keychainService+" "+account,
+		"service", keychainService, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to store keychain item: %v %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// KeychainDelete removes a secret from the Secret Service.
+func KeychainDelete(account string) error {
+	return exec.Command("secret-tool", "clear",
+		"service", keychainService, "account", account).Run()
+}
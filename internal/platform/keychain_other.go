@@ -0,0 +1,18 @@
+//go:build !darwin && !linux && !windows
+
+package platform
+
+// KeychainGet reads a secret (not supported on this platform)
+func KeychainGet(account string) (string, error) {
+	return "", ErrKeychainUnavailable
+}
+
+// KeychainSet stores a secret (not supported on this platform)
+func KeychainSet(account, secret string) error {
+	return ErrKeychainUnavailable
+}
+
+// KeychainDelete removes a secret (not supported on this platform)
+func KeychainDelete(account string) error {
+	return ErrKeychainUnavailable
+}
@@ -0,0 +1,82 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Windows has no keychain CLI, so secrets are sealed with DPAPI (bound to the
+// user's login) and kept in %APPDATA%\nikte.
+func keychainPath(account string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, keychainService, account+".dpapi"), nil
+}
+
+// KeychainGet reads a DPAPI-protected secret.
+func KeychainGet(account string) (string, error) {
+	path, err := keychainPath(account)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("keychain item %q not found: %w", account, err)
+	}
+	plain, err := dpapi(data, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to unprotect keychain item: %w", err)
+	}
+	return string(plain), nil
+}
+
+// KeychainSet stores a DPAPI-protected secret, replacing any existing one.
+func KeychainSet(account, secret string) error {
+	path, err := keychainPath(account)
+	if err != nil {
+		return err
+	}
+	sealed, err := dpapi([]byte(secret), true)
+	if err != nil {
+		return fmt.Errorf("failed to protect keychain item: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, sealed, 0600)
+}
+
+// KeychainDelete removes a DPAPI-protected secret.
+func KeychainDelete(account string) error {
+	path, err := keychainPath(account)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// dpapi protects or unprotects data for the current user.
+func dpapi(data []byte, protect bool) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty data")
+	}
+	in := windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+	var out windows.DataBlob
+	var err error
+	if protect {
+		err = windows.CryptProtectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out)
+	} else {
+		err = windows.CryptUnprotectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...), nil
+}
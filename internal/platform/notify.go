@@ -0,0 +1,29 @@
+package platform
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+)
+
+// ErrNotifyUnsupported is returned by Notify when there is no way to show a
+// desktop notification here.
+var ErrNotifyUnsupported = errors.New("desktop notifications are not supported on this system")
+
+// notifyScript shows a notification with the title and message given as
+// arguments, so neither needs quoting.
+const notifyScript = `on run argv
+	display notification (item 2 of argv) with title (item 1 of argv)
+end run`
+
+// Notify shows a desktop notification: through osascript on macOS and
+// notify-send (libnotify) elsewhere.
+func Notify(title, message string) error {
+	switch {
+	case runtime.GOOS == "darwin":
+		return exec.Command("osascript", "-e", notifyScript, title, message).Run()
+	case hasCommands("notify-send"):
+		return exec.Command("notify-send", "--app-name=nikte", title, message).Run()
+	}
+	return ErrNotifyUnsupported
+}
@@ -0,0 +1,201 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrScreenshotUnsupported is returned when no screenshot backend is
+// available.
+var ErrScreenshotUnsupported = errors.New("screenshot capture needs macOS, or on Linux one of grim and slurp (Wayland), gnome-screenshot, spectacle, maim or scrot")
+
+// CaptureCapabilities is what a Capturer can do beyond capturing a region
+// the user selects.
+type CaptureCapabilities struct {
+	Window     bool `json:"window"`     // capture a window the user clicks
+	Fullscreen bool `json:"fullscreen"` // capture every display at once
+	Displays   bool `json:"displays"`   // capture one display by itself
+	Windows    bool `json:"windows"`    // capture a window by ID, unobscured
+	Regions    bool `json:"regions"`    // capture an area given by coordinates
+}
+
+// Capturer is a screenshot backend, usually a screenshot program.
+type Capturer interface {
+	Name() string
+	// Available reports whether the backend works here: on this OS and
+	// desktop session, with its programs installed.
+	Available() bool
+	Capabilities() CaptureCapabilities
+	// Capture captures a region the user selects, the window they click
+	// (window) or all displays (fullscreen). It returns nil data if the user
+	// cancelled.
+	Capture(window, fullscreen bool) ([]byte, error)
+}
+
+// DisplayCapturer is a Capturer that captures single displays itself.
+// Otherwise a full-screen capture is cropped to the display.
+type DisplayCapturer interface {
+	Capturer
+	CaptureDisplay(d Display) ([]byte, error)
+}
+
+// WindowCapturer is a Capturer that captures listed windows itself.
+// Otherwise the window's area is captured, including anything covering it.
+type WindowCapturer interface {
+	Capturer
+	CaptureWindow(w Window) ([]byte, error)
+}
+
+// RegionCapturer is a Capturer that captures an area given by coordinates
+// itself. Otherwise a full-screen capture is cropped to it.
+type RegionCapturer interface {
+	Capturer
+	CaptureRegion(r image.Rectangle) ([]byte, error)
+}
+
+// capturers are the screenshot backends in order of preference; the first
+// available one is used. New backends are added here.
+var capturers = []Capturer{
+	screencaptureBackend{},
+	grimBackend{},
+	gnomeScreenshotBackend,
+	spectacleBackend,
+	maimBackend,
+	scrotBackend,
+}
+
+// capturerOverride replaces the detected screenshot backend; see
+// SetCapturer.
+var capturerOverride Capturer
+
+// SetCapturer makes nk use c instead of the screenshot backend it detects,
+// e.g. one returning a fixed image in tests. Passing nil restores detection.
+// It returns the previous override, for putting it back.
+func SetCapturer(c Capturer) (previous Capturer) {
+	previous, capturerOverride = capturerOverride, c
+	return previous
+}
+
+// SelectedCapturer returns the screenshot backend in use, if any.
+func SelectedCapturer() (Capturer, bool) {
+	if capturerOverride != nil {
+		return capturerOverride, true
+	}
+	for _, c := range capturers {
+		if c.Available() {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// IsScreenshotSupported reports whether a screenshot backend is available.
+func IsScreenshotSupported() bool {
+	_, ok := SelectedCapturer()
+	return ok
+}
+
+// CaptureScreenshot captures a region the user selects, the window they
+// click or the full screen with the selected backend. It returns nil data if
+// the user cancelled.
+func CaptureScreenshot(window, fullscreen bool) ([]byte, error) {
+	c, ok := SelectedCapturer()
+	if !ok {
+		return nil, ErrScreenshotUnsupported
+	}
+	return c.Capture(window, fullscreen)
+}
+
+// CaptureDisplay captures the whole of display n (see ListDisplays).
+func CaptureDisplay(n int) ([]byte, error) {
+	c, ok := SelectedCapturer()
+	if !ok {
+		return nil, ErrScreenshotUnsupported
+	}
+	displays, err := ListDisplays()
+	if err != nil {
+		return nil, err
+	}
+	d, err := findDisplay(displays, n)
+	if err != nil {
+		return nil, err
+	}
+	if dc, ok := c.(DisplayCapturer); ok {
+		return dc.CaptureDisplay(d)
+	}
+	if !canCrop() {
+		return nil, fmt.Errorf("capturing one display on Wayland needs grim")
+	}
+	return captureCropped(c, image.Rect(d.X, d.Y, d.X+d.Width, d.Y+d.Height))
+}
+
+// CaptureWindow captures w (see ListWindows) without the user clicking it.
+func CaptureWindow(w Window) ([]byte, error) {
+	c, ok := SelectedCapturer()
+	if !ok {
+		return nil, ErrScreenshotUnsupported
+	}
+	if wc, ok := c.(WindowCapturer); ok {
+		return wc.CaptureWindow(w)
+	}
+	return captureRegion(c, image.Rect(w.X, w.Y, w.X+w.Width, w.Y+w.Height))
+}
+
+// CaptureRegion captures the area of the screen at x,y of the given size,
+// in screen coordinates.
+func CaptureRegion(x, y, width, height int) ([]byte, error) {
+	c, ok := SelectedCapturer()
+	if !ok {
+		return nil, ErrScreenshotUnsupported
+	}
+	return captureRegion(c, image.Rect(x, y, x+width, y+height))
+}
+
+func captureRegion(c Capturer, r image.Rectangle) ([]byte, error) {
+	if rc, ok := c.(RegionCapturer); ok {
+		return rc.CaptureRegion(r)
+	}
+	if !canCrop() {
+		return nil, fmt.Errorf("capturing part of the screen on Wayland needs grim")
+	}
+	return captureCropped(c, r)
+}
+
+// ScreenshotCapabilities returns the selected backend and what it can
+// capture, counting displays and windows cropped from full-screen captures.
+func ScreenshotCapabilities() (Capturer, CaptureCapabilities, bool) {
+	c, ok := SelectedCapturer()
+	if !ok {
+		return nil, CaptureCapabilities{}, false
+	}
+	caps := c.Capabilities()
+	if caps.Fullscreen && canCrop() {
+		caps.Displays, caps.Windows, caps.Regions = true, true, true
+	}
+	return c, caps, true
+}
+
+// canCrop reports whether full-screen captures can be cropped to a display
+// or window. They can't on Wayland, where they are scaled per output.
+func canCrop() bool {
+	return os.Getenv("WAYLAND_DISPLAY") == ""
+}
+
+// captureCropped captures the full screen with c and crops it to r.
+func captureCropped(c Capturer, r image.Rectangle) ([]byte, error) {
+	full, err := c.Capture(false, true)
+	if err != nil || full == nil {
+		return full, err
+	}
+	return cropPNG(full, r)
+}
+
+// tempScreenshotFile returns a path for a backend to write a capture to.
+// The caller removes it.
+func tempScreenshotFile() string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("nk-screenshot-%d.png", time.Now().UnixNano()))
+}
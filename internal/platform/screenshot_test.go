@@ -0,0 +1,170 @@
+package platform
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// fakeCapturer captures a fixed image, recording what it was asked for.
+type fakeCapturer struct {
+	name      string
+	available bool
+	img       image.Image
+	calls     []string
+}
+
+func (f *fakeCapturer) Name() string    { return f.name }
+func (f *fakeCapturer) Available() bool { return f.available }
+
+func (f *fakeCapturer) Capabilities() CaptureCapabilities {
+	return CaptureCapabilities{Window: true, Fullscreen: true}
+}
+
+func (f *fakeCapturer) Capture(window, fullscreen bool) ([]byte, error) {
+	switch {
+	case window:
+		f.calls = append(f.calls, "window")
+	case fullscreen:
+		f.calls = append(f.calls, "fullscreen")
+	default:
+		f.calls = append(f.calls, "region")
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, f.img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fakeWindowCapturer also captures windows itself.
+type fakeWindowCapturer struct {
+	fakeCapturer
+	captured []Window
+}
+
+func (f *fakeWindowCapturer) CaptureWindow(w Window) ([]byte, error) {
+	f.captured = append(f.captured, w)
+	return []byte("window"), nil
+}
+
+// useCapturers replaces the screenshot backends for the test.
+func useCapturers(t *testing.T, cs ...Capturer) {
+	t.Helper()
+	saved := capturers
+	capturers = cs
+	t.Cleanup(func() { capturers = saved })
+}
+
+// twoTone is 300x100: red in the left 100 pixels, blue in the rest.
+func twoTone() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 300; x++ {
+			c := color.RGBA{B: 255, A: 255}
+			if x < 100 {
+				c = color.RGBA{R: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestSelectedCapturerPicksFirstAvailable(t *testing.T) {
+	first := &fakeCapturer{name: "first"}
+	second := &fakeCapturer{name: "second", available: true}
+	third := &fakeCapturer{name: "third", available: true}
+	useCapturers(t, first, second, third)
+
+	c, ok := SelectedCapturer()
+	if !ok || c.Name() != "second" {
+		t.Fatalf("SelectedCapturer = %v, %v; want second", c, ok)
+	}
+
+	useCapturers(t, first)
+	if IsScreenshotSupported() {
+		t.Error("IsScreenshotSupported with no available backend")
+	}
+	if _, err := CaptureScreenshot(false, false); err != ErrScreenshotUnsupported {
+		t.Errorf("CaptureScreenshot error = %v, want ErrScreenshotUnsupported", err)
+	}
+}
+
+func TestCaptureWindowCropsFullScreen(t *testing.T) {
+	t.Setenv("WAYLAND_DISPLAY", "")
+	fake := &fakeCapturer{name: "fake", available: true, img: twoTone()}
+	useCapturers(t, fake)
+
+	data, err := CaptureWindow(Window{X: 100, Width: 200, Height: 50})
+	if err != nil {
+		t.Fatalf("CaptureWindow: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 200 || b.Dy() != 50 {
+		t.Errorf("cropped to %v, want 200x50", b)
+	}
+	if r, _, b, _ := img.At(img.Bounds().Min.X, img.Bounds().Min.Y).RGBA(); r != 0 || b == 0 {
+		t.Error("crop starts in the red area, want blue")
+	}
+	if len(fake.calls) != 1 || fake.calls[0] != "fullscreen" {
+		t.Errorf("captures = %v, want one fullscreen", fake.calls)
+	}
+
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	if _, err := CaptureWindow(Window{Width: 10, Height: 10}); err == nil {
+		t.Error("cropping on Wayland should fail")
+	}
+}
+
+func TestCaptureWindowPrefersWindowCapturer(t *testing.T) {
+	fake := &fakeWindowCapturer{fakeCapturer: fakeCapturer{name: "fake", available: true}}
+	useCapturers(t, fake)
+
+	w := Window{ID: "42", App: "Editor"}
+	data, err := CaptureWindow(w)
+	if err != nil || string(data) != "window" {
+		t.Fatalf("CaptureWindow = %q, %v", data, err)
+	}
+	if len(fake.captured) != 1 || fake.captured[0].ID != "42" || len(fake.calls) != 0 {
+		t.Errorf("captured %v with captures %v, want window 42 only", fake.captured, fake.calls)
+	}
+}
+
+func TestParseXrandrMonitors(t *testing.T) {
+	out := "Monitors: 2\n" +
+		" 0: +*eDP-1 1920/344x1080/193+0+0  eDP-1\n" +
+		" 1: +HDMI-1 2560/597x1440/336+1920+0  HDMI-1\n"
+	got := parseXrandrMonitors([]byte(out))
+	want := []Display{
+		{Number: 1, Name: "eDP-1", Width: 1920, Height: 1080, Main: true},
+		{Number: 2, Name: "HDMI-1", Width: 2560, Height: 1440, X: 1920},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("display %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseWmctrl(t *testing.T) {
+	out := "0x01000003 -1 0 0 1920 24 xfce4-panel.Xfce4-panel  host xfce4-panel\n" +
+		"0x04000007  0 10 20 800 600 Navigator.firefox  host Mozilla Firefox - Docs\n"
+	got := parseWmctrl([]byte(out))
+	if len(got) != 1 {
+		t.Fatalf("got %v, want the firefox window only", got)
+	}
+	want := Window{Number: 1, ID: "0x04000007", App: "firefox", Title: "Mozilla Firefox - Docs",
+		X: 10, Y: 20, Width: 800, Height: 600}
+	if got[0] != want {
+		t.Errorf("got %+v, want %+v", got[0], want)
+	}
+}
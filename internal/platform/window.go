@@ -0,0 +1,175 @@
+package platform
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Window is an open application window that can be captured with
+// CaptureWindow.
+type Window struct {
+	Number int    `json:"number"` // 1-based, in the order listed
+	ID     string `json:"id"`     // the window system's ID for it
+	App    string `json:"app"`
+	Title  string `json:"title"`
+	X      int    `json:"-"` // position and size, where captures are cropped
+	Y      int    `json:"-"`
+	Width  int    `json:"-"`
+	Height int    `json:"-"`
+}
+
+func (w Window) String() string {
+	if w.Title == "" {
+		return fmt.Sprintf("%d: %s", w.Number, w.App)
+	}
+	return fmt.Sprintf("%d: %s - %s", w.Number, w.App, w.Title)
+}
+
+// numberWindows sets the Number of each window by its position.
+func numberWindows(windows []Window) []Window {
+	for i := range windows {
+		windows[i].Number = i + 1
+	}
+	return windows
+}
+
+// ListWindows returns the open application windows: on macOS frontmost
+// first, on Linux from wmctrl under X11 or swaymsg under Sway. Other Wayland
+// compositors don't let programs list windows.
+func ListWindows() ([]Window, error) {
+	switch {
+	case runtime.GOOS == "darwin":
+		return listMacWindows()
+	case runtime.GOOS != "linux":
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		out, err := exec.Command("swaymsg", "-t", "get_tree").Output()
+		if err != nil {
+			return nil, fmt.Errorf("listing windows on Wayland needs Sway (swaymsg): %w", err)
+		}
+		return parseSwayTree(out)
+	case os.Getenv("DISPLAY") != "":
+		out, err := exec.Command("wmctrl", "-l", "-G", "-x").Output()
+		if err != nil {
+			return nil, fmt.Errorf("listing windows needs wmctrl: %w", err)
+		}
+		return parseWmctrl(out), nil
+	}
+	return nil, ErrScreenshotUnsupported
+}
+
+// listWindowsScript prints the on-screen application windows (layer 0) as
+// JSON, front to back, using CoreGraphics through JavaScript for Automation.
+const listWindowsScript = `ObjC.import('CoreGraphics');
+const list = ObjC.castRefToObject($.CGWindowListCopyWindowInfo(
+	$.kCGWindowListOptionOnScreenOnly | $.kCGWindowListExcludeDesktopElements, $.kCGNullWindowID));
+const out = [];
+for (let i = 0; i < list.count; i++) {
+	const w = list.objectAtIndex(i);
+	if (ObjC.unwrap(w.objectForKey('kCGWindowLayer')) !== 0) continue;
+	out.push({
+		id: String(ObjC.unwrap(w.objectForKey('kCGWindowNumber'))),
+		app: ObjC.unwrap(w.objectForKey('kCGWindowOwnerName')) || '',
+		title: ObjC.unwrap(w.objectForKey('kCGWindowName')) || '',
+	});
+}
+JSON.stringify(out);`
+
+// listMacWindows lists the windows through CoreGraphics. Titles are only
+// visible once the terminal has the Screen Recording permission.
+func listMacWindows() ([]Window, error) {
+	out, err := exec.Command("osascript", "-l", "JavaScript", "-e", listWindowsScript).Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing windows: %w", err)
+	}
+	var windows []Window
+	if err := json.Unmarshal(out, &windows); err != nil {
+		return nil, fmt.Errorf("listing windows: %w", err)
+	}
+	return numberWindows(windows), nil
+}
+
+// parseWmctrl parses `wmctrl -l -G -x`, whose lines look like
+// "0x04000007  0 0 24 1920 1056 Navigator.firefox  host Title words".
+// Sticky windows (desktop -1), such as panels, are left out.
+func parseWmctrl(out []byte) []Window {
+	var windows []Window
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 8 || fields[1] == "-1" {
+			continue
+		}
+		var geom [4]int
+		var err error
+		for i := range geom {
+			if geom[i], err = strconv.Atoi(fields[2+i]); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			continue
+		}
+		app := fields[6]
+		if i := strings.LastIndex(app, "."); i >= 0 {
+			app = app[i+1:]
+		}
+		windows = append(windows, Window{
+			ID: fields[0], App: app, Title: strings.Join(fields[8:], " "),
+			X: geom[0], Y: geom[1], Width: geom[2], Height: geom[3],
+		})
+	}
+	return numberWindows(windows)
+}
+
+// swayNode is the part of a node in Sway's layout tree needed to find the
+// visible windows.
+type swayNode struct {
+	ID               int64  `json:"id"`
+	Name             string `json:"name"`
+	PID              int    `json:"pid"`
+	AppID            string `json:"app_id"`
+	Visible          bool   `json:"visible"`
+	Rect             struct{ X, Y, Width, Height int }
+	WindowProperties struct {
+		Class string `json:"class"`
+	} `json:"window_properties"`
+	Nodes         []swayNode `json:"nodes"`
+	FloatingNodes []swayNode `json:"floating_nodes"`
+}
+
+// parseSwayTree collects the visible windows from `swaymsg -t get_tree`.
+func parseSwayTree(out []byte) ([]Window, error) {
+	var root swayNode
+	if err := json.Unmarshal(out, &root); err != nil {
+		return nil, fmt.Errorf("listing windows: %w", err)
+	}
+	var windows []Window
+	var walk func(n swayNode)
+	walk = func(n swayNode) {
+		if n.PID > 0 && n.Visible {
+			app := n.AppID
+			if app == "" {
+				app = n.WindowProperties.Class
+			}
+			windows = append(windows, Window{
+				ID: strconv.FormatInt(n.ID, 10), App: app, Title: n.Name,
+				X: n.Rect.X, Y: n.Rect.Y, Width: n.Rect.Width, Height: n.Rect.Height,
+			})
+		}
+		for _, c := range n.Nodes {
+			walk(c)
+		}
+		for _, c := range n.FloatingNodes {
+			walk(c)
+		}
+	}
+	walk(root)
+	return numberWindows(windows), nil
+}
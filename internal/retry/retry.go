@@ -0,0 +1,76 @@
+// Package retry holds the backoff policy shared by API requests and uploads.
+package retry
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MaxDelay caps a single backoff wait, however many attempts have failed.
+const MaxDelay = 30 * time.Second
+
+// MaxRetryAfter is the longest Retry-After a request will wait out on its
+// own. A server asking for more is treated as a final answer.
+const MaxRetryAfter = 60 * time.Second
+
+// Backoff returns the wait before retry number attempt (0 for the first
+// retry): base doubled per attempt, capped at MaxDelay, with the upper half
+// jittered so parallel clients don't retry in lockstep.
+func Backoff(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	d := base
+	for i := 0; i < attempt && d < MaxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, MaxDelay)
+	return d/2 + rand.N(d/2+1)
+}
+
+// RetryAfter parses a Retry-After header, given either in seconds or as an
+// HTTP date. It reports false if the header is missing or malformed.
+func RetryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// Delay picks the wait before retrying resp: the server's Retry-After when it
+// sent one, otherwise Backoff. ok is false when Retry-After exceeds
+// MaxRetryAfter, meaning the caller should give up rather than wait.
+func Delay(resp *http.Response, base time.Duration, attempt int) (d time.Duration, ok bool) {
+	if resp != nil {
+		if after, found := RetryAfter(resp.Header); found {
+			return after, after <= MaxRetryAfter
+		}
+	}
+	return Backoff(base, attempt), true
+}
+
+// Sleep waits for d, returning early with ctx's error if it is cancelled
+// first.
+func Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
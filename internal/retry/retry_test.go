@@ -0,0 +1,99 @@
+package retry
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		base    time.Duration
+		attempt int
+		max     time.Duration // the un-jittered delay; results fall in [max/2, max]
+	}{
+		{"first retry", time.Second, 0, time.Second},
+		{"doubles", time.Second, 1, 2 * time.Second},
+		{"doubles again", time.Second, 3, 8 * time.Second},
+		{"capped", time.Second, 5, MaxDelay},
+		{"capped far out", 2 * time.Second, 100, MaxDelay},
+		{"base above the cap", time.Minute, 0, MaxDelay},
+		{"zero base", 0, 3, 0},
+		{"negative base", -time.Second, 0, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 200 {
+				d := Backoff(tt.base, tt.attempt)
+				if d < tt.max/2 || d > tt.max {
+					t.Fatalf("Backoff(%v, %d) = %v, want between %v and %v", tt.base, tt.attempt, d, tt.max/2, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"missing", "", 0, false},
+		{"seconds", "5", 5 * time.Second, true},
+		{"zero seconds", "0", 0, true},
+		{"negative seconds", "-1", 0, false},
+		{"fractional seconds", "1.5", 0, false},
+		{"garbage", "soon", 0, false},
+		{"date in the past", "Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			if tt.header != "" {
+				h.Set("Retry-After", tt.header)
+			}
+			got, ok := RetryAfter(h)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("RetryAfter(%q) = %v, %v, want %v, %v", tt.header, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	t.Run("date in the future", func(t *testing.T) {
+		h := http.Header{}
+		h.Set("Retry-After", time.Now().Add(20*time.Second).UTC().Format(http.TimeFormat))
+		// HTTP dates have whole seconds, so allow for the truncation.
+		if got, ok := RetryAfter(h); !ok || got < 18*time.Second || got > 20*time.Second {
+			t.Errorf("RetryAfter(%q) = %v, %v, want about 20s", h.Get("Retry-After"), got, ok)
+		}
+	})
+}
+
+func TestDelay(t *testing.T) {
+	withRetryAfter := func(v string) *http.Response {
+		resp := &http.Response{Header: http.Header{}}
+		resp.Header.Set("Retry-After", v)
+		return resp
+	}
+	for _, tt := range []struct {
+		name     string
+		resp     *http.Response
+		min, max time.Duration
+		ok       bool
+	}{
+		{"no response", nil, time.Second, 2 * time.Second, true},
+		{"no Retry-After", &http.Response{Header: http.Header{}}, time.Second, 2 * time.Second, true},
+		{"Retry-After wins", withRetryAfter("10"), 10 * time.Second, 10 * time.Second, true},
+		{"at the limit", withRetryAfter("60"), MaxRetryAfter, MaxRetryAfter, true},
+		{"over the limit", withRetryAfter("61"), 61 * time.Second, 61 * time.Second, false},
+		{"malformed Retry-After", withRetryAfter("later"), time.Second, 2 * time.Second, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			d, ok := Delay(tt.resp, time.Second, 1)
+			if d < tt.min || d > tt.max || ok != tt.ok {
+				t.Errorf("Delay = %v, %v, want %v to %v, %v", d, ok, tt.min, tt.max, tt.ok)
+			}
+		})
+	}
+}
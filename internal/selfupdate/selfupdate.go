@@ -0,0 +1,340 @@
+// Package selfupdate finds the latest GitHub release of the CLI, downloads
+// the archive for this OS and architecture, checks it against the release's
+// checksums.txt and replaces the running executable with the binary inside.
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/transport"
+)
+
+// Repo is the GitHub repository releases are published to.
+const Repo = "sim4gh/nikte-cli"
+
+// projectName is the GoReleaser project name archives are named after.
+const projectName = "nikte-cli"
+
+// checksumsName is the release asset listing the SHA-256 of every archive.
+const checksumsName = "checksums.txt"
+
+// APIBase is the GitHub API root; tests point it at a local server.
+var APIBase = "https://api.github.com"
+
+// maxDownload caps how much of an archive is read, well above any release.
+const maxDownload = 200 << 20
+
+// Release is a published GitHub release.
+type Release struct {
+	Tag    string  `json:"tag_name"`
+	URL    string  `json:"html_url"`
+	Assets []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// Version returns the release's version without the leading "v".
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.Tag, "v")
+}
+
+// Latest fetches the newest published (non-draft, non-prerelease) release.
+func Latest(ctx context.Context) (*Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", strings.TrimSuffix(APIBase, "/"), Repo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := transport.Client(30 * time.Second).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	defer transport.DrainAndClose(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to check for updates: GitHub returned %s", resp.Status)
+	}
+	var rel Release
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return nil, fmt.Errorf("failed to read release: %w", err)
+	}
+	if rel.Tag == "" {
+		return nil, fmt.Errorf("failed to read release: no tag")
+	}
+	return &rel, nil
+}
+
+// Newer reports whether version latest is newer than current. Versions are
+// compared numerically by major.minor.patch; a current version that isn't
+// one (like "dev") is never considered out of date.
+func Newer(current, latest string) bool {
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	for i := range c {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
+
+// parseVersion splits "v1.2.3" (or "1.2.3-rc1", "1.2") into numbers.
+func parseVersion(v string) ([3]int, bool) {
+	var out [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return out, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
+
+// ArchiveName is the release archive GoReleaser builds for goos/goarch.
+func ArchiveName(version, goos, goarch string) string {
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("%s_%s_%s_%s%s", projectName, version, goos, goarch, ext)
+}
+
+// binaryName is the executable inside the archive for goos.
+func binaryName(goos string) string {
+	if goos == "windows" {
+		return "nk.exe"
+	}
+	return "nk"
+}
+
+// asset returns the release asset called name.
+func (r *Release) asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Download fetches the archive for this OS and architecture, verifies its
+// SHA-256 against the release's checksums.txt, and returns the binary inside.
+func (r *Release) Download(ctx context.Context) ([]byte, error) {
+	name := ArchiveName(r.Version(), runtime.GOOS, runtime.GOARCH)
+	archive, ok := r.asset(name)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no build for %s/%s", r.Tag, runtime.GOOS, runtime.GOARCH)
+	}
+	sums, ok := r.asset(checksumsName)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s; not installing an unverified binary", r.Tag, checksumsName)
+	}
+
+	sumData, err := fetch(ctx, sums.URL)
+	if err != nil {
+		return nil, err
+	}
+	want, err := checksumFor(sumData, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := fetch(ctx, archive.URL)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyChecksum(data, want); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return extractBinary(data, name, binaryName(runtime.GOOS))
+}
+
+// fetch downloads url into memory.
+func fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := transport.Client(0).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	defer transport.DrainAndClose(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed: %s returned %s", filepath.Base(url), resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownload+1))
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	if len(data) > maxDownload {
+		return nil, fmt.Errorf("download failed: %s is too large", filepath.Base(url))
+	}
+	return data, nil
+}
+
+// checksumFor finds name's hex SHA-256 in a checksums.txt ("<sum>  <name>"
+// per line, as written by sha256sum).
+func checksumFor(sums []byte, name string) (string, error) {
+	sc := bufio.NewScanner(bytes.NewReader(sums))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no checksum for %s", checksumsName, name)
+}
+
+// verifyChecksum checks data's SHA-256 against the hex digest want.
+func verifyChecksum(data []byte, want string) error {
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch (got %s, want %s)", got, want)
+	}
+	return nil
+}
+
+// extractBinary returns the file called bin from a tar.gz or zip archive.
+func extractBinary(data []byte, archiveName, bin string) ([]byte, error) {
+	if strings.HasSuffix(archiveName, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", archiveName, err)
+		}
+		for _, f := range zr.File {
+			if filepath.Base(f.Name) != bin || f.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(io.LimitReader(rc, maxDownload))
+		}
+		return nil, fmt.Errorf("%s has no %s", archiveName, bin)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", archiveName, err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s has no %s", archiveName, bin)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", archiveName, err)
+		}
+		if hdr.Typeflag == tar.TypeReg && filepath.Base(hdr.Name) == bin {
+			return io.ReadAll(io.LimitReader(tr, maxDownload))
+		}
+	}
+}
+
+// Executable returns the path of the running binary with symlinks resolved,
+// which is the file Replace swaps out.
+func Executable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exe)
+}
+
+// Replace atomically swaps the executable at exe for binary: the new file is
+// written next to it and renamed over it, so an interrupted upgrade leaves
+// the old binary in place. Windows won't replace a running executable, so
+// there it is first moved aside to exe+".old", removed on the next upgrade.
+func Replace(exe string, binary []byte) error {
+	dir := filepath.Dir(exe)
+	tmp, err := os.CreateTemp(dir, ".nk-upgrade-*")
+	if err != nil {
+		return fmt.Errorf("cannot write to %s: %w", dir, err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	mode := os.FileMode(0o755)
+	if fi, err := os.Stat(exe); err == nil {
+		mode = fi.Mode().Perm() | 0o111
+	}
+	if err := os.Chmod(tmpName, mode); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		old := exe + ".old"
+		os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return fmt.Errorf("failed to move aside %s: %w", exe, err)
+		}
+		if err := os.Rename(tmpName, exe); err != nil {
+			os.Rename(old, exe)
+			return fmt.Errorf("failed to replace %s: %w", exe, err)
+		}
+		return nil
+	}
+	if err := os.Rename(tmpName, exe); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+	return nil
+}
+
+// ManagedBy names the package manager that installed exe, if any, so the
+// caller can point at its upgrade command instead of overwriting its files.
+func ManagedBy(exe string) string {
+	slash := filepath.ToSlash(exe)
+	switch {
+	case strings.Contains(slash, "/Cellar/") || strings.Contains(slash, "/homebrew/") || strings.Contains(slash, "/linuxbrew/"):
+		return "homebrew"
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            bool
+	}{
+		{"0.6.0", "0.7.0", true},
+		{"0.6.0", "v0.6.1", true},
+		{"0.9.0", "0.10.0", true},
+		{"0.6.0", "0.6.0", false},
+		{"1.0.0", "0.9.9", false},
+		{"0.6.0-rc1", "0.6.0", false},
+		{"dev", "9.9.9", false},
+	}
+	for _, tt := range tests {
+		if got := Newer(tt.current, tt.latest); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
+
+func tarGz(t *testing.T, name string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{{"README.md", []byte("readme")}, {name, data}} {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0o755, Size: int64(len(f.data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(f.data)
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+// releaseServer serves a release with one archive for this platform; sum
+// overrides its checksum when set.
+func releaseServer(t *testing.T, archive []byte, sum string) *httptest.Server {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("release archives are zips on Windows")
+	}
+	name := ArchiveName("9.9.9", runtime.GOOS, runtime.GOARCH)
+	if sum == "" {
+		h := sha256.Sum256(archive)
+		sum = hex.EncodeToString(h[:])
+	}
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/" + Repo + "/releases/latest":
+			json.NewEncoder(w).Encode(Release{Tag: "v9.9.9", Assets: []Asset{
+				{Name: name, URL: srv.URL + "/dl/" + name},
+				{Name: checksumsName, URL: srv.URL + "/dl/" + checksumsName},
+			}})
+		case "/dl/" + name:
+			w.Write(archive)
+		case "/dl/" + checksumsName:
+			fmt.Fprintf(w, "%s  other_file.tar.gz\n%s  %s\n", sum, sum, name)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	old := APIBase
+	APIBase = srv.URL
+	t.Cleanup(func() { APIBase = old })
+	return srv
+}
+
+func TestDownloadAndReplace(t *testing.T) {
+	releaseServer(t, tarGz(t, "nk", []byte("new binary")), "")
+
+	rel, err := Latest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel.Version() != "9.9.9" {
+		t.Fatalf("version = %q", rel.Version())
+	}
+	binary, err := rel.Download(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(binary) != "new binary" {
+		t.Fatalf("binary = %q", binary)
+	}
+
+	exe := filepath.Join(t.TempDir(), "nk")
+	if err := os.WriteFile(exe, []byte("old binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Replace(exe, binary); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(exe)
+	if string(got) != "new binary" {
+		t.Errorf("after Replace = %q", got)
+	}
+	if fi, _ := os.Stat(exe); fi.Mode().Perm()&0o111 == 0 {
+		t.Errorf("mode = %v, want executable", fi.Mode())
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(exe)); len(entries) != 1 {
+		t.Errorf("left %d files behind", len(entries)-1)
+	}
+}
+
+func TestDownloadChecksumMismatch(t *testing.T) {
+	releaseServer(t, tarGz(t, "nk", []byte("tampered")), hex.EncodeToString(make([]byte, 32)))
+
+	rel, err := Latest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rel.Download(context.Background()); err == nil {
+		t.Fatal("Download accepted an archive with the wrong checksum")
+	}
+}
@@ -0,0 +1,97 @@
+// Package throttle caps transfer bandwidth with a token bucket shared by
+// every upload and download in the process, so parallel parts together stay
+// under the limit.
+package throttle
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/retry"
+)
+
+// chunk is the most a throttled reader passes on per Read, so transfers are
+// paced smoothly instead of in bursts of a whole buffer.
+const chunk = 32 << 10
+
+// Limiter is a token bucket refilled at rate bytes per second, holding at
+// most one second's worth.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// New returns a Limiter allowing bytesPerSec, or nil (no limit) if it is 0.
+func New(bytesPerSec int64) *Limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return &Limiter{rate: float64(bytesPerSec), last: time.Now()}
+}
+
+// WaitN blocks until n bytes may be sent, or ctx is done. Callers that
+// overdraw the bucket are delayed in turn, so concurrent transfers share the
+// rate fairly.
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.rate, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens -= float64(n)
+	debt := -l.tokens
+	l.mu.Unlock()
+	if debt <= 0 {
+		return nil
+	}
+	return retry.Sleep(ctx, time.Duration(debt/l.rate*float64(time.Second)))
+}
+
+var (
+	sharedMu sync.Mutex
+	shared   *Limiter
+)
+
+// SetRate sets the process-wide limit used by Reader; 0 removes it.
+func SetRate(bytesPerSec int64) {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	shared = New(bytesPerSec)
+}
+
+// Reader returns r paced by the process-wide limit, or r itself when there
+// is none. Reads return early with ctx's error once it is done.
+func Reader(ctx context.Context, r io.Reader) io.Reader {
+	sharedMu.Lock()
+	l := shared
+	sharedMu.Unlock()
+	if l == nil {
+		return r
+	}
+	return &reader{ctx: ctx, r: r, l: l}
+}
+
+type reader struct {
+	ctx context.Context
+	r   io.Reader
+	l   *Limiter
+}
+
+func (t *reader) Read(p []byte) (int, error) {
+	if len(p) > chunk {
+		p = p[:chunk]
+	}
+	n, err := t.r.Read(p)
+	if n > 0 {
+		if werr := t.l.WaitN(t.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,274 @@
+package transport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DebugEnvVars enable request logging like --debug when set to anything but
+// "", "0" or "false". OIO_DEBUG is kept for users of the Node.js CLI.
+var DebugEnvVars = []string{"NIKTE_DEBUG", "OIO_DEBUG"}
+
+// maxLoggedBody is the largest request or response body written to the debug
+// log; bigger ones (uploads, downloads) are summarised by size.
+const maxLoggedBody = 16 << 10
+
+// requestIDHeaders are the response headers that identify a request to the
+// API or to S3/CloudFront, in the order they are reported.
+var requestIDHeaders = []string{"X-Request-Id", "X-Amzn-Requestid", "X-Amz-Request-Id", "X-Amz-Cf-Id"}
+
+var (
+	// debugOverride is set from the global --debug flag.
+	debugOverride bool
+
+	// debugOut receives the log. Writes are serialised so parallel part
+	// uploads don't interleave.
+	debugOut io.Writer = os.Stderr
+	debugMu  sync.Mutex
+)
+
+// SetDebug turns request logging on. Like SetProxy it must be called before
+// the first request.
+func SetDebug(on bool) {
+	debugOverride = on
+}
+
+// DebugEnabled reports whether requests are logged, via --debug or one of
+// DebugEnvVars.
+func DebugEnabled() bool {
+	if debugOverride {
+		return true
+	}
+	for _, name := range DebugEnvVars {
+		switch strings.ToLower(os.Getenv(name)) {
+		case "", "0", "false":
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// debugTransport logs every request and response passing through next, with
+// credentials redacted.
+type debugTransport struct {
+	next http.RoundTripper
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--> %s %s\n", req.Method, redactURL(req.URL))
+	writeHeaders(&b, req.Header)
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.ContentLength >= 0 && req.ContentLength <= maxLoggedBody && loggableType(req.Header.Get("Content-Type")) {
+			data, err := io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = io.NopCloser(bytes.NewReader(data))
+			if plain, ok := decodeForLog(req.Header.Get("Content-Encoding"), data); ok {
+				writeBody(&b, req.Header.Get("Content-Type"), plain)
+			} else {
+				fmt.Fprintf(&b, "    [%s %s body not logged]\n", describeSize(req.ContentLength), req.Header.Get("Content-Encoding"))
+			}
+		} else {
+			fmt.Fprintf(&b, "    [%s body not logged]\n", describeSize(req.ContentLength))
+		}
+	}
+	debugWrite(b.String())
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	b.Reset()
+	if err != nil {
+		fmt.Fprintf(&b, "<-- %s %s: %v (%s)\n", req.Method, redactURL(req.URL), err, elapsed)
+		debugWrite(b.String())
+		return resp, err
+	}
+
+	fmt.Fprintf(&b, "<-- %s %s (%s)\n", resp.Status, redactURL(req.URL), elapsed)
+	for _, h := range requestIDHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			fmt.Fprintf(&b, "    request id: %s\n", v)
+			break
+		}
+	}
+	writeHeaders(&b, resp.Header)
+	if resp.ContentLength <= maxLoggedBody && loggableType(resp.Header.Get("Content-Type")) {
+		// Peek at most maxLoggedBody+1 bytes and put them back in front of
+		// the rest, so a large streamed body still reaches the caller intact.
+		data, readErr := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBody+1))
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+		switch {
+		case readErr != nil:
+			fmt.Fprintf(&b, "    [reading body: %v]\n", readErr)
+		case len(data) > maxLoggedBody:
+			fmt.Fprintf(&b, "    [%s body not logged]\n", describeSize(resp.ContentLength))
+		default:
+			writeBody(&b, resp.Header.Get("Content-Type"), data)
+		}
+	} else if resp.ContentLength != 0 {
+		fmt.Fprintf(&b, "    [%s body not logged]\n", describeSize(resp.ContentLength))
+	}
+	debugWrite(b.String())
+	return resp, nil
+}
+
+// decodeForLog undoes a gzip Content-Encoding so a compressed request body can
+// be logged as text. It fails for other encodings and for bodies that inflate
+// past maxLoggedBody.
+func decodeForLog(encoding string, data []byte) ([]byte, bool) {
+	switch strings.ToLower(encoding) {
+	case "", "identity":
+		return data, true
+	case "gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, false
+		}
+		plain, err := io.ReadAll(io.LimitReader(zr, maxLoggedBody+1))
+		if err != nil || len(plain) > maxLoggedBody {
+			return nil, false
+		}
+		return plain, true
+	}
+	return nil, false
+}
+
+func debugWrite(s string) {
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	fmt.Fprint(debugOut, s)
+}
+
+func describeSize(n int64) string {
+	if n < 0 {
+		return "streamed"
+	}
+	return fmt.Sprintf("%d-byte", n)
+}
+
+// loggableType reports whether a body of this content type is text worth
+// logging.
+func loggableType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json", mediaType == "application/x-www-form-urlencoded",
+		strings.HasPrefix(mediaType, "application/x-amz-json"), strings.HasPrefix(mediaType, "text/"):
+		return true
+	}
+	return false
+}
+
+// sensitiveName reports whether a header, field or query parameter carries a
+// credential.
+func sensitiveName(name string) bool {
+	n := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+	for _, s := range []string{"authorization", "cookie", "token", "password", "secret", "signature", "credential", "devicecode"} {
+		if strings.Contains(n, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL hides credential query parameters such as presigned-URL
+// signatures.
+func redactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.String()
+	}
+	q := u.Query()
+	for k := range q {
+		if sensitiveName(k) {
+			q[k] = []string{"REDACTED"}
+		}
+	}
+	c := *u
+	c.RawQuery = q.Encode()
+	return c.String()
+}
+
+func writeHeaders(b *strings.Builder, h http.Header) {
+	names := make([]string, 0, len(h))
+	for k := range h {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		v := strings.Join(h[k], ", ")
+		if sensitiveName(k) {
+			v = "REDACTED"
+		}
+		fmt.Fprintf(b, "    %s: %s\n", k, v)
+	}
+}
+
+// writeBody logs a text body, redacting credential fields in JSON and form
+// bodies.
+func writeBody(b *strings.Builder, contentType string, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		if form, err := url.ParseQuery(string(data)); err == nil {
+			for k := range form {
+				if sensitiveName(k) {
+					form[k] = []string{"REDACTED"}
+				}
+			}
+			data = []byte(form.Encode())
+		}
+	case mediaType == "application/json", strings.HasPrefix(mediaType, "application/x-amz-json"):
+		var v any
+		if err := json.Unmarshal(data, &v); err == nil {
+			if redacted, err := json.Marshal(redactJSON(v)); err == nil {
+				data = redacted
+			}
+		}
+	}
+	fmt.Fprintf(b, "    %s\n", data)
+}
+
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if sensitiveName(k) {
+				v[k] = "REDACTED"
+			} else {
+				v[k] = redactJSON(child)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = redactJSON(child)
+		}
+	case string:
+		// Presigned URLs carry their signature in the query string.
+		if u, err := url.Parse(v); err == nil && u.Scheme != "" && u.RawQuery != "" {
+			return redactURL(u)
+		}
+	}
+	return v
+}
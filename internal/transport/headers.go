@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// UserAgent is sent with every request. The CLI sets it to include its
+// version at startup.
+var UserAgent = "nikte-cli"
+
+// RequestIDHeader carries the ID generated for each request, so a failure
+// can be matched to the server's logs.
+const RequestIDHeader = "X-Request-ID"
+
+// headerTransport adds User-Agent and X-Request-ID to requests that don't
+// already have them.
+type headerTransport struct {
+	next http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	setUA := req.Header.Get("User-Agent") == ""
+	setID := req.Header.Get(RequestIDHeader) == ""
+	if setUA || setID {
+		req = req.Clone(req.Context())
+		if setUA {
+			req.Header.Set("User-Agent", UserAgent)
+		}
+		if setID {
+			req.Header.Set(RequestIDHeader, newRequestID())
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// newRequestID returns a random UUID (version 4).
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// RequestID returns the ID to quote for resp: the one the server assigned if
+// it sent one, otherwise the X-Request-ID we generated.
+func RequestID(resp *http.Response) string {
+	for _, h := range requestIDHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			return v
+		}
+	}
+	if resp.Request != nil {
+		return resp.Request.Header.Get(RequestIDHeader)
+	}
+	return ""
+}
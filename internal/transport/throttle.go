@@ -0,0 +1,34 @@
+package transport
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/sim4gh/nikte-cli/internal/throttle"
+)
+
+// throttleTransport paces request and response bodies with the bandwidth
+// limit set by limit_rate or --limit-rate.
+type throttleTransport struct {
+	next http.RoundTripper
+}
+
+// throttledBody is a body read through the limiter but closed directly.
+type throttledBody struct {
+	io.Reader
+	io.Closer
+}
+
+func (t *throttleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if req.Body != nil && req.Body != http.NoBody {
+		req = req.Clone(ctx)
+		req.Body = throttledBody{throttle.Reader(ctx, req.Body), req.Body}
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = throttledBody{throttle.Reader(ctx, resp.Body), resp.Body}
+	return resp, nil
+}
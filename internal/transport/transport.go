@@ -0,0 +1,161 @@
+// Package transport provides the HTTP transport shared by all network calls
+// (API, uploads, downloads and login), configured with the proxy settings.
+package transport
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"golang.org/x/net/http/httpproxy"
+)
+
+var (
+	// proxyOverride is set from the global --proxy flag and takes precedence
+	// over the config and environment.
+	proxyOverride string
+
+	// base replaces the network transport when set with SetTransport.
+	base http.RoundTripper
+
+	mu      sync.Mutex
+	shared  http.RoundTripper
+	storage http.RoundTripper
+)
+
+// SetProxy routes every request through proxyURL (http, https or socks5,
+// with optional credentials). It must be called before the first request.
+func SetProxy(proxyURL string) {
+	proxyOverride = proxyURL
+}
+
+// Connection pool limits for the shared transport. Parallel part uploads and
+// ranged downloads all go to one storage host, so keep enough idle
+// connections per host for them to be reused rather than redialled.
+const (
+	maxIdleConns        = 64
+	maxIdleConnsPerHost = 16
+	idleConnTimeout     = 90 * time.Second
+)
+
+// SetTransport sends every request through rt instead of the network, e.g.
+// an httptest server's transport in unit tests. Requests still get the
+// User-Agent and request ID headers, and are logged with --debug. Passing nil
+// restores the default.
+func SetTransport(rt http.RoundTripper) {
+	mu.Lock()
+	defer mu.Unlock()
+	base = rt
+	shared = nil
+	storage = nil
+}
+
+// Transport returns the shared transport. It is built on first use, so the
+// config has been loaded and SetProxy and SetDebug applied by then.
+func Transport() http.RoundTripper {
+	mu.Lock()
+	defer mu.Unlock()
+	if shared == nil {
+		shared = build(proxySettings())
+	}
+	return shared
+}
+
+// StorageTransport returns the transport for presigned storage URLs (part
+// uploads and downloads). It follows storage_proxy: unset shares Transport,
+// "direct" bypasses every proxy, and a URL uses that proxy instead, still
+// honouring no_proxy. --proxy overrides it like everything else.
+func StorageTransport() http.RoundTripper {
+	cfg := config.Get()
+	if proxyOverride != "" || cfg == nil || cfg.StorageProxy == "" {
+		return Transport()
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if storage == nil {
+		settings := &httpproxy.Config{NoProxy: proxySettings().NoProxy}
+		if cfg.StorageProxy != config.StorageProxyDirect {
+			settings.HTTPProxy = cfg.StorageProxy
+			settings.HTTPSProxy = cfg.StorageProxy
+		}
+		storage = build(settings)
+	}
+	return storage
+}
+
+// build returns a transport using the given proxies, wrapped with request
+// headers and, if enabled, debug logging. It uses base instead when set.
+func build(settings *httpproxy.Config) http.RoundTripper {
+	rt := base
+	if rt == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.Proxy = proxyFunc(settings)
+		t.ForceAttemptHTTP2 = true
+		t.MaxIdleConns = maxIdleConns
+		t.MaxIdleConnsPerHost = maxIdleConnsPerHost
+		t.IdleConnTimeout = idleConnTimeout
+		rt = t
+	}
+	if DebugEnabled() {
+		rt = &debugTransport{next: rt}
+	}
+	return &headerTransport{next: rt}
+}
+
+// Client returns an HTTP client using the shared transport. A zero timeout
+// means no timeout.
+func Client(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: Transport(), Timeout: timeout}
+}
+
+// StorageClient is Client for presigned storage URLs, using StorageTransport.
+// Its transfers are held to the limit_rate bandwidth cap.
+func StorageClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: &throttleTransport{next: StorageTransport()}, Timeout: timeout}
+}
+
+// DrainAndClose reads what is left of a response body we don't need (up to a
+// limit) and closes it, so its keep-alive connection can be reused.
+func DrainAndClose(body io.ReadCloser) {
+	_, _ = io.CopyN(io.Discard, body, 64<<10)
+	body.Close()
+}
+
+// proxySettings merges the proxy sources, lowest precedence first: the
+// HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment, socks5_proxy (for both
+// schemes), the scheme-specific http_proxy/https_proxy keys, then --proxy.
+func proxySettings() *httpproxy.Config {
+	settings := httpproxy.FromEnvironment()
+
+	if cfg := config.Get(); cfg != nil {
+		if cfg.SOCKS5Proxy != "" {
+			settings.HTTPProxy = cfg.SOCKS5Proxy
+			settings.HTTPSProxy = cfg.SOCKS5Proxy
+		}
+		if cfg.HTTPProxy != "" {
+			settings.HTTPProxy = cfg.HTTPProxy
+		}
+		if cfg.HTTPSProxy != "" {
+			settings.HTTPSProxy = cfg.HTTPSProxy
+		}
+		if cfg.NoProxy != "" {
+			settings.NoProxy = cfg.NoProxy
+		}
+	}
+
+	if proxyOverride != "" {
+		settings.HTTPProxy = proxyOverride
+		settings.HTTPSProxy = proxyOverride
+	}
+	return settings
+}
+
+func proxyFunc(settings *httpproxy.Config) func(*http.Request) (*url.URL, error) {
+	fn := settings.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return fn(req.URL)
+	}
+}
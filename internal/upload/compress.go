@@ -0,0 +1,94 @@
+package upload
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/sim4gh/nikte-cli/internal/util"
+)
+
+// Compression formats for CompressFile.
+const (
+	Gzip = "gzip"
+	Zstd = "zstd"
+)
+
+// Compression describes a format files can be compressed with before upload.
+type Compression struct {
+	Name        string // as sent in the init request's compression field
+	Extension   string // appended to the uploaded filename
+	ContentType string
+}
+
+var compressions = map[string]Compression{
+	Gzip: {Name: Gzip, Extension: ".gz", ContentType: "application/gzip"},
+	Zstd: {Name: Zstd, Extension: ".zst", ContentType: "application/zstd"},
+}
+
+// CompressionFor returns the Compression named name.
+func CompressionFor(name string) (Compression, bool) {
+	c, ok := compressions[name]
+	return c, ok
+}
+
+// CompressFile streams src, size bytes long, through the compressor for c
+// into a temporary file in os.TempDir, so it can be uploaded in parts like
+// any other file. Since compressed data can be as large as its input, it
+// fails up front unless size bytes are free there. The caller closes and
+// removes the returned file.
+func CompressFile(ctx context.Context, src io.Reader, size int64, c Compression) (*os.File, error) {
+	dir := os.TempDir()
+	if free, err := freeSpace(dir); err == nil && free < size {
+		return nil, fmt.Errorf("not enough disk space in %s for the compressed copy: it may need up to %s, %s is free (point TMPDIR, or TMP on Windows, at a larger disk)",
+			dir, util.FormatBytes(size), util.FormatBytes(free))
+	}
+	tmp, err := os.CreateTemp(dir, "nk-upload-*"+c.Extension)
+	if err != nil {
+		return nil, err
+	}
+	if err := compressTo(ctx, tmp, src, c); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("%s compression failed: %w", c.Name, err)
+	}
+	return tmp, nil
+}
+
+func compressTo(ctx context.Context, dst io.Writer, src io.Reader, c Compression) error {
+	var w io.WriteCloser
+	switch c.Name {
+	case Gzip:
+		w = gzip.NewWriter(dst)
+	case Zstd:
+		zw, err := zstd.NewWriter(dst)
+		if err != nil {
+			return err
+		}
+		w = zw
+	default:
+		return fmt.Errorf("unknown compression %q", c.Name)
+	}
+	if _, err := io.Copy(w, &ctxReader{ctx: ctx, r: src}); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// ctxReader stops a copy once ctx is done, so Ctrl-C interrupts compressing
+// a large file.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *ctxReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
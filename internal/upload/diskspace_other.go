@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !windows
+
+package upload
+
+import "errors"
+
+// freeSpace is not supported on this platform.
+func freeSpace(dir string) (int64, error) {
+	return 0, errors.ErrUnsupported
+}
@@ -0,0 +1,16 @@
+package upload
+
+import "golang.org/x/sys/windows"
+
+// freeSpace returns the bytes available to this user on dir's volume.
+func freeSpace(dir string) (int64, error) {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+	var avail, total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(path, &avail, &total, &free); err != nil {
+		return 0, err
+	}
+	return int64(avail), nil
+}
@@ -0,0 +1,49 @@
+package upload
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDownloadRangesStopsOtherRangesOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("APPDATA", dir)
+
+	started, stopped := make(chan struct{}), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") == "bytes=0-4" {
+			// The first range fails once the second is on its way.
+			<-started
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// The second range sends part of its bytes, then stalls until its
+		// request is cancelled.
+		w.Header().Set("Content-Range", "bytes 5-9/10")
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte("xx"))
+		w.(http.Flusher).Flush()
+		close(started)
+		select {
+		case <-r.Context().Done():
+			close(stopped)
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	if err := downloadRanges(context.Background(), srv.URL, filepath.Join(dir, "out"), 10, 2); err == nil {
+		t.Fatal("downloadRanges succeeded with a failing range")
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the second range was still downloading after downloadRanges returned")
+	}
+}
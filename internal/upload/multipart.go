@@ -2,43 +2,98 @@ package upload
 
 import (
 	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
+	"net"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
-)
 
-const (
-	maxConcurrentUploads = 2
-	maxRetries           = 8
-	retryDelayMS         = 2000
-	bodyTimeoutMS        = 300000
+	"github.com/sim4gh/nikte-cli/internal/config"
+	"github.com/sim4gh/nikte-cli/internal/models"
+	"github.com/sim4gh/nikte-cli/internal/retry"
+	"github.com/sim4gh/nikte-cli/internal/transport"
 )
 
 // PresignedURL represents a presigned URL for a part upload
-type PresignedURL struct {
-	PartNumber int    `json:"partNumber"`
-	URL        string `json:"url"`
-}
+type PresignedURL = models.PresignedURL
 
 // CompletedPart represents a completed part upload
-type CompletedPart struct {
-	PartNumber int    `json:"partNumber"`
-	ETag       string `json:"etag"`
+type CompletedPart = models.CompletedPart
+
+// Bounds for PartSizeFor. S3 allows parts of 5MB to 5GB; starting at 8MB
+// keeps small uploads to a part or two, and capping the count keeps
+// per-request overhead down on big files.
+const (
+	minAutoPartSize = 8 << 20
+	maxAutoPartSize = 5 << 30
+	maxAutoParts    = 100
+)
+
+// PartSizeFor picks the part size to request for a file of size bytes when
+// upload_part_size isn't set: 8MB, doubled until the file fits in at most
+// 100 parts. The server may still choose its own.
+func PartSizeFor(size int64) int64 {
+	partSize := int64(minAutoPartSize)
+	for partSize < maxAutoPartSize && (size+partSize-1)/partSize > maxAutoParts {
+		partSize *= 2
+	}
+	return min(partSize, maxAutoPartSize)
 }
 
 // ProgressCallback is called during upload with progress updates
 type ProgressCallback func(completed, total int, completedBytes, totalBytes int64)
 
-// UploadParts uploads file parts to S3 using presigned URLs
-func UploadParts(presignedUrls []PresignedURL, fileBuffer []byte, partSize int, onProgress ProgressCallback) ([]CompletedPart, error) {
+// PartCallback is called as each part finishes uploading.
+type PartCallback func(part CompletedPart)
+
+// Options are the optional hooks for UploadParts.
+type Options struct {
+	OnProgress ProgressCallback // progress while parts upload
+	OnPart     PartCallback     // each part as it completes
+	Refresh    URLRefresher     // fresh URLs when presigned ones expire; nil fails the upload instead
+}
+
+// UploadParts uploads file parts to S3 using presigned URLs, reading each
+// part of the size bytes in r as it is sent, so the file is never held in
+// memory whole. Only the parts in presignedUrls are sent, so a resumed
+// upload can pass just the missing ones; progress counts those alone.
+// Concurrency and retries follow the upload_concurrency, upload_retry_count
+// and retry_backoff settings. Cancelling ctx aborts the parts in flight. When a
+// part fails the others in flight are cancelled, and UploadParts returns only
+// once they have stopped, so the caller can abort the upload and close r.
+func UploadParts(ctx context.Context, presignedUrls []PresignedURL, r io.ReaderAt, size int64, partSize int, opts Options) ([]CompletedPart, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	urls := newURLSet(presignedUrls, opts.Refresh)
+	tuning := config.GetTuning()
+	maxConcurrentUploads := tuning.UploadConcurrency
 	totalParts := len(presignedUrls)
-	totalBytes := int64(len(fileBuffer))
+	var totalBytes int64
+	for _, pu := range presignedUrls {
+		start := int64(pu.PartNumber-1) * int64(partSize)
+		totalBytes += max(0, min(int64(partSize), size-start))
+	}
 	completedParts := make([]CompletedPart, 0, totalParts)
-	var completedBytes int64
+	// sent counts bytes as they are read for sending, so progress is
+	// reported while parts are in flight rather than only as they finish.
+	var sent atomic.Int64
+	tick := time.NewTicker(progressInterval)
+	defer tick.Stop()
+	report := func() {
+		if opts.OnProgress != nil {
+			opts.OnProgress(len(completedParts), totalParts, min(sent.Load(), totalBytes), totalBytes)
+		}
+	}
 
 	// Process uploads in batches to limit concurrency
 	for i := 0; i < len(presignedUrls); i += maxConcurrentUploads {
@@ -51,50 +106,71 @@ func UploadParts(presignedUrls []PresignedURL, fileBuffer []byte, partSize int,
 		// Upload batch in parallel
 		results := make(chan struct {
 			part CompletedPart
-			size int64
 			err  error
 		}, len(batch))
 
 		for idx, pu := range batch {
 			go func(pu PresignedURL, idx int) {
-				start := (pu.PartNumber - 1) * partSize
-				endIdx := start + partSize
-				if endIdx > len(fileBuffer) {
-					endIdx = len(fileBuffer)
-				}
-				partData := fileBuffer[start:endIdx]
+				start := int64(pu.PartNumber-1) * int64(partSize)
+				partData := io.NewSectionReader(r, start, min(int64(partSize), size-start))
 
 				// Small delay between starting concurrent uploads
+				var (
+					etag string
+					err  error
+				)
 				if idx > 0 {
-					time.Sleep(100 * time.Millisecond * time.Duration(idx))
+					err = retry.Sleep(ctx, 100*time.Millisecond*time.Duration(idx))
+				}
+				// A part whose URL expired gets a fresh one and one more go.
+				counter := &partCounter{sent: &sent}
+				for renewed := false; err == nil; renewed = true {
+					url, gen := urls.get(pu.PartNumber)
+					etag, err = uploadPart(ctx, url, partData, pu.PartNumber, tuning, counter)
+					if !errors.Is(err, ErrURLExpired) || renewed || opts.Refresh == nil {
+						break
+					}
+					if err = urls.renew(ctx, gen); err != nil {
+						err = fmt.Errorf("part %d: failed to refresh expired upload URLs: %w", pu.PartNumber, err)
+					}
 				}
-
-				etag, err := uploadPart(pu.URL, partData, pu.PartNumber)
 				results <- struct {
 					part CompletedPart
-					size int64
 					err  error
 				}{
 					part: CompletedPart{PartNumber: pu.PartNumber, ETag: etag},
-					size: int64(len(partData)),
 					err:  err,
 				}
 			}(pu, idx)
 		}
 
-		// Collect batch results
-		for range batch {
-			result := <-results
-			if result.err != nil {
-				return nil, result.err
-			}
-			completedParts = append(completedParts, result.part)
-			completedBytes += result.size
-
-			if onProgress != nil {
-				onProgress(len(completedParts), totalParts, completedBytes, totalBytes)
+		// Collect batch results, reporting progress as parts upload. After
+		// the first failure the rest are cancelled but still waited for.
+		var failed error
+		for pending := len(batch); pending > 0; {
+			select {
+			case <-tick.C:
+				report()
+			case result := <-results:
+				pending--
+				if result.err != nil {
+					if failed == nil {
+						failed = result.err
+						cancel()
+					}
+					continue
+				}
+				completedParts = append(completedParts, result.part)
+				urls.markDone(result.part.PartNumber)
+				if opts.OnPart != nil {
+					opts.OnPart(result.part)
+				}
+				report()
 			}
 		}
+		if failed != nil {
+			return nil, failed
+		}
 	}
 
 	// Sort by part number
@@ -103,63 +179,106 @@ func UploadParts(presignedUrls []PresignedURL, fileBuffer []byte, partSize int,
 	return completedParts, nil
 }
 
-func uploadPart(presignedURL string, data []byte, partNumber int) (string, error) {
+func uploadPart(ctx context.Context, presignedURL string, data *io.SectionReader, partNumber int, tuning config.Tuning, counter *partCounter) (string, error) {
 	var lastErr error
+	maxRetries := tuning.UploadRetryCount + 1
+	client := transport.StorageClient(tuning.UploadTimeout)
+
+	// Content-MD5 makes S3 reject a part corrupted in transit (BadDigest),
+	// so it is retried here rather than failing the complete call.
+	sum, err := partMD5(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to read part %d: %w", partNumber, err)
+	}
 
+	// wait is the delay before the next attempt, set by the failure of the
+	// one before. Cancelling ctx ends the wait and any attempt in flight.
+	var wait time.Duration
 	for attempt := 0; attempt < maxRetries; attempt++ {
-		client := &http.Client{
-			Timeout: time.Duration(bodyTimeoutMS) * time.Millisecond,
+		if err := retry.Sleep(ctx, wait); err != nil {
+			return "", err
 		}
 
-		req, err := http.NewRequest("PUT", presignedURL, bytes.NewReader(data))
+		req, err := http.NewRequestWithContext(ctx, "PUT", presignedURL, counter.reader(io.NewSectionReader(data, 0, data.Size())))
 		if err != nil {
-			lastErr = err
-			continue
+			return "", fmt.Errorf("failed to upload part %d: %w", partNumber, err)
 		}
 
-		req.Header.Set("Content-Length", fmt.Sprintf("%d", len(data)))
-		req.ContentLength = int64(len(data))
+		req.Header.Set("Content-Length", fmt.Sprintf("%d", data.Size()))
+		req.Header.Set("Content-MD5", sum)
+		req.ContentLength = data.Size()
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(counter.reader(io.NewSectionReader(data, 0, data.Size()))), nil
+		}
 
 		resp, err := client.Do(req)
 		if err != nil {
-			lastErr = err
-			isConnectionError := strings.Contains(err.Error(), "EPIPE") ||
-				strings.Contains(err.Error(), "ECONNRESET") ||
-				strings.Contains(err.Error(), "timeout")
-
-			baseDelay := time.Duration(retryDelayMS) * time.Millisecond
-			if isConnectionError {
-				baseDelay *= 3
+			if ctx.Err() != nil {
+				return "", ctx.Err()
 			}
-
-			if attempt < maxRetries-1 {
-				time.Sleep(baseDelay * time.Duration(attempt+1))
+			lastErr = err
+			// A dropped or stalled connection usually means a congested
+			// link, so back off harder than for a server error.
+			base := tuning.RetryBackoff
+			if connectionError(err) {
+				base *= 3
 			}
+			wait = retry.Backoff(base, attempt)
 			continue
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != 200 {
-			body, _ := io.ReadAll(resp.Body)
-			lastErr = fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(body))
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+			lastErr = fmt.Errorf("upload failed with status %d [request id %s]: %s",
+				resp.StatusCode, transport.RequestID(resp), string(body))
+			if urlExpired(resp.StatusCode, body) {
+				return "", fmt.Errorf("failed to upload part %d: %w", partNumber, ErrURLExpired)
+			}
 
-			if attempt < maxRetries-1 {
-				time.Sleep(time.Duration(retryDelayMS) * time.Millisecond * time.Duration(attempt+1))
+			var ok bool
+			if wait, ok = retry.Delay(resp, tuning.RetryBackoff, attempt); !ok {
+				return "", fmt.Errorf("failed to upload part %d: %w", partNumber, lastErr)
 			}
 			continue
 		}
 
 		// Get ETag from response headers
+		transport.DrainAndClose(resp.Body)
 		etag := resp.Header.Get("ETag")
 		if etag == "" {
 			lastErr = fmt.Errorf("no ETag in response headers")
+			wait = retry.Backoff(tuning.RetryBackoff, attempt)
 			continue
 		}
 
 		return etag, nil
 	}
 
-	return "", fmt.Errorf("failed to upload part %d after %d attempts: %v", partNumber, maxRetries, lastErr)
+	return "", fmt.Errorf("failed to upload part %d after %d attempts: %w", partNumber, maxRetries, lastErr)
+}
+
+// urlExpired reports whether storage rejected a part because its presigned
+// URL has expired: S3 answers 403 AccessDenied, "Request has expired".
+func urlExpired(status int, body []byte) bool {
+	return status == http.StatusForbidden && bytes.Contains(bytes.ToLower(body), []byte("expired"))
+}
+
+// connectionError reports whether err is a reset, broken or timed-out
+// connection rather than a failure to connect at all.
+func connectionError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) ||
+		(errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// partMD5 returns the base64 MD5 digest of a part, as Content-MD5 expects.
+func partMD5(data *io.SectionReader) (string, error) {
+	h := md5.New()
+	if _, err := io.Copy(h, io.NewSectionReader(data, 0, data.Size())); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
 }
 
 func sortParts(parts []CompletedPart) {
@@ -175,16 +294,51 @@ func sortParts(parts []CompletedPart) {
 	}
 }
 
-// GetMimeType returns the MIME type for a file based on its extension
+// GetMimeType returns the MIME type for a file. The extension decides it
+// unless the file's first bytes say otherwise: extensionless files and
+// unknown extensions get the type http.DetectContentType finds, and so does
+// a file whose content is clearly media of another kind, like a PNG saved
+// as .txt.
 func GetMimeType(filePath string) string {
-	ext := filepath.Ext(filePath)
-	mimeType := mime.TypeByExtension(ext)
-	if mimeType == "" {
+	byExt := mime.TypeByExtension(filepath.Ext(filePath))
+	// Remove charset suffix if present
+	if idx := strings.Index(byExt, ";"); idx != -1 {
+		byExt = strings.TrimSpace(byExt[:idx])
+	}
+
+	sniffed := sniffMimeType(filePath)
+	if byExt == "" {
+		return sniffed
+	}
+	// Only media and PDF signatures are trusted over an extension: container
+	// formats are too generic (every .docx sniffs as a ZIP).
+	top := topLevelType(sniffed)
+	if top != topLevelType(byExt) && (top == "image" || top == "audio" || top == "video" || sniffed == "application/pdf") {
+		return sniffed
+	}
+	return byExt
+}
+
+// sniffMimeType returns the type http.DetectContentType finds in the start
+// of the file, without parameters. It falls back to
+// application/octet-stream if the file can't be read.
+func sniffMimeType(filePath string) string {
+	f, err := os.Open(filePath)
+	if err != nil {
 		return "application/octet-stream"
 	}
-	// Remove charset suffix if present
-	if idx := strings.Index(mimeType, ";"); idx != -1 {
-		mimeType = strings.TrimSpace(mimeType[:idx])
+	defer f.Close()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	detected := http.DetectContentType(head[:n])
+	if idx := strings.Index(detected, ";"); idx != -1 {
+		detected = strings.TrimSpace(detected[:idx])
 	}
-	return mimeType
+	return detected
+}
+
+// topLevelType returns the part of a MIME type before the slash.
+func topLevelType(mimeType string) string {
+	top, _, _ := strings.Cut(mimeType, "/")
+	return top
 }
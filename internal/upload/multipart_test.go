@@ -0,0 +1,51 @@
+package upload
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUploadPartsStopsOtherPartsOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("APPDATA", dir)
+
+	started, stopped := make(chan struct{}), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/part1" {
+			// Part 1 fails once part 2 is on its way, with a Retry-After
+			// too long to wait out.
+			<-started
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		// Part 2 hangs until its request is cancelled, which the server
+		// only notices once the body has been read.
+		io.Copy(io.Discard, r.Body)
+		close(started)
+		select {
+		case <-r.Context().Done():
+			close(stopped)
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	data := strings.NewReader(strings.Repeat("x", 10))
+	urls := []PresignedURL{{PartNumber: 1, URL: srv.URL + "/part1"}, {PartNumber: 2, URL: srv.URL + "/part2"}}
+	if _, err := UploadParts(context.Background(), urls, data, data.Size(), 5, Options{}); err == nil {
+		t.Fatal("UploadParts succeeded with a failing part")
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("part 2 was still uploading after UploadParts returned")
+	}
+}
@@ -0,0 +1,72 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrURLExpired is returned for a part whose presigned URL expired before
+// it was uploaded.
+var ErrURLExpired = errors.New("presigned URL expired")
+
+// URLRefresher presigns fresh URLs for the given parts of an upload.
+type URLRefresher func(ctx context.Context, partNumbers []int) ([]PresignedURL, error)
+
+// urlSet hands out the URL for each part. URLs are presigned together at
+// init, so when one expires the rest soon follow; renew replaces every URL
+// not yet used in one request.
+type urlSet struct {
+	mu      sync.Mutex
+	urls    map[int]string
+	done    map[int]bool
+	gen     int // bumped on each renewal
+	refresh URLRefresher
+}
+
+func newURLSet(presigned []PresignedURL, refresh URLRefresher) *urlSet {
+	u := &urlSet{urls: make(map[int]string, len(presigned)), done: make(map[int]bool), refresh: refresh}
+	for _, pu := range presigned {
+		u.urls[pu.PartNumber] = pu.URL
+	}
+	return u
+}
+
+// get returns the URL for part and the renewal it came from.
+func (u *urlSet) get(part int) (string, int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.urls[part], u.gen
+}
+
+// markDone records that part has uploaded, so renew skips it.
+func (u *urlSet) markDone(part int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.done[part] = true
+}
+
+// renew replaces the URLs of every part not yet uploaded, unless that has
+// already happened since renewal gen, when another part saw the expiry first.
+func (u *urlSet) renew(ctx context.Context, gen int) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.gen != gen {
+		return nil
+	}
+	var missing []int
+	for part := range u.urls {
+		if !u.done[part] {
+			missing = append(missing, part)
+		}
+	}
+	fresh, err := u.refresh(ctx, missing)
+	if err != nil {
+		return err
+	}
+	for _, pu := range fresh {
+		u.urls[pu.PartNumber] = pu.URL
+	}
+	u.gen++
+	return nil
+}
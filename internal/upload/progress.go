@@ -0,0 +1,112 @@
+package upload
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/util"
+)
+
+// progressInterval is how often UploadParts reports progress while parts
+// are in flight.
+const progressInterval = 250 * time.Millisecond
+
+// rateWindow is how far back Meter looks for the current transfer rate.
+const rateWindow = 5 * time.Second
+
+// Meter turns progress updates into transfer rates and an estimated time
+// remaining. It is not safe for concurrent use; UploadParts calls its
+// ProgressCallback from one goroutine.
+type Meter struct {
+	total   int64
+	start   time.Time
+	samples []sample
+}
+
+type sample struct {
+	at   time.Time
+	done int64
+}
+
+// NewMeter returns a Meter for a transfer of total bytes, starting now.
+func NewMeter(total int64) *Meter {
+	now := time.Now()
+	return &Meter{total: total, start: now, samples: []sample{{now, 0}}}
+}
+
+// Update records that done bytes have been transferred so far.
+func (m *Meter) Update(done int64) {
+	now := time.Now()
+	m.samples = append(m.samples, sample{now, done})
+	// Keep one sample older than the window so Rate always spans it.
+	for len(m.samples) > 2 && now.Sub(m.samples[1].at) > rateWindow {
+		m.samples = m.samples[1:]
+	}
+}
+
+// Rate returns the bytes per second over the last few seconds.
+func (m *Meter) Rate() float64 {
+	first, last := m.samples[0], m.samples[len(m.samples)-1]
+	if secs := last.at.Sub(first.at).Seconds(); secs > 0 {
+		return float64(last.done-first.done) / secs
+	}
+	return 0
+}
+
+// Average returns the bytes per second since the transfer started.
+func (m *Meter) Average() float64 {
+	last := m.samples[len(m.samples)-1]
+	if secs := last.at.Sub(m.start).Seconds(); secs > 0 {
+		return float64(last.done) / secs
+	}
+	return 0
+}
+
+// ETA estimates the time left from the current rate. It returns false until
+// there is a rate to go by.
+func (m *Meter) ETA() (time.Duration, bool) {
+	rate := m.Rate()
+	if rate <= 0 {
+		return 0, false
+	}
+	left := m.total - m.samples[len(m.samples)-1].done
+	return time.Duration(float64(left) / rate * float64(time.Second)).Round(time.Second), true
+}
+
+// String renders the rates and time left for a spinner, like
+// "2.1 MB/s (avg 1.8 MB/s), 42s left".
+func (m *Meter) String() string {
+	s := fmt.Sprintf("%s/s (avg %s/s)", util.FormatBytes(int64(m.Rate())), util.FormatBytes(int64(m.Average())))
+	if eta, ok := m.ETA(); ok {
+		s += fmt.Sprintf(", %s left", eta)
+	}
+	return s
+}
+
+// partCounter counts the bytes of a part read for sending into sent, so
+// progress moves while a part is uploading. Each attempt takes back what the
+// one before it counted.
+type partCounter struct {
+	sent *atomic.Int64
+	read atomic.Int64
+}
+
+// reader returns r counted as a new attempt at sending the part.
+func (c *partCounter) reader(r io.Reader) io.Reader {
+	c.sent.Add(-c.read.Swap(0))
+	return &countingReader{r: r, c: c}
+}
+
+type countingReader struct {
+	r io.Reader
+	c *partCounter
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.c.read.Add(int64(n))
+	cr.c.sent.Add(int64(n))
+	return n, err
+}
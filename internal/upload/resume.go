@@ -0,0 +1,151 @@
+package upload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sim4gh/nikte-cli/internal/config"
+)
+
+// ResumeState records a multipart upload in progress, so running the same
+// upload again after it was interrupted sends only the parts still missing.
+// It is tied to the file's path, size and modification time; editing the
+// file starts a fresh upload.
+type ResumeState struct {
+	ShortID    string          `json:"shortId"`
+	Path       string          `json:"path"`
+	Size       int64           `json:"size"`
+	ModTime    time.Time       `json:"modTime"`
+	PartSize   int             `json:"partSize"`
+	TotalParts int             `json:"totalParts"`
+	ExpiresAt  int64           `json:"expiresAt,omitempty"`
+	Parts      []CompletedPart `json:"parts"`
+
+	mu   sync.Mutex
+	file string
+}
+
+// resumeFile returns where the state for the upload of path is kept. Files
+// are kept per profile so one account never resumes another's upload.
+func resumeFile(path string) (string, bool) {
+	dir, err := config.UploadStateDir()
+	if err != nil {
+		return "", false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(config.ActiveProfile() + "\x00" + abs))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json"), true
+}
+
+// NewResumeState starts tracking an upload of the file at path described by
+// fi. Nothing is written until the first part completes.
+func NewResumeState(path string, fi os.FileInfo, shortID string, partSize, totalParts int, expiresAt int64) *ResumeState {
+	file, _ := resumeFile(path)
+	return &ResumeState{
+		ShortID:    shortID,
+		Path:       path,
+		Size:       fi.Size(),
+		ModTime:    fi.ModTime(),
+		PartSize:   partSize,
+		TotalParts: totalParts,
+		ExpiresAt:  expiresAt,
+		file:       file,
+	}
+}
+
+// LoadResumeState returns the saved state for an earlier upload of the file
+// at path, or nil if there is none or the file has changed since. State
+// for a changed file, or for an item that has since expired, is removed.
+func LoadResumeState(path string, fi os.FileInfo) *ResumeState {
+	file, ok := resumeFile(path)
+	if !ok {
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var s ResumeState
+	if err := json.Unmarshal(data, &s); err != nil || s.ShortID == "" || s.PartSize <= 0 {
+		os.Remove(file)
+		return nil
+	}
+	s.file = file
+	if s.Size != fi.Size() || !s.ModTime.Equal(fi.ModTime()) ||
+		(s.ExpiresAt > 0 && time.Now().Unix() >= s.ExpiresAt) {
+		s.Remove()
+		return nil
+	}
+	return &s
+}
+
+// Missing returns the part numbers not uploaded yet, in order.
+func (s *ResumeState) Missing() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	done := make(map[int]bool, len(s.Parts))
+	for _, p := range s.Parts {
+		done[p.PartNumber] = true
+	}
+	var missing []int
+	for n := 1; n <= s.TotalParts; n++ {
+		if !done[n] {
+			missing = append(missing, n)
+		}
+	}
+	return missing
+}
+
+// Completed returns the parts uploaded so far, sorted by part number.
+func (s *ResumeState) Completed() []CompletedPart {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	parts := append([]CompletedPart(nil), s.Parts...)
+	sortParts(parts)
+	return parts
+}
+
+// Add records a finished part and saves the state. Failing to save only
+// means the part is sent again on resume, so errors are ignored.
+func (s *ResumeState) Add(part CompletedPart) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Parts = append(s.Parts, part)
+	s.save()
+}
+
+func (s *ResumeState) save() {
+	if s.file == "" {
+		return
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(s.file), 0700); err != nil {
+		return
+	}
+	tmp := s.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, s.file); err != nil {
+		os.Remove(tmp)
+	}
+}
+
+// Remove deletes the saved state, once the upload has completed or can no
+// longer be resumed.
+func (s *ResumeState) Remove() {
+	if s.file != "" {
+		os.Remove(s.file)
+	}
+}
@@ -0,0 +1,52 @@
+package upload
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CompositeETag returns the ETag S3 gives an object uploaded in parts of
+// partSize from the size bytes in r: the hex MD5 of the parts' binary MD5s,
+// then "-" and the part count.
+func CompositeETag(r io.ReaderAt, size int64, partSize int) (string, error) {
+	if partSize <= 0 {
+		return "", fmt.Errorf("invalid part size %d", partSize)
+	}
+	var sums []byte
+	parts := 0
+	for start := int64(0); start < size; start += int64(partSize) {
+		h := md5.New()
+		if _, err := io.Copy(h, io.NewSectionReader(r, start, min(int64(partSize), size-start))); err != nil {
+			return "", err
+		}
+		sums = h.Sum(sums)
+		parts++
+	}
+	sum := md5.Sum(sums)
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), parts), nil
+}
+
+// VerifyETag checks the ETag reported for an object uploaded in parts of
+// partSize from the size bytes in r: a composite ETag for a multipart
+// object, or a plain MD5 otherwise. It returns the ETag the data should
+// have, and whether etag matches it, ignoring quotes and case.
+func VerifyETag(r io.ReaderAt, size int64, partSize int, etag string) (string, bool, error) {
+	etag = strings.ToLower(strings.Trim(etag, `"`))
+	var want string
+	if strings.Contains(etag, "-") {
+		var err error
+		if want, err = CompositeETag(r, size, partSize); err != nil {
+			return "", false, err
+		}
+	} else {
+		h := md5.New()
+		if _, err := io.Copy(h, io.NewSectionReader(r, 0, size)); err != nil {
+			return "", false, err
+		}
+		want = hex.EncodeToString(h.Sum(nil))
+	}
+	return want, etag == want, nil
+}
@@ -0,0 +1,24 @@
+package upload
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyETag(t *testing.T) {
+	data := strings.NewReader("hello world")
+	for _, tc := range []struct {
+		etag string
+		want string
+		ok   bool
+	}{
+		{`"df349a9519959b17a605009540f4b31d-3"`, "df349a9519959b17a605009540f4b31d-3", true},
+		{"5eb63bbbe01eeed093cb22bb8f5acdc3", "5eb63bbbe01eeed093cb22bb8f5acdc3", true},
+		{"00000000000000000000000000000000-3", "df349a9519959b17a605009540f4b31d-3", false},
+	} {
+		want, ok, err := VerifyETag(data, data.Size(), 5, tc.etag)
+		if err != nil || want != tc.want || ok != tc.ok {
+			t.Errorf("VerifyETag(%s) = %q, %v, %v; want %q, %v", tc.etag, want, ok, err, tc.want, tc.ok)
+		}
+	}
+}
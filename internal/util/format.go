@@ -28,6 +28,29 @@ func FormatBytes(bytes int64) string {
 	return fmt.Sprintf("%.0f %s", value, units[i])
 }
 
+// ParseBytes parses a size such as "8MB", "512KB", "1.5GB" or a plain byte
+// count. Units are binary (1KB = 1024 bytes), matching FormatBytes.
+func ParseBytes(s string) (int64, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{{"GIB", 1 << 30}, {"MIB", 1 << 20}, {"KIB", 1 << 10}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(str, u.suffix) {
+			str = strings.TrimSpace(strings.TrimSuffix(str, u.suffix))
+			multiplier = u.mult
+			break
+		}
+	}
+
+	var value float64
+	if _, err := fmt.Sscanf(str, "%g", &value); err != nil || value < 0 || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
 // Truncate truncates text to specified length with ellipsis
 func Truncate(text string, length int) string {
 	if text == "" {
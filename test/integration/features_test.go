@@ -22,7 +22,7 @@ func accessShare(t *testing.T, shareID string) int {
 	}
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "nikte-integration/1.0")
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := useCassette(t).Client().Do(req)
 	if err != nil {
 		t.Fatalf("share access failed: %v", err)
 	}
@@ -56,10 +56,20 @@ func createPublicShare(t *testing.T, shortID string, maxViews int) string {
 // CLI can decrypt the retrieved blob.
 func TestEncryptedShortRoundTrip(t *testing.T) {
 	ensureAuth(t)
-	plaintext := fmt.Sprintf("integration secret %d", time.Now().UnixNano())
+	plaintext := testValue(t, "plaintext", func() string {
+		return fmt.Sprintf("integration secret %d", time.Now().UnixNano())
+	})
 	pass := "integration-passphrase"
 
-	blob, err := crypto.EncryptText(pass, plaintext)
+	// Encryption is randomized, so a replay must send the recorded blob.
+	var err error
+	blob := testValue(t, "blob", func() string {
+		var b string
+		if b, err = crypto.EncryptText(pass, plaintext); err != nil {
+			return ""
+		}
+		return b
+	})
 	if err != nil {
 		t.Fatalf("encrypt: %v", err)
 	}
@@ -105,7 +115,7 @@ func TestBurnAfterRead(t *testing.T) {
 		t.Fatalf("first public access: expected 200, got %d", code)
 	}
 	// Allow the burn (delete) to settle.
-	time.Sleep(1500 * time.Millisecond)
+	pause(1500 * time.Millisecond)
 
 	if code := accessShare(t, shareID); code != 404 {
 		t.Fatalf("second public access: expected 404 (burned), got %d", code)
@@ -129,7 +139,7 @@ func TestShareViewAnalytics(t *testing.T) {
 
 	accessShare(t, shareID)
 	accessShare(t, shareID)
-	time.Sleep(1 * time.Second)
+	pause(1 * time.Second)
 
 	resp, err := api.Get("/shares")
 	if err != nil {
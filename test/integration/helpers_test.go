@@ -3,16 +3,54 @@
 package integration
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/sim4gh/nikte-cli/internal/api"
 	"github.com/sim4gh/nikte-cli/internal/auth"
 	"github.com/sim4gh/nikte-cli/internal/config"
 )
 
+// mode is how API requests are served: sent live (the default), recorded
+// from the live API into testdata cassettes, or replayed from them.
+var mode api.RecordMode
+
 func TestMain(m *testing.M) {
+	var err error
+	if mode, err = api.RecordModeFromEnv(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if mode == api.ModeReplay {
+		// Replays need no account: keep the developer's config and tokens
+		// out of it, and fail a missing response at once instead of retrying.
+		dir, err := os.MkdirTemp("", "nikte-replay")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Setenv("XDG_CONFIG_HOME", dir)
+		os.Setenv("HOME", dir)
+		os.Setenv("APPDATA", dir)
+		os.Setenv("NIKTE_TOKEN", "replay")
+		if _, err := config.Load(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+			os.Exit(1)
+		}
+		config.Set("retry_count", "0")
+		config.Set("upload_retry_count", "0")
+		code := m.Run()
+		os.RemoveAll(dir)
+		os.Exit(code)
+	}
+
 	// Initialize config singleton
 	if _, err := config.Load(); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
@@ -33,7 +71,7 @@ func TestMain(m *testing.M) {
 	}
 
 	// Validate auth works by refreshing tokens
-	if _, err := auth.RefreshTokens(); err != nil {
+	if _, err := auth.RefreshTokens(context.Background()); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to refresh tokens: %v\n", err)
 		os.Exit(1)
 	}
@@ -41,15 +79,74 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
-// ensureAuth refreshes tokens if needed and skips the test if auth is unavailable.
+// recorders holds the recorder installed for each running top-level test.
+var recorders = map[string]*api.Recorder{}
+
+// useCassette routes the API client through the recorder for t's
+// testdata/<TestName>.json cassette. Replaying a cassette that hasn't been
+// recorded skips t locally and fails it in CI (CI set), so a job can't pass
+// by skipping everything. Calling it again within the same test returns the
+// same recorder.
+func useCassette(t *testing.T) *api.Recorder {
+	t.Helper()
+	name, _, _ := strings.Cut(t.Name(), "/")
+	if rec, ok := recorders[name]; ok {
+		return rec
+	}
+	rec, err := api.NewRecorder(filepath.Join("testdata", name+".json"), mode)
+	if errors.Is(err, os.ErrNotExist) {
+		msg := fmt.Sprintf("no cassette for %s; record one with: make test-integration-record", name)
+		if os.Getenv("CI") != "" {
+			t.Fatal(msg)
+		}
+		t.Skip(msg)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := api.DefaultClient
+	api.SetClient(rec.Client())
+	recorders[name] = rec
+	// Registered before the test's own cleanups, so it runs after them and
+	// the cassette includes their deletes.
+	t.Cleanup(func() {
+		if err := rec.Save(); err != nil {
+			t.Errorf("save cassette: %v", err)
+		}
+		api.SetClient(old)
+		delete(recorders, name)
+	})
+	return rec
+}
+
+// testValue returns gen(), or the value it returned when t's cassette was
+// recorded, so replayed requests carry the content the responses echo.
+func testValue(t *testing.T, name string, gen func() string) string {
+	t.Helper()
+	return useCassette(t).Value(name, gen)
+}
+
+// pause waits d for the live API to settle; replays don't need to.
+func pause(d time.Duration) {
+	if mode != api.ModeReplay {
+		time.Sleep(d)
+	}
+}
+
+// ensureAuth installs t's cassette and, unless replaying, refreshes tokens
+// if needed, skipping the test if auth is unavailable.
 func ensureAuth(t *testing.T) {
 	t.Helper()
+	useCassette(t)
+	if mode == api.ModeReplay {
+		return
+	}
 	cfg := config.Get()
 	if cfg == nil || cfg.RefreshToken == "" {
 		t.Skip("no refresh token available")
 	}
 	if auth.IsTokenExpired(cfg.IDToken) {
-		if _, err := auth.RefreshTokens(); err != nil {
+		if _, err := auth.RefreshTokens(context.Background()); err != nil {
 			t.Fatalf("failed to refresh tokens: %v", err)
 		}
 	}
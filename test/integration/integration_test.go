@@ -13,6 +13,7 @@ import (
 )
 
 func TestHealthEndpoint(t *testing.T) {
+	useCassette(t)
 	resp, err := api.GetNoAuth("/health")
 	if err != nil {
 		t.Fatalf("health request failed: %v", err)
@@ -22,7 +23,9 @@ func TestHealthEndpoint(t *testing.T) {
 
 func TestShortsCRUDLifecycle(t *testing.T) {
 	ensureAuth(t)
-	content := fmt.Sprintf("integration-test-%d", time.Now().UnixNano())
+	content := testValue(t, "content", func() string {
+		return fmt.Sprintf("integration-test-%d", time.Now().UnixNano())
+	})
 
 	var shortID string
 